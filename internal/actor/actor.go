@@ -0,0 +1,42 @@
+// Package actor carries who is acting, and in which request, through a
+// context.Context, so the repositories can stamp audit records without
+// threading an admin id through every call.
+package actor
+
+import "context"
+
+type contextKey struct{}
+
+type requestKey struct{}
+
+// Key is the context value key of the acting admin id. The JWT middleware sets
+// it as a fiber local, which fiber exposes through c.Context().Value(Key).
+var Key any = contextKey{}
+
+// RequestKey is the context value key of the request id, set the same way by
+// the requestid middleware.
+var RequestKey any = requestKey{}
+
+// WithAdminID returns ctx acting as the admin id (e.g. for jobs and tests).
+func WithAdminID(ctx context.Context, adminID string) context.Context {
+	return context.WithValue(ctx, Key, adminID)
+}
+
+// AdminID returns the acting admin id, or "" for system work (background
+// jobs) and unauthenticated requests.
+func AdminID(ctx context.Context) string {
+	id, _ := ctx.Value(Key).(string)
+	return id
+}
+
+// WithRequestID returns ctx carrying the request id.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, RequestKey, requestID)
+}
+
+// RequestID returns the id of the HTTP request being served, or "" outside
+// of one.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(RequestKey).(string)
+	return id
+}
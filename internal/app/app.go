@@ -1,19 +1,34 @@
 package app
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/riolentius/cahaya-gading-backend/internal/actor"
 	"github.com/riolentius/cahaya-gading-backend/internal/config"
 	"github.com/riolentius/cahaya-gading-backend/internal/db"
 	httpdelivery "github.com/riolentius/cahaya-gading-backend/internal/delivery/http"
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+	txuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
+	"github.com/riolentius/cahaya-gading-backend/internal/worker"
 )
 
 type App struct {
-	f *fiber.App
+	f   *fiber.App
+	cfg config.Config
+
+	reservations *worker.ReservationExpiry // nil when RESERVATION_TTL_MINUTES=0
 }
 
 func New() *App {
@@ -29,13 +44,66 @@ func New() *App {
 	})
 
 	f.Use(recover.New())
+	// X-Request-ID is echoed back and stamped on audit records
+	f.Use(requestid.New(requestid.Config{ContextKey: actor.RequestKey}))
 	f.Use(logger.New())
 
 	httpdelivery.RegisterRoutes(f, cfg, pool)
 
-	return &App{f: f}
+	return &App{f: f, cfg: cfg, reservations: newReservationExpiry(cfg, pool)}
 }
 
+// Run serves until the listener fails or SIGINT/SIGTERM arrives. On a signal
+// the reservation sweeper is cancelled and waited for before the server shuts
+// down, so no sweep is left half-way through.
 func (a *App) Run() error {
-	return a.f.Listen(":" + config.Load().Port)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	workerCtx, cancelWorker := context.WithCancel(ctx)
+	defer cancelWorker()
+
+	var wg sync.WaitGroup
+	if a.reservations != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.reservations.Run(workerCtx)
+		}()
+	}
+
+	listenErr := make(chan error, 1)
+	go func() { listenErr <- a.f.Listen(":" + a.cfg.Port) }()
+
+	select {
+	case err := <-listenErr:
+		cancelWorker()
+		wg.Wait()
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("shutting down")
+	cancelWorker()
+	wg.Wait()
+	if err := a.f.Shutdown(); err != nil {
+		return err
+	}
+	return <-listenErr
+}
+
+func newReservationExpiry(cfg config.Config, pool *pgxpool.Pool) *worker.ReservationExpiry {
+	if cfg.ReservationTTLMinutes <= 0 {
+		return nil
+	}
+	sweep := cfg.ReservationSweepSeconds
+	if sweep <= 0 {
+		sweep = 60
+	}
+
+	uc := txuc.New(trxpg.NewTransactionStoreAdapter(trxpg.NewTransactionRepo(pool), pool))
+	return worker.NewReservationExpiry(pool, uc,
+		time.Duration(cfg.ReservationTTLMinutes)*time.Minute,
+		time.Duration(sweep)*time.Second,
+	)
 }
@@ -13,6 +13,18 @@ type Config struct {
 	DatabaseURL       string
 	JWTSecret         string
 	JWTExpiresMinutes int
+
+	IdempotencyTTLHours int
+
+	// reporting currency; amounts in other currencies are converted with exchange_rates
+	BaseCurrency string
+
+	// when set, payments and receipts can only be posted into the admin's open cash shift
+	ShiftsEnabled bool
+
+	// pending transactions holding stock longer than this are cancelled; 0 disables
+	ReservationTTLMinutes   int
+	ReservationSweepSeconds int
 }
 
 func Load() Config {
@@ -22,6 +34,11 @@ func Load() Config {
 	dbURL := getEnv("DATABASE_URL", "")
 	jwtSecret := getEnv("JWT_SECRET", "dev-secret-change-me")
 	jwtExp := getEnvInt("JWT_EXPIRES_MINUTES", 60)
+	idemTTL := getEnvInt("IDEMPOTENCY_TTL_HOURS", 24)
+	baseCurrency := getEnv("BASE_CURRENCY", "IDR")
+	shiftsEnabled := getEnvBool("SHIFTS_ENABLED", false)
+	reservationTTL := getEnvInt("RESERVATION_TTL_MINUTES", 0)
+	reservationSweep := getEnvInt("RESERVATION_SWEEP_SECONDS", 60)
 
 	if dbURL == "" {
 		log.Fatal("DATABASE_URL is required")
@@ -32,6 +49,15 @@ func Load() Config {
 		DatabaseURL:       dbURL,
 		JWTSecret:         jwtSecret,
 		JWTExpiresMinutes: jwtExp,
+
+		IdempotencyTTLHours: idemTTL,
+
+		BaseCurrency: baseCurrency,
+
+		ShiftsEnabled: shiftsEnabled,
+
+		ReservationTTLMinutes:   reservationTTL,
+		ReservationSweepSeconds: reservationSweep,
 	}
 }
 
@@ -54,3 +80,15 @@ func getEnvInt(key string, def int) int {
 	}
 	return n
 }
+
+func getEnvBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Advisory lock keys; one per singleton job across app instances.
+const (
+	LockReservationExpiry int64 = 45001
+)
+
+// WithAdvisoryLock runs fn only if this process obtains the session-level
+// advisory lock key, so among several instances one does the work and the
+// others skip. The lock lives on a dedicated connection and is released when
+// fn returns (or the connection drops). Reports whether fn ran.
+func WithAdvisoryLock(ctx context.Context, pool *pgxpool.Pool, key int64, fn func(ctx context.Context) error) (bool, error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	defer func() {
+		// the caller's ctx may be cancelled by now; unlock regardless
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, key)
+	}()
+
+	return true, fn(ctx)
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	audituc "github.com/riolentius/cahaya-gading-backend/internal/usecase/audit"
+)
+
+type Handler struct {
+	uc *audituc.Usecase
+}
+
+func New(uc *audituc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// List: GET /audit?actorId=&entity=&entityId=&action=&requestId=&from=&to=&limit=&offset=
+// from/to are RFC3339 timestamps or YYYY-MM-DD dates (to is exclusive).
+func (h *Handler) List(c *fiber.Ctx) error {
+	q := audituc.ListQuery{
+		Limit:  c.QueryInt("limit", 50),
+		Offset: c.QueryInt("offset", 0),
+	}
+	if v := c.Query("actorId"); v != "" {
+		q.ActorID = &v
+	}
+	if v := c.Query("entity"); v != "" {
+		q.Entity = &v
+	}
+	if v := c.Query("entityId"); v != "" {
+		q.EntityID = &v
+	}
+	if v := c.Query("action"); v != "" {
+		q.Action = &v
+	}
+	if v := c.Query("requestId"); v != "" {
+		q.RequestID = &v
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := parseAuditTime(v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid from")
+		}
+		q.From = &t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := parseAuditTime(v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid to")
+		}
+		q.To = &t
+	}
+
+	out, err := h.uc.List(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) GetByID(c *fiber.Ctx) error {
+	out, err := h.uc.GetByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func parseAuditTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", v, time.Local)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, audituc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, audituc.ErrNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,41 @@
+package credit
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	credituc "github.com/riolentius/cahaya-gading-backend/internal/usecase/credit"
+)
+
+type Handler struct {
+	uc *credituc.Usecase
+}
+
+func New(uc *credituc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+func (h *Handler) GetForCustomer(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+
+	out, err := h.uc.GetForCustomer(c.Context(), customerID, credituc.HistoryQuery{
+		Limit:  c.QueryInt("limit", 50),
+		Offset: c.QueryInt("offset", 0),
+	})
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, credituc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, credituc.ErrCustomerMissing):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
@@ -26,8 +26,14 @@ func (h *Handler) Create(c *fiber.Ctx) error {
 func (h *Handler) List(c *fiber.Ctx) error {
 	limit := c.QueryInt("limit", 50)
 	offset := c.QueryInt("offset", 0)
+	includeArchived := c.QueryBool("includeArchived", false)
 
-	out, err := h.uc.List(c.Context(), customeruc.ListQuery{Limit: limit, Offset: offset})
+	q := customeruc.ListQuery{IncludeArchived: includeArchived, Limit: limit, Offset: offset}
+	if v := c.Query("search"); v != "" {
+		q.Search = &v
+	}
+
+	out, err := h.uc.List(c.Context(), q)
 	if err != nil {
 		return mapErr(c, err)
 	}
@@ -52,6 +58,32 @@ func (h *Handler) Update(c *fiber.Ctx) error {
 	return writeOne(c, out, err, fiber.StatusOK)
 }
 
+// Archive: DELETE /customers/:id
+func (h *Handler) Archive(c *fiber.Ctx) error {
+	out, err := h.uc.Archive(c.Context(), c.Params("id"))
+	return writeOne(c, out, err, fiber.StatusOK)
+}
+
+// Restore: POST /customers/:id/restore
+func (h *Handler) Restore(c *fiber.Ctx) error {
+	out, err := h.uc.Restore(c.Context(), c.Params("id"))
+	return writeOne(c, out, err, fiber.StatusOK)
+}
+
+// Merge: POST /customers/:id/merge {"duplicateId": "..."} folds the duplicate into :id
+func (h *Handler) Merge(c *fiber.Ctx) error {
+	var in customeruc.MergeInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	out, err := h.uc.Merge(c.Context(), c.Params("id"), in)
+	if err != nil {
+		return mapErr(c, err)
+	}
+	return c.JSON(out)
+}
+
 func writeOne(c *fiber.Ctx, out *customeruc.Customer, err error, okStatus int) error {
 	if err != nil {
 		return mapErr(c, err)
@@ -65,7 +97,7 @@ func mapErr(c *fiber.Ctx, err error) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	case customeruc.ErrNotFound:
 		return fiber.NewError(fiber.StatusNotFound, err.Error())
-	case customeruc.ErrEmailConflict:
+	case customeruc.ErrEmailConflict, customeruc.ErrAlreadyMerged, customeruc.ErrArchived:
 		return fiber.NewError(fiber.StatusConflict, err.Error())
 	default:
 		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
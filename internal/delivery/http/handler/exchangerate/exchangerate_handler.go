@@ -0,0 +1,86 @@
+package exchangerate
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	fxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/exchangerate"
+)
+
+type Handler struct {
+	uc *fxuc.Usecase
+}
+
+func New(uc *fxuc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+func (h *Handler) Create(c *fiber.Ctx) error {
+	var in fxuc.CreateInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	out, err := h.uc.Create(c.Context(), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+// List: GET /exchange-rates?from=USD&to=IDR&limit=&offset=
+func (h *Handler) List(c *fiber.Ctx) error {
+	q := fxuc.ListQuery{
+		Limit:  c.QueryInt("limit", 50),
+		Offset: c.QueryInt("offset", 0),
+	}
+	if v := c.Query("from"); v != "" {
+		q.FromCurrency = &v
+	}
+	if v := c.Query("to"); v != "" {
+		q.ToCurrency = &v
+	}
+
+	out, err := h.uc.List(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Convert: GET /exchange-rates/convert?amount=100&from=USD&to=IDR&at=RFC3339
+func (h *Handler) Convert(c *fiber.Ctx) error {
+	q := fxuc.ConvertQuery{
+		Amount:       c.Query("amount"),
+		FromCurrency: c.Query("from"),
+		ToCurrency:   c.Query("to"),
+	}
+	if v := c.Query("at"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid at")
+		}
+		q.At = &t
+	}
+
+	out, err := h.uc.Convert(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, fxuc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, fxuc.ErrRateMissing):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, fxuc.ErrRateConflict):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
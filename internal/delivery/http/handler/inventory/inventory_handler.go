@@ -0,0 +1,138 @@
+package inventory
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	invuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/inventory"
+)
+
+type Handler struct {
+	uc *invuc.Usecase
+}
+
+func New(uc *invuc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// Receive: POST /inventory/receipts
+func (h *Handler) Receive(c *fiber.Ctx) error {
+	var in invuc.ReceiveInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	out, err := h.uc.Receive(c.Context(), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+// ListReceipts: GET /inventory/receipts?productId=&limit=&offset=
+func (h *Handler) ListReceipts(c *fiber.Ctx) error {
+	q := invuc.ListQuery{
+		Limit:  c.QueryInt("limit", 50),
+		Offset: c.QueryInt("offset", 0),
+	}
+	if v := c.Query("productId"); v != "" {
+		q.ProductID = &v
+	}
+
+	out, err := h.uc.ListReceipts(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) GetReceipt(c *fiber.Ctx) error {
+	out, err := h.uc.GetReceipt(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Valuation: GET /inventory/valuation
+func (h *Handler) Valuation(c *fiber.Ctx) error {
+	out, err := h.uc.Valuation(c.Context())
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// LowStock: GET /inventory/low-stock
+func (h *Handler) LowStock(c *fiber.Ctx) error {
+	out, err := h.uc.LowStock(c.Context())
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// ReorderSuggestions: GET /inventory/reorder-suggestions?windowDays=30&coverDays=14
+func (h *Handler) ReorderSuggestions(c *fiber.Ctx) error {
+	out, err := h.uc.ReorderSuggestions(c.Context(), invuc.SuggestionQuery{
+		WindowDays: c.QueryInt("windowDays", 0),
+		CoverDays:  c.QueryInt("coverDays", 0),
+	})
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// StockEvents: GET /inventory/stock-events?since=RFC3339&kind=low_stock|restocked&limit=&offset=
+func (h *Handler) StockEvents(c *fiber.Ctx) error {
+	q := invuc.StockEventQuery{
+		Limit:  c.QueryInt("limit", 50),
+		Offset: c.QueryInt("offset", 0),
+	}
+	if v := c.Query("since"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid since")
+		}
+		q.Since = &t
+	}
+	if v := c.Query("kind"); v != "" {
+		q.Kind = &v
+	}
+
+	out, err := h.uc.ListStockEvents(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Expiring: GET /inventory/expiring?days=30&locationId=
+func (h *Handler) Expiring(c *fiber.Ctx) error {
+	q := invuc.ExpiringQuery{Days: c.QueryInt("days", 0)}
+	if v := c.Query("locationId"); v != "" {
+		q.LocationID = &v
+	}
+
+	out, err := h.uc.Expiring(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, invuc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, invuc.ErrNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, invuc.ErrProductMissing), errors.Is(err, invuc.ErrFractionalStock), errors.Is(err, invuc.ErrLocationMissing):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
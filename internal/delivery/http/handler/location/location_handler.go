@@ -0,0 +1,107 @@
+package location
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	locationuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/location"
+)
+
+type Handler struct {
+	uc *locationuc.Usecase
+}
+
+func New(uc *locationuc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+func (h *Handler) Create(c *fiber.Ctx) error {
+	var in locationuc.CreateInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	out, err := h.uc.Create(c.Context(), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+// List: GET /locations?isActive=true|false
+func (h *Handler) List(c *fiber.Ctx) error {
+	var q locationuc.ListQuery
+	if v := c.Query("isActive"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid isActive")
+		}
+		q.IsActive = &b
+	}
+
+	out, err := h.uc.List(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) GetByID(c *fiber.Ctx) error {
+	out, err := h.uc.GetByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) Update(c *fiber.Ctx) error {
+	var in locationuc.UpdateInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	out, err := h.uc.Update(c.Context(), c.Params("id"), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// ListStock: GET /locations/:id/stock?productId=&inStock=true&limit=&offset=
+func (h *Handler) ListStock(c *fiber.Ctx) error {
+	q := locationuc.StockQuery{
+		Limit:  c.QueryInt("limit", 100),
+		Offset: c.QueryInt("offset", 0),
+	}
+	if v := c.Query("productId"); v != "" {
+		q.ProductID = &v
+	}
+	if v := c.Query("inStock"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid inStock")
+		}
+		q.InStockOnly = b
+	}
+
+	out, err := h.uc.ListStock(c.Context(), c.Params("id"), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, locationuc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, locationuc.ErrNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, locationuc.ErrCodeConflict), errors.Is(err, locationuc.ErrDefaultLocation):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
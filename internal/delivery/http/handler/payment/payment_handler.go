@@ -3,6 +3,7 @@ package payment
 import (
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/riolentius/cahaya-gading-backend/internal/delivery/middleware"
 	payuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/payment"
 )
 
@@ -22,6 +23,7 @@ func (h *Handler) CreateForTransaction(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
 	}
 	req.TransactionID = trxID
+	req.PostedBy = postedBy(c)
 
 	p, state, err := h.uc.Create(c.Context(), req)
 	if err != nil {
@@ -30,6 +32,12 @@ func (h *Handler) CreateForTransaction(c *fiber.Ctx) error {
 			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		case payuc.ErrTransactionMissing:
 			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		case payuc.ErrInsufficientCredit, payuc.ErrCreditExceedsDue:
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		case payuc.ErrNoOpenShift:
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		case payuc.ErrRateMissing, payuc.ErrCurrencyMismatch:
+			return c.Status(422).JSON(fiber.Map{"error": err.Error()})
 		default:
 			return c.Status(500).JSON(fiber.Map{"error": "internal error"})
 		}
@@ -54,3 +62,11 @@ func (h *Handler) ListForTransaction(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{"items": items})
 }
+
+// postedBy is the authenticated admin, recorded on payments/receipts for shift close-out.
+func postedBy(c *fiber.Ctx) *string {
+	if id := middleware.AdminID(c); id != "" {
+		return &id
+	}
+	return nil
+}
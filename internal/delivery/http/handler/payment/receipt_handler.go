@@ -0,0 +1,55 @@
+package payment
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	payuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/payment"
+)
+
+func (h *Handler) CreateReceiptForCustomer(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+
+	var req payuc.CreateReceiptInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+	req.CustomerID = customerID
+	req.PostedBy = postedBy(c)
+
+	out, err := h.uc.CreateReceipt(c.Context(), req)
+	if err != nil {
+		return writeReceiptErr(c, err)
+	}
+	return c.Status(201).JSON(out)
+}
+
+func (h *Handler) ListReceiptsForCustomer(c *fiber.Ctx) error {
+	customerID := c.Params("id")
+
+	items, err := h.uc.ListReceiptsByCustomer(c.Context(), customerID, c.QueryInt("limit", 50), c.QueryInt("offset", 0))
+	if err != nil {
+		return writeReceiptErr(c, err)
+	}
+	return c.JSON(fiber.Map{"items": items})
+}
+
+func (h *Handler) GetReceipt(c *fiber.Ctx) error {
+	out, err := h.uc.GetReceipt(c.Context(), c.Params("id"))
+	if err != nil {
+		return writeReceiptErr(c, err)
+	}
+	return c.JSON(out)
+}
+
+func writeReceiptErr(c *fiber.Ctx, err error) error {
+	switch err {
+	case payuc.ErrInvalidInput:
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case payuc.ErrCustomerMissing, payuc.ErrReceiptMissing, payuc.ErrTransactionMissing:
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case payuc.ErrInvalidAllocation, payuc.ErrAllocationExceedsDue, payuc.ErrAllocationExceedsReceipt, payuc.ErrNoOpenShift:
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(500).JSON(fiber.Map{"error": "internal error"})
+	}
+}
@@ -0,0 +1,78 @@
+package pricing
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	pricinguc "github.com/riolentius/cahaya-gading-backend/internal/usecase/pricing"
+)
+
+type Handler struct {
+	uc *pricinguc.Usecase
+}
+
+func New(uc *pricinguc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// EffectivePrice: GET /products/:id/effective-price?customerId=&qty=&at=RFC3339|YYYY-MM-DD
+func (h *Handler) EffectivePrice(c *fiber.Ctx) error {
+	q := pricinguc.EffectivePriceQuery{
+		ProductID: c.Params("id"),
+		Qty:       c.QueryInt("qty", 1),
+	}
+
+	if v := c.Query("customerId"); v != "" {
+		q.CustomerID = &v
+	}
+	if v := c.Query("at"); v != "" {
+		at, err := parseAt(v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid at")
+		}
+		q.At = &at
+	}
+
+	out, err := h.uc.EffectivePrice(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Quote: POST /pricing/quote {customerId, at, items:[{productId, qty}]}
+func (h *Handler) Quote(c *fiber.Ctx) error {
+	var req pricinguc.QuoteInput
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	out, err := h.uc.Quote(c.Context(), req)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// parseAt accepts a full timestamp or a plain date (start of that day, UTC).
+func parseAt(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, pricinguc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, pricinguc.ErrProductMissing),
+		errors.Is(err, pricinguc.ErrCustomerMissing),
+		errors.Is(err, pricinguc.ErrPriceMissing):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
@@ -53,7 +53,9 @@ func (h *Handler) List(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
 	offset, _ := strconv.Atoi(c.Query("offset", "0"))
 
-	out, err := h.uc.List(c.Context(), limit, offset)
+	includeArchived := c.QueryBool("includeArchived", false)
+
+	out, err := h.uc.List(c.Context(), limit, offset, includeArchived)
 	if err != nil {
 		log.Printf("[product.list] failed: %v", err)
 		if isDev() {
@@ -87,3 +89,38 @@ func (h *Handler) Update(c *fiber.Ctx) error {
 
 	return c.JSON(out)
 }
+
+// Archive: DELETE /products/:id
+func (h *Handler) Archive(c *fiber.Ctx) error {
+	out, err := h.uc.Archive(c.Context(), c.Params("id"))
+	if err != nil {
+		return h.archiveErr(c, "product.archive", err)
+	}
+	return c.JSON(out)
+}
+
+// Restore: POST /products/:id/restore
+func (h *Handler) Restore(c *fiber.Ctx) error {
+	out, err := h.uc.Restore(c.Context(), c.Params("id"))
+	if err != nil {
+		return h.archiveErr(c, "product.restore", err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) archiveErr(c *fiber.Ctx, op string, err error) error {
+	switch {
+	case errors.Is(err, productuc.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, productuc.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, productuc.ErrHasActivePacks), errors.Is(err, productuc.ErrHasReservations):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	log.Printf("[%s] failed: %v", op, err)
+	if isDev() {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "internal error"})
+}
@@ -1,6 +1,10 @@
 package product_price
 
 import (
+	"errors"
+	"io"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 
 	priceuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/product_price"
@@ -24,7 +28,7 @@ func (h *Handler) CreateForProduct(c *fiber.Ctx) error {
 
 	out, err := h.uc.CreateForProduct(c.Context(), productID, in)
 	if err != nil {
-		return err
+		return mapErr(err)
 	}
 	return c.Status(fiber.StatusCreated).JSON(out)
 }
@@ -34,7 +38,7 @@ func (h *Handler) ListForProduct(c *fiber.Ctx) error {
 
 	out, err := h.uc.ListForProduct(c.Context(), productID)
 	if err != nil {
-		return err
+		return mapErr(err)
 	}
 	return c.JSON(out)
 }
@@ -49,7 +53,90 @@ func (h *Handler) Update(c *fiber.Ctx) error {
 
 	out, err := h.uc.Update(c.Context(), priceID, in)
 	if err != nil {
-		return err
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// ListUpcoming: GET /prices/upcoming?days=30&productId=
+func (h *Handler) ListUpcoming(c *fiber.Ctx) error {
+	q := priceuc.UpcomingQuery{Days: c.QueryInt("days", 30)}
+	if v := c.Query("productId"); v != "" {
+		q.ProductID = &v
+	}
+
+	out, err := h.uc.ListUpcoming(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Bulk: POST /prices/bulk {validFrom, dryRun, rows:[{sku, categoryCode, currency, amount, minQty, qtyBasis}]}
+func (h *Handler) Bulk(c *fiber.Ctx) error {
+	var in priceuc.BulkInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	out, err := h.uc.BulkCreate(c.Context(), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return writeBulkResult(c, out)
+}
+
+// Import: POST /prices/import?validFrom=RFC3339&dryRun=true
+// Body is either multipart form with field "file" or the raw CSV.
+func (h *Handler) Import(c *fiber.Ctx) error {
+	var validFrom *time.Time
+	if v := c.Query("validFrom"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid validFrom")
+		}
+		validFrom = &t
+	}
+
+	data := c.Body()
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid file")
+		}
+		defer f.Close()
+
+		if data, err = io.ReadAll(f); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid file")
+		}
+	}
+
+	out, err := h.uc.ImportCSV(c.Context(), data, validFrom, c.QueryBool("dryRun", false))
+	if err != nil {
+		return mapErr(err)
+	}
+	return writeBulkResult(c, out)
+}
+
+// rows with errors -> 422 with the full per-row report (nothing was written)
+func writeBulkResult(c *fiber.Ctx, out *priceuc.BulkResult) error {
+	if out.Failed > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(out)
 	}
 	return c.JSON(out)
 }
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, priceuc.ErrInvalidInput),
+		errors.Is(err, priceuc.ErrInvalidWindow),
+		errors.Is(err, priceuc.ErrInvalidCSV):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, priceuc.ErrProductMissing), errors.Is(err, priceuc.ErrNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, priceuc.ErrPriceOverlap):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
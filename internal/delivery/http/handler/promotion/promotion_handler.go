@@ -0,0 +1,89 @@
+package promotion
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	promouc "github.com/riolentius/cahaya-gading-backend/internal/usecase/promotion"
+)
+
+type Handler struct {
+	uc *promouc.Usecase
+}
+
+func New(uc *promouc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+func (h *Handler) Create(c *fiber.Ctx) error {
+	var in promouc.CreateInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	out, err := h.uc.Create(c.Context(), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+// List: GET /promotions?activeAt=RFC3339&limit=&offset=
+func (h *Handler) List(c *fiber.Ctx) error {
+	q := promouc.ListQuery{
+		Limit:  c.QueryInt("limit", 20),
+		Offset: c.QueryInt("offset", 0),
+	}
+	if v := c.Query("activeAt"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid activeAt")
+		}
+		q.ActiveAt = &t
+	}
+
+	out, err := h.uc.List(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) GetByID(c *fiber.Ctx) error {
+	out, err := h.uc.GetByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) Update(c *fiber.Ctx) error {
+	var in promouc.UpdateInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	out, err := h.uc.Update(c.Context(), c.Params("id"), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, promouc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, promouc.ErrNotFound),
+		errors.Is(err, promouc.ErrProductMissing),
+		errors.Is(err, promouc.ErrCategoryMissing),
+		errors.Is(err, promouc.ErrCustomerMissing):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, promouc.ErrCodeConflict):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,109 @@
+package purchase
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	purchaseuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/purchase"
+)
+
+type Handler struct {
+	uc *purchaseuc.Usecase
+}
+
+func New(uc *purchaseuc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+func (h *Handler) Create(c *fiber.Ctx) error {
+	var in purchaseuc.CreateInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	out, err := h.uc.Create(c.Context(), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+// List: GET /purchase-orders?supplierId=&status=&limit=&offset=
+func (h *Handler) List(c *fiber.Ctx) error {
+	q := purchaseuc.ListQuery{
+		Limit:  c.QueryInt("limit", 50),
+		Offset: c.QueryInt("offset", 0),
+	}
+	if v := c.Query("supplierId"); v != "" {
+		q.SupplierID = &v
+	}
+	if v := c.Query("status"); v != "" {
+		q.Status = &v
+	}
+
+	out, err := h.uc.List(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) GetByID(c *fiber.Ctx) error {
+	out, err := h.uc.GetByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Order: POST /purchase-orders/:id/order
+func (h *Handler) Order(c *fiber.Ctx) error {
+	out, err := h.uc.Order(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Cancel: POST /purchase-orders/:id/cancel
+func (h *Handler) Cancel(c *fiber.Ctx) error {
+	out, err := h.uc.Cancel(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Receive: POST /purchase-orders/:id/receive — partial receipts allowed.
+func (h *Handler) Receive(c *fiber.Ctx) error {
+	var in purchaseuc.ReceiveInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+
+	out, err := h.uc.Receive(c.Context(), c.Params("id"), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, purchaseuc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, purchaseuc.ErrNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, purchaseuc.ErrSupplierMissing),
+		errors.Is(err, purchaseuc.ErrProductMissing),
+		errors.Is(err, purchaseuc.ErrLineMissing),
+		errors.Is(err, purchaseuc.ErrFractionalStock),
+		errors.Is(err, purchaseuc.ErrLocationMissing):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, purchaseuc.ErrInvalidTransition), errors.Is(err, purchaseuc.ErrOverReceipt):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
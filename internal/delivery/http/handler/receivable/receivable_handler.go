@@ -0,0 +1,82 @@
+package receivable
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	recvuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/receivable"
+)
+
+type Handler struct {
+	uc *recvuc.Usecase
+}
+
+func New(uc *recvuc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// Aging: GET /receivables/aging?asOf=YYYY-MM-DD&customerId=
+func (h *Handler) Aging(c *fiber.Ctx) error {
+	var q recvuc.AgingQuery
+
+	if v := c.Query("asOf"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid asOf")
+		}
+		// end of that day: everything posted on asOf counts
+		q.AsOf = d.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if v := c.Query("customerId"); v != "" {
+		q.CustomerID = &v
+	}
+
+	out, err := h.uc.Aging(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Statement: GET /customers/:id/statement?from=YYYY-MM-DD&to=YYYY-MM-DD&currency=IDR
+func (h *Handler) Statement(c *fiber.Ctx) error {
+	q := recvuc.StatementQuery{
+		CustomerID: c.Params("id"),
+		Currency:   c.Query("currency"),
+	}
+
+	if v := c.Query("from"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid from")
+		}
+		q.From = d
+	}
+	if v := c.Query("to"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid to")
+		}
+		// inclusive end date
+		q.To = d.AddDate(0, 0, 1)
+	}
+
+	out, err := h.uc.Statement(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, recvuc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, recvuc.ErrCustomerMissing):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,138 @@
+package reconciliation
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+
+	recuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/reconciliation"
+)
+
+// uploads above this size are rejected; a monthly mutation export is far smaller
+const maxStatementBytes = 5 << 20
+
+type Handler struct {
+	uc *recuc.Usecase
+}
+
+func New(uc *recuc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// Import: POST /bank-statements/import?bank=bca|mandiri&accountNo=&currency=IDR&year=2025
+// Body is either multipart form with field "file" or the raw CSV.
+func (h *Handler) Import(c *fiber.Ctx) error {
+	in := recuc.ImportInput{
+		Bank:     c.Query("bank"),
+		Currency: c.Query("currency"),
+		Year:     c.QueryInt("year", 0),
+	}
+	if v := c.Query("accountNo"); v != "" {
+		in.AccountNo = &v
+	}
+
+	if fh, err := c.FormFile("file"); err == nil {
+		if fh.Size > maxStatementBytes {
+			return fiber.NewError(fiber.StatusRequestEntityTooLarge, "file too large")
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid file")
+		}
+		defer f.Close()
+
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid file")
+		}
+		name := fh.Filename
+		in.Data = data
+		in.FileName = &name
+		if v := c.FormValue("bank"); v != "" {
+			in.Bank = v
+		}
+	} else {
+		if len(c.Body()) > maxStatementBytes {
+			return fiber.NewError(fiber.StatusRequestEntityTooLarge, "file too large")
+		}
+		in.Data = append([]byte(nil), c.Body()...)
+	}
+
+	out, err := h.uc.Import(c.Context(), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+// ListLines: GET /bank-statements/:id/lines?status=unmatched|suggested|confirmed|flagged
+func (h *Handler) ListLines(c *fiber.Ctx) error {
+	items, err := h.uc.ListLines(c.Context(), c.Params("id"), c.Query("status"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(fiber.Map{"items": items})
+}
+
+// Match: POST /bank-statements/:id/match re-runs auto matching for unmatched lines.
+func (h *Handler) Match(c *fiber.Ctx) error {
+	out, err := h.uc.Match(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Confirm: POST /bank-statement-lines/:id/confirm
+// Body {paymentId|receiptId, note}; an empty body accepts the suggestion.
+func (h *Handler) Confirm(c *fiber.Ctx) error {
+	var req recuc.ConfirmInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+		}
+	}
+
+	out, err := h.uc.Confirm(c.Context(), c.Params("id"), req)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Flag: POST /bank-statement-lines/:id/flag
+func (h *Handler) Flag(c *fiber.Ctx) error {
+	var req recuc.FlagInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+		}
+	}
+
+	out, err := h.uc.Flag(c.Context(), c.Params("id"), req)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, recuc.ErrInvalidInput),
+		errors.Is(err, recuc.ErrInvalidStatement),
+		errors.Is(err, recuc.ErrEmptyStatement):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, recuc.ErrImportMissing),
+		errors.Is(err, recuc.ErrLineMissing),
+		errors.Is(err, recuc.ErrTargetMissing):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, recuc.ErrNotTransfer),
+		errors.Is(err, recuc.ErrNotCreditLine),
+		errors.Is(err, recuc.ErrAmountMismatch),
+		errors.Is(err, recuc.ErrAlreadyReconciled):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
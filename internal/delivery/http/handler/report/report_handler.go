@@ -0,0 +1,104 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	reportuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/report"
+)
+
+type Handler struct {
+	uc *reportuc.Usecase
+}
+
+func New(uc *reportuc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// Sales: GET /reports/sales?from=YYYY-MM-DD&to=YYYY-MM-DD&interval=day|week|month
+// &by=period|product|customer_category|payment_method&currency=IDR&format=json|csv
+func (h *Handler) Sales(c *fiber.Ctx) error {
+	q := reportuc.SalesQuery{
+		Interval: c.Query("interval"),
+		By:       c.Query("by"),
+	}
+
+	if v := c.Query("from"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid from")
+		}
+		q.From = d
+	}
+	if v := c.Query("to"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid to")
+		}
+		// inclusive end date
+		q.To = d.AddDate(0, 0, 1)
+	}
+	if v := c.Query("currency"); v != "" {
+		q.Currency = &v
+	}
+
+	out, err := h.uc.Sales(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+
+	switch c.Query("format", "json") {
+	case "json":
+		return c.JSON(out)
+	case "csv":
+		data, err := reportuc.SalesCSV(out)
+		if err != nil {
+			return mapErr(err)
+		}
+		name := fmt.Sprintf("sales_%s_%s_%s.csv", out.By, out.From.Format("20060102"), out.To.Format("20060102"))
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, name))
+		return c.Send(data)
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "invalid format")
+	}
+}
+
+// Margin: GET /reports/margin?from=YYYY-MM-DD&to=YYYY-MM-DD&by=product|transaction
+func (h *Handler) Margin(c *fiber.Ctx) error {
+	q := reportuc.MarginQuery{By: c.Query("by")}
+
+	if v := c.Query("from"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid from")
+		}
+		q.From = d
+	}
+	if v := c.Query("to"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid to")
+		}
+		// inclusive end date
+		q.To = d.AddDate(0, 0, 1)
+	}
+
+	out, err := h.uc.Margin(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, reportuc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,120 @@
+package shift
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/riolentius/cahaya-gading-backend/internal/delivery/middleware"
+	shiftuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/shift"
+)
+
+type Handler struct {
+	uc *shiftuc.Usecase
+}
+
+func New(uc *shiftuc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// Open: POST /shifts — opens a shift for the calling admin.
+func (h *Handler) Open(c *fiber.Ctx) error {
+	var in shiftuc.OpenInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	in.AdminID = middleware.AdminID(c)
+
+	out, err := h.uc.Open(c.Context(), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+// Current: GET /shifts/current
+func (h *Handler) Current(c *fiber.Ctx) error {
+	out, err := h.uc.Current(c.Context(), middleware.AdminID(c))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// List: GET /shifts?adminId=&status=open|closed&limit=&offset=
+func (h *Handler) List(c *fiber.Ctx) error {
+	q := shiftuc.ListQuery{
+		Limit:  c.QueryInt("limit", 20),
+		Offset: c.QueryInt("offset", 0),
+	}
+	if v := c.Query("adminId"); v != "" {
+		q.AdminID = &v
+	}
+	if v := c.Query("status"); v != "" {
+		q.Status = &v
+	}
+
+	out, err := h.uc.List(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) GetByID(c *fiber.Ctx) error {
+	out, err := h.uc.GetByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Close: POST /shifts/:id/close — only the admin who opened the shift may close it.
+func (h *Handler) Close(c *fiber.Ctx) error {
+	var in shiftuc.CloseInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	in.AdminID = middleware.AdminID(c)
+
+	out, err := h.uc.Close(c.Context(), c.Params("id"), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// ZReport: GET /shifts/:id/z-report?format=json|text
+func (h *Handler) ZReport(c *fiber.Ctx) error {
+	out, err := h.uc.ZReport(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+
+	switch c.Query("format", "json") {
+	case "json":
+		return c.JSON(out)
+	case "text":
+		c.Set(fiber.HeaderContentType, "text/plain; charset=utf-8")
+		return c.SendString(shiftuc.RenderZReport(out))
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "invalid format")
+	}
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, shiftuc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, shiftuc.ErrNotFound), errors.Is(err, shiftuc.ErrNoOpenShift):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, shiftuc.ErrAdminMissing):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, shiftuc.ErrNotShiftOwner):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, shiftuc.ErrShiftAlreadyOpen), errors.Is(err, shiftuc.ErrShiftClosed):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,131 @@
+package shipment
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/riolentius/cahaya-gading-backend/internal/delivery/middleware"
+	shipmentuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/shipment"
+)
+
+type Handler struct {
+	uc *shipmentuc.Usecase
+}
+
+func New(uc *shipmentuc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+// Create: POST /transactions/:id/shipments
+func (h *Handler) Create(c *fiber.Ctx) error {
+	var in shipmentuc.CreateInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	if id := middleware.AdminID(c); id != "" {
+		in.CreatedBy = &id
+	}
+
+	out, err := h.uc.Create(c.Context(), c.Params("id"), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+// ListByTransaction: GET /transactions/:id/shipments
+func (h *Handler) ListByTransaction(c *fiber.Ctx) error {
+	out, err := h.uc.ListByTransaction(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) GetByID(c *fiber.Ctx) error {
+	out, err := h.uc.GetByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Pack: POST /shipments/:id/pack
+func (h *Handler) Pack(c *fiber.Ctx) error {
+	out, err := h.uc.Pack(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Ship: POST /shipments/:id/ship {"carrier","trackingNumber"}
+func (h *Handler) Ship(c *fiber.Ctx) error {
+	var in shipmentuc.ShipInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&in); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+		}
+	}
+
+	out, err := h.uc.Ship(c.Context(), c.Params("id"), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Deliver: POST /shipments/:id/deliver
+func (h *Handler) Deliver(c *fiber.Ctx) error {
+	out, err := h.uc.Deliver(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Cancel: POST /shipments/:id/cancel
+func (h *Handler) Cancel(c *fiber.Ctx) error {
+	out, err := h.uc.Cancel(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// DeliveryNote: GET /shipments/:id/delivery-note?format=json|text
+func (h *Handler) DeliveryNote(c *fiber.Ctx) error {
+	out, err := h.uc.DeliveryNote(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+
+	switch c.Query("format", "json") {
+	case "json":
+		return c.JSON(out)
+	case "text":
+		c.Set(fiber.HeaderContentType, "text/plain; charset=utf-8")
+		return c.SendString(shipmentuc.RenderDeliveryNote(out))
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "invalid format")
+	}
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, shipmentuc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, shipmentuc.ErrNotFound), errors.Is(err, shipmentuc.ErrTransactionMissing):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, shipmentuc.ErrItemMissing), errors.Is(err, shipmentuc.ErrAddressMissing):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, shipmentuc.ErrInvalidTransition),
+		errors.Is(err, shipmentuc.ErrOverShipped),
+		errors.Is(err, shipmentuc.ErrNothingToShip),
+		errors.Is(err, shipmentuc.ErrExpiredStock):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
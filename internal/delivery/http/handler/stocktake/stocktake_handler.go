@@ -0,0 +1,118 @@
+package stocktake
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/riolentius/cahaya-gading-backend/internal/delivery/middleware"
+	stocktakeuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/stocktake"
+)
+
+type Handler struct {
+	uc *stocktakeuc.Usecase
+}
+
+func New(uc *stocktakeuc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+func (h *Handler) Create(c *fiber.Ctx) error {
+	var in stocktakeuc.CreateInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	if id := middleware.AdminID(c); id != "" {
+		in.CreatedBy = &id
+	}
+
+	out, err := h.uc.Create(c.Context(), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+// List: GET /stock-takes?locationId=&status=&limit=&offset=
+func (h *Handler) List(c *fiber.Ctx) error {
+	q := stocktakeuc.ListQuery{
+		Limit:  c.QueryInt("limit", 50),
+		Offset: c.QueryInt("offset", 0),
+	}
+	if v := c.Query("locationId"); v != "" {
+		q.LocationID = &v
+	}
+	if v := c.Query("status"); v != "" {
+		q.Status = &v
+	}
+
+	out, err := h.uc.List(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) GetByID(c *fiber.Ctx) error {
+	out, err := h.uc.GetByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// RecordCounts: POST /stock-takes/:id/counts {"counts":[{"productId","qty"}]}
+// The signed-in admin is the counter; resubmitting replaces their count.
+func (h *Handler) RecordCounts(c *fiber.Ctx) error {
+	var in stocktakeuc.CountInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	in.CounterID = middleware.AdminID(c)
+
+	out, err := h.uc.RecordCounts(c.Context(), c.Params("id"), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Approve: POST /stock-takes/:id/approve
+func (h *Handler) Approve(c *fiber.Ctx) error {
+	var approvedBy *string
+	if id := middleware.AdminID(c); id != "" {
+		approvedBy = &id
+	}
+
+	out, err := h.uc.Approve(c.Context(), c.Params("id"), approvedBy)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Cancel: POST /stock-takes/:id/cancel
+func (h *Handler) Cancel(c *fiber.Ctx) error {
+	out, err := h.uc.Cancel(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, stocktakeuc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, stocktakeuc.ErrNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, stocktakeuc.ErrLocationMissing), errors.Is(err, stocktakeuc.ErrProductMissing):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, stocktakeuc.ErrSessionOpen),
+		errors.Is(err, stocktakeuc.ErrInvalidTransition),
+		errors.Is(err, stocktakeuc.ErrBelowReserved):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
@@ -75,8 +75,12 @@ func mapErr(err error) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	case errors.Is(err, txuc.ErrInvalidTransition):
 		return fiber.NewError(fiber.StatusConflict, err.Error())
-	case errors.Is(err, txuc.ErrInsufficientStock):
+	case errors.Is(err, txuc.ErrInsufficientStock), errors.Is(err, txuc.ErrExpiredStock):
 		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, txuc.ErrMixedCurrency):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, txuc.ErrLocationMissing):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
 	case errors.Is(err, txuc.ErrTransactionMissing):
 		return fiber.NewError(fiber.StatusNotFound, err.Error())
 	default:
@@ -96,9 +100,9 @@ func (h *Handler) Fulfill(c *fiber.Ctx) error {
 			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
 		case errors.Is(err, txuc.ErrAlreadyFulfilled):
 			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
-		case errors.Is(err, txuc.ErrTransactionCanceled):
+		case errors.Is(err, txuc.ErrTransactionCanceled), errors.Is(err, txuc.ErrShipmentsOpen):
 			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
-		case errors.Is(err, txuc.ErrInsufficientStock):
+		case errors.Is(err, txuc.ErrInsufficientStock), errors.Is(err, txuc.ErrExpiredStock):
 			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
 		default:
 			return c.Status(500).JSON(fiber.Map{"error": "internal error"})
@@ -0,0 +1,95 @@
+package transfer
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/riolentius/cahaya-gading-backend/internal/delivery/middleware"
+	transferuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transfer"
+)
+
+type Handler struct {
+	uc *transferuc.Usecase
+}
+
+func New(uc *transferuc.Usecase) *Handler {
+	return &Handler{uc: uc}
+}
+
+func (h *Handler) Create(c *fiber.Ctx) error {
+	var in transferuc.CreateInput
+	if err := c.BodyParser(&in); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	if id := middleware.AdminID(c); id != "" {
+		in.CreatedBy = &id
+	}
+
+	out, err := h.uc.Create(c.Context(), in)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+// List: GET /stock-transfers?locationId=&status=&limit=&offset=
+func (h *Handler) List(c *fiber.Ctx) error {
+	q := transferuc.ListQuery{
+		Limit:  c.QueryInt("limit", 50),
+		Offset: c.QueryInt("offset", 0),
+	}
+	if v := c.Query("locationId"); v != "" {
+		q.LocationID = &v
+	}
+	if v := c.Query("status"); v != "" {
+		q.Status = &v
+	}
+
+	out, err := h.uc.List(c.Context(), q)
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func (h *Handler) GetByID(c *fiber.Ctx) error {
+	out, err := h.uc.GetByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Complete: POST /stock-transfers/:id/complete
+func (h *Handler) Complete(c *fiber.Ctx) error {
+	out, err := h.uc.Complete(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+// Cancel: POST /stock-transfers/:id/cancel
+func (h *Handler) Cancel(c *fiber.Ctx) error {
+	out, err := h.uc.Cancel(c.Context(), c.Params("id"))
+	if err != nil {
+		return mapErr(err)
+	}
+	return c.JSON(out)
+}
+
+func mapErr(err error) error {
+	switch {
+	case errors.Is(err, transferuc.ErrInvalidInput):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, transferuc.ErrNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, transferuc.ErrLocationMissing), errors.Is(err, transferuc.ErrProductMissing):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, transferuc.ErrInvalidTransition), errors.Is(err, transferuc.ErrInsufficientStock):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+	}
+}
@@ -2,29 +2,80 @@ package http
 
 import (
 	"context"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/riolentius/cahaya-gading-backend/internal/config"
+	audithandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/audit"
 	authhandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/auth"
+	credithandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/credit"
 	customerhandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/customer"
+	fxhandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/exchangerate"
+	invhandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/inventory"
+	locationhandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/location"
 	payhandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/payment"
+	pricinghandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/pricing"
 	producthandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/product"
 	pricehandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/product_price"
+	promohandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/promotion"
+	purchasehandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/purchase"
+	recvhandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/receivable"
+	rechandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/reconciliation"
+	reporthandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/report"
+	shifthandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/shift"
+	shipmenthandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/shipment"
+	stocktakehandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/stocktake"
+	supplierhandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/supplier"
 	trxhandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/transaction"
+	transferhandler "github.com/riolentius/cahaya-gading-backend/internal/delivery/http/handler/transfer"
 	"github.com/riolentius/cahaya-gading-backend/internal/delivery/middleware"
 	adminpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/admin"
+	auditpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/audit"
+	creditpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/credit"
 	customerpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/customer"
+	fxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/exchangerate"
+	idempg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/idempotency"
+	invpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/inventory"
+	locationpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/location"
 	paypg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/payment"
+	pricingpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/pricing"
 	productpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/product"
 	pricepg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/product_price"
+	promopg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/promotion"
+	purchasepg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/purchase"
+	recvpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/receivable"
+	recpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/reconciliation"
+	reportpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/report"
+	shiftpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/shift"
+	shipmentpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/shipment"
+	stocktakepg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/stocktake"
+	supplierpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/supplier"
 	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+	transferpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transfer"
+	audituc "github.com/riolentius/cahaya-gading-backend/internal/usecase/audit"
 	authuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/auth"
+	credituc "github.com/riolentius/cahaya-gading-backend/internal/usecase/credit"
 	customeruc "github.com/riolentius/cahaya-gading-backend/internal/usecase/customer"
+	fxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/exchangerate"
+	idemuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/idempotency"
+	invuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/inventory"
+	locationuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/location"
 	payuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/payment"
+	pricinguc "github.com/riolentius/cahaya-gading-backend/internal/usecase/pricing"
 	productuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/product"
 	priceuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/product_price"
+	promouc "github.com/riolentius/cahaya-gading-backend/internal/usecase/promotion"
+	purchaseuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/purchase"
+	recvuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/receivable"
+	recuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/reconciliation"
+	reportuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/report"
+	shiftuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/shift"
+	shipmentuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/shipment"
+	stocktakeuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/stocktake"
+	supplieruc "github.com/riolentius/cahaya-gading-backend/internal/usecase/supplier"
 	txuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
+	transferuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transfer"
 )
 
 func RegisterRoutes(app *fiber.App, cfg config.Config, db *pgxpool.Pool) {
@@ -43,9 +94,18 @@ func RegisterRoutes(app *fiber.App, cfg config.Config, db *pgxpool.Pool) {
 	// Public route
 	api.Post("/admin/login", loginHandler.Handle)
 
+	// Audit wiring (every mutating admin request is logged)
+	auditRepo := auditpg.NewAuditRepo(db)
+	auditStore := auditpg.NewAuditStoreAdapter(auditRepo)
+	auditUC := audituc.New(auditStore)
+	auditH := audithandler.New(auditUC)
+
 	// Protected admin group (MUST be defined before use)
 	admin := api.Group("/admin", middleware.RequireAdminJWT(middleware.JWTConfig{
 		Secret: cfg.JWTSecret,
+	}), middleware.Audit(middleware.AuditConfig{
+		UC:            auditUC,
+		ReadOnlyPaths: []string{"/api/admin/pricing/quote"},
 	}))
 
 	admin.Get("/me", func(c *fiber.Ctx) error {
@@ -55,6 +115,12 @@ func RegisterRoutes(app *fiber.App, cfg config.Config, db *pgxpool.Pool) {
 		})
 	})
 
+	// Idempotency wiring (Idempotency-Key header on POST endpoints)
+	idemRepo := idempg.NewIdempotencyRepo(db)
+	idemStore := idempg.NewIdempotencyStoreAdapter(idemRepo)
+	idemUC := idemuc.New(idemStore, time.Duration(cfg.IdempotencyTTLHours)*time.Hour)
+	idempotent := middleware.Idempotency(middleware.IdempotencyConfig{UC: idemUC})
+
 	// Products wiring
 	productRepo := productpg.NewProductRepo(db)
 	productStore := productpg.NewProductStoreAdapter(productRepo)
@@ -67,10 +133,22 @@ func RegisterRoutes(app *fiber.App, cfg config.Config, db *pgxpool.Pool) {
 	priceUC := priceuc.New(priceStore)
 	priceH := pricehandler.New(priceUC)
 
-	// Transactions wiring
+	// Pricing wiring (effective price resolution / quotes)
+	pricingRepo := pricingpg.NewPricingRepo(db)
+	pricingStore := pricingpg.NewPricingStoreAdapter(pricingRepo)
+	pricingUC := pricinguc.New(pricingStore)
+	pricingH := pricinghandler.New(pricingUC)
+
+	// Promotions wiring
+	promoRepo := promopg.NewPromotionRepo(db)
+	promoStore := promopg.NewPromotionStoreAdapter(promoRepo)
+	promoUC := promouc.New(promoStore)
+	promoH := promohandler.New(promoUC)
+
+	// Transactions wiring (promotions are applied on create)
 	trxRepo := trxpg.NewTransactionRepo(db)
 	trxStore := trxpg.NewTransactionStoreAdapter(trxRepo, db)
-	trxUC := txuc.New(trxStore)
+	trxUC := txuc.New(trxStore).WithPromotions(promoUC)
 	trxH := trxhandler.New(trxUC)
 
 	// Customer wiring
@@ -82,12 +160,90 @@ func RegisterRoutes(app *fiber.App, cfg config.Config, db *pgxpool.Pool) {
 	// Payments wiring
 	paymentRepo := paypg.NewPaymentRepo(db)
 	paymentStore := paypg.NewPaymentStoreAdapter(paymentRepo)
-	paymentUC := payuc.New(paymentStore)
+	paymentUC := payuc.New(paymentStore).WithShiftsRequired(cfg.ShiftsEnabled)
 	paymentH := payhandler.New(paymentUC)
 
+	// Customer credit wiring
+	creditRepo := creditpg.NewCreditRepo(db)
+	creditStore := creditpg.NewCreditStoreAdapter(creditRepo)
+	creditUC := credituc.New(creditStore)
+	creditH := credithandler.New(creditUC)
+
+	// Receivables wiring
+	recvRepo := recvpg.NewReceivableRepo(db)
+	recvStore := recvpg.NewReceivableStoreAdapter(recvRepo)
+	recvUC := recvuc.New(recvStore).WithBaseCurrency(cfg.BaseCurrency)
+	recvH := recvhandler.New(recvUC)
+
+	// Reports wiring
+	reportRepo := reportpg.NewReportRepo(db)
+	reportStore := reportpg.NewReportStoreAdapter(reportRepo)
+	reportUC := reportuc.New(reportStore).WithBaseCurrency(cfg.BaseCurrency)
+	reportH := reporthandler.New(reportUC)
+
+	// Inventory wiring
+	invRepo := invpg.NewInventoryRepo(db)
+	invStore := invpg.NewInventoryStoreAdapter(invRepo)
+	invUC := invuc.New(invStore).WithBaseCurrency(cfg.BaseCurrency)
+	invH := invhandler.New(invUC)
+
+	// Suppliers wiring
+	supplierRepo := supplierpg.NewSupplierRepo(db)
+	supplierStore := supplierpg.NewSupplierStoreAdapter(supplierRepo)
+	supplierUC := supplieruc.New(supplierStore)
+	supplierH := supplierhandler.New(supplierUC)
+
+	// Purchase orders wiring
+	purchaseRepo := purchasepg.NewPurchaseRepo(db)
+	purchaseStore := purchasepg.NewPurchaseStoreAdapter(purchaseRepo)
+	purchaseUC := purchaseuc.New(purchaseStore)
+	purchaseH := purchasehandler.New(purchaseUC)
+
+	// Locations wiring
+	locationRepo := locationpg.NewLocationRepo(db)
+	locationStore := locationpg.NewLocationStoreAdapter(locationRepo)
+	locationUC := locationuc.New(locationStore)
+	locationH := locationhandler.New(locationUC)
+
+	// Stock transfers wiring
+	transferRepo := transferpg.NewTransferRepo(db)
+	transferStore := transferpg.NewTransferStoreAdapter(transferRepo)
+	transferUC := transferuc.New(transferStore)
+	transferH := transferhandler.New(transferUC)
+
+	// Stock takes wiring
+	stockTakeRepo := stocktakepg.NewStockTakeRepo(db)
+	stockTakeStore := stocktakepg.NewStockTakeStoreAdapter(stockTakeRepo)
+	stockTakeUC := stocktakeuc.New(stockTakeStore)
+	stockTakeH := stocktakehandler.New(stockTakeUC)
+
+	// Shipments wiring
+	shipmentRepo := shipmentpg.NewShipmentRepo(db)
+	shipmentStore := shipmentpg.NewShipmentStoreAdapter(shipmentRepo)
+	shipmentUC := shipmentuc.New(shipmentStore)
+	shipmentH := shipmenthandler.New(shipmentUC)
+
+	// Cash shifts wiring
+	shiftRepo := shiftpg.NewShiftRepo(db)
+	shiftStore := shiftpg.NewShiftStoreAdapter(shiftRepo)
+	shiftUC := shiftuc.New(shiftStore)
+	shiftH := shifthandler.New(shiftUC)
+
+	// Exchange rates wiring
+	fxRepo := fxpg.NewExchangeRateRepo(db)
+	fxStore := fxpg.NewExchangeRateStoreAdapter(fxRepo)
+	fxUC := fxuc.New(fxStore)
+	fxH := fxhandler.New(fxUC)
+
+	// Bank reconciliation wiring
+	recRepo := recpg.NewReconciliationRepo(db)
+	recStore := recpg.NewReconciliationStoreAdapter(recRepo)
+	recUC := recuc.New(recStore)
+	recH := rechandler.New(recUC)
+
 	// Endpoints
 	admin.Get("/transactions/:id/view", trxH.GetViewByID)
-	admin.Post("/transactions/:id/payments", paymentH.CreateForTransaction)
+	admin.Post("/transactions/:id/payments", idempotent, paymentH.CreateForTransaction)
 	admin.Get("/transactions/:id/payments", paymentH.ListForTransaction)
 	admin.Post("/transactions/:id/fulfill", trxH.Fulfill)
 
@@ -96,9 +252,102 @@ func RegisterRoutes(app *fiber.App, cfg config.Config, db *pgxpool.Pool) {
 	admin.Get("/customers", customerH.List)
 	admin.Get("/customers/:id", customerH.GetByID)
 	admin.Patch("/customers/:id", customerH.Update)
+	admin.Delete("/customers/:id", customerH.Archive)
+	admin.Post("/customers/:id/restore", customerH.Restore)
+	admin.Post("/customers/:id/merge", customerH.Merge)
+	admin.Get("/customers/:id/credit", creditH.GetForCustomer)
+	admin.Get("/customers/:id/statement", recvH.Statement)
+	admin.Post("/customers/:id/receipts", idempotent, paymentH.CreateReceiptForCustomer)
+	admin.Get("/customers/:id/receipts", paymentH.ListReceiptsForCustomer)
+
+	// Receipt routes
+	admin.Get("/receipts/:id", paymentH.GetReceipt)
+
+	// Receivable routes
+	admin.Get("/receivables/aging", recvH.Aging)
+
+	// Report routes
+	admin.Get("/reports/sales", reportH.Sales)
+	admin.Get("/reports/margin", reportH.Margin)
+
+	// Inventory routes
+	admin.Post("/inventory/receipts", invH.Receive)
+	admin.Get("/inventory/receipts", invH.ListReceipts)
+	admin.Get("/inventory/receipts/:id", invH.GetReceipt)
+	admin.Get("/inventory/valuation", invH.Valuation)
+	admin.Get("/inventory/low-stock", invH.LowStock)
+	admin.Get("/inventory/reorder-suggestions", invH.ReorderSuggestions)
+	admin.Get("/inventory/stock-events", invH.StockEvents)
+	admin.Get("/inventory/expiring", invH.Expiring)
+
+	// Supplier routes
+	admin.Post("/suppliers", supplierH.Create)
+	admin.Get("/suppliers", supplierH.List)
+	admin.Get("/suppliers/:id", supplierH.GetByID)
+	admin.Patch("/suppliers/:id", supplierH.Update)
+
+	// Purchase order routes
+	admin.Post("/purchase-orders", purchaseH.Create)
+	admin.Get("/purchase-orders", purchaseH.List)
+	admin.Get("/purchase-orders/:id", purchaseH.GetByID)
+	admin.Post("/purchase-orders/:id/order", purchaseH.Order)
+	admin.Post("/purchase-orders/:id/cancel", purchaseH.Cancel)
+	admin.Post("/purchase-orders/:id/receive", purchaseH.Receive)
+
+	// Location routes
+	admin.Post("/locations", locationH.Create)
+	admin.Get("/locations", locationH.List)
+	admin.Get("/locations/:id", locationH.GetByID)
+	admin.Patch("/locations/:id", locationH.Update)
+	admin.Get("/locations/:id/stock", locationH.ListStock)
+
+	// Stock transfer routes
+	admin.Post("/stock-transfers", transferH.Create)
+	admin.Get("/stock-transfers", transferH.List)
+	admin.Get("/stock-transfers/:id", transferH.GetByID)
+	admin.Post("/stock-transfers/:id/complete", transferH.Complete)
+	admin.Post("/stock-transfers/:id/cancel", transferH.Cancel)
+
+	// Stock take routes
+	admin.Post("/stock-takes", stockTakeH.Create)
+	admin.Get("/stock-takes", stockTakeH.List)
+	admin.Get("/stock-takes/:id", stockTakeH.GetByID)
+	admin.Post("/stock-takes/:id/counts", stockTakeH.RecordCounts)
+	admin.Post("/stock-takes/:id/approve", stockTakeH.Approve)
+	admin.Post("/stock-takes/:id/cancel", stockTakeH.Cancel)
+
+	// Shipment routes
+	admin.Post("/transactions/:id/shipments", shipmentH.Create)
+	admin.Get("/transactions/:id/shipments", shipmentH.ListByTransaction)
+	admin.Get("/shipments/:id", shipmentH.GetByID)
+	admin.Get("/shipments/:id/delivery-note", shipmentH.DeliveryNote)
+	admin.Post("/shipments/:id/pack", shipmentH.Pack)
+	admin.Post("/shipments/:id/ship", shipmentH.Ship)
+	admin.Post("/shipments/:id/deliver", shipmentH.Deliver)
+	admin.Post("/shipments/:id/cancel", shipmentH.Cancel)
+
+	// Cash shift routes
+	admin.Post("/shifts", shiftH.Open)
+	admin.Get("/shifts", shiftH.List)
+	admin.Get("/shifts/current", shiftH.Current)
+	admin.Get("/shifts/:id", shiftH.GetByID)
+	admin.Post("/shifts/:id/close", shiftH.Close)
+	admin.Get("/shifts/:id/z-report", shiftH.ZReport)
+
+	// Exchange rate routes
+	admin.Post("/exchange-rates", fxH.Create)
+	admin.Get("/exchange-rates", fxH.List)
+	admin.Get("/exchange-rates/convert", fxH.Convert)
+
+	// Bank reconciliation routes
+	admin.Post("/bank-statements/import", recH.Import)
+	admin.Get("/bank-statements/:id/lines", recH.ListLines)
+	admin.Post("/bank-statements/:id/match", recH.Match)
+	admin.Post("/bank-statement-lines/:id/confirm", recH.Confirm)
+	admin.Post("/bank-statement-lines/:id/flag", recH.Flag)
 
 	// Transaction routes
-	admin.Post("/transactions", trxH.Create)
+	admin.Post("/transactions", idempotent, trxH.Create)
 	admin.Get("/transactions", trxH.List)
 	admin.Get("/transactions/:id", trxH.GetByID)
 	admin.Patch("/transactions/:id/status", trxH.UpdateStatus)
@@ -107,11 +356,30 @@ func RegisterRoutes(app *fiber.App, cfg config.Config, db *pgxpool.Pool) {
 	admin.Post("/products", productH.Create)
 	admin.Get("/products", productH.List)
 	admin.Patch("/products/:id", productH.Update)
+	admin.Delete("/products/:id", productH.Archive)
+	admin.Post("/products/:id/restore", productH.Restore)
 
 	// Product price routes
 	admin.Post("/products/:id/prices", priceH.CreateForProduct)
 	admin.Get("/products/:id/prices", priceH.ListForProduct)
+	admin.Get("/prices/upcoming", priceH.ListUpcoming)
+	admin.Post("/prices/bulk", priceH.Bulk)
+	admin.Post("/prices/import", priceH.Import)
 	admin.Patch("/prices/:id", priceH.Update)
+
+	// Pricing routes
+	admin.Get("/products/:id/effective-price", pricingH.EffectivePrice)
+	admin.Post("/pricing/quote", pricingH.Quote)
+
+	// Promotion routes
+	admin.Post("/promotions", promoH.Create)
+	admin.Get("/promotions", promoH.List)
+	admin.Get("/promotions/:id", promoH.GetByID)
+	admin.Patch("/promotions/:id", promoH.Update)
+
+	// Audit routes
+	admin.Get("/audit", auditH.List)
+	admin.Get("/audit/:id", auditH.GetByID)
 }
 
 type adminFinderAdapter struct {
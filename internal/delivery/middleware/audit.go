@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	audituc "github.com/riolentius/cahaya-gading-backend/internal/usecase/audit"
+)
+
+type AuditConfig struct {
+	UC *audituc.Usecase
+
+	// ReadOnlyPaths are POST endpoints that do not change anything (e.g. a
+	// price quote) and are not logged.
+	ReadOnlyPaths []string
+}
+
+// Audit logs every successful mutating request (POST, PUT, PATCH, DELETE).
+// Repositories that log their writes with before/after snapshots inside their
+// own DB transaction mark the request's trail; everything else is logged here
+// after the handler, with the request body as "after". Replayed idempotent
+// responses are not logged again.
+func Audit(cfg AuditConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		default:
+			return c.Next()
+		}
+		for _, p := range cfg.ReadOnlyPaths {
+			if c.Path() == p {
+				return c.Next()
+			}
+		}
+
+		trail := &audituc.Trail{Method: c.Method(), Path: c.Path()}
+		c.Locals(audituc.TrailKey, trail)
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() >= fiber.StatusBadRequest ||
+			trail.Recorded() ||
+			c.GetRespHeader("Idempotent-Replayed") != "" {
+			return nil
+		}
+
+		entity, action := auditTarget(c.Route().Path, c.Method())
+		in := audituc.RecordInput{
+			Action:   action,
+			Entity:   entity,
+			EntityID: auditEntityID(c),
+			Method:   trail.Method,
+			Path:     trail.Path,
+		}
+		if body := c.Body(); len(body) > 0 && json.Valid(body) {
+			in.After = append(json.RawMessage(nil), body...)
+		}
+
+		if err := cfg.UC.Record(c.Context(), in); err != nil {
+			log.Printf("[audit.record] %s %s failed: %v", trail.Method, trail.Path, err)
+		}
+		return nil
+	}
+}
+
+// auditTarget derives the entity and action from the route pattern:
+// "/api/admin/stock-takes/:id/approve" is ("stock_takes", "approve") and a
+// plain "PATCH /api/admin/customers/:id" is ("customers", "update").
+func auditTarget(route, method string) (entity, action string) {
+	if i := strings.Index(route, "/admin/"); i >= 0 {
+		route = route[i+len("/admin/"):]
+	}
+
+	var verbs []string
+	for i, seg := range strings.Split(strings.Trim(route, "/"), "/") {
+		if seg == "" || strings.HasPrefix(seg, ":") {
+			continue
+		}
+		seg = strings.ReplaceAll(seg, "-", "_")
+		if i == 0 {
+			entity = seg
+			continue
+		}
+		verbs = append(verbs, seg)
+	}
+	if len(verbs) > 0 {
+		return entity, strings.Join(verbs, "_")
+	}
+
+	switch method {
+	case fiber.MethodPost:
+		return entity, "create"
+	case fiber.MethodDelete:
+		return entity, "delete"
+	default:
+		return entity, "update"
+	}
+}
+
+// auditEntityID is the :id route param, or the id of the created resource.
+func auditEntityID(c *fiber.Ctx) *string {
+	if id := c.Params("id"); id != "" {
+		return &id
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(c.Response().Body(), &created); err == nil && created.ID != "" {
+		return &created.ID
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	idemuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/idempotency"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type IdempotencyConfig struct {
+	UC *idemuc.Usecase
+}
+
+// Idempotency makes a POST endpoint safe to retry.
+// Requests without an Idempotency-Key header pass through unchanged.
+// A replay with the same key and body returns the stored response;
+// the same key with a different body (or while the first one is running) gets 409.
+func Idempotency(cfg IdempotencyConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := strings.TrimSpace(c.Get(IdempotencyKeyHeader))
+		if key == "" {
+			return c.Next()
+		}
+
+		scope := AdminID(c)
+
+		rec, err := cfg.UC.Begin(c.Context(), idemuc.BeginInput{
+			Scope:  scope,
+			Key:    key,
+			Method: c.Method(),
+			Path:   c.Path(),
+			Body:   c.Body(),
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, idemuc.ErrInvalidKey):
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			case errors.Is(err, idemuc.ErrKeyReused), errors.Is(err, idemuc.ErrRequestInProgress):
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+			default:
+				log.Printf("[idempotency.begin] failed: %v", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "internal error"})
+			}
+		}
+
+		// replay stored response
+		if rec != nil {
+			if rec.ContentType != nil {
+				c.Set(fiber.HeaderContentType, *rec.ContentType)
+			}
+			c.Set("Idempotent-Replayed", "true")
+			return c.Status(*rec.StatusCode).Send(rec.ResponseBody)
+		}
+
+		if err := c.Next(); err != nil {
+			// error responses are rendered later by the error handler, nothing to store
+			if relErr := cfg.UC.Release(c.Context(), scope, key); relErr != nil {
+				log.Printf("[idempotency.release] failed: %v", relErr)
+			}
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			// let the client retry server errors with the same key
+			if relErr := cfg.UC.Release(c.Context(), scope, key); relErr != nil {
+				log.Printf("[idempotency.release] failed: %v", relErr)
+			}
+			return nil
+		}
+
+		body := append([]byte(nil), c.Response().Body()...)
+		if err := cfg.UC.Complete(c.Context(), idemuc.CompleteInput{
+			Scope:        scope,
+			Key:          key,
+			StatusCode:   status,
+			ContentType:  string(c.Response().Header.ContentType()),
+			ResponseBody: body,
+		}); err != nil {
+			log.Printf("[idempotency.complete] failed: %v", err)
+		}
+		return nil
+	}
+}
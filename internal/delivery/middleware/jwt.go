@@ -5,6 +5,8 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/riolentius/cahaya-gading-backend/internal/actor"
 )
 
 type JWTConfig struct {
@@ -40,6 +42,20 @@ func RequireAdminJWT(cfg JWTConfig) fiber.Handler {
 		}
 
 		c.Locals("claims", claims)
+		if sub, ok := claims["sub"].(string); ok {
+			c.Locals(actor.Key, sub)
+		}
 		return c.Next()
 	}
 }
+
+// AdminID returns the authenticated admin id (JWT "sub"), or "" if the request
+// did not pass through RequireAdminJWT.
+func AdminID(c *fiber.Ctx) string {
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	sub, _ := claims["sub"].(string)
+	return sub
+}
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	audituc "github.com/riolentius/cahaya-gading-backend/internal/usecase/audit"
+)
+
+type AuditStoreAdapter struct {
+	repo *AuditRepo
+}
+
+func NewAuditStoreAdapter(repo *AuditRepo) *AuditStoreAdapter {
+	return &AuditStoreAdapter{repo: repo}
+}
+
+func (a *AuditStoreAdapter) Record(ctx context.Context, in audituc.RecordInput) error {
+	return a.repo.Insert(ctx, AuditRow{
+		Action:   in.Action,
+		Entity:   in.Entity,
+		EntityID: in.EntityID,
+		After:    in.After,
+		Method:   &in.Method,
+		Path:     &in.Path,
+	})
+}
+
+func (a *AuditStoreAdapter) GetByID(ctx context.Context, id string) (*audituc.Entry, error) {
+	row, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, audituc.ErrNotFound
+		}
+		return nil, err
+	}
+	return mapEntry(row), nil
+}
+
+func (a *AuditStoreAdapter) List(ctx context.Context, q audituc.ListQuery) ([]audituc.Entry, error) {
+	rows, err := a.repo.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]audituc.Entry, 0, len(rows))
+	for i := range rows {
+		out = append(out, *mapEntry(&rows[i]))
+	}
+	return out, nil
+}
+
+func mapEntry(r *AuditRow) *audituc.Entry {
+	return &audituc.Entry{
+		ID:         r.ID,
+		ActorID:    r.ActorID,
+		ActorEmail: r.ActorEmail,
+		Action:     r.Action,
+		Entity:     r.Entity,
+		EntityID:   r.EntityID,
+		Before:     rawOrNil(r.Before),
+		After:      rawOrNil(r.After),
+		Changes:    rawOrNil(r.Changes),
+		RequestID:  r.RequestID,
+		Method:     r.Method,
+		Path:       r.Path,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+func rawOrNil(b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return nil
+	}
+	return json.RawMessage(b)
+}
+
+// Compile-time check
+var _ audituc.Store = (*AuditStoreAdapter)(nil)
@@ -0,0 +1,260 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/riolentius/cahaya-gading-backend/internal/actor"
+	audituc "github.com/riolentius/cahaya-gading-backend/internal/usecase/audit"
+)
+
+type AuditRow struct {
+	ID         string
+	ActorID    *string
+	ActorEmail *string
+	Action     string
+	Entity     string
+	EntityID   *string
+	Before     []byte
+	After      []byte
+	Changes    []byte
+	RequestID  *string
+	Method     *string
+	Path       *string
+	CreatedAt  time.Time
+}
+
+type AuditRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepo(db *pgxpool.Pool) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+// Querier is satisfied by pgx.Tx and *pgxpool.Pool.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Change is a mutation logged by a repository inside its DB transaction.
+// Before and After are JSON snapshots of the row (see Snapshot); Before is
+// nil on create.
+type Change struct {
+	Action   string
+	Entity   string
+	EntityID string
+	Before   []byte
+	After    []byte
+}
+
+// Entity names of the in-transaction entries (the table names).
+const (
+	EntityCustomers     = "customers"
+	EntityProducts      = "products"
+	EntityProductPrices = "product_prices"
+)
+
+// Actions of the in-transaction entries.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionArchive = "archive"
+	ActionRestore = "restore"
+	ActionMerge   = "merge"
+)
+
+// Snapshot returns the row of table with the given id as JSON.
+func Snapshot(ctx context.Context, db Querier, table, id string) ([]byte, error) {
+	q := `SELECT to_jsonb(t) FROM ` + pgx.Identifier{table}.Sanitize() + ` t WHERE t.id = $1::uuid;`
+
+	var out []byte
+	if err := db.QueryRow(ctx, q, id).Scan(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Record logs c in the caller's DB transaction with the acting admin and the
+// request id from ctx, and marks the request's trail so the audit middleware
+// does not log it a second time.
+func Record(ctx context.Context, db Querier, c Change) error {
+	changes, err := diff(c.Before, c.After)
+	if err != nil {
+		return err
+	}
+
+	in := AuditRow{
+		Action:   c.Action,
+		Entity:   c.Entity,
+		EntityID: &c.EntityID,
+		Before:   c.Before,
+		After:    c.After,
+		Changes:  changes,
+	}
+	if t := audituc.TrailFrom(ctx); t != nil {
+		in.Method = &t.Method
+		in.Path = &t.Path
+	}
+	if err := insertEntry(ctx, db, in); err != nil {
+		return err
+	}
+
+	audituc.TrailFrom(ctx).MarkRecorded()
+	return nil
+}
+
+func insertEntry(ctx context.Context, db Querier, in AuditRow) error {
+	if id := actor.AdminID(ctx); id != "" {
+		in.ActorID = &id
+	}
+	if id := actor.RequestID(ctx); id != "" {
+		in.RequestID = &id
+	}
+
+	const q = `
+INSERT INTO audit_log (actor_id, action, entity, entity_id, before, after, changes, request_id, method, path)
+VALUES ($1::uuid, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+`
+	_, err := db.Exec(ctx, q,
+		in.ActorID, in.Action, in.Entity, in.EntityID,
+		jsonOrNil(in.Before), jsonOrNil(in.After), jsonOrNil(in.Changes),
+		in.RequestID, in.Method, in.Path,
+	)
+	return err
+}
+
+// diff returns {"field": {"from": old, "to": new}} for every top-level field
+// that differs between the two snapshots, updated_at aside. It returns nil
+// when nothing changed.
+func diff(before, after []byte) ([]byte, error) {
+	var b, a map[string]any
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &b); err != nil {
+			return nil, err
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &a); err != nil {
+			return nil, err
+		}
+	}
+
+	type change struct {
+		From any `json:"from"`
+		To   any `json:"to"`
+	}
+	out := map[string]change{}
+	for k, v := range a {
+		if k == "updated_at" {
+			continue
+		}
+		if old, ok := b[k]; !ok || !reflect.DeepEqual(old, v) {
+			out[k] = change{From: b[k], To: v}
+		}
+	}
+	for k, v := range b {
+		if _, ok := a[k]; !ok && k != "updated_at" {
+			out[k] = change{From: v}
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(out)
+}
+
+func jsonOrNil(b []byte) any {
+	if len(b) == 0 {
+		return nil
+	}
+	return json.RawMessage(b)
+}
+
+const auditColumns = `
+  e.id::text, e.actor_id::text, a.email, e.action, e.entity, e.entity_id,
+  e.before, e.after, e.changes, e.request_id, e.method, e.path, e.created_at
+`
+
+func scanAuditRow(row pgx.Row) (*AuditRow, error) {
+	var out AuditRow
+	if err := row.Scan(
+		&out.ID,
+		&out.ActorID,
+		&out.ActorEmail,
+		&out.Action,
+		&out.Entity,
+		&out.EntityID,
+		&out.Before,
+		&out.After,
+		&out.Changes,
+		&out.RequestID,
+		&out.Method,
+		&out.Path,
+		&out.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *AuditRepo) Insert(ctx context.Context, in AuditRow) error {
+	return insertEntry(ctx, r.db, in)
+}
+
+func (r *AuditRepo) GetByID(ctx context.Context, id string) (*AuditRow, error) {
+	q := `
+SELECT` + auditColumns + `
+FROM audit_log e
+LEFT JOIN admins a ON a.id = e.actor_id
+WHERE e.id = $1::uuid;
+`
+	return scanAuditRow(r.db.QueryRow(ctx, q, id))
+}
+
+func (r *AuditRepo) List(ctx context.Context, q audituc.ListQuery) ([]AuditRow, error) {
+	sql := `
+SELECT` + auditColumns + `
+FROM audit_log e
+LEFT JOIN admins a ON a.id = e.actor_id
+WHERE ($1::uuid IS NULL OR e.actor_id = $1::uuid)
+  AND ($2::text IS NULL OR e.entity = $2)
+  AND ($3::text IS NULL OR e.entity_id = $3)
+  AND ($4::text IS NULL OR e.action = $4)
+  AND ($5::text IS NULL OR e.request_id = $5)
+  AND ($6::timestamptz IS NULL OR e.created_at >= $6)
+  AND ($7::timestamptz IS NULL OR e.created_at < $7)
+ORDER BY e.created_at DESC, e.id
+LIMIT $8 OFFSET $9;
+`
+	rows, err := r.db.Query(ctx, sql,
+		q.ActorID, q.Entity, q.EntityID, q.Action, q.RequestID, q.From, q.To,
+		q.Limit, q.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]AuditRow, 0, q.Limit)
+	for rows.Next() {
+		row, err := scanAuditRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *row)
+	}
+	return out, rows.Err()
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/riolentius/cahaya-gading-backend/internal/actor"
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	audituc "github.com/riolentius/cahaya-gading-backend/internal/usecase/audit"
+)
+
+func TestAudit_RecordDiffAndSearch(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	adminID := testutil.MustInsertAdmin(t, db, "auditor@example.com")
+	customerID := testutil.MustInsertCustomer(t, db, "Rio", "Audit", "rio.audit@example.com", nil)
+
+	trail := &audituc.Trail{Method: "PATCH", Path: "/api/admin/customers/" + customerID}
+	ctx := actor.WithRequestID(actor.WithAdminID(context.Background(), adminID), "req-1")
+	ctx = context.WithValue(ctx, audituc.TrailKey, trail)
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := Snapshot(ctx, tx, EntityCustomers, customerID)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE customers SET phone = '0812', updated_at = now() WHERE id = $1::uuid`, customerID); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	after, err := Snapshot(ctx, tx, EntityCustomers, customerID)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if err := Record(ctx, tx, Change{Action: ActionUpdate, Entity: EntityCustomers, EntityID: customerID, Before: before, After: after}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if !trail.Recorded() {
+		t.Fatalf("expected the trail to be marked")
+	}
+
+	uc := audituc.New(NewAuditStoreAdapter(NewAuditRepo(db)))
+
+	// an HTTP-level entry for another request
+	if err := uc.Record(actor.WithAdminID(context.Background(), adminID), audituc.RecordInput{
+		Action: "fulfill", Entity: "transactions", Method: "POST", Path: "/api/admin/transactions/x/fulfill",
+	}); err != nil {
+		t.Fatalf("record http: %v", err)
+	}
+
+	entity, requestID := EntityCustomers, "req-1"
+	got, err := uc.List(context.Background(), audituc.ListQuery{Entity: &entity, EntityID: &customerID, RequestID: &requestID})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 entry got=%d", len(got))
+	}
+	e := got[0]
+	if e.ActorEmail == nil || !strings.HasSuffix(*e.ActorEmail, "auditor@example.com") || e.Action != ActionUpdate {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+
+	var changes map[string]struct {
+		From any `json:"from"`
+		To   any `json:"to"`
+	}
+	if err := json.Unmarshal(e.Changes, &changes); err != nil {
+		t.Fatalf("changes: %v", err)
+	}
+	if len(changes) != 1 || changes["phone"].From != nil || changes["phone"].To != "0812" {
+		t.Fatalf("expected only the phone change got=%s", e.Changes)
+	}
+
+	all, err := uc.List(context.Background(), audituc.ListQuery{ActorID: &adminID})
+	if err != nil {
+		t.Fatalf("list by actor: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 entries by the admin got=%d", len(all))
+	}
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+
+	credituc "github.com/riolentius/cahaya-gading-backend/internal/usecase/credit"
+)
+
+type CreditStoreAdapter struct {
+	repo *CreditRepo
+}
+
+func NewCreditStoreAdapter(repo *CreditRepo) *CreditStoreAdapter {
+	return &CreditStoreAdapter{repo: repo}
+}
+
+func (a *CreditStoreAdapter) CustomerExists(ctx context.Context, customerID string) (bool, error) {
+	return a.repo.CustomerExists(ctx, customerID)
+}
+
+func (a *CreditStoreAdapter) GetBalances(ctx context.Context, customerID string) ([]credituc.Balance, error) {
+	rows, err := a.repo.GetBalances(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]credituc.Balance, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, credituc.Balance{Currency: r.Currency, Amount: r.Amount})
+	}
+	return out, nil
+}
+
+func (a *CreditStoreAdapter) ListEntries(ctx context.Context, customerID string, q credituc.HistoryQuery) ([]credituc.Entry, error) {
+	rows, err := a.repo.ListEntries(ctx, customerID, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]credituc.Entry, 0, len(rows))
+	for i := range rows {
+		out = append(out, mapCreditEntryRow(&rows[i]))
+	}
+	return out, nil
+}
+
+func mapCreditEntryRow(r *CreditEntryRow) credituc.Entry {
+	return credituc.Entry{
+		ID:            r.ID,
+		CustomerID:    r.CustomerID,
+		TransactionID: r.TransactionID,
+		PaymentID:     r.PaymentID,
+		Kind:          r.Kind,
+		Amount:        r.Amount,
+		Currency:      r.Currency,
+		Note:          r.Note,
+		CreatedAt:     r.CreatedAt,
+	}
+}
+
+// Compile-time check
+var _ credituc.Store = (*CreditStoreAdapter)(nil)
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CreditEntryRow struct {
+	ID            string
+	CustomerID    string
+	TransactionID *string
+	PaymentID     *string
+	Kind          string
+	Amount        string
+	Currency      string
+	Note          *string
+	CreatedAt     time.Time
+}
+
+type CreditBalanceRow struct {
+	Currency string
+	Amount   string
+}
+
+type CreditRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewCreditRepo(db *pgxpool.Pool) *CreditRepo {
+	return &CreditRepo{db: db}
+}
+
+func (r *CreditRepo) CustomerExists(ctx context.Context, customerID string) (bool, error) {
+	const q = `SELECT 1 FROM customers WHERE id = $1::uuid`
+	var one int
+	if err := r.db.QueryRow(ctx, q, customerID).Scan(&one); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *CreditRepo) GetBalances(ctx context.Context, customerID string) ([]CreditBalanceRow, error) {
+	const q = `
+SELECT currency, SUM(amount)::text
+FROM customer_credit_entries
+WHERE customer_id = $1::uuid
+GROUP BY currency
+ORDER BY currency;
+`
+	rows, err := r.db.Query(ctx, q, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]CreditBalanceRow, 0, 1)
+	for rows.Next() {
+		var b CreditBalanceRow
+		if err := rows.Scan(&b.Currency, &b.Amount); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+func (r *CreditRepo) ListEntries(ctx context.Context, customerID string, limit, offset int) ([]CreditEntryRow, error) {
+	const q = `
+SELECT
+  id::text,
+  customer_id::text,
+  transaction_id::text,
+  payment_id::text,
+  kind,
+  amount::text,
+  currency,
+  note,
+  created_at
+FROM customer_credit_entries
+WHERE customer_id = $1::uuid
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+`
+	rows, err := r.db.Query(ctx, q, customerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]CreditEntryRow, 0, limit)
+	for rows.Next() {
+		var e CreditEntryRow
+		if err := rows.Scan(
+			&e.ID,
+			&e.CustomerID,
+			&e.TransactionID,
+			&e.PaymentID,
+			&e.Kind,
+			&e.Amount,
+			&e.Currency,
+			&e.Note,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -47,7 +47,7 @@ func (a *CustomerStoreAdapter) GetByID(ctx context.Context, id string) (*custome
 }
 
 func (a *CustomerStoreAdapter) List(ctx context.Context, q customeruc.ListQuery) ([]customeruc.Customer, error) {
-	rows, err := a.repo.List(ctx, q.Limit, q.Offset)
+	rows, err := a.repo.List(ctx, q.Search, searchPhone(q.Search), q.Limit, q.Offset, q.IncludeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -64,9 +64,7 @@ func (a *CustomerStoreAdapter) Update(ctx context.Context, id string, in custome
 		rowIn.FirstName = *in.FirstName
 	}
 
-	if in.Email != nil {
-		rowIn.Email = *in.Email
-	}
+	rowIn.Email = in.Email
 
 	rowIn.LastName = in.LastName
 	rowIn.Phone = in.Phone
@@ -86,6 +84,53 @@ func (a *CustomerStoreAdapter) Update(ctx context.Context, id string, in custome
 	return mapCustomer(row), nil
 }
 
+func (a *CustomerStoreAdapter) SetArchived(ctx context.Context, id string, archived bool) (*customeruc.Customer, error) {
+	row, err := a.repo.SetArchived(ctx, id, archived)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customeruc.ErrNotFound
+		}
+		return nil, err
+	}
+	return mapCustomer(row), nil
+}
+
+func (a *CustomerStoreAdapter) Merge(ctx context.Context, survivorID, duplicateID string) (*customeruc.MergeResult, error) {
+	row, counts, err := a.repo.Merge(ctx, survivorID, duplicateID)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, customeruc.ErrNotFound
+		case errors.Is(err, errAlreadyMerged):
+			return nil, customeruc.ErrAlreadyMerged
+		case errors.Is(err, errArchived):
+			return nil, customeruc.ErrArchived
+		case isUniqueViolation(err):
+			return nil, customeruc.ErrEmailConflict
+		}
+		return nil, err
+	}
+	return &customeruc.MergeResult{
+		Customer:      *mapCustomer(row),
+		Transactions:  counts.Transactions,
+		Addresses:     counts.Addresses,
+		CreditEntries: counts.CreditEntries,
+		Receipts:      counts.Receipts,
+	}, nil
+}
+
+// searchPhone is the search as an E.164 phone, so "0812..." finds "+62812...".
+func searchPhone(search *string) *string {
+	if search == nil {
+		return nil
+	}
+	p, err := customeruc.NormalizePhone(*search)
+	if err != nil {
+		return nil
+	}
+	return &p
+}
+
 func mapCustomer(r *CustomerRow) *customeruc.Customer {
 	return &customeruc.Customer{
 		ID:                   r.ID,
@@ -97,5 +142,7 @@ func mapCustomer(r *CustomerRow) *customeruc.Customer {
 		CategoryID:           r.CategoryID,
 		CreatedAt:            r.CreatedAt,
 		UpdatedAt:            r.UpdatedAt,
+		DeletedAt:            r.DeletedAt,
+		MergedIntoID:         r.MergedIntoID,
 	}
 }
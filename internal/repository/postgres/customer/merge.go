@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	auditpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/audit"
+)
+
+var (
+	errAlreadyMerged = errors.New("customer already merged")
+	errArchived      = errors.New("customer archived")
+)
+
+type MergeCounts struct {
+	Transactions  int
+	Addresses     int
+	CreditEntries int
+	Receipts      int
+}
+
+// Merge moves the duplicate's transactions, addresses, credit entries and
+// receipts to the survivor, copies the contact details the survivor lacks and
+// archives the duplicate with merged_into_id set, all in one DB transaction.
+// Moved addresses lose their default flag when the survivor has a default.
+func (r *CustomerRepo) Merge(ctx context.Context, survivorID, duplicateID string) (*CustomerRow, *MergeCounts, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// lock both in id order so concurrent merges of the same pair cannot deadlock
+	rows, err := tx.Query(ctx, `
+SELECT id::text, merged_into_id IS NOT NULL, deleted_at IS NOT NULL, email
+FROM customers
+WHERE id IN ($1::uuid, $2::uuid)
+ORDER BY id
+FOR UPDATE;
+`, survivorID, duplicateID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var found int
+	var dupEmail *string
+	for rows.Next() {
+		var id string
+		var merged, archived bool
+		var email *string
+		if err := rows.Scan(&id, &merged, &archived, &email); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		if merged {
+			rows.Close()
+			return nil, nil, errAlreadyMerged
+		}
+		// an archived customer would stay hidden with the moved history
+		if archived {
+			rows.Close()
+			return nil, nil, errArchived
+		}
+		if id == duplicateID {
+			dupEmail = email
+		}
+		found++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if found != 2 {
+		return nil, nil, pgx.ErrNoRows
+	}
+
+	survivorBefore, err := auditpg.Snapshot(ctx, tx, auditpg.EntityCustomers, survivorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	duplicateBefore, err := auditpg.Snapshot(ctx, tx, auditpg.EntityCustomers, duplicateID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var counts MergeCounts
+	move := func(dst *int, q string) error {
+		tag, err := tx.Exec(ctx, q, survivorID, duplicateID)
+		if err != nil {
+			return err
+		}
+		*dst = int(tag.RowsAffected())
+		return nil
+	}
+	if err := move(&counts.Transactions, `
+UPDATE transactions SET customer_id = $1::uuid, updated_at = now() WHERE customer_id = $2::uuid;
+`); err != nil {
+		return nil, nil, err
+	}
+	if err := move(&counts.Addresses, `
+UPDATE customer_addresses
+SET customer_id = $1::uuid,
+    is_default = is_default AND NOT EXISTS (
+      SELECT 1 FROM customer_addresses WHERE customer_id = $1::uuid AND is_default
+    ),
+    updated_at = now()
+WHERE customer_id = $2::uuid;
+`); err != nil {
+		return nil, nil, err
+	}
+	if err := move(&counts.CreditEntries, `
+UPDATE customer_credit_entries SET customer_id = $1::uuid WHERE customer_id = $2::uuid;
+`); err != nil {
+		return nil, nil, err
+	}
+	if err := move(&counts.Receipts, `
+UPDATE receipts SET customer_id = $1::uuid, updated_at = now() WHERE customer_id = $2::uuid;
+`); err != nil {
+		return nil, nil, err
+	}
+
+	// the email is unique: release it from the duplicate before the survivor takes it
+	if _, err := tx.Exec(ctx, `
+UPDATE customers
+SET merged_into_id = $1::uuid,
+    email = NULL,
+    deleted_at = COALESCE(deleted_at, now()),
+    updated_at = now()
+WHERE id = $2::uuid;
+`, survivorID, duplicateID); err != nil {
+		return nil, nil, err
+	}
+
+	const q = `
+UPDATE customers s
+SET email = COALESCE(s.email, $3),
+    last_name = COALESCE(s.last_name, d.last_name),
+    phone = COALESCE(s.phone, d.phone),
+    identification_number = COALESCE(s.identification_number, d.identification_number),
+    category_id = COALESCE(s.category_id, d.category_id),
+    updated_at = now()
+FROM customers d
+WHERE s.id = $1::uuid
+  AND d.id = $2::uuid
+RETURNING
+  s.id::text, s.first_name, s.last_name, s.email, s.phone, s.identification_number, s.category_id,
+  s.created_at, s.updated_at, s.deleted_at, s.merged_into_id::text;
+`
+	var out CustomerRow
+	if err := tx.QueryRow(ctx, q, survivorID, duplicateID, dupEmail).Scan(
+		&out.ID,
+		&out.FirstName,
+		&out.LastName,
+		&out.Email,
+		&out.Phone,
+		&out.IdentificationNumber,
+		&out.CategoryID,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+		&out.DeletedAt,
+		&out.MergedIntoID,
+	); err != nil {
+		return nil, nil, err
+	}
+
+	if err := recordCustomerChange(ctx, tx, auditpg.ActionMerge, duplicateID, duplicateBefore); err != nil {
+		return nil, nil, err
+	}
+	if err := recordCustomerChange(ctx, tx, auditpg.ActionMerge, survivorID, survivorBefore); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return &out, &counts, nil
+}
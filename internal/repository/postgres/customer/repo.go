@@ -9,18 +9,22 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	auditpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/audit"
 )
 
 type CustomerRow struct {
 	ID                   string
 	FirstName            string
 	LastName             *string
-	Email                string
+	Email                *string
 	Phone                *string
 	IdentificationNumber *string
 	CategoryID           *string
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
+	DeletedAt            *time.Time
+	MergedIntoID         *string
 }
 
 type CustomerRepo struct {
@@ -39,12 +43,18 @@ INSERT INTO customers (
   $1, $2, $3, $4, $5, $6, $7
 )
 RETURNING
-  id::text, first_name, last_name, email, phone, identification_number, category_id, created_at, updated_at;
+  id::text, first_name, last_name, email, phone, identification_number, category_id, created_at, updated_at, deleted_at, merged_into_id::text;
 `
 	id := uuid.New().String()
 
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
 	var out CustomerRow
-	err := r.db.QueryRow(ctx, q,
+	err = tx.QueryRow(ctx, q,
 		id,
 		in.FirstName,
 		in.LastName,
@@ -62,17 +72,27 @@ RETURNING
 		&out.CategoryID,
 		&out.CreatedAt,
 		&out.UpdatedAt,
+		&out.DeletedAt,
+		&out.MergedIntoID,
 	)
 	if err != nil {
 		return nil, err
 	}
+
+	if err := recordCustomerChange(ctx, tx, auditpg.ActionCreate, out.ID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &out, nil
 }
 
 func (r *CustomerRepo) GetByID(ctx context.Context, id string) (*CustomerRow, error) {
 	const q = `
 SELECT
-  id::text, first_name, last_name, email, phone, identification_number, category_id, created_at, updated_at
+  id::text, first_name, last_name, email, phone, identification_number, category_id, created_at, updated_at, deleted_at, merged_into_id::text
 FROM customers
 WHERE id = $1
 LIMIT 1;
@@ -88,6 +108,8 @@ LIMIT 1;
 		&out.CategoryID,
 		&out.CreatedAt,
 		&out.UpdatedAt,
+		&out.DeletedAt,
+		&out.MergedIntoID,
 	); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, pgx.ErrNoRows
@@ -97,15 +119,24 @@ LIMIT 1;
 	return &out, nil
 }
 
-func (r *CustomerRepo) List(ctx context.Context, limit, offset int) ([]CustomerRow, error) {
+// List hides archived customers unless includeArchived. search matches the
+// name, email or phone (case-insensitive, substring); phone, when set, is the
+// search normalized as a phone number and matched exactly.
+func (r *CustomerRepo) List(ctx context.Context, search, phone *string, limit, offset int, includeArchived bool) ([]CustomerRow, error) {
 	const q = `
 SELECT
-  id::text, first_name, last_name, email, phone, identification_number, category_id, created_at, updated_at
+  id::text, first_name, last_name, email, phone, identification_number, category_id, created_at, updated_at, deleted_at, merged_into_id::text
 FROM customers
+WHERE ($3::boolean OR deleted_at IS NULL)
+  AND ($4::text IS NULL
+    OR first_name || COALESCE(' ' || last_name, '') ILIKE '%' || $4 || '%'
+    OR email ILIKE '%' || $4 || '%'
+    OR phone LIKE '%' || $4 || '%'
+    OR phone = $5::text)
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
 `
-	rows, err := r.db.Query(ctx, q, limit, offset)
+	rows, err := r.db.Query(ctx, q, limit, offset, includeArchived, search, phone)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +155,8 @@ LIMIT $1 OFFSET $2;
 			&c.CategoryID,
 			&c.CreatedAt,
 			&c.UpdatedAt,
+			&c.DeletedAt,
+			&c.MergedIntoID,
 		); err != nil {
 			return nil, err
 		}
@@ -145,11 +178,22 @@ SET
   updated_at = now()
 WHERE id = $1
 RETURNING
-  id::text, first_name, last_name, email, phone, identification_number, category_id, created_at, updated_at;
+  id::text, first_name, last_name, email, phone, identification_number, category_id, created_at, updated_at, deleted_at, merged_into_id::text;
 `
 
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := auditpg.Snapshot(ctx, tx, auditpg.EntityCustomers, id)
+	if err != nil {
+		return nil, err
+	}
+
 	var out CustomerRow
-	err := r.db.QueryRow(ctx, q,
+	err = tx.QueryRow(ctx, q,
 		id,
 		nullIfEmptyStrPtr(in.FirstName),
 		in.LastName,
@@ -167,6 +211,8 @@ RETURNING
 		&out.CategoryID,
 		&out.CreatedAt,
 		&out.UpdatedAt,
+		&out.DeletedAt,
+		&out.MergedIntoID,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -174,9 +220,86 @@ RETURNING
 		}
 		return nil, err
 	}
+
+	if err := recordCustomerChange(ctx, tx, auditpg.ActionUpdate, out.ID, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetArchived archives (soft-deletes) or restores the customer. Archiving an
+// archived customer, or restoring an active one, changes nothing.
+func (r *CustomerRepo) SetArchived(ctx context.Context, id string, archived bool) (*CustomerRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := auditpg.Snapshot(ctx, tx, auditpg.EntityCustomers, id)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `
+UPDATE customers
+SET
+  deleted_at = CASE WHEN $2::boolean THEN COALESCE(deleted_at, now()) END,
+  updated_at = CASE WHEN (deleted_at IS NULL) = $2::boolean THEN now() ELSE updated_at END
+WHERE id = $1
+RETURNING
+  id::text, first_name, last_name, email, phone, identification_number, category_id, created_at, updated_at, deleted_at, merged_into_id::text;
+`
+	var out CustomerRow
+	if err := tx.QueryRow(ctx, q, id, archived).Scan(
+		&out.ID,
+		&out.FirstName,
+		&out.LastName,
+		&out.Email,
+		&out.Phone,
+		&out.IdentificationNumber,
+		&out.CategoryID,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+		&out.DeletedAt,
+		&out.MergedIntoID,
+	); err != nil {
+		return nil, err
+	}
+
+	action := auditpg.ActionRestore
+	if archived {
+		action = auditpg.ActionArchive
+	}
+	if err := recordCustomerChange(ctx, tx, action, out.ID, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &out, nil
 }
 
+// recordCustomerChange logs the write in the audit log, in tx.
+func recordCustomerChange(ctx context.Context, tx pgx.Tx, action, id string, before []byte) error {
+	after, err := auditpg.Snapshot(ctx, tx, auditpg.EntityCustomers, id)
+	if err != nil {
+		return err
+	}
+	return auditpg.Record(ctx, tx, auditpg.Change{
+		Action:   action,
+		Entity:   auditpg.EntityCustomers,
+		EntityID: id,
+		Before:   before,
+		After:    after,
+	})
+}
+
 func isUniqueViolation(err error) bool {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	customeruc "github.com/riolentius/cahaya-gading-backend/internal/usecase/customer"
+)
+
+func TestCustomer_PhoneSearchAndMerge(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+	uc := customeruc.New(NewCustomerStoreAdapter(NewCustomerRepo(db)))
+
+	// walk-in customer known by phone only
+	phone := "0812-3456-7890"
+	walkIn, err := uc.Create(ctx, customeruc.CreateInput{FirstName: "Budi", Phone: &phone})
+	if err != nil {
+		t.Fatalf("create walk-in: %v", err)
+	}
+	if walkIn.Email != nil || walkIn.Phone == nil || *walkIn.Phone != "+6281234567890" {
+		t.Fatalf("unexpected walk-in: %+v", walkIn)
+	}
+
+	email := "budi@example.com"
+	survivor, err := uc.Create(ctx, customeruc.CreateInput{FirstName: "Budi", LastName: strPtr("Santoso"), Email: &email})
+	if err != nil {
+		t.Fatalf("create survivor: %v", err)
+	}
+
+	search := "81234567890"
+	found, err := uc.List(ctx, customeruc.ListQuery{Search: &search})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != walkIn.ID {
+		t.Fatalf("expected the walk-in by phone got=%+v", found)
+	}
+
+	// the duplicate's history moves over
+	if _, err := db.Exec(ctx, `INSERT INTO transactions (customer_id) VALUES ($1::uuid), ($1::uuid)`, walkIn.ID); err != nil {
+		t.Fatalf("seed transactions: %v", err)
+	}
+	if _, err := db.Exec(ctx, `
+		INSERT INTO customer_addresses (customer_id, address_line1, is_default) VALUES ($1::uuid, 'Jl. Lama 1', true), ($2::uuid, 'Jl. Baru 2', true)
+	`, walkIn.ID, survivor.ID); err != nil {
+		t.Fatalf("seed addresses: %v", err)
+	}
+	if _, err := db.Exec(ctx, `INSERT INTO customer_credit_entries (customer_id, kind, amount) VALUES ($1::uuid, 'adjustment', 5000)`, walkIn.ID); err != nil {
+		t.Fatalf("seed credit: %v", err)
+	}
+	if _, err := db.Exec(ctx, `INSERT INTO receipts (customer_id, method, amount) VALUES ($1::uuid, 'cash', 10000)`, walkIn.ID); err != nil {
+		t.Fatalf("seed receipt: %v", err)
+	}
+
+	res, err := uc.Merge(ctx, survivor.ID, customeruc.MergeInput{DuplicateID: walkIn.ID})
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if res.Transactions != 2 || res.Addresses != 1 || res.CreditEntries != 1 || res.Receipts != 1 {
+		t.Fatalf("unexpected counts: %+v", res)
+	}
+	if res.Customer.Phone == nil || *res.Customer.Phone != "+6281234567890" {
+		t.Fatalf("expected the survivor to take the phone got=%+v", res.Customer)
+	}
+
+	var defaults int
+	if err := db.QueryRow(ctx, `SELECT count(*) FROM customer_addresses WHERE customer_id = $1::uuid AND is_default`, survivor.ID).Scan(&defaults); err != nil {
+		t.Fatalf("count defaults: %v", err)
+	}
+	if defaults != 1 {
+		t.Fatalf("expected one default address got=%d", defaults)
+	}
+
+	dup, err := uc.GetByID(ctx, walkIn.ID)
+	if err != nil {
+		t.Fatalf("get duplicate: %v", err)
+	}
+	if dup.DeletedAt == nil || dup.MergedIntoID == nil || *dup.MergedIntoID != survivor.ID {
+		t.Fatalf("expected the duplicate archived and pointing at the survivor got=%+v", dup)
+	}
+
+	if _, err := uc.Merge(ctx, survivor.ID, customeruc.MergeInput{DuplicateID: walkIn.ID}); err != customeruc.ErrAlreadyMerged {
+		t.Fatalf("expected ErrAlreadyMerged got=%v", err)
+	}
+
+	// archived customers are restored before they can take part in a merge
+	archivedID := testutil.MustInsertCustomer(t, db, "Arsip", "Lama", "arsip@test.local", nil)
+	if _, err := uc.Archive(ctx, archivedID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if _, err := uc.Merge(ctx, survivor.ID, customeruc.MergeInput{DuplicateID: archivedID}); err != customeruc.ErrArchived {
+		t.Fatalf("expected ErrArchived got=%v", err)
+	}
+	if _, err := uc.Merge(ctx, archivedID, customeruc.MergeInput{DuplicateID: survivor.ID}); err != customeruc.ErrArchived {
+		t.Fatalf("expected ErrArchived for an archived survivor got=%v", err)
+	}
+}
+
+func strPtr(s string) *string { return &s }
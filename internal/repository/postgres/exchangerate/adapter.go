@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	fxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/exchangerate"
+)
+
+type ExchangeRateStoreAdapter struct {
+	repo *ExchangeRateRepo
+}
+
+func NewExchangeRateStoreAdapter(repo *ExchangeRateRepo) *ExchangeRateStoreAdapter {
+	return &ExchangeRateStoreAdapter{repo: repo}
+}
+
+func (a *ExchangeRateStoreAdapter) Create(ctx context.Context, in fxuc.CreateInput) (*fxuc.Rate, error) {
+	row, err := a.repo.Create(ctx, in.FromCurrency, in.ToCurrency, in.Rate, *in.EffectiveAt, in.Source)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fxuc.ErrRateConflict
+		}
+		return nil, err
+	}
+	out := mapRateRow(row)
+	return &out, nil
+}
+
+func (a *ExchangeRateStoreAdapter) List(ctx context.Context, q fxuc.ListQuery) ([]fxuc.Rate, error) {
+	rows, err := a.repo.List(ctx, q.FromCurrency, q.ToCurrency, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]fxuc.Rate, 0, len(rows))
+	for i := range rows {
+		out = append(out, mapRateRow(&rows[i]))
+	}
+	return out, nil
+}
+
+func (a *ExchangeRateStoreAdapter) Resolve(ctx context.Context, from, to string, at time.Time) (*fxuc.ResolvedRate, error) {
+	row, err := a.repo.Resolve(ctx, from, to, at)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, fxuc.ErrRateMissing
+		}
+		return nil, err
+	}
+	return &fxuc.ResolvedRate{
+		RateID:      row.RateID,
+		Rate:        row.Rate,
+		Inverted:    row.Inverted,
+		EffectiveAt: row.EffectiveAt,
+	}, nil
+}
+
+func mapRateRow(r *RateRow) fxuc.Rate {
+	return fxuc.Rate{
+		ID:           r.ID,
+		FromCurrency: r.FromCurrency,
+		ToCurrency:   r.ToCurrency,
+		Rate:         r.Rate,
+		EffectiveAt:  r.EffectiveAt,
+		Source:       r.Source,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+// Compile-time check
+var _ fxuc.Store = (*ExchangeRateStoreAdapter)(nil)
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RateRow struct {
+	ID           string
+	FromCurrency string
+	ToCurrency   string
+	Rate         string
+	EffectiveAt  time.Time
+	Source       *string
+	CreatedAt    time.Time
+}
+
+// ResolvedRateRow is the rate converting from -> to; Inverted means it was
+// derived (1/rate) from the reverse pair.
+type ResolvedRateRow struct {
+	RateID      string
+	Rate        string
+	Inverted    bool
+	EffectiveAt time.Time
+}
+
+// Queryer is satisfied by *pgxpool.Pool and pgx.Tx, so rate lookups can run
+// inside another repository's DB transaction.
+type Queryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type ExchangeRateRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewExchangeRateRepo(db *pgxpool.Pool) *ExchangeRateRepo {
+	return &ExchangeRateRepo{db: db}
+}
+
+const rateColumns = `
+  id::text,
+  from_currency,
+  to_currency,
+  rate::text,
+  effective_at,
+  source,
+  created_at
+`
+
+func scanRateRow(row pgx.Row) (*RateRow, error) {
+	var out RateRow
+	if err := row.Scan(&out.ID, &out.FromCurrency, &out.ToCurrency, &out.Rate, &out.EffectiveAt, &out.Source, &out.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *ExchangeRateRepo) Create(ctx context.Context, from, to, rate string, effectiveAt time.Time, source *string) (*RateRow, error) {
+	q := `
+INSERT INTO exchange_rates (from_currency, to_currency, rate, effective_at, source)
+VALUES ($1, $2, $3::numeric, $4, $5)
+RETURNING ` + rateColumns + `;
+`
+	return scanRateRow(r.db.QueryRow(ctx, q, from, to, rate, effectiveAt, source))
+}
+
+func (r *ExchangeRateRepo) List(ctx context.Context, from, to *string, limit, offset int) ([]RateRow, error) {
+	q := `
+SELECT ` + rateColumns + `
+FROM exchange_rates
+WHERE ($1::text IS NULL OR from_currency = $1)
+  AND ($2::text IS NULL OR to_currency = $2)
+ORDER BY effective_at DESC, from_currency, to_currency
+LIMIT $3 OFFSET $4;
+`
+	rows, err := r.db.Query(ctx, q, from, to, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]RateRow, 0, 16)
+	for rows.Next() {
+		rr, err := scanRateRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *rr)
+	}
+	return out, rows.Err()
+}
+
+func (r *ExchangeRateRepo) Resolve(ctx context.Context, from, to string, at time.Time) (*ResolvedRateRow, error) {
+	return ResolveRate(ctx, r.db, from, to, at)
+}
+
+// ResolveRate returns the most recent rate effective at `at` for from -> to,
+// falling back to the inverse of to -> from when that one is more recent or the
+// only one recorded. Returns pgx.ErrNoRows if the pair has no rate yet.
+func ResolveRate(ctx context.Context, q Queryer, from, to string, at time.Time) (*ResolvedRateRow, error) {
+	const sql = `
+SELECT id::text, rate::text, inverted, effective_at
+FROM (
+  (SELECT id, rate, false AS inverted, effective_at
+   FROM exchange_rates
+   WHERE from_currency = $1 AND to_currency = $2 AND effective_at <= $3
+   ORDER BY effective_at DESC
+   LIMIT 1)
+  UNION ALL
+  (SELECT id, round(1 / rate, 10), true AS inverted, effective_at
+   FROM exchange_rates
+   WHERE from_currency = $2 AND to_currency = $1 AND effective_at <= $3
+   ORDER BY effective_at DESC
+   LIMIT 1)
+) r
+ORDER BY effective_at DESC, inverted ASC
+LIMIT 1;
+`
+	var out ResolvedRateRow
+	if err := q.QueryRow(ctx, sql, from, to, at).Scan(&out.RateID, &out.Rate, &out.Inverted, &out.EffectiveAt); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	fxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/exchangerate"
+)
+
+func TestExchangeRate_ResolveLatestAndInverse(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+	uc := fxuc.New(NewExchangeRateStoreAdapter(NewExchangeRateRepo(db)))
+
+	lastWeek := time.Now().AddDate(0, 0, -7)
+	yesterday := time.Now().AddDate(0, 0, -1)
+
+	if _, err := uc.Create(ctx, fxuc.CreateInput{FromCurrency: "usd", ToCurrency: "IDR", Rate: "15000", EffectiveAt: &lastWeek}); err != nil {
+		t.Fatalf("create rate 1: %v", err)
+	}
+	if _, err := uc.Create(ctx, fxuc.CreateInput{FromCurrency: "USD", ToCurrency: "IDR", Rate: "16000", EffectiveAt: &yesterday}); err != nil {
+		t.Fatalf("create rate 2: %v", err)
+	}
+	if _, err := uc.Create(ctx, fxuc.CreateInput{FromCurrency: "USD", ToCurrency: "IDR", Rate: "1", EffectiveAt: &yesterday}); err != fxuc.ErrRateConflict {
+		t.Fatalf("expected ErrRateConflict got=%v", err)
+	}
+
+	// latest rate today, the older one before it took effect
+	conv, err := uc.Convert(ctx, fxuc.ConvertQuery{Amount: "10", FromCurrency: "USD", ToCurrency: "IDR"})
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if conv.ConvertedAmount != "160000.00" || conv.Inverted {
+		t.Fatalf("unexpected conversion: %+v", conv)
+	}
+	threeDaysAgo := time.Now().AddDate(0, 0, -3)
+	conv, err = uc.Convert(ctx, fxuc.ConvertQuery{Amount: "10", FromCurrency: "USD", ToCurrency: "IDR", At: &threeDaysAgo})
+	if err != nil || conv.ConvertedAmount != "150000.00" {
+		t.Fatalf("unexpected historical conversion: %+v err=%v", conv, err)
+	}
+
+	// reverse pair is derived from the recorded one
+	conv, err = uc.Convert(ctx, fxuc.ConvertQuery{Amount: "32000", FromCurrency: "IDR", ToCurrency: "USD"})
+	if err != nil || !conv.Inverted || conv.ConvertedAmount != "2.00" {
+		t.Fatalf("unexpected inverse conversion: %+v err=%v", conv, err)
+	}
+
+	if _, err := uc.Convert(ctx, fxuc.ConvertQuery{Amount: "1", FromCurrency: "SGD", ToCurrency: "IDR"}); err != fxuc.ErrRateMissing {
+		t.Fatalf("expected ErrRateMissing got=%v", err)
+	}
+}
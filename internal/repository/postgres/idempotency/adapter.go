@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	idemuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/idempotency"
+)
+
+type IdempotencyStoreAdapter struct {
+	repo *IdempotencyRepo
+}
+
+func NewIdempotencyStoreAdapter(repo *IdempotencyRepo) *IdempotencyStoreAdapter {
+	return &IdempotencyStoreAdapter{repo: repo}
+}
+
+func (a *IdempotencyStoreAdapter) Reserve(ctx context.Context, in idemuc.ReserveInput) (bool, *idemuc.Record, error) {
+	reserved, err := a.repo.Reserve(ctx, in.Scope, in.Key, in.Method, in.Path, in.RequestHash, in.ExpiresAt)
+	if err != nil {
+		return false, nil, err
+	}
+	if reserved {
+		return true, nil, nil
+	}
+
+	row, err := a.repo.Get(ctx, in.Scope, in.Key)
+	if err != nil {
+		// row released between reserve and read: treat as still in progress
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	return false, mapIdempotencyRow(row), nil
+}
+
+func (a *IdempotencyStoreAdapter) Complete(ctx context.Context, in idemuc.CompleteInput) error {
+	return a.repo.Complete(ctx, in.Scope, in.Key, in.StatusCode, in.ContentType, in.ResponseBody)
+}
+
+func (a *IdempotencyStoreAdapter) Release(ctx context.Context, scope, key string) error {
+	return a.repo.Release(ctx, scope, key)
+}
+
+func mapIdempotencyRow(r *IdempotencyKeyRow) *idemuc.Record {
+	return &idemuc.Record{
+		Scope:        r.Scope,
+		Key:          r.Key,
+		Method:       r.Method,
+		Path:         r.Path,
+		RequestHash:  r.RequestHash,
+		StatusCode:   r.StatusCode,
+		ContentType:  r.ContentType,
+		ResponseBody: r.ResponseBody,
+		ExpiresAt:    r.ExpiresAt,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+// Compile-time check
+var _ idemuc.Store = (*IdempotencyStoreAdapter)(nil)
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotencyKeyRow struct {
+	Scope        string
+	Key          string
+	Method       string
+	Path         string
+	RequestHash  string
+	StatusCode   *int
+	ContentType  *string
+	ResponseBody []byte
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+type IdempotencyRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewIdempotencyRepo(db *pgxpool.Pool) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db}
+}
+
+// Reserve inserts a fresh in-flight row for (scope, key).
+// An expired row with the same key is recycled; a live one is left untouched
+// and reserved=false is returned.
+func (r *IdempotencyRepo) Reserve(ctx context.Context, scope, key, method, path, requestHash string, expiresAt time.Time) (bool, error) {
+	const q = `
+INSERT INTO idempotency_keys (scope, key, method, path, request_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (scope, key) DO UPDATE
+SET method = EXCLUDED.method,
+    path = EXCLUDED.path,
+    request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    content_type = NULL,
+    response_body = NULL,
+    expires_at = EXCLUDED.expires_at,
+    created_at = now(),
+    updated_at = now()
+WHERE idempotency_keys.expires_at <= now()
+RETURNING id::text;
+`
+	var id string
+	if err := r.db.QueryRow(ctx, q, scope, key, method, path, requestHash, expiresAt).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *IdempotencyRepo) Get(ctx context.Context, scope, key string) (*IdempotencyKeyRow, error) {
+	const q = `
+SELECT scope, key, method, path, request_hash, status_code, content_type, response_body, expires_at, created_at
+FROM idempotency_keys
+WHERE scope = $1
+  AND key = $2
+LIMIT 1;
+`
+	var out IdempotencyKeyRow
+	if err := r.db.QueryRow(ctx, q, scope, key).Scan(
+		&out.Scope,
+		&out.Key,
+		&out.Method,
+		&out.Path,
+		&out.RequestHash,
+		&out.StatusCode,
+		&out.ContentType,
+		&out.ResponseBody,
+		&out.ExpiresAt,
+		&out.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *IdempotencyRepo) Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error {
+	const q = `
+UPDATE idempotency_keys
+SET status_code = $3,
+    content_type = NULLIF($4, ''),
+    response_body = $5,
+    updated_at = now()
+WHERE scope = $1
+  AND key = $2;
+`
+	_, err := r.db.Exec(ctx, q, scope, key, statusCode, contentType, body)
+	return err
+}
+
+func (r *IdempotencyRepo) Release(ctx context.Context, scope, key string) error {
+	const q = `
+DELETE FROM idempotency_keys
+WHERE scope = $1
+  AND key = $2
+  AND status_code IS NULL;
+`
+	_, err := r.db.Exec(ctx, q, scope, key)
+	return err
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	idemuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/idempotency"
+)
+
+func TestIdempotency_ReplayAndConflict(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+	uc := idemuc.New(NewIdempotencyStoreAdapter(NewIdempotencyRepo(db)), time.Hour)
+
+	in := idemuc.BeginInput{
+		Scope:  "admin-1",
+		Key:    "key-1",
+		Method: "POST",
+		Path:   "/api/admin/transactions",
+		Body:   []byte(`{"customerId":"c1"}`),
+	}
+
+	// first request reserves the key
+	rec, err := uc.Begin(ctx, in)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if rec != nil {
+		t.Fatalf("expected fresh reservation, got replay")
+	}
+
+	// concurrent retry while in flight
+	if _, err := uc.Begin(ctx, in); !errors.Is(err, idemuc.ErrRequestInProgress) {
+		t.Fatalf("expected ErrRequestInProgress, got %v", err)
+	}
+
+	if err := uc.Complete(ctx, idemuc.CompleteInput{
+		Scope:        in.Scope,
+		Key:          in.Key,
+		StatusCode:   201,
+		ContentType:  "application/json",
+		ResponseBody: []byte(`{"id":"t1"}`),
+	}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	// replay returns stored response
+	rec, err = uc.Begin(ctx, in)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if rec == nil || rec.StatusCode == nil || *rec.StatusCode != 201 || string(rec.ResponseBody) != `{"id":"t1"}` {
+		t.Fatalf("unexpected replay record: %+v", rec)
+	}
+
+	// same key, different body
+	other := in
+	other.Body = []byte(`{"customerId":"c2"}`)
+	if _, err := uc.Begin(ctx, other); !errors.Is(err, idemuc.ErrKeyReused) {
+		t.Fatalf("expected ErrKeyReused, got %v", err)
+	}
+
+	// same key under another admin is independent
+	other = in
+	other.Scope = "admin-2"
+	rec, err = uc.Begin(ctx, other)
+	if err != nil || rec != nil {
+		t.Fatalf("expected fresh reservation for other scope, got rec=%v err=%v", rec, err)
+	}
+}
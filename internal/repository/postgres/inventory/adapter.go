@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	invuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/inventory"
+)
+
+type InventoryStoreAdapter struct {
+	repo *InventoryRepo
+}
+
+func NewInventoryStoreAdapter(repo *InventoryRepo) *InventoryStoreAdapter {
+	return &InventoryStoreAdapter{repo: repo}
+}
+
+func (a *InventoryStoreAdapter) Receive(ctx context.Context, in invuc.ReceiveInput) (*invuc.GoodsReceipt, error) {
+	row, err := a.repo.Receive(ctx, ReceiveParams{
+		ProductID:  in.ProductID,
+		LocationID: in.LocationID,
+		Qty:        in.Qty,
+		UnitCost:   in.UnitCost,
+		Reference:  in.Reference,
+		Note:       in.Note,
+		ReceivedAt: *in.ReceivedAt,
+		LotNumber:  in.LotNumber,
+		ExpiresAt:  in.ExpiresAt,
+	})
+	if err != nil {
+		switch {
+		case isNoRows(err):
+			return nil, invuc.ErrProductMissing
+		case errors.Is(err, ErrFractionalStock):
+			return nil, invuc.ErrFractionalStock
+		case errors.Is(err, ErrLocationMissing):
+			return nil, invuc.ErrLocationMissing
+		}
+		return nil, err
+	}
+	return mapGoodsReceipt(row), nil
+}
+
+func (a *InventoryStoreAdapter) GetReceipt(ctx context.Context, id string) (*invuc.GoodsReceipt, error) {
+	row, err := a.repo.GetReceipt(ctx, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, invuc.ErrNotFound
+		}
+		return nil, err
+	}
+	return mapGoodsReceipt(row), nil
+}
+
+func (a *InventoryStoreAdapter) ListReceipts(ctx context.Context, q invuc.ListQuery) ([]invuc.GoodsReceipt, error) {
+	rows, err := a.repo.ListReceipts(ctx, q.ProductID, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]invuc.GoodsReceipt, 0, len(rows))
+	for i := range rows {
+		out = append(out, *mapGoodsReceipt(&rows[i]))
+	}
+	return out, nil
+}
+
+func (a *InventoryStoreAdapter) Valuation(ctx context.Context) ([]invuc.ValuationRow, error) {
+	rows, err := a.repo.Valuation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]invuc.ValuationRow, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, invuc.ValuationRow{
+			ProductID:   r.ProductID,
+			SKU:         r.SKU,
+			Name:        r.Name,
+			StockOnHand: r.StockOnHand,
+			AvgCost:     r.AvgCost,
+			Value:       r.Value,
+		})
+	}
+	return out, nil
+}
+
+func (a *InventoryStoreAdapter) LowStock(ctx context.Context) ([]invuc.StockLevel, error) {
+	rows, err := a.repo.LowStock(ctx)
+	return mapStockLevels(rows), err
+}
+
+func (a *InventoryStoreAdapter) StockLevelsWithSales(ctx context.Context, since time.Time) ([]invuc.StockLevel, error) {
+	rows, err := a.repo.StockLevelsWithSales(ctx, since)
+	return mapStockLevels(rows), err
+}
+
+func (a *InventoryStoreAdapter) ListStockEvents(ctx context.Context, q invuc.StockEventQuery) ([]invuc.StockEvent, error) {
+	rows, err := a.repo.ListStockEvents(ctx, q.Since, q.Kind, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]invuc.StockEvent, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, invuc.StockEvent{
+			ID:              r.ID,
+			ProductID:       r.ProductID,
+			ProductName:     r.ProductName,
+			Kind:            r.Kind,
+			AvailableBefore: r.AvailableBefore,
+			AvailableAfter:  r.AvailableAfter,
+			ReorderPoint:    r.ReorderPoint,
+			CreatedAt:       r.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+func (a *InventoryStoreAdapter) ExpiringLots(ctx context.Context, days int, locationID *string) ([]invuc.ExpiringLot, error) {
+	rows, err := a.repo.ExpiringLots(ctx, days, locationID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]invuc.ExpiringLot, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, invuc.ExpiringLot{
+			LotID:        r.LotID,
+			ProductID:    r.ProductID,
+			SKU:          r.SKU,
+			Name:         r.Name,
+			LocationID:   r.LocationID,
+			LocationName: r.LocationName,
+			LotNumber:    r.LotNumber,
+			ExpiresAt:    r.ExpiresAt.Format("2006-01-02"),
+			DaysLeft:     r.DaysLeft,
+			QtyOnHand:    r.QtyOnHand,
+			Expired:      r.DaysLeft < 0,
+		})
+	}
+	return out, nil
+}
+
+func mapStockLevels(rows []StockLevelRow) []invuc.StockLevel {
+	if rows == nil {
+		return nil
+	}
+	out := make([]invuc.StockLevel, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, invuc.StockLevel{
+			ProductID:    r.ProductID,
+			SKU:          r.SKU,
+			Name:         r.Name,
+			StockOnHand:  r.StockOnHand,
+			Reserved:     r.Reserved,
+			Available:    r.StockOnHand - r.Reserved,
+			ReorderPoint: r.ReorderPoint,
+			ReorderQty:   r.ReorderQty,
+			SoldQty:      r.SoldQty,
+		})
+	}
+	return out
+}
+
+func mapGoodsReceipt(r *GoodsReceiptRow) *invuc.GoodsReceipt {
+	return &invuc.GoodsReceipt{
+		ID:             r.ID,
+		ProductID:      r.ProductID,
+		StockProductID: r.StockProductID,
+		LocationID:     r.LocationID,
+		Qty:            r.Qty,
+		UnitCost:       r.UnitCost,
+		BaseQty:        r.BaseQty,
+		BaseUnitCost:   r.BaseUnitCost,
+		StockBefore:    r.StockBefore,
+		AvgCostBefore:  r.AvgCostBefore,
+		AvgCostAfter:   r.AvgCostAfter,
+		Reference:      r.Reference,
+		Note:           r.Note,
+		ReceivedAt:     r.ReceivedAt,
+		LotNumber:      r.LotNumber,
+		ExpiresAt:      formatDate(r.ExpiresAt),
+
+		PurchaseOrderLineID: r.PurchaseOrderLineID,
+
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+func formatDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format("2006-01-02")
+	return &s
+}
+
+// Compile-time check
+var _ invuc.Store = (*InventoryStoreAdapter)(nil)
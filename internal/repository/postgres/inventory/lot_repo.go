@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+type ExpiringLotRow struct {
+	LotID        string
+	ProductID    string
+	SKU          *string
+	Name         string
+	LocationID   string
+	LocationName string
+	LotNumber    *string
+	ExpiresAt    time.Time
+	DaysLeft     int
+	QtyOnHand    int
+}
+
+// ExpiringLots lists dated lots with stock left expiring within days from
+// today, soonest first.
+func (r *InventoryRepo) ExpiringLots(ctx context.Context, days int, locationID *string) ([]ExpiringLotRow, error) {
+	const q = `
+SELECT
+  sk.id::text, p.id::text, p.sku, p.name, l.id::text, l.name,
+  sk.lot_number, sk.expires_at::timestamptz, (sk.expires_at - CURRENT_DATE), sk.qty_on_hand
+FROM stock_lots sk
+JOIN products p ON p.id = sk.product_id
+JOIN locations l ON l.id = sk.location_id
+WHERE sk.qty_on_hand > 0
+  AND sk.expires_at <= CURRENT_DATE + $1::int
+  AND ($2::uuid IS NULL OR sk.location_id = $2::uuid)
+ORDER BY sk.expires_at, p.name, sk.created_at;
+`
+	rows, err := r.db.Query(ctx, q, days, locationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]ExpiringLotRow, 0, 16)
+	for rows.Next() {
+		var e ExpiringLotRow
+		if err := rows.Scan(
+			&e.LotID, &e.ProductID, &e.SKU, &e.Name, &e.LocationID, &e.LocationName,
+			&e.LotNumber, &e.ExpiresAt, &e.DaysLeft, &e.QtyOnHand,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
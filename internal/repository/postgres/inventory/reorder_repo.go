@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+type StockEventRow struct {
+	ID              string
+	ProductID       string
+	ProductName     string
+	Kind            string
+	AvailableBefore int
+	AvailableAfter  int
+	ReorderPoint    int
+	CreatedAt       time.Time
+}
+
+// StockLevelRow is a stock product with its reorder settings and, for
+// suggestions, the base units sold in the trailing window.
+type StockLevelRow struct {
+	ProductID    string
+	SKU          *string
+	Name         string
+	StockOnHand  int
+	Reserved     int
+	ReorderPoint *int
+	ReorderQty   *int
+	SoldQty      int
+}
+
+func (r *InventoryRepo) ListStockEvents(ctx context.Context, since *time.Time, kind *string, limit, offset int) ([]StockEventRow, error) {
+	const q = `
+SELECT e.id::text, e.product_id::text, p.name, e.kind, e.available_before, e.available_after, e.reorder_point, e.created_at
+FROM stock_events e
+JOIN products p ON p.id = e.product_id
+WHERE ($1::timestamptz IS NULL OR e.created_at > $1)
+  AND ($2::text IS NULL OR e.kind = $2)
+ORDER BY e.created_at, e.id
+LIMIT $3 OFFSET $4;
+`
+	rows, err := r.db.Query(ctx, q, since, kind, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]StockEventRow, 0, 16)
+	for rows.Next() {
+		var e StockEventRow
+		if err := rows.Scan(&e.ID, &e.ProductID, &e.ProductName, &e.Kind, &e.AvailableBefore, &e.AvailableAfter, &e.ReorderPoint, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// LowStock lists active, unarchived stock products at or below their reorder point.
+func (r *InventoryRepo) LowStock(ctx context.Context) ([]StockLevelRow, error) {
+	const q = `
+SELECT id::text, sku, name, stock_on_hand, stock_reserved, reorder_point, reorder_qty, 0
+FROM products
+WHERE base_product_id IS NULL
+  AND is_active = true
+  AND deleted_at IS NULL
+  AND reorder_point IS NOT NULL
+  AND stock_on_hand - stock_reserved <= reorder_point
+ORDER BY (stock_on_hand - stock_reserved) - reorder_point, name;
+`
+	return r.queryStockLevels(ctx, q)
+}
+
+// StockLevelsWithSales lists active, unarchived stock products with base units sold on
+// pending or completed transactions since `since` (packs count as pack_size units).
+func (r *InventoryRepo) StockLevelsWithSales(ctx context.Context, since time.Time) ([]StockLevelRow, error) {
+	const q = `
+SELECT
+  sp.id::text, sp.sku, sp.name, sp.stock_on_hand, sp.stock_reserved, sp.reorder_point, sp.reorder_qty,
+  COALESCE(sold.qty, 0)::int
+FROM products sp
+LEFT JOIN (
+  SELECT COALESCE(p.base_product_id, p.id) AS stock_product_id, SUM(ti.qty * p.pack_size) AS qty
+  FROM transaction_items ti
+  JOIN transactions t ON t.id = ti.transaction_id
+  JOIN products p ON p.id = ti.product_id
+  WHERE t.status IN ('pending', 'completed')
+    AND t.created_at >= $1
+  GROUP BY 1
+) sold ON sold.stock_product_id = sp.id
+WHERE sp.base_product_id IS NULL
+  AND sp.is_active = true
+  AND sp.deleted_at IS NULL
+ORDER BY sp.name;
+`
+	return r.queryStockLevels(ctx, q, since)
+}
+
+func (r *InventoryRepo) queryStockLevels(ctx context.Context, q string, args ...any) ([]StockLevelRow, error) {
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]StockLevelRow, 0, 32)
+	for rows.Next() {
+		var s StockLevelRow
+		if err := rows.Scan(&s.ProductID, &s.SKU, &s.Name, &s.StockOnHand, &s.Reserved, &s.ReorderPoint, &s.ReorderQty, &s.SoldQty); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
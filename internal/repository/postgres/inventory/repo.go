@@ -0,0 +1,297 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+)
+
+type GoodsReceiptRow struct {
+	ID             string
+	ProductID      string
+	StockProductID string
+	LocationID     string
+	Qty            int
+	UnitCost       string
+	BaseQty        int
+	BaseUnitCost   string
+	StockBefore    int
+	AvgCostBefore  *string
+	AvgCostAfter   string
+	Reference      *string
+	Note           *string
+	ReceivedAt     time.Time
+	LotNumber      *string
+	ExpiresAt      *time.Time
+
+	PurchaseOrderLineID *string
+
+	CreatedAt time.Time
+}
+
+type ValuationRow struct {
+	ProductID   string
+	SKU         *string
+	Name        string
+	StockOnHand int
+	AvgCost     *string
+	Value       *string
+}
+
+var (
+	// ErrFractionalStock: the received quantity is not a whole number of stock units.
+	ErrFractionalStock = errors.New("fractional base quantity")
+	// ErrLocationMissing: the location does not exist or is inactive.
+	ErrLocationMissing = errors.New("location missing")
+)
+
+type InventoryRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewInventoryRepo(db *pgxpool.Pool) *InventoryRepo {
+	return &InventoryRepo{db: db}
+}
+
+const goodsReceiptColumns = `
+  id::text,
+  product_id::text,
+  stock_product_id::text,
+  location_id::text,
+  qty,
+  unit_cost::text,
+  base_qty,
+  base_unit_cost::text,
+  stock_before,
+  avg_cost_before::text,
+  avg_cost_after::text,
+  reference,
+  note,
+  received_at,
+  lot_number,
+  expires_at::timestamptz,
+  purchase_order_line_id::text,
+  created_at
+`
+
+func scanGoodsReceiptRow(row pgx.Row) (*GoodsReceiptRow, error) {
+	var out GoodsReceiptRow
+	if err := row.Scan(
+		&out.ID, &out.ProductID, &out.StockProductID, &out.LocationID, &out.Qty, &out.UnitCost,
+		&out.BaseQty, &out.BaseUnitCost, &out.StockBefore, &out.AvgCostBefore, &out.AvgCostAfter,
+		&out.Reference, &out.Note, &out.ReceivedAt, &out.LotNumber, &out.ExpiresAt, &out.PurchaseOrderLineID, &out.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReceiveParams describes one stock receipt; PurchaseOrderLineID links it to
+// the purchase order line it was received against. A nil LocationID books the
+// stock into the default location. With a lot number or expiry date the stock
+// goes into a new lot, otherwise it is untracked.
+type ReceiveParams struct {
+	ProductID           string
+	LocationID          *string
+	Qty                 int
+	UnitCost            string
+	Reference           *string
+	Note                *string
+	ReceivedAt          time.Time
+	LotNumber           *string
+	ExpiresAt           *time.Time
+	PurchaseOrderLineID *string
+}
+
+func (r *InventoryRepo) Receive(ctx context.Context, p ReceiveParams) (*GoodsReceiptRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	out, err := ReceiveStock(ctx, tx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReceiveStock books stock in against its stock product inside the caller's
+// transaction. A pack product is converted to base units (qty * pack_size,
+// cost / pack_size). The new average cost is
+//
+//	(on_hand * avg_cost + base_qty * base_unit_cost) / (on_hand + base_qty)
+//
+// and falls back to the receipt cost when the product had no cost or no stock.
+// The average cost is product-wide; only the on-hand quantity is per location.
+// Returns pgx.ErrNoRows for an unknown product, ErrLocationMissing for an
+// unknown location and ErrFractionalStock when the quantity is not a whole
+// number of stock units.
+func ReceiveStock(ctx context.Context, tx pgx.Tx, p ReceiveParams) (*GoodsReceiptRow, error) {
+	locationID, err := trxpg.ResolveLocation(ctx, tx, p.LocationID)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, ErrLocationMissing
+		}
+		return nil, err
+	}
+
+	var stockID, baseQtyStr, baseCost string
+	if err := tx.QueryRow(ctx, `
+SELECT
+  COALESCE(base_product_id, id)::text,
+  ($2 * pack_size)::text,
+  round($3::numeric / pack_size, 4)::text
+FROM products
+WHERE id = $1::uuid;
+`, p.ProductID, p.Qty, p.UnitCost).Scan(&stockID, &baseQtyStr, &baseCost); err != nil {
+		return nil, err
+	}
+
+	f, err := strconv.ParseFloat(baseQtyStr, 64)
+	if err != nil {
+		return nil, err
+	}
+	if f != float64(int(f)) {
+		return nil, ErrFractionalStock
+	}
+	baseQty := int(f)
+
+	lvl, err := trxpg.LockStockLevel(ctx, tx, stockID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// stock_before is the product-wide on hand the average was taken over
+	var stockBefore int
+	var avgBefore *string
+	if err := tx.QueryRow(ctx, `
+SELECT stock_on_hand, avg_cost::text
+FROM products
+WHERE id = $1::uuid;
+`, stockID).Scan(&stockBefore, &avgBefore); err != nil {
+		return nil, err
+	}
+
+	var avgAfter string
+	if err := tx.QueryRow(ctx, `
+UPDATE products
+SET avg_cost = CASE
+      WHEN avg_cost IS NULL OR stock_on_hand <= 0 THEN $3::numeric
+      ELSE round((stock_on_hand * avg_cost + $2 * $3::numeric) / (stock_on_hand + $2), 4)
+    END,
+    updated_at = now()
+WHERE id = $1::uuid
+RETURNING avg_cost::text;
+`, stockID, baseQty, baseCost).Scan(&avgAfter); err != nil {
+		return nil, err
+	}
+	if err := trxpg.AdjustStockLevel(ctx, tx, stockID, locationID, baseQty, 0); err != nil {
+		return nil, err
+	}
+	if err := trxpg.RecordStockThreshold(ctx, tx, stockID, lvl.TotalAvailable); err != nil {
+		return nil, err
+	}
+
+	q := `
+INSERT INTO goods_receipts (
+  product_id, stock_product_id, qty, unit_cost, base_qty, base_unit_cost,
+  stock_before, avg_cost_before, avg_cost_after, reference, note, received_at,
+  purchase_order_line_id, location_id, lot_number, expires_at
+)
+VALUES ($1::uuid, $2::uuid, $3, $4::numeric, $5, $6::numeric, $7, $8::numeric, $9::numeric, $10, $11, $12, $13::uuid, $14::uuid, $15, $16::date)
+RETURNING ` + goodsReceiptColumns + `;
+`
+	out, err := scanGoodsReceiptRow(tx.QueryRow(ctx, q,
+		p.ProductID, stockID, p.Qty, p.UnitCost, baseQty, baseCost,
+		stockBefore, avgBefore, avgAfter, p.Reference, p.Note, p.ReceivedAt,
+		p.PurchaseOrderLineID, locationID, p.LotNumber, p.ExpiresAt,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if p.LotNumber != nil || p.ExpiresAt != nil {
+		if _, err := trxpg.AddLot(ctx, tx, stockID, locationID, p.LotNumber, p.ExpiresAt, baseQty, &out.ID, nil); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (r *InventoryRepo) GetReceipt(ctx context.Context, id string) (*GoodsReceiptRow, error) {
+	q := `SELECT ` + goodsReceiptColumns + ` FROM goods_receipts WHERE id = $1::uuid;`
+	return scanGoodsReceiptRow(r.db.QueryRow(ctx, q, id))
+}
+
+func (r *InventoryRepo) ListReceipts(ctx context.Context, stockProductID *string, limit, offset int) ([]GoodsReceiptRow, error) {
+	q := `
+SELECT ` + goodsReceiptColumns + `
+FROM goods_receipts
+WHERE ($1::uuid IS NULL OR stock_product_id = $1::uuid)
+ORDER BY received_at DESC, created_at DESC
+LIMIT $2 OFFSET $3;
+`
+	rows, err := r.db.Query(ctx, q, stockProductID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]GoodsReceiptRow, 0, 16)
+	for rows.Next() {
+		g, err := scanGoodsReceiptRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *g)
+	}
+	return out, rows.Err()
+}
+
+// Valuation lists stock products (no base product) with their value at average cost.
+func (r *InventoryRepo) Valuation(ctx context.Context) ([]ValuationRow, error) {
+	const q = `
+SELECT
+  id::text,
+  sku,
+  name,
+  stock_on_hand,
+  avg_cost::text,
+  round(stock_on_hand * avg_cost, 2)::text
+FROM products
+WHERE base_product_id IS NULL
+  AND is_active = true
+ORDER BY name;
+`
+	rows, err := r.db.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]ValuationRow, 0, 32)
+	for rows.Next() {
+		var v ValuationRow
+		if err := rows.Scan(&v.ProductID, &v.SKU, &v.Name, &v.StockOnHand, &v.AvgCost, &v.Value); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
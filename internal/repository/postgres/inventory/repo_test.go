@@ -0,0 +1,247 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	productpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/product"
+	reportpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/report"
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+	invuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/inventory"
+	productuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/product"
+	reportuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/report"
+	trxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
+)
+
+func TestInventory_WeightedAverageCostAndCOGS(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	custID := testutil.MustInsertCustomer(t, db, "Margin", "Test", "margin@test.local", nil)
+	prodID := testutil.MustInsertProduct(t, db, "SKU-COST-1", "Gula 1kg", nil, 0, 0)
+	testutil.MustInsertPrice(t, db, prodID, nil, "IDR", "2000.00")
+
+	uc := invuc.New(NewInventoryStoreAdapter(NewInventoryRepo(db)))
+
+	r1, err := uc.Receive(ctx, invuc.ReceiveInput{ProductID: prodID, Qty: 10, UnitCost: "1000"})
+	if err != nil {
+		t.Fatalf("receive 1: %v", err)
+	}
+	if r1.AvgCostBefore != nil || r1.AvgCostAfter != "1000.0000" {
+		t.Fatalf("unexpected first receipt: %+v", r1)
+	}
+
+	// (10 * 1000 + 10 * 1600) / 20 = 1300
+	r2, err := uc.Receive(ctx, invuc.ReceiveInput{ProductID: prodID, Qty: 10, UnitCost: "1600"})
+	if err != nil {
+		t.Fatalf("receive 2: %v", err)
+	}
+	if r2.StockBefore != 10 || r2.AvgCostAfter != "1300.0000" {
+		t.Fatalf("unexpected second receipt: %+v", r2)
+	}
+
+	val, err := uc.Valuation(ctx)
+	if err != nil {
+		t.Fatalf("valuation: %v", err)
+	}
+	if val.TotalValue != "26000.00" {
+		t.Fatalf("unexpected valuation: %+v", val)
+	}
+
+	trxUC := trxuc.New(trxpg.NewTransactionStoreAdapter(trxpg.NewTransactionRepo(db), db))
+	trx, err := trxUC.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Status:     trxuc.StatusDraft,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 4}},
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	if _, err := trxUC.UpdateStatus(ctx, trx.ID, trxuc.UpdateStatusInput{Status: trxuc.StatusPending}); err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if _, err := trxUC.Fulfill(ctx, trx.ID); err != nil {
+		t.Fatalf("fulfill: %v", err)
+	}
+
+	var cogs string
+	if err := db.QueryRow(ctx, `SELECT cogs_amount::text FROM transaction_items WHERE transaction_id = $1::uuid`, trx.ID).Scan(&cogs); err != nil {
+		t.Fatalf("read cogs: %v", err)
+	}
+	if cogs != "5200.00" {
+		t.Fatalf("expected cogs 5200.00 got=%s", cogs)
+	}
+
+	reportUC := reportuc.New(reportpg.NewReportStoreAdapter(reportpg.NewReportRepo(db)))
+	rep, err := reportUC.Margin(ctx, reportuc.MarginQuery{By: reportuc.MarginByTransaction})
+	if err != nil {
+		t.Fatalf("margin: %v", err)
+	}
+	if len(rep.Rows) != 1 || rep.Total.Revenue != "8000.00" || rep.Total.Margin != "2800.00" ||
+		rep.Total.MarginPct == nil || *rep.Total.MarginPct != "35.00" || rep.Total.UncostedLines != 0 {
+		t.Fatalf("unexpected margin report: %+v", rep.Total)
+	}
+}
+
+func TestInventory_LowStockEventsAndSuggestions(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	custID := testutil.MustInsertCustomer(t, db, "Reorder", "Test", "reorder@test.local", nil)
+	prodID := testutil.MustInsertProduct(t, db, "SKU-RO-1", "Minyak 2L", nil, 10, 0)
+	testutil.MustInsertPrice(t, db, prodID, nil, "IDR", "35000.00")
+
+	point, reorderQty := 5, 24
+	productUC := productuc.New(productpg.NewProductStoreAdapter(productpg.NewProductRepo(db)))
+	if _, err := productUC.Update(ctx, prodID, productuc.UpdateInput{ReorderPoint: &point, ReorderQty: &reorderQty}); err != nil {
+		t.Fatalf("set reorder point: %v", err)
+	}
+
+	// an archived product below its reorder point is neither low nor suggested
+	archivedID := testutil.MustInsertProduct(t, db, "SKU-RO-2", "Minyak 5L", nil, 1, 0)
+	if _, err := db.Exec(ctx, `UPDATE products SET reorder_point = 5, reorder_qty = 10, deleted_at = now() WHERE id = $1::uuid`, archivedID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	// reserving 6 of 10 leaves 4 available: crosses the reorder point
+	trxUC := trxuc.New(trxpg.NewTransactionStoreAdapter(trxpg.NewTransactionRepo(db), db))
+	trx, err := trxUC.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Status:     trxuc.StatusDraft,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 6}},
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	if _, err := trxUC.UpdateStatus(ctx, trx.ID, trxuc.UpdateStatusInput{Status: trxuc.StatusPending}); err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+
+	uc := invuc.New(NewInventoryStoreAdapter(NewInventoryRepo(db)))
+
+	low, err := uc.LowStock(ctx)
+	if err != nil {
+		t.Fatalf("low stock: %v", err)
+	}
+	if len(low) != 1 || low[0].ProductID != prodID || low[0].Available != 4 {
+		t.Fatalf("unexpected low stock: %+v", low)
+	}
+
+	sugg, err := uc.ReorderSuggestions(ctx, invuc.SuggestionQuery{WindowDays: 30, CoverDays: 14})
+	if err != nil {
+		t.Fatalf("suggestions: %v", err)
+	}
+	// 6 sold / 30 days * 14 days + point 5 - 4 available = 4, raised to reorder qty 24
+	if len(sugg.Suggestions) != 1 || sugg.Suggestions[0].SuggestedQty != 24 || sugg.Suggestions[0].SoldQty != 6 {
+		t.Fatalf("unexpected suggestions: %+v", sugg.Suggestions)
+	}
+
+	if _, err := uc.Receive(ctx, invuc.ReceiveInput{ProductID: prodID, Qty: 24, UnitCost: "30000"}); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+
+	events, err := uc.ListStockEvents(ctx, invuc.StockEventQuery{})
+	if err != nil {
+		t.Fatalf("stock events: %v", err)
+	}
+	if len(events) != 2 || events[0].Kind != invuc.EventLowStock || events[0].AvailableAfter != 4 ||
+		events[1].Kind != invuc.EventRestocked || events[1].AvailableAfter != 28 {
+		t.Fatalf("unexpected stock events: %+v", events)
+	}
+}
+
+func TestInventory_LotsPickedFEFOAndExpiredBlocked(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	custID := testutil.MustInsertCustomer(t, db, "Lot", "Test", "lot@test.local", nil)
+	prodID := testutil.MustInsertProduct(t, db, "SKU-LOT-1", "Susu UHT", nil, 0, 0)
+	testutil.MustInsertPrice(t, db, prodID, nil, "IDR", "6000.00")
+
+	uc := invuc.New(NewInventoryStoreAdapter(NewInventoryRepo(db)))
+
+	day := func(offset int) *string {
+		s := time.Now().AddDate(0, 0, offset).Format("2006-01-02")
+		return &s
+	}
+	for _, in := range []invuc.ReceiveInput{
+		{ProductID: prodID, Qty: 5, UnitCost: "4000", LotNumber: strPtr("LATE"), ExpiresAtRaw: day(10)},
+		{ProductID: prodID, Qty: 5, UnitCost: "4000", LotNumber: strPtr("SOON"), ExpiresAtRaw: day(3)},
+		{ProductID: prodID, Qty: 5, UnitCost: "4000", LotNumber: strPtr("GONE"), ExpiresAtRaw: day(-1)},
+	} {
+		if _, err := uc.Receive(ctx, in); err != nil {
+			t.Fatalf("receive %s: %v", *in.LotNumber, err)
+		}
+	}
+
+	// 7 units: all of SOON, then 2 of LATE; GONE is never picked
+	trxUC := trxuc.New(trxpg.NewTransactionStoreAdapter(trxpg.NewTransactionRepo(db), db))
+	trx, err := trxUC.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Status:     trxuc.StatusCompleted,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 7}},
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+
+	rows, err := db.Query(ctx, `
+SELECT sk.lot_number, a.qty
+FROM stock_lot_allocations a
+JOIN stock_lots sk ON sk.id = a.lot_id
+WHERE a.transaction_id = $1::uuid
+ORDER BY sk.expires_at`, trx.ID)
+	if err != nil {
+		t.Fatalf("allocations: %v", err)
+	}
+	var got []string
+	for rows.Next() {
+		var lot string
+		var qty int
+		if err := rows.Scan(&lot, &qty); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%s:%d", lot, qty))
+	}
+	rows.Close()
+	if strings.Join(got, ",") != "SOON:5,LATE:2" {
+		t.Fatalf("unexpected allocations: %v", got)
+	}
+
+	// 8 on hand but 5 of them expired: only 3 can be sold
+	if _, err := trxUC.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Status:     trxuc.StatusPending,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 4}},
+	}); !errors.Is(err, trxuc.ErrInsufficientStock) {
+		t.Fatalf("expected ErrInsufficientStock got=%v", err)
+	}
+
+	rep, err := uc.Expiring(ctx, invuc.ExpiringQuery{Days: 30})
+	if err != nil {
+		t.Fatalf("expiring: %v", err)
+	}
+	if len(rep.Lots) != 2 || !rep.Lots[0].Expired || *rep.Lots[0].LotNumber != "GONE" ||
+		*rep.Lots[1].LotNumber != "LATE" || rep.Lots[1].QtyOnHand != 3 || rep.ExpiredQty != 5 || rep.ExpiringQty != 3 {
+		t.Fatalf("unexpected expiring report: %+v", rep)
+	}
+}
+
+func strPtr(s string) *string { return &s }
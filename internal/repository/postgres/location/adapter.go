@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	locationuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/location"
+)
+
+type LocationStoreAdapter struct {
+	repo *LocationRepo
+}
+
+func NewLocationStoreAdapter(repo *LocationRepo) *LocationStoreAdapter {
+	return &LocationStoreAdapter{repo: repo}
+}
+
+func (a *LocationStoreAdapter) Create(ctx context.Context, in locationuc.CreateInput) (*locationuc.Location, error) {
+	row, err := a.repo.Create(ctx, in.Code, in.Name, in.Address)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, locationuc.ErrCodeConflict
+		}
+		return nil, err
+	}
+	return mapLocation(row), nil
+}
+
+func (a *LocationStoreAdapter) GetByID(ctx context.Context, id string) (*locationuc.Location, error) {
+	row, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, locationuc.ErrNotFound
+		}
+		return nil, err
+	}
+	return mapLocation(row), nil
+}
+
+func (a *LocationStoreAdapter) List(ctx context.Context, q locationuc.ListQuery) ([]locationuc.Location, error) {
+	rows, err := a.repo.List(ctx, q.IsActive)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]locationuc.Location, 0, len(rows))
+	for i := range rows {
+		out = append(out, *mapLocation(&rows[i]))
+	}
+	return out, nil
+}
+
+func (a *LocationStoreAdapter) Update(ctx context.Context, id string, in locationuc.UpdateInput) (*locationuc.Location, error) {
+	row, err := a.repo.Update(ctx, id, in.Code, in.Name, in.Address, in.IsDefault, in.IsActive)
+	if err != nil {
+		switch {
+		case isNoRows(err):
+			return nil, locationuc.ErrNotFound
+		case isUniqueViolation(err):
+			return nil, locationuc.ErrCodeConflict
+		case errors.Is(err, errDefaultLocation):
+			return nil, locationuc.ErrDefaultLocation
+		}
+		return nil, err
+	}
+	return mapLocation(row), nil
+}
+
+func (a *LocationStoreAdapter) ListStock(ctx context.Context, locationID string, q locationuc.StockQuery) ([]locationuc.StockLevel, error) {
+	rows, err := a.repo.ListStock(ctx, locationID, q.ProductID, q.InStockOnly, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]locationuc.StockLevel, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, locationuc.StockLevel{
+			ProductID: r.ProductID,
+			SKU:       r.SKU,
+			Name:      r.Name,
+			OnHand:    r.OnHand,
+			Reserved:  r.Reserved,
+			Available: r.OnHand - r.Reserved,
+			UpdatedAt: r.UpdatedAt,
+		})
+	}
+	return out, nil
+}
+
+func mapLocation(r *LocationRow) *locationuc.Location {
+	return &locationuc.Location{
+		ID:        r.ID,
+		Code:      r.Code,
+		Name:      r.Name,
+		Address:   r.Address,
+		IsDefault: r.IsDefault,
+		IsActive:  r.IsActive,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// Compile-time check
+var _ locationuc.Store = (*LocationStoreAdapter)(nil)
@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LocationRow struct {
+	ID        string
+	Code      string
+	Name      string
+	Address   *string
+	IsDefault bool
+	IsActive  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type StockLevelRow struct {
+	ProductID string
+	SKU       *string
+	Name      string
+	OnHand    int
+	Reserved  int
+	UpdatedAt time.Time
+}
+
+var errDefaultLocation = errors.New("default location")
+
+type LocationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewLocationRepo(db *pgxpool.Pool) *LocationRepo {
+	return &LocationRepo{db: db}
+}
+
+const locationColumns = `
+  id::text,
+  code,
+  name,
+  address,
+  is_default,
+  is_active,
+  created_at,
+  updated_at
+`
+
+func scanLocationRow(row pgx.Row) (*LocationRow, error) {
+	var out LocationRow
+	if err := row.Scan(
+		&out.ID, &out.Code, &out.Name, &out.Address, &out.IsDefault, &out.IsActive, &out.CreatedAt, &out.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *LocationRepo) Create(ctx context.Context, code, name string, address *string) (*LocationRow, error) {
+	q := `
+INSERT INTO locations (code, name, address)
+VALUES ($1, $2, $3)
+RETURNING ` + locationColumns + `;
+`
+	return scanLocationRow(r.db.QueryRow(ctx, q, code, name, address))
+}
+
+func (r *LocationRepo) GetByID(ctx context.Context, id string) (*LocationRow, error) {
+	q := `SELECT ` + locationColumns + ` FROM locations WHERE id = $1::uuid;`
+	return scanLocationRow(r.db.QueryRow(ctx, q, id))
+}
+
+func (r *LocationRepo) List(ctx context.Context, isActive *bool) ([]LocationRow, error) {
+	q := `
+SELECT ` + locationColumns + `
+FROM locations
+WHERE ($1::boolean IS NULL OR is_active = $1)
+ORDER BY is_default DESC, name, code;
+`
+	rows, err := r.db.Query(ctx, q, isActive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]LocationRow, 0, 8)
+	for rows.Next() {
+		l, err := scanLocationRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *l)
+	}
+	return out, rows.Err()
+}
+
+// Update moves the default flag in the same transaction, so there is never a
+// moment without (or with two) default locations.
+func (r *LocationRepo) Update(ctx context.Context, id string, code, name, address *string, isDefault, isActive *bool) (*LocationRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var wasDefault bool
+	if err := tx.QueryRow(ctx, `SELECT is_default FROM locations WHERE id = $1::uuid FOR UPDATE;`, id).Scan(&wasDefault); err != nil {
+		return nil, err
+	}
+	if wasDefault && isActive != nil && !*isActive {
+		return nil, errDefaultLocation
+	}
+
+	if isDefault != nil && *isDefault && !wasDefault {
+		var active bool
+		if err := tx.QueryRow(ctx, `SELECT is_active FROM locations WHERE id = $1::uuid;`, id).Scan(&active); err != nil {
+			return nil, err
+		}
+		if !active && (isActive == nil || !*isActive) {
+			return nil, errDefaultLocation
+		}
+		if _, err := tx.Exec(ctx, `UPDATE locations SET is_default = false, updated_at = now() WHERE is_default;`); err != nil {
+			return nil, err
+		}
+	}
+
+	q := `
+UPDATE locations
+SET
+  code = COALESCE($2, code),
+  name = COALESCE($3, name),
+  address = COALESCE($4, address),
+  is_default = is_default OR COALESCE($5, false),
+  is_active = COALESCE($6, is_active),
+  updated_at = now()
+WHERE id = $1::uuid
+RETURNING ` + locationColumns + `;
+`
+	out, err := scanLocationRow(tx.QueryRow(ctx, q, id, code, name, address, isDefault, isActive))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListStock lists the stock products held at the location.
+func (r *LocationRepo) ListStock(ctx context.Context, locationID string, productID *string, inStockOnly bool, limit, offset int) ([]StockLevelRow, error) {
+	const q = `
+SELECT p.id::text, p.sku, p.name, sl.on_hand, sl.reserved, sl.updated_at
+FROM stock_levels sl
+JOIN products p ON p.id = sl.product_id
+WHERE sl.location_id = $1::uuid
+  AND ($2::uuid IS NULL OR sl.product_id = $2::uuid)
+  AND (NOT $3::boolean OR sl.on_hand > 0)
+ORDER BY p.name, p.id
+LIMIT $4 OFFSET $5;
+`
+	rows, err := r.db.Query(ctx, q, locationID, productID, inStockOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]StockLevelRow, 0, 32)
+	for rows.Next() {
+		var s StockLevelRow
+		if err := rows.Scan(&s.ProductID, &s.SKU, &s.Name, &s.OnHand, &s.Reserved, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -2,10 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"math/big"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 
+	fxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/exchangerate"
 	payuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/payment"
 )
 
@@ -24,8 +27,13 @@ func (a *PaymentStoreAdapter) Create(ctx context.Context, in payuc.CreateInput)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	// 1) lock transaction row (ensures transaction exists + prevents race)
-	total, currency, err := lockTransactionForPayment(ctx, tx, in.TransactionID)
+	// 1) lock transaction row (ensures transaction exists + prevents race);
+	// credit payments lock the customer first, like receipts
+	lock := lockTransactionForPayment
+	if in.Method == payuc.MethodCredit {
+		lock = lockCustomerThenTransaction
+	}
+	trx, err := lock(ctx, tx, in.TransactionID)
 	if err != nil {
 		if pgx.ErrNoRows == err || isNoRows(err) {
 			return nil, nil, payuc.ErrTransactionMissing
@@ -33,34 +41,128 @@ func (a *PaymentStoreAdapter) Create(ctx context.Context, in payuc.CreateInput)
 		return nil, nil, err
 	}
 
+	amount, ok := new(big.Rat).SetString(in.Amount)
+	if !ok || amount.Sign() <= 0 {
+		return nil, nil, payuc.ErrInvalidInput
+	}
+
+	shiftID, err := resolveShift(ctx, tx, in.PostedBy, in.RequireShift)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	paidAt := time.Now()
 	if in.PaidAt != nil {
 		paidAt = *in.PaidAt
 	}
 
+	// foreign-currency payment: book the converted amount in the transaction currency
+	// at the rate effective when it was paid, keeping what was tendered
+	var conv *PaymentRow
+	if in.Currency != nil && *in.Currency != trx.Currency {
+		if in.Method == payuc.MethodCredit {
+			return nil, nil, payuc.ErrCurrencyMismatch
+		}
+		rate, err := fxpg.ResolveRate(ctx, tx, *in.Currency, trx.Currency, paidAt)
+		if err != nil {
+			if isNoRows(err) {
+				return nil, nil, payuc.ErrRateMissing
+			}
+			return nil, nil, err
+		}
+		r, ok := new(big.Rat).SetString(rate.Rate)
+		if !ok {
+			return nil, nil, errors.New("invalid exchange rate")
+		}
+		original := amount.FloatString(2)
+		in.Amount = r.Mul(r, amount).FloatString(2)
+		if in.Amount == "0.00" {
+			return nil, nil, payuc.ErrInvalidInput
+		}
+		conv = &PaymentRow{
+			OriginalAmount:   &original,
+			OriginalCurrency: in.Currency,
+			ExchangeRate:     &rate.Rate,
+			ExchangeRateID:   &rate.RateID,
+		}
+	}
+
+	// paying with store credit: must be covered by the balance and may not create a new overpayment
+	if in.Method == payuc.MethodCredit {
+		balanceStr, err := lockCustomerCredit(ctx, tx, trx.CustomerID, trx.Currency)
+		if err != nil {
+			return nil, nil, err
+		}
+		balance, _ := new(big.Rat).SetString(balanceStr)
+		if balance == nil || balance.Cmp(amount) < 0 {
+			return nil, nil, payuc.ErrInsufficientCredit
+		}
+
+		total, _ := new(big.Rat).SetString(trx.TotalAmount)
+		paid, _ := new(big.Rat).SetString(trx.PaidAmount)
+		if total == nil || paid == nil {
+			return nil, nil, errors.New("invalid transaction amounts")
+		}
+		due := new(big.Rat).Sub(total, paid)
+		if amount.Cmp(due) > 0 {
+			return nil, nil, payuc.ErrCreditExceedsDue
+		}
+	}
+
 	// 2) insert payment
-	row, err := insertPayment(ctx, tx, PaymentRow{
+	prow := PaymentRow{
 		TransactionID: in.TransactionID,
 		Method:        in.Method,
 		Amount:        in.Amount,
-		Currency:      currency,
+		Currency:      trx.Currency,
 		PaidAt:        paidAt,
 		SenderName:    in.SenderName,
 		Reference:     in.Reference,
 		Note:          in.Note,
 		Status:        "posted",
-	})
+		PostedBy:      in.PostedBy,
+		ShiftID:       shiftID,
+	}
+	if conv != nil {
+		prow.OriginalAmount = conv.OriginalAmount
+		prow.OriginalCurrency = conv.OriginalCurrency
+		prow.ExchangeRate = conv.ExchangeRate
+		prow.ExchangeRateID = conv.ExchangeRateID
+	}
+	row, err := insertPayment(ctx, tx, prow)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if in.Method == payuc.MethodCredit {
+		if err := insertCreditEntry(ctx, tx, CreditEntryRow{
+			CustomerID:    trx.CustomerID,
+			TransactionID: &in.TransactionID,
+			PaymentID:     &row.ID,
+			Kind:          "applied",
+			Amount:        "-" + row.Amount,
+			Currency:      row.Currency,
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// 3) recompute + update paid_amount + payment_status
 	stateRow, err := recomputeAndUpdateTransactionPaymentState(ctx, tx, in.TransactionID)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	_ = total
+	// 4) surplus becomes store credit, transaction ends up exactly paid
+	if stateRow.PaymentStatus == "overpaid" {
+		if _, err := convertOverpaymentToCredit(ctx, tx, in.TransactionID, row.ID); err != nil {
+			return nil, nil, err
+		}
+		stateRow, err = recomputeAndUpdateTransactionPaymentState(ctx, tx, in.TransactionID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, nil, err
@@ -84,18 +186,24 @@ func (a *PaymentStoreAdapter) ListByTransaction(ctx context.Context, transaction
 
 func mapPaymentRowToUC(r *PaymentRow) *payuc.Payment {
 	return &payuc.Payment{
-		ID:            r.ID,
-		TransactionID: r.TransactionID,
-		Method:        r.Method,
-		Amount:        r.Amount,
-		Currency:      r.Currency,
-		PaidAt:        r.PaidAt,
-		SenderName:    r.SenderName,
-		Reference:     r.Reference,
-		Note:          r.Note,
-		Status:        r.Status,
-		CreatedAt:     r.CreatedAt,
-		UpdatedAt:     r.UpdatedAt,
+		ID:               r.ID,
+		TransactionID:    r.TransactionID,
+		ReceiptID:        r.ReceiptID,
+		Method:           r.Method,
+		Amount:           r.Amount,
+		Currency:         r.Currency,
+		OriginalAmount:   r.OriginalAmount,
+		OriginalCurrency: r.OriginalCurrency,
+		ExchangeRate:     r.ExchangeRate,
+		PostedBy:         r.PostedBy,
+		ShiftID:          r.ShiftID,
+		PaidAt:           r.PaidAt,
+		SenderName:       r.SenderName,
+		Reference:        r.Reference,
+		Note:             r.Note,
+		Status:           r.Status,
+		CreatedAt:        r.CreatedAt,
+		UpdatedAt:        r.UpdatedAt,
 	}
 }
 
@@ -109,5 +217,26 @@ func mapStateRowToUC(r *TransactionPaymentStateRow) *payuc.TransactionPaymentSta
 	}
 }
 
+// resolveShift returns the open shift of the posting admin (nil when none and not required).
+func resolveShift(ctx context.Context, tx pgx.Tx, postedBy *string, required bool) (*string, error) {
+	if postedBy == nil {
+		if required {
+			return nil, payuc.ErrNoOpenShift
+		}
+		return nil, nil
+	}
+	id, err := lockOpenShift(ctx, tx, *postedBy)
+	if err != nil {
+		if isNoRows(err) {
+			if required {
+				return nil, payuc.ErrNoOpenShift
+			}
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &id, nil
+}
+
 // Compile-time check
 var _ payuc.Store = (*PaymentStoreAdapter)(nil)
@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	payuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/payment"
+)
+
+type plannedAllocation struct {
+	TransactionID string
+	Amount        *big.Rat
+}
+
+func (a *PaymentStoreAdapter) CreateReceipt(ctx context.Context, in payuc.CreateReceiptInput) (*payuc.Receipt, error) {
+	tx, err := a.repo.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// 1) lock customer (ensures it exists + serializes allocations for this customer)
+	if err := lockCustomer(ctx, tx, in.CustomerID); err != nil {
+		if isNoRows(err) {
+			return nil, payuc.ErrCustomerMissing
+		}
+		return nil, err
+	}
+
+	shiftID, err := resolveShift(ctx, tx, in.PostedBy, in.RequireShift)
+	if err != nil {
+		return nil, err
+	}
+
+	receivedAt := time.Now()
+	if in.ReceivedAt != nil {
+		receivedAt = *in.ReceivedAt
+	}
+
+	// 2) insert receipt header
+	rcpt, err := insertReceipt(ctx, tx, ReceiptRow{
+		CustomerID: in.CustomerID,
+		Method:     in.Method,
+		Amount:     in.Amount,
+		Currency:   in.Currency,
+		ReceivedAt: receivedAt,
+		SenderName: in.SenderName,
+		Reference:  in.Reference,
+		Note:       in.Note,
+		PostedBy:   in.PostedBy,
+		ShiftID:    shiftID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, _ := new(big.Rat).SetString(in.Amount)
+
+	// 3) plan allocations (explicit or FIFO by due date)
+	var plan []plannedAllocation
+	if len(in.Allocations) > 0 {
+		plan, err = planExplicitAllocations(ctx, tx, in)
+	} else {
+		plan, err = planFIFOAllocations(ctx, tx, in.CustomerID, in.Currency, remaining)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 4) post one payment per allocation + recompute each affected transaction
+	allocs := make([]payuc.ReceiptAllocation, 0, len(plan))
+	for _, p := range plan {
+		prow, err := insertPayment(ctx, tx, PaymentRow{
+			TransactionID: p.TransactionID,
+			ReceiptID:     &rcpt.ID,
+			Method:        in.Method,
+			Amount:        p.Amount.FloatString(2),
+			Currency:      in.Currency,
+			PaidAt:        receivedAt,
+			SenderName:    in.SenderName,
+			Reference:     in.Reference,
+			Note:          in.Note,
+			Status:        "posted",
+			PostedBy:      in.PostedBy,
+			ShiftID:       shiftID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		state, err := recomputeAndUpdateTransactionPaymentState(ctx, tx, p.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+
+		remaining.Sub(remaining, p.Amount)
+		allocs = append(allocs, payuc.ReceiptAllocation{
+			PaymentID:     prow.ID,
+			TransactionID: p.TransactionID,
+			Amount:        prow.Amount,
+			Transaction:   mapStateRowToUC(state),
+		})
+	}
+
+	// 5) leftover becomes store credit
+	if remaining.Sign() > 0 {
+		note := "unallocated receipt amount"
+		if err := insertCreditEntry(ctx, tx, CreditEntryRow{
+			CustomerID: in.CustomerID,
+			ReceiptID:  &rcpt.ID,
+			Kind:       "overpayment",
+			Amount:     remaining.FloatString(2),
+			Currency:   in.Currency,
+			Note:       &note,
+		}); err != nil {
+			return nil, err
+		}
+
+		rcpt, err = updateReceiptUnallocated(ctx, tx, rcpt.ID, remaining.FloatString(2))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	out := mapReceiptRowToUC(rcpt)
+	out.Allocations = allocs
+	return out, nil
+}
+
+// planExplicitAllocations validates caller-chosen allocations against each transaction's balance due.
+func planExplicitAllocations(ctx context.Context, tx pgx.Tx, in payuc.CreateReceiptInput) ([]plannedAllocation, error) {
+	// lock in id order so two receipts touching the same transactions cannot deadlock
+	sorted := append([]payuc.AllocationInput(nil), in.Allocations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TransactionID < sorted[j].TransactionID })
+
+	byID := map[string]*big.Rat{}
+	for _, a := range sorted {
+		t, err := lockTransactionForAllocation(ctx, tx, a.TransactionID)
+		if err != nil {
+			if isNoRows(err) {
+				return nil, payuc.ErrTransactionMissing
+			}
+			return nil, err
+		}
+		if t.CustomerID != in.CustomerID || t.Currency != in.Currency ||
+			(t.Status != "pending" && t.Status != "completed") {
+			return nil, payuc.ErrInvalidAllocation
+		}
+
+		due, err := balanceDue(t)
+		if err != nil {
+			return nil, err
+		}
+		amt, _ := new(big.Rat).SetString(a.Amount)
+		if amt.Cmp(due) > 0 {
+			return nil, payuc.ErrAllocationExceedsDue
+		}
+		byID[a.TransactionID] = amt
+	}
+
+	// keep the caller's order in the output
+	plan := make([]plannedAllocation, 0, len(in.Allocations))
+	for _, a := range in.Allocations {
+		plan = append(plan, plannedAllocation{TransactionID: a.TransactionID, Amount: byID[a.TransactionID]})
+	}
+	return plan, nil
+}
+
+// planFIFOAllocations pays off open transactions oldest due date first until the amount runs out.
+func planFIFOAllocations(ctx context.Context, tx pgx.Tx, customerID, currency string, amount *big.Rat) ([]plannedAllocation, error) {
+	open, err := lockOpenTransactionsFIFO(ctx, tx, customerID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	left := new(big.Rat).Set(amount)
+	plan := make([]plannedAllocation, 0, len(open))
+	for i := range open {
+		if left.Sign() <= 0 {
+			break
+		}
+		due, err := balanceDue(&open[i])
+		if err != nil {
+			return nil, err
+		}
+		if due.Sign() <= 0 {
+			continue
+		}
+
+		amt := new(big.Rat).Set(due)
+		if left.Cmp(due) < 0 {
+			amt.Set(left)
+		}
+		left.Sub(left, amt)
+		plan = append(plan, plannedAllocation{TransactionID: open[i].ID, Amount: amt})
+	}
+	return plan, nil
+}
+
+func balanceDue(t *OpenTransactionRow) (*big.Rat, error) {
+	total, ok := new(big.Rat).SetString(t.TotalAmount)
+	if !ok {
+		return nil, errors.New("invalid total_amount")
+	}
+	paid, ok := new(big.Rat).SetString(t.PaidAmount)
+	if !ok {
+		return nil, errors.New("invalid paid_amount")
+	}
+	return total.Sub(total, paid), nil
+}
+
+func (a *PaymentStoreAdapter) GetReceipt(ctx context.Context, id string) (*payuc.Receipt, error) {
+	row, err := a.repo.GetReceipt(ctx, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, payuc.ErrReceiptMissing
+		}
+		return nil, err
+	}
+
+	pays, err := a.repo.ListByReceipt(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := mapReceiptRowToUC(row)
+	for _, p := range pays {
+		out.Allocations = append(out.Allocations, payuc.ReceiptAllocation{
+			PaymentID:     p.ID,
+			TransactionID: p.TransactionID,
+			Amount:        p.Amount,
+		})
+	}
+	return out, nil
+}
+
+func (a *PaymentStoreAdapter) ListReceiptsByCustomer(ctx context.Context, customerID string, limit, offset int) ([]payuc.Receipt, error) {
+	rows, err := a.repo.ListReceiptsByCustomer(ctx, customerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]payuc.Receipt, 0, len(rows))
+	for i := range rows {
+		out = append(out, *mapReceiptRowToUC(&rows[i]))
+	}
+	return out, nil
+}
+
+func mapReceiptRowToUC(r *ReceiptRow) *payuc.Receipt {
+	return &payuc.Receipt{
+		ID:                r.ID,
+		CustomerID:        r.CustomerID,
+		Method:            r.Method,
+		Amount:            r.Amount,
+		Currency:          r.Currency,
+		ReceivedAt:        r.ReceivedAt,
+		SenderName:        r.SenderName,
+		Reference:         r.Reference,
+		Note:              r.Note,
+		UnallocatedAmount: r.UnallocatedAmount,
+		PostedBy:          r.PostedBy,
+		ShiftID:           r.ShiftID,
+		Status:            r.Status,
+		CreatedAt:         r.CreatedAt,
+		UpdatedAt:         r.UpdatedAt,
+		Allocations:       []payuc.ReceiptAllocation{},
+	}
+}
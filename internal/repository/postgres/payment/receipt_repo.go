@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type ReceiptRow struct {
+	ID                string
+	CustomerID        string
+	Method            string
+	Amount            string
+	Currency          string
+	ReceivedAt        time.Time
+	SenderName        *string
+	Reference         *string
+	Note              *string
+	UnallocatedAmount string
+	PostedBy          *string
+	ShiftID           *string
+	Status            string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+type OpenTransactionRow struct {
+	ID          string
+	CustomerID  string
+	Status      string
+	Currency    string
+	TotalAmount string
+	PaidAmount  string
+}
+
+const receiptColumns = `
+  id::text,
+  customer_id::text,
+  method,
+  amount::text,
+  currency,
+  received_at,
+  sender_name,
+  reference,
+  note,
+  unallocated_amount::text,
+  posted_by::text,
+  shift_id::text,
+  status,
+  created_at,
+  updated_at`
+
+func scanReceiptRow(row pgx.Row) (*ReceiptRow, error) {
+	var out ReceiptRow
+	if err := row.Scan(
+		&out.ID,
+		&out.CustomerID,
+		&out.Method,
+		&out.Amount,
+		&out.Currency,
+		&out.ReceivedAt,
+		&out.SenderName,
+		&out.Reference,
+		&out.Note,
+		&out.UnallocatedAmount,
+		&out.PostedBy,
+		&out.ShiftID,
+		&out.Status,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// lockCustomer serializes receipts/credit usage for one customer.
+func lockCustomer(ctx context.Context, tx pgx.Tx, customerID string) error {
+	const q = `
+SELECT 1
+FROM customers
+WHERE id = $1::uuid
+FOR UPDATE;
+`
+	var one int
+	return tx.QueryRow(ctx, q, customerID).Scan(&one)
+}
+
+func insertReceipt(ctx context.Context, tx pgx.Tx, in ReceiptRow) (*ReceiptRow, error) {
+	q := `
+INSERT INTO receipts (customer_id, method, amount, currency, received_at, sender_name, reference, note, posted_by, shift_id)
+VALUES ($1::uuid, $2, $3::numeric, $4, $5, $6, $7, $8, $9::uuid, $10::uuid)
+RETURNING` + receiptColumns + `;`
+
+	return scanReceiptRow(tx.QueryRow(ctx, q,
+		in.CustomerID,
+		in.Method,
+		in.Amount,
+		in.Currency,
+		in.ReceivedAt,
+		in.SenderName,
+		in.Reference,
+		in.Note,
+		in.PostedBy,
+		in.ShiftID,
+	))
+}
+
+func updateReceiptUnallocated(ctx context.Context, tx pgx.Tx, receiptID string, unallocated string) (*ReceiptRow, error) {
+	q := `
+UPDATE receipts
+SET unallocated_amount = $2::numeric,
+    updated_at = now()
+WHERE id = $1::uuid
+RETURNING` + receiptColumns + `;`
+
+	return scanReceiptRow(tx.QueryRow(ctx, q, receiptID, unallocated))
+}
+
+// lockOpenTransactionsFIFO locks every transaction of the customer that still has a balance due,
+// oldest due date first (the auto-allocation order).
+func lockOpenTransactionsFIFO(ctx context.Context, tx pgx.Tx, customerID string, currency string) ([]OpenTransactionRow, error) {
+	const q = `
+SELECT id::text, customer_id::text, status, currency, total_amount::text, paid_amount::text
+FROM transactions
+WHERE customer_id = $1::uuid
+  AND currency = $2
+  AND status IN ('pending', 'completed')
+  AND paid_amount < total_amount
+ORDER BY due_date ASC, created_at ASC, id ASC
+FOR UPDATE;
+`
+	rows, err := tx.Query(ctx, q, customerID, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]OpenTransactionRow, 0, 10)
+	for rows.Next() {
+		var t OpenTransactionRow
+		if err := rows.Scan(&t.ID, &t.CustomerID, &t.Status, &t.Currency, &t.TotalAmount, &t.PaidAmount); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func lockTransactionForAllocation(ctx context.Context, tx pgx.Tx, transactionID string) (*OpenTransactionRow, error) {
+	const q = `
+SELECT id::text, customer_id::text, status, currency, total_amount::text, paid_amount::text
+FROM transactions
+WHERE id = $1::uuid
+FOR UPDATE;
+`
+	var t OpenTransactionRow
+	if err := tx.QueryRow(ctx, q, transactionID).Scan(&t.ID, &t.CustomerID, &t.Status, &t.Currency, &t.TotalAmount, &t.PaidAmount); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *PaymentRepo) GetReceipt(ctx context.Context, id string) (*ReceiptRow, error) {
+	q := `
+SELECT` + receiptColumns + `
+FROM receipts
+WHERE id = $1::uuid;
+`
+	return scanReceiptRow(r.db.QueryRow(ctx, q, id))
+}
+
+func (r *PaymentRepo) ListReceiptsByCustomer(ctx context.Context, customerID string, limit, offset int) ([]ReceiptRow, error) {
+	q := `
+SELECT` + receiptColumns + `
+FROM receipts
+WHERE customer_id = $1::uuid
+ORDER BY received_at DESC, created_at DESC
+LIMIT $2 OFFSET $3;
+`
+	rows, err := r.db.Query(ctx, q, customerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]ReceiptRow, 0, limit)
+	for rows.Next() {
+		row, err := scanReceiptRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *row)
+	}
+	return out, rows.Err()
+}
+
+func (r *PaymentRepo) ListByReceipt(ctx context.Context, receiptID string) ([]PaymentRow, error) {
+	const q = `
+SELECT
+  id::text,
+  transaction_id::text,
+  receipt_id::text,
+  method,
+  amount::text,
+  currency,
+  original_amount::text,
+  original_currency,
+  exchange_rate::text,
+  posted_by::text,
+  shift_id::text,
+  paid_at,
+  sender_name,
+  reference,
+  note,
+  status,
+  created_at,
+  updated_at
+FROM payments
+WHERE receipt_id = $1::uuid
+ORDER BY created_at ASC;
+`
+	rows, err := r.db.Query(ctx, q, receiptID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]PaymentRow, 0, 10)
+	for rows.Next() {
+		var p PaymentRow
+		if err := rows.Scan(
+			&p.ID,
+			&p.TransactionID,
+			&p.ReceiptID,
+			&p.Method,
+			&p.Amount,
+			&p.Currency,
+			&p.OriginalAmount,
+			&p.OriginalCurrency,
+			&p.ExchangeRate,
+			&p.PostedBy,
+			&p.ShiftID,
+			&p.PaidAt,
+			&p.SenderName,
+			&p.Reference,
+			&p.Note,
+			&p.Status,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
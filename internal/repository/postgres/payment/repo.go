@@ -7,21 +7,31 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
 )
 
 type PaymentRow struct {
 	ID            string
 	TransactionID string
+	ReceiptID     *string
 	Method        string
 	Amount        string
 	Currency      string
-	PaidAt        time.Time
-	SenderName    *string
-	Reference     *string
-	Note          *string
-	Status        string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	// set when the payment was tendered in another currency than the transaction
+	OriginalAmount   *string
+	OriginalCurrency *string
+	ExchangeRate     *string
+	ExchangeRateID   *string
+	PostedBy         *string // admin who posted it
+	ShiftID          *string // cash shift open for that admin at the time
+	PaidAt           time.Time
+	SenderName       *string
+	Reference        *string
+	Note             *string
+	Status           string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
 
 type TransactionPaymentStateRow struct {
@@ -44,35 +54,79 @@ func (r *PaymentRepo) Begin(ctx context.Context) (pgx.Tx, error) {
 	return r.db.BeginTx(ctx, pgx.TxOptions{})
 }
 
-func lockTransactionForPayment(ctx context.Context, tx pgx.Tx, transactionID string) (totalAmount string, currency string, err error) {
+type TransactionForPaymentRow struct {
+	CustomerID  string
+	TotalAmount string
+	PaidAmount  string
+	Currency    string
+}
+
+func lockTransactionForPayment(ctx context.Context, tx pgx.Tx, transactionID string) (*TransactionForPaymentRow, error) {
 	const q = `
-SELECT total_amount::text, currency
+SELECT customer_id::text, total_amount::text, paid_amount::text, currency
 FROM transactions
 WHERE id = $1::uuid
 FOR UPDATE;
 `
-	if err := tx.QueryRow(ctx, q, transactionID).Scan(&totalAmount, &currency); err != nil {
-		return "", "", err
+	var out TransactionForPaymentRow
+	if err := tx.QueryRow(ctx, q, transactionID).Scan(&out.CustomerID, &out.TotalAmount, &out.PaidAmount, &out.Currency); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// lockCustomerThenTransaction locks the owning customer before the transaction,
+// the order receipts use, so a credit payment and a receipt for one customer
+// cannot deadlock. If the transaction changed owner (merge) before its lock was
+// taken, the new owner is locked as well.
+func lockCustomerThenTransaction(ctx context.Context, tx pgx.Tx, transactionID string) (*TransactionForPaymentRow, error) {
+	const q = `SELECT customer_id::text FROM transactions WHERE id = $1::uuid;`
+
+	var customerID string
+	if err := tx.QueryRow(ctx, q, transactionID).Scan(&customerID); err != nil {
+		return nil, err
+	}
+	for {
+		if err := lockCustomer(ctx, tx, customerID); err != nil {
+			return nil, err
+		}
+		trx, err := lockTransactionForPayment(ctx, tx, transactionID)
+		if err != nil {
+			return nil, err
+		}
+		if trx.CustomerID == customerID {
+			return trx, nil
+		}
+		customerID = trx.CustomerID
 	}
-	return totalAmount, currency, nil
 }
 
 func insertPayment(ctx context.Context, tx pgx.Tx, in PaymentRow) (*PaymentRow, error) {
 	const q = `
 INSERT INTO payments (
   transaction_id, method, amount, currency, paid_at,
-  sender_name, reference, note, status
+  sender_name, reference, note, status, receipt_id,
+  original_amount, original_currency, exchange_rate, exchange_rate_id,
+  posted_by, shift_id
 )
 VALUES (
   $1::uuid, $2, $3::numeric, $4, COALESCE($5, now()),
-  $6, $7, $8, COALESCE($9, 'posted')
+  $6, $7, $8, COALESCE($9, 'posted'), $10::uuid,
+  $11::numeric, $12, $13::numeric, $14::uuid,
+  $15::uuid, $16::uuid
 )
 RETURNING
   id::text,
   transaction_id::text,
+  receipt_id::text,
   method,
   amount::text,
   currency,
+  original_amount::text,
+  original_currency,
+  exchange_rate::text,
+  posted_by::text,
+  shift_id::text,
   paid_at,
   sender_name,
   reference,
@@ -92,15 +146,28 @@ RETURNING
 		in.Reference,
 		in.Note,
 		in.Status,
+		in.ReceiptID,
+		in.OriginalAmount,
+		in.OriginalCurrency,
+		in.ExchangeRate,
+		in.ExchangeRateID,
+		in.PostedBy,
+		in.ShiftID,
 	)
 
 	var out PaymentRow
 	if err := row.Scan(
 		&out.ID,
 		&out.TransactionID,
+		&out.ReceiptID,
 		&out.Method,
 		&out.Amount,
 		&out.Currency,
+		&out.OriginalAmount,
+		&out.OriginalCurrency,
+		&out.ExchangeRate,
+		&out.PostedBy,
+		&out.ShiftID,
 		&out.PaidAt,
 		&out.SenderName,
 		&out.Reference,
@@ -111,19 +178,42 @@ RETURNING
 	); err != nil {
 		return nil, err
 	}
+
+	data := map[string]any{
+		"paymentId": out.ID,
+		"method":    out.Method,
+		"amount":    out.Amount,
+		"currency":  out.Currency,
+	}
+	if out.ReceiptID != nil {
+		data["receiptId"] = *out.ReceiptID
+	}
+	if err := trxpg.RecordEvent(ctx, tx, out.TransactionID, trxpg.EventPaymentPosted, nil, &out.Status, data); err != nil {
+		return nil, err
+	}
 	return &out, nil
 }
 
 func recomputeAndUpdateTransactionPaymentState(ctx context.Context, tx pgx.Tx, transactionID string) (*TransactionPaymentStateRow, error) {
-	// paid_amount = sum(posted payments)
+	// paid_amount = sum(posted payments) - overpayment already moved to store credit
 	// payment_status based on paid_amount vs total_amount
 	const q = `
 WITH paid AS (
   SELECT
-    COALESCE(SUM(amount), 0)::numeric AS paid_amount
-  FROM payments
-  WHERE transaction_id = $1::uuid
-    AND status = 'posted'
+    (
+      COALESCE((
+        SELECT SUM(amount)
+        FROM payments
+        WHERE transaction_id = $1::uuid
+          AND status = 'posted'
+      ), 0)
+      - COALESCE((
+        SELECT SUM(amount)
+        FROM customer_credit_entries
+        WHERE transaction_id = $1::uuid
+          AND kind = 'overpayment'
+      ), 0)
+    )::numeric AS paid_amount
 ),
 upd AS (
   UPDATE transactions t
@@ -160,14 +250,77 @@ SELECT * FROM upd;
 	return &out, nil
 }
 
+// lockCustomerCredit serializes credit usage per customer and returns the current balance.
+func lockCustomerCredit(ctx context.Context, tx pgx.Tx, customerID string, currency string) (string, error) {
+	if err := lockCustomer(ctx, tx, customerID); err != nil {
+		return "", err
+	}
+
+	const q = `
+SELECT COALESCE(SUM(amount), 0)::text
+FROM customer_credit_entries
+WHERE customer_id = $1::uuid
+  AND currency = $2;
+`
+	var balance string
+	if err := tx.QueryRow(ctx, q, customerID, currency).Scan(&balance); err != nil {
+		return "", err
+	}
+	return balance, nil
+}
+
+type CreditEntryRow struct {
+	CustomerID    string
+	TransactionID *string
+	PaymentID     *string
+	ReceiptID     *string
+	Kind          string
+	Amount        string
+	Currency      string
+	Note          *string
+}
+
+func insertCreditEntry(ctx context.Context, tx pgx.Tx, in CreditEntryRow) error {
+	const q = `
+INSERT INTO customer_credit_entries (customer_id, transaction_id, payment_id, receipt_id, kind, amount, currency, note)
+VALUES ($1::uuid, $2::uuid, $3::uuid, $4::uuid, $5, $6::numeric, $7, $8);
+`
+	_, err := tx.Exec(ctx, q, in.CustomerID, in.TransactionID, in.PaymentID, in.ReceiptID, in.Kind, in.Amount, in.Currency, in.Note)
+	return err
+}
+
+// convertOverpaymentToCredit moves paid_amount above total_amount into the customer's
+// store credit. Returns false when the transaction is not overpaid.
+func convertOverpaymentToCredit(ctx context.Context, tx pgx.Tx, transactionID string, paymentID string) (bool, error) {
+	const q = `
+INSERT INTO customer_credit_entries (customer_id, transaction_id, payment_id, kind, amount, currency, note)
+SELECT t.customer_id, t.id, $2::uuid, 'overpayment', t.paid_amount - t.total_amount, t.currency,
+       'overpayment converted to store credit'
+FROM transactions t
+WHERE t.id = $1::uuid
+  AND t.paid_amount > t.total_amount;
+`
+	ct, err := tx.Exec(ctx, q, transactionID, paymentID)
+	if err != nil {
+		return false, err
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
 func (r *PaymentRepo) ListByTransaction(ctx context.Context, transactionID string) ([]PaymentRow, error) {
 	const q = `
 SELECT
   id::text,
   transaction_id::text,
+  receipt_id::text,
   method,
   amount::text,
   currency,
+  original_amount::text,
+  original_currency,
+  exchange_rate::text,
+  posted_by::text,
+  shift_id::text,
   paid_at,
   sender_name,
   reference,
@@ -191,9 +344,15 @@ ORDER BY paid_at DESC, created_at DESC;
 		if err := rows.Scan(
 			&p.ID,
 			&p.TransactionID,
+			&p.ReceiptID,
 			&p.Method,
 			&p.Amount,
 			&p.Currency,
+			&p.OriginalAmount,
+			&p.OriginalCurrency,
+			&p.ExchangeRate,
+			&p.PostedBy,
+			&p.ShiftID,
 			&p.PaidAt,
 			&p.SenderName,
 			&p.Reference,
@@ -209,6 +368,23 @@ ORDER BY paid_at DESC, created_at DESC;
 	return out, rows.Err()
 }
 
+// lockOpenShift returns the admin's open cash shift, share-locked so it cannot be
+// closed while a payment is being posted into it. pgx.ErrNoRows when none is open.
+func lockOpenShift(ctx context.Context, tx pgx.Tx, adminID string) (string, error) {
+	const q = `
+SELECT id::text
+FROM cash_shifts
+WHERE admin_id = $1::uuid
+  AND status = 'open'
+FOR SHARE;
+`
+	var id string
+	if err := tx.QueryRow(ctx, q, adminID).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
 func isNoRows(err error) bool {
 	return errors.Is(err, pgx.ErrNoRows)
 }
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -97,3 +98,235 @@ func TestPayment_CreateAndList_UpdatesTransactionState(t *testing.T) {
 		t.Fatalf("expected 2 payments, got %d", len(items))
 	}
 }
+
+func TestPayment_OverpaymentBecomesCredit_AndCreditCanBeApplied(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	custID := testutil.MustInsertCustomer(t, db, "Rio", "Credit", "rio.credit@test.local", nil)
+	prodID := testutil.MustInsertProduct(t, db, "SKU-CR-1", "Teh Botol", nil, 10, 0)
+	testutil.MustInsertPrice(t, db, prodID, nil, "IDR", "5000.00")
+
+	trxStore := trxrepo.NewTransactionStoreAdapter(trxrepo.NewTransactionRepo(db), db)
+	pUC := payuc.New(NewPaymentStoreAdapter(NewPaymentRepo(db)))
+
+	// trx1 = 10,000; customer pays 12,000 -> 2,000 store credit
+	trx1, err := trxStore.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 2}},
+	})
+	if err != nil {
+		t.Fatalf("create transaction 1: %v", err)
+	}
+
+	_, state, err := pUC.Create(ctx, payuc.CreateInput{
+		TransactionID: trx1.ID,
+		Method:        payuc.MethodCash,
+		Amount:        "12000.00",
+	})
+	if err != nil {
+		t.Fatalf("overpay: %v", err)
+	}
+	if state.PaymentStatus != "paid" || state.PaidAmount != "10000.00" {
+		t.Fatalf("expected paid/10000.00 got=%s/%s", state.PaymentStatus, state.PaidAmount)
+	}
+
+	var balance string
+	if err := db.QueryRow(ctx, `
+		SELECT SUM(amount)::text FROM customer_credit_entries WHERE customer_id = $1::uuid
+	`, custID).Scan(&balance); err != nil {
+		t.Fatalf("read balance: %v", err)
+	}
+	if balance != "2000.00" {
+		t.Fatalf("expected credit balance 2000.00 got=%s", balance)
+	}
+
+	// trx2 = 5,000; more credit than available is rejected, exact credit is accepted
+	trx2, err := trxStore.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 1}},
+	})
+	if err != nil {
+		t.Fatalf("create transaction 2: %v", err)
+	}
+
+	if _, _, err := pUC.Create(ctx, payuc.CreateInput{
+		TransactionID: trx2.ID,
+		Method:        payuc.MethodCredit,
+		Amount:        "3000.00",
+	}); err != payuc.ErrInsufficientCredit {
+		t.Fatalf("expected ErrInsufficientCredit got=%v", err)
+	}
+
+	_, state, err = pUC.Create(ctx, payuc.CreateInput{
+		TransactionID: trx2.ID,
+		Method:        payuc.MethodCredit,
+		Amount:        "2000.00",
+	})
+	if err != nil {
+		t.Fatalf("pay with credit: %v", err)
+	}
+	if state.PaymentStatus != "partial" || state.PaidAmount != "2000.00" {
+		t.Fatalf("expected partial/2000.00 got=%s/%s", state.PaymentStatus, state.PaidAmount)
+	}
+
+	if err := db.QueryRow(ctx, `
+		SELECT SUM(amount)::text FROM customer_credit_entries WHERE customer_id = $1::uuid
+	`, custID).Scan(&balance); err != nil {
+		t.Fatalf("read balance: %v", err)
+	}
+	if balance != "0.00" {
+		t.Fatalf("expected credit balance 0.00 got=%s", balance)
+	}
+}
+
+func TestReceipt_FIFOAllocationAcrossTransactions(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	custID := testutil.MustInsertCustomer(t, db, "Toko", "Lump", "toko.lump@test.local", nil)
+	prodID := testutil.MustInsertProduct(t, db, "SKU-RC-1", "Gula 1kg", nil, 100, 0)
+	testutil.MustInsertPrice(t, db, prodID, nil, "IDR", "10000.00")
+
+	trxStore := trxrepo.NewTransactionStoreAdapter(trxrepo.NewTransactionRepo(db), db)
+
+	older := time.Now().AddDate(0, 0, -10)
+	newer := time.Now().AddDate(0, 0, 5)
+
+	// created newest-due first to prove ordering is by due date, not insertion
+	trxNewer, err := trxStore.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Status:     trxuc.StatusPending,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 3}}, // 30,000
+		DueDate:    &newer,
+	})
+	if err != nil {
+		t.Fatalf("create newer: %v", err)
+	}
+	trxOlder, err := trxStore.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Status:     trxuc.StatusPending,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 2}}, // 20,000
+		DueDate:    &older,
+	})
+	if err != nil {
+		t.Fatalf("create older: %v", err)
+	}
+
+	pUC := payuc.New(NewPaymentStoreAdapter(NewPaymentRepo(db)))
+
+	// 60,000 covers both (50,000) and leaves 10,000 as credit
+	rcpt, err := pUC.CreateReceipt(ctx, payuc.CreateReceiptInput{
+		CustomerID: custID,
+		Method:     payuc.MethodTransfer,
+		Amount:     "60000",
+	})
+	if err != nil {
+		t.Fatalf("create receipt: %v", err)
+	}
+	if len(rcpt.Allocations) != 2 {
+		t.Fatalf("expected 2 allocations got=%d", len(rcpt.Allocations))
+	}
+	if rcpt.Allocations[0].TransactionID != trxOlder.ID || rcpt.Allocations[1].TransactionID != trxNewer.ID {
+		t.Fatalf("expected FIFO by due date")
+	}
+	for _, a := range rcpt.Allocations {
+		if a.Transaction.PaymentStatus != "paid" {
+			t.Fatalf("expected transaction %s paid got=%s", a.TransactionID, a.Transaction.PaymentStatus)
+		}
+	}
+	if rcpt.UnallocatedAmount != "10000.00" {
+		t.Fatalf("expected unallocated 10000.00 got=%s", rcpt.UnallocatedAmount)
+	}
+
+	// explicit allocation larger than balance due is rejected
+	trx3, err := trxStore.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Status:     trxuc.StatusPending,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 1}},
+	})
+	if err != nil {
+		t.Fatalf("create trx3: %v", err)
+	}
+	if _, err := pUC.CreateReceipt(ctx, payuc.CreateReceiptInput{
+		CustomerID:  custID,
+		Method:      payuc.MethodCash,
+		Amount:      "20000",
+		Allocations: []payuc.AllocationInput{{TransactionID: trx3.ID, Amount: "15000"}},
+	}); err != payuc.ErrAllocationExceedsDue {
+		t.Fatalf("expected ErrAllocationExceedsDue got=%v", err)
+	}
+}
+
+func TestPayment_ForeignCurrencyConvertedAtRecordedRate(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	custID := testutil.MustInsertCustomer(t, db, "Fx", "Buyer", "fx@test.local", nil)
+	prodID := testutil.MustInsertProduct(t, db, "SKU-FX-1", "Kopi Export", nil, 10, 0)
+	usdProdID := testutil.MustInsertProduct(t, db, "SKU-FX-2", "Imported Tea", nil, 10, 0)
+	testutil.MustInsertPrice(t, db, prodID, nil, "IDR", "80000.00")
+	testutil.MustInsertPrice(t, db, usdProdID, nil, "USD", "5.00")
+
+	trxStore := trxrepo.NewTransactionStoreAdapter(trxrepo.NewTransactionRepo(db), db)
+
+	// one transaction, one currency
+	if _, err := trxStore.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 1}, {ProductID: usdProdID, Qty: 1}},
+	}); !errors.Is(err, trxuc.ErrMixedCurrency) {
+		t.Fatalf("expected ErrMixedCurrency got=%v", err)
+	}
+
+	trx, err := trxStore.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 2}},
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+
+	pUC := payuc.New(NewPaymentStoreAdapter(NewPaymentRepo(db)))
+	usd := "USD"
+
+	if _, _, err := pUC.Create(ctx, payuc.CreateInput{TransactionID: trx.ID, Method: "cash", Amount: "5", Currency: &usd}); err != payuc.ErrRateMissing {
+		t.Fatalf("expected ErrRateMissing got=%v", err)
+	}
+
+	if _, err := db.Exec(ctx, `
+		INSERT INTO exchange_rates (from_currency, to_currency, rate, effective_at)
+		VALUES ('USD', 'IDR', 16000, now() - interval '1 day')`); err != nil {
+		t.Fatalf("insert rate: %v", err)
+	}
+
+	p, state, err := pUC.Create(ctx, payuc.CreateInput{TransactionID: trx.ID, Method: "cash", Amount: "5", Currency: &usd})
+	if err != nil {
+		t.Fatalf("create usd payment: %v", err)
+	}
+	if p.Currency != "IDR" || p.Amount != "80000.00" {
+		t.Fatalf("expected 80000.00 IDR booked got=%s %s", p.Amount, p.Currency)
+	}
+	if p.OriginalCurrency == nil || *p.OriginalCurrency != "USD" || p.OriginalAmount == nil || *p.OriginalAmount != "5.00" || p.ExchangeRate == nil {
+		t.Fatalf("expected original USD amount and rate recorded: %+v", p)
+	}
+	if state.PaymentStatus != "partial" || state.PaidAmount != "80000.00" {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+
+	// store credit is always in the transaction currency
+	if _, _, err := pUC.Create(ctx, payuc.CreateInput{TransactionID: trx.ID, Method: "credit", Amount: "1", Currency: &usd}); err != payuc.ErrCurrencyMismatch {
+		t.Fatalf("expected ErrCurrencyMismatch got=%v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	pricinguc "github.com/riolentius/cahaya-gading-backend/internal/usecase/pricing"
+)
+
+type PricingStoreAdapter struct {
+	repo *PricingRepo
+}
+
+func NewPricingStoreAdapter(repo *PricingRepo) *PricingStoreAdapter {
+	return &PricingStoreAdapter{repo: repo}
+}
+
+func (a *PricingStoreAdapter) GetStockRule(ctx context.Context, productID string) (string, float64, error) {
+	stockProductID, packSize, err := a.repo.GetStockRule(ctx, productID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", 0, pricinguc.ErrProductMissing
+		}
+		return "", 0, err
+	}
+	return stockProductID, packSize, nil
+}
+
+func (a *PricingStoreAdapter) GetCustomerCategoryID(ctx context.Context, customerID string) (*string, error) {
+	categoryID, err := a.repo.GetCustomerCategoryID(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, pricinguc.ErrCustomerMissing
+		}
+		return nil, err
+	}
+	return categoryID, nil
+}
+
+func (a *PricingStoreAdapter) ResolvePrice(ctx context.Context, productID string, categoryID *string, qty pricinguc.Qty, at time.Time) (*pricinguc.EffectivePrice, error) {
+	r, err := a.repo.ResolvePrice(ctx, productID, categoryID, PriceQty{Line: qty.Line, BaseUnits: qty.BaseUnits}, at)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	out := mapEffectivePriceRow(r)
+	return &out, nil
+}
+
+func mapEffectivePriceRow(r *EffectivePriceRow) pricinguc.EffectivePrice {
+	rule := pricinguc.RuleDefault
+	if r.CategoryID != nil {
+		rule = pricinguc.RuleCategory
+	}
+	return pricinguc.EffectivePrice{
+		ProductID:  r.ProductID,
+		PriceID:    r.ID,
+		Rule:       rule,
+		CategoryID: r.CategoryID,
+		Currency:   r.Currency,
+		UnitAmount: r.Amount,
+		ValidFrom:  r.ValidFrom,
+		ValidTo:    r.ValidTo,
+		MinQty:     r.MinQty,
+		QtyBasis:   r.QtyBasis,
+	}
+}
+
+// Compile-time check
+var _ pricinguc.Store = (*PricingStoreAdapter)(nil)
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EffectivePriceRow struct {
+	ID         string
+	ProductID  string
+	CategoryID *string // NULL = default price
+	Currency   string
+	Amount     string
+	ValidFrom  time.Time
+	ValidTo    *time.Time
+	MinQty     int
+	QtyBasis   string
+}
+
+// Queryer is satisfied by *pgxpool.Pool and pgx.Tx, so price resolution can run
+// standalone or inside another repository's DB transaction.
+type Queryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type PricingRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPricingRepo(db *pgxpool.Pool) *PricingRepo {
+	return &PricingRepo{db: db}
+}
+
+// PriceQty is the quantity a price tier is compared with: Line for qty_basis
+// 'line' rows, BaseUnits for 'base_units' rows (qty * pack_size summed over all
+// items sharing the stock product).
+type PriceQty struct {
+	Line      int
+	BaseUnits float64
+}
+
+// ResolveEffectivePrice picks the price row valid at `at` (nil = DB now()):
+// a row for the customer category wins over the default (category_id IS NULL) row,
+// then the highest quantity tier reached, then the most recent valid_from.
+// Returns pgx.ErrNoRows if nothing applies.
+func ResolveEffectivePrice(
+	ctx context.Context,
+	q Queryer,
+	productID string,
+	categoryID *string, // can be nil
+	qty PriceQty,
+	at *time.Time, // can be nil
+) (*EffectivePriceRow, error) {
+	const sql = `
+SELECT id::text, product_id::text, category_id::text, currency, amount::text, valid_from, valid_to, min_qty, qty_basis
+FROM product_prices
+WHERE product_id = $1::uuid
+  AND (
+    ($2::uuid IS NOT NULL AND category_id = $2::uuid)
+    OR category_id IS NULL
+  )
+  AND valid_from <= COALESCE($3::timestamptz, now())
+  AND (valid_to IS NULL OR COALESCE($3::timestamptz, now()) < valid_to)
+  AND min_qty <= CASE WHEN qty_basis = 'base_units' THEN $5::numeric ELSE $4::int END
+ORDER BY (category_id IS NULL) ASC, min_qty DESC, valid_from DESC, created_at DESC
+LIMIT 1;
+`
+	// note: if categoryID is nil, $2::uuid becomes NULL, query falls back to category_id IS NULL.
+	var out EffectivePriceRow
+	if err := q.QueryRow(ctx, sql, productID, categoryID, at, qty.Line, qty.BaseUnits).Scan(
+		&out.ID,
+		&out.ProductID,
+		&out.CategoryID,
+		&out.Currency,
+		&out.Amount,
+		&out.ValidFrom,
+		&out.ValidTo,
+		&out.MinQty,
+		&out.QtyBasis,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *PricingRepo) ResolvePrice(ctx context.Context, productID string, categoryID *string, qty PriceQty, at time.Time) (*EffectivePriceRow, error) {
+	out, err := ResolveEffectivePrice(ctx, r.db, productID, categoryID, qty, &at)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetStockRule returns where the product's stock lives and its pack size
+// (pgx.ErrNoRows if the product does not exist).
+func (r *PricingRepo) GetStockRule(ctx context.Context, productID string) (stockProductID string, packSize float64, err error) {
+	const q = `
+SELECT COALESCE(base_product_id, id)::text, pack_size::float8
+FROM products
+WHERE id = $1::uuid;
+`
+	if err := r.db.QueryRow(ctx, q, productID).Scan(&stockProductID, &packSize); err != nil {
+		return "", 0, err
+	}
+	return stockProductID, packSize, nil
+}
+
+// GetCustomerCategoryID returns pgx.ErrNoRows if the customer does not exist.
+func (r *PricingRepo) GetCustomerCategoryID(ctx context.Context, customerID string) (*string, error) {
+	const q = `SELECT category_id::text FROM customers WHERE id = $1::uuid`
+	var categoryID *string
+	if err := r.db.QueryRow(ctx, q, customerID).Scan(&categoryID); err != nil {
+		return nil, err
+	}
+	return categoryID, nil
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	pricinguc "github.com/riolentius/cahaya-gading-backend/internal/usecase/pricing"
+)
+
+func TestPricing_EffectivePriceAndQuote(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	catID := testutil.MustInsertCategory(t, db, "WHOLESALE", "Wholesale")
+	wholesaleID := testutil.MustInsertCustomer(t, db, "Toko", "Grosir", "grosir@test.local", &catID)
+	walkInID := testutil.MustInsertCustomer(t, db, "Walk", "In", "walkin@test.local", nil)
+
+	prodID := testutil.MustInsertProduct(t, db, "SKU-PR-1", "Minyak 2L", nil, 10, 0)
+	noPriceID := testutil.MustInsertProduct(t, db, "SKU-PR-2", "Tanpa Harga", nil, 10, 0)
+	testutil.MustInsertPrice(t, db, prodID, nil, "IDR", "35000.00")
+	testutil.MustInsertPrice(t, db, prodID, &catID, "IDR", "32000.00")
+
+	uc := pricinguc.New(NewPricingStoreAdapter(NewPricingRepo(db)))
+
+	// category price wins for wholesale customers
+	res, err := uc.EffectivePrice(ctx, pricinguc.EffectivePriceQuery{ProductID: prodID, CustomerID: &wholesaleID})
+	if err != nil {
+		t.Fatalf("effective price: %v", err)
+	}
+	if res.Price.Rule != pricinguc.RuleCategory || res.Price.UnitAmount != "32000.00" {
+		t.Fatalf("expected category price 32000.00 got=%+v", res.Price)
+	}
+
+	// everyone else falls back to the default
+	res, err = uc.EffectivePrice(ctx, pricinguc.EffectivePriceQuery{ProductID: prodID, CustomerID: &walkInID})
+	if err != nil {
+		t.Fatalf("effective price: %v", err)
+	}
+	if res.Price.Rule != pricinguc.RuleDefault || res.Price.UnitAmount != "35000.00" {
+		t.Fatalf("expected default price 35000.00 got=%+v", res.Price)
+	}
+
+	// before any price became valid
+	past := time.Now().AddDate(0, 0, -7)
+	if _, err := uc.EffectivePrice(ctx, pricinguc.EffectivePriceQuery{ProductID: prodID, At: &past}); err != pricinguc.ErrPriceMissing {
+		t.Fatalf("expected ErrPriceMissing got=%v", err)
+	}
+
+	q, err := uc.Quote(ctx, pricinguc.QuoteInput{
+		CustomerID: &wholesaleID,
+		Items: []pricinguc.QuoteItemIn{
+			{ProductID: prodID, Qty: 3},
+			{ProductID: noPriceID, Qty: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("quote: %v", err)
+	}
+	if len(q.Lines) != 2 || q.Lines[0].LineTotal == nil || *q.Lines[0].LineTotal != "96000.00" {
+		t.Fatalf("unexpected quote lines: %+v", q.Lines)
+	}
+	if q.Lines[1].Error == nil {
+		t.Fatalf("expected per-line error for unpriced product")
+	}
+	if len(q.Totals) != 1 || q.Totals[0].Amount != "96000.00" {
+		t.Fatalf("unexpected totals: %+v", q.Totals)
+	}
+}
+
+func TestPricing_QuantityTiers(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	baseID := testutil.MustInsertProduct(t, db, "SKU-MIE-1", "Mie Instan", nil, 1000, 0)
+	packID := testutil.MustInsertProduct(t, db, "SKU-MIE-40", "Mie Instan Dus 40", nil, 0, 0)
+	if _, err := db.Exec(ctx, `UPDATE products SET base_product_id = $1::uuid, pack_size = 40 WHERE id = $2::uuid`, baseID, packID); err != nil {
+		t.Fatalf("set pack: %v", err)
+	}
+	testutil.MustInsertPrice(t, db, packID, nil, "IDR", "110000.00")
+
+	for _, tier := range []struct {
+		minQty int
+		basis  string
+		amount string
+	}{
+		{1, "line", "3000.00"},
+		{10, "line", "2800.00"},
+		{50, "base_units", "2500.00"},
+	} {
+		if _, err := db.Exec(ctx, `
+INSERT INTO product_prices (product_id, currency, amount, valid_from, min_qty, qty_basis)
+VALUES ($1::uuid, 'IDR', $2::numeric, now() - interval '1 day', $3, $4)`,
+			baseID, tier.amount, tier.minQty, tier.basis); err != nil {
+			t.Fatalf("insert tier %d: %v", tier.minQty, err)
+		}
+	}
+
+	uc := pricinguc.New(NewPricingStoreAdapter(NewPricingRepo(db)))
+
+	for _, tc := range []struct {
+		qty  int
+		want string
+	}{
+		{5, "3000.00"},
+		{12, "2800.00"},
+		{60, "2500.00"},
+	} {
+		res, err := uc.EffectivePrice(ctx, pricinguc.EffectivePriceQuery{ProductID: baseID, Qty: tc.qty})
+		if err != nil {
+			t.Fatalf("qty %d: %v", tc.qty, err)
+		}
+		if res.Price.UnitAmount != tc.want {
+			t.Fatalf("qty %d: expected %s got=%s", tc.qty, tc.want, res.Price.UnitAmount)
+		}
+	}
+
+	// 20 loose + 1 box of 40 = 60 base units -> base-unit tier applies to the loose items
+	q, err := uc.Quote(ctx, pricinguc.QuoteInput{
+		Items: []pricinguc.QuoteItemIn{
+			{ProductID: baseID, Qty: 20},
+			{ProductID: packID, Qty: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("quote: %v", err)
+	}
+	if q.Lines[0].BaseUnits != 60 || q.Lines[0].Price == nil || q.Lines[0].Price.UnitAmount != "2500.00" {
+		t.Fatalf("unexpected tier for aggregated base units: %+v", q.Lines[0])
+	}
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 
 	productuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/product"
 )
@@ -19,9 +20,10 @@ func (a *ProductStoreAdapter) Create(
 	sku *string,
 	name string,
 	description *string,
+	category *string,
 	stockOnHand int,
 ) (*productuc.Product, error) {
-	row, err := a.repo.Create(ctx, sku, name, description, stockOnHand)
+	row, err := a.repo.Create(ctx, sku, name, description, category, stockOnHand)
 	if err != nil {
 		return nil, err
 	}
@@ -32,8 +34,9 @@ func (a *ProductStoreAdapter) List(
 	ctx context.Context,
 	limit int,
 	offset int,
+	includeArchived bool,
 ) ([]productuc.Product, error) {
-	rows, err := a.repo.List(ctx, limit, offset)
+	rows, err := a.repo.List(ctx, limit, offset, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -51,25 +54,49 @@ func (a *ProductStoreAdapter) Update(
 	sku *string,
 	name *string,
 	description *string,
+	category *string,
 	isActive *bool,
 	stockOnHand *int,
+	reorderPoint *int,
+	reorderQty *int,
 ) (*productuc.Product, error) {
-	row, err := a.repo.Update(ctx, id, sku, name, description, isActive, stockOnHand)
+	row, err := a.repo.Update(ctx, id, sku, name, description, category, isActive, stockOnHand, reorderPoint, reorderQty)
 	if err != nil {
 		return nil, err
 	}
 	return mapProductRowToUC(row), nil
 }
 
+func (a *ProductStoreAdapter) SetArchived(ctx context.Context, id string, archived bool) (*productuc.Product, error) {
+	row, err := a.repo.SetArchived(ctx, id, archived)
+	if err != nil {
+		switch {
+		case isNoRows(err):
+			return nil, productuc.ErrNotFound
+		case errors.Is(err, errActivePacks):
+			return nil, productuc.ErrHasActivePacks
+		case errors.Is(err, errOpenReservations):
+			return nil, productuc.ErrHasReservations
+		}
+		return nil, err
+	}
+	return mapProductRowToUC(row), nil
+}
+
 func mapProductRowToUC(r *ProductRow) *productuc.Product {
 	return &productuc.Product{
 		ID:            r.ID,
 		SKU:           r.SKU,
 		Name:          r.Name,
 		Description:   r.Description,
+		Category:      r.Category,
 		IsActive:      r.IsActive,
 		StockOnHand:   r.StockOnHand,
 		StockReserved: r.StockReserved,
+		AvgCost:       r.AvgCost,
+		ReorderPoint:  r.ReorderPoint,
+		ReorderQty:    r.ReorderQty,
+		DeletedAt:     r.DeletedAt,
 	}
 }
 
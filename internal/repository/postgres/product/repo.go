@@ -2,9 +2,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	auditpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/audit"
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
 )
 
 type ProductRow struct {
@@ -12,13 +17,23 @@ type ProductRow struct {
 	SKU           *string
 	Name          string
 	Description   *string
+	Category      *string
 	IsActive      bool
 	StockOnHand   int
 	StockReserved int
+	AvgCost       *string
+	ReorderPoint  *int
+	ReorderQty    *int
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	DeletedAt     *time.Time
 }
 
+var (
+	errActivePacks      = errors.New("product is the base of active packs")
+	errOpenReservations = errors.New("product has open reservations")
+)
+
 type ProductRepo struct {
 	db *pgxpool.Pool
 }
@@ -32,17 +47,34 @@ func (r *ProductRepo) Create(
 	sku *string,
 	name string,
 	description *string,
+	category *string,
 	stockOnHand int,
 ) (*ProductRow, error) {
+	// opening stock is held at the default location
 	const q = `
-INSERT INTO products (sku, name, description, stock_on_hand)
-VALUES ($1, $2, $3, $4)
-RETURNING
-  id::text, sku, name, description, is_active,
-  stock_on_hand, stock_reserved,
-  created_at, updated_at;
+WITH p AS (
+  INSERT INTO products (sku, name, description, category, stock_on_hand)
+  VALUES ($1, $2, $3, $4, $5)
+  RETURNING *
+), sl AS (
+  INSERT INTO stock_levels (product_id, location_id, on_hand)
+  SELECT p.id, l.id, p.stock_on_hand
+  FROM p, locations l
+  WHERE l.is_default
+)
+SELECT
+  id::text, sku, name, description, category, is_active,
+  stock_on_hand, stock_reserved, avg_cost::text, reorder_point, reorder_qty,
+  created_at, updated_at, deleted_at
+FROM p;
 `
-	row := r.db.QueryRow(ctx, q, sku, name, description, stockOnHand)
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row := tx.QueryRow(ctx, q, sku, name, description, category, stockOnHand)
 
 	var out ProductRow
 	if err := row.Scan(
@@ -50,28 +82,43 @@ RETURNING
 		&out.SKU,
 		&out.Name,
 		&out.Description,
+		&out.Category,
 		&out.IsActive,
 		&out.StockOnHand,
 		&out.StockReserved,
+		&out.AvgCost,
+		&out.ReorderPoint,
+		&out.ReorderQty,
 		&out.CreatedAt,
 		&out.UpdatedAt,
+		&out.DeletedAt,
 	); err != nil {
 		return nil, err
 	}
+
+	if err := recordProductChange(ctx, tx, auditpg.ActionCreate, out.ID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &out, nil
 }
 
-func (r *ProductRepo) List(ctx context.Context, limit int, offset int) ([]ProductRow, error) {
+// List hides archived products unless includeArchived.
+func (r *ProductRepo) List(ctx context.Context, limit int, offset int, includeArchived bool) ([]ProductRow, error) {
 	const q = `
 SELECT
-  id::text, sku, name, description, is_active,
-  stock_on_hand, stock_reserved,
-  created_at, updated_at
+  id::text, sku, name, description, category, is_active,
+  stock_on_hand, stock_reserved, avg_cost::text, reorder_point, reorder_qty,
+  created_at, updated_at, deleted_at
 FROM products
+WHERE $3::boolean OR deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
 `
-	rows, err := r.db.Query(ctx, q, limit, offset)
+	rows, err := r.db.Query(ctx, q, limit, offset, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -85,11 +132,16 @@ LIMIT $1 OFFSET $2;
 			&p.SKU,
 			&p.Name,
 			&p.Description,
+			&p.Category,
 			&p.IsActive,
 			&p.StockOnHand,
 			&p.StockReserved,
+			&p.AvgCost,
+			&p.ReorderPoint,
+			&p.ReorderQty,
 			&p.CreatedAt,
 			&p.UpdatedAt,
+			&p.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -104,9 +156,54 @@ func (r *ProductRepo) Update(
 	sku *string,
 	name *string,
 	description *string,
+	category *string,
 	isActive *bool,
 	stockOnHand *int,
+	reorderPoint *int,
+	reorderQty *int,
 ) (*ProductRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// lock first: a manual stock correction can cross the reorder point
+	var onHand, availableBefore int
+	if err := tx.QueryRow(ctx, `
+SELECT stock_on_hand, stock_on_hand - stock_reserved
+FROM products
+WHERE id = $1::uuid
+FOR UPDATE;
+`, id).Scan(&onHand, &availableBefore); err != nil {
+		return nil, err
+	}
+
+	before, err := auditpg.Snapshot(ctx, tx, auditpg.EntityProducts, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// stockOnHand is the new total; the difference is booked at the default location
+	if stockOnHand != nil && *stockOnHand != onHand {
+		locationID, err := trxpg.ResolveLocation(ctx, tx, nil)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := trxpg.LockStockLevel(ctx, tx, id, locationID); err != nil {
+			return nil, err
+		}
+		// a decrease writes stock off lots, expired ones first
+		if delta := *stockOnHand - onHand; delta < 0 {
+			if _, err := trxpg.TakeLots(ctx, tx, id, locationID, -delta, true); err != nil {
+				return nil, err
+			}
+		}
+		if err := trxpg.AdjustStockLevel(ctx, tx, id, locationID, *stockOnHand-onHand, 0); err != nil {
+			return nil, err
+		}
+	}
+
 	const q = `
 UPDATE products
 SET
@@ -114,15 +211,17 @@ SET
   name = COALESCE($3, name),
   description = COALESCE($4, description),
   is_active = COALESCE($5, is_active),
-  stock_on_hand = COALESCE($6, stock_on_hand),
+  category = COALESCE($6, category),
+  reorder_point = COALESCE($7, reorder_point),
+  reorder_qty = COALESCE($8, reorder_qty),
   updated_at = now()
 WHERE id = $1::uuid
 RETURNING
-  id::text, sku, name, description, is_active,
-  stock_on_hand, stock_reserved,
-  created_at, updated_at;
+  id::text, sku, name, description, category, is_active,
+  stock_on_hand, stock_reserved, avg_cost::text, reorder_point, reorder_qty,
+  created_at, updated_at, deleted_at;
 `
-	row := r.db.QueryRow(ctx, q, id, sku, name, description, isActive, stockOnHand)
+	row := tx.QueryRow(ctx, q, id, sku, name, description, isActive, category, reorderPoint, reorderQty)
 
 	var out ProductRow
 	if err := row.Scan(
@@ -130,13 +229,155 @@ RETURNING
 		&out.SKU,
 		&out.Name,
 		&out.Description,
+		&out.Category,
 		&out.IsActive,
 		&out.StockOnHand,
 		&out.StockReserved,
+		&out.AvgCost,
+		&out.ReorderPoint,
+		&out.ReorderQty,
 		&out.CreatedAt,
 		&out.UpdatedAt,
+		&out.DeletedAt,
 	); err != nil {
 		return nil, err
 	}
+
+	if stockOnHand != nil {
+		if err := trxpg.RecordStockThreshold(ctx, tx, id, availableBefore); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordProductChange(ctx, tx, auditpg.ActionUpdate, id, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &out, nil
 }
+
+// SetArchived archives (soft-deletes) or restores the product. A product
+// still packed by active pack products, or with stock reserved by open
+// transactions, cannot be archived.
+func (r *ProductRepo) SetArchived(ctx context.Context, id string, archived bool) (*ProductRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var reserved int
+	var wasArchived bool
+	if err := tx.QueryRow(ctx, `
+SELECT stock_reserved, deleted_at IS NOT NULL
+FROM products
+WHERE id = $1::uuid
+FOR UPDATE;
+`, id).Scan(&reserved, &wasArchived); err != nil {
+		return nil, err
+	}
+
+	if archived && !wasArchived {
+		var packs bool
+		if err := tx.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1 FROM products
+  WHERE base_product_id = $1::uuid
+    AND is_active
+    AND deleted_at IS NULL
+);
+`, id).Scan(&packs); err != nil {
+			return nil, err
+		}
+		if packs {
+			return nil, errActivePacks
+		}
+
+		var open bool
+		if err := tx.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1
+  FROM transaction_items ti
+  JOIN transactions t ON t.id = ti.transaction_id
+  WHERE ti.product_id = $1::uuid
+    AND t.status = 'pending'
+);
+`, id).Scan(&open); err != nil {
+			return nil, err
+		}
+		if open || reserved > 0 {
+			return nil, errOpenReservations
+		}
+	}
+
+	before, err := auditpg.Snapshot(ctx, tx, auditpg.EntityProducts, id)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `
+UPDATE products
+SET
+  deleted_at = CASE WHEN $2::boolean THEN COALESCE(deleted_at, now()) END,
+  updated_at = CASE WHEN (deleted_at IS NULL) = $2::boolean THEN now() ELSE updated_at END
+WHERE id = $1::uuid
+RETURNING
+  id::text, sku, name, description, category, is_active,
+  stock_on_hand, stock_reserved, avg_cost::text, reorder_point, reorder_qty,
+  created_at, updated_at, deleted_at;
+`
+	var out ProductRow
+	if err := tx.QueryRow(ctx, q, id, archived).Scan(
+		&out.ID,
+		&out.SKU,
+		&out.Name,
+		&out.Description,
+		&out.Category,
+		&out.IsActive,
+		&out.StockOnHand,
+		&out.StockReserved,
+		&out.AvgCost,
+		&out.ReorderPoint,
+		&out.ReorderQty,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+		&out.DeletedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	action := auditpg.ActionRestore
+	if archived {
+		action = auditpg.ActionArchive
+	}
+	if err := recordProductChange(ctx, tx, action, id, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+// recordProductChange logs the write in the audit log, in tx.
+func recordProductChange(ctx context.Context, tx pgx.Tx, action, id string, before []byte) error {
+	after, err := auditpg.Snapshot(ctx, tx, auditpg.EntityProducts, id)
+	if err != nil {
+		return err
+	}
+	return auditpg.Record(ctx, tx, auditpg.Change{
+		Action:   action,
+		Entity:   auditpg.EntityProducts,
+		EntityID: id,
+		Before:   before,
+		After:    after,
+	})
+}
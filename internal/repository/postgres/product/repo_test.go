@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	productuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/product"
+)
+
+func TestProduct_ListReturnsCategory(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+	uc := productuc.New(NewProductStoreAdapter(NewProductRepo(db)))
+
+	sku, category := "SKU-LS-1", "Minuman"
+	created, err := uc.Create(ctx, productuc.CreateInput{SKU: &sku, Name: "Teh Botol", Category: &category})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	listed, err := uc.List(ctx, 50, 0, false)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != created.ID {
+		t.Fatalf("expected the created product got=%+v", listed)
+	}
+	if listed[0].Category == nil || *listed[0].Category != "minuman" {
+		t.Fatalf("expected category minuman got=%v", listed[0].Category)
+	}
+}
+
+func TestProduct_ArchiveGuardsAndRestore(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+	uc := productuc.New(NewProductStoreAdapter(NewProductRepo(db)))
+
+	baseID := testutil.MustInsertProduct(t, db, "SKU-AR-1", "Gula 1kg", nil, 10, 0)
+	packID := testutil.MustInsertProduct(t, db, "SKU-AR-12", "Gula 1kg x12", nil, 0, 0)
+	reservedID := testutil.MustInsertProduct(t, db, "SKU-AR-2", "Kopi", nil, 10, 3)
+
+	if _, err := db.Exec(ctx, `UPDATE products SET base_product_id = $1::uuid, pack_size = 12 WHERE id = $2::uuid`, baseID, packID); err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+
+	if _, err := uc.Archive(ctx, baseID); err != productuc.ErrHasActivePacks {
+		t.Fatalf("expected ErrHasActivePacks got=%v", err)
+	}
+	if _, err := uc.Archive(ctx, reservedID); err != productuc.ErrHasReservations {
+		t.Fatalf("expected ErrHasReservations got=%v", err)
+	}
+
+	// once the pack is archived its base product can go too
+	if _, err := uc.Archive(ctx, packID); err != nil {
+		t.Fatalf("archive pack: %v", err)
+	}
+	p, err := uc.Archive(ctx, baseID)
+	if err != nil {
+		t.Fatalf("archive base: %v", err)
+	}
+	if p.DeletedAt == nil {
+		t.Fatalf("expected deletedAt to be set")
+	}
+
+	listed, err := uc.List(ctx, 50, 0, false)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != reservedID {
+		t.Fatalf("expected only the active product got=%+v", listed)
+	}
+	all, err := uc.List(ctx, 50, 0, true)
+	if err != nil {
+		t.Fatalf("list archived: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 products with archived got=%d", len(all))
+	}
+
+	p, err = uc.Restore(ctx, baseID)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if p.DeletedAt != nil {
+		t.Fatalf("expected deletedAt to be cleared")
+	}
+}
@@ -3,6 +3,9 @@ package postgres
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5"
+
+	auditpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/audit"
 	priceuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/product_price"
 )
 
@@ -19,19 +22,20 @@ func (a *ProductPriceStoreAdapter) CreateForProduct(
 	productID string,
 	in priceuc.CreateInput,
 ) (*priceuc.ProductPrice, error) {
-	row, err := a.repo.Create(
-		ctx,
-		productID,
-		in.CategoryID,
-		in.Currency,
-		in.Amount,
-		in.ValidFrom,
-		in.ValidTo,
-	)
+	tx, err := a.repo.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row, err := schedulePrice(ctx, tx, productID, in)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return mapProductPriceRowToUC(row), nil
 }
 
@@ -67,12 +71,119 @@ func (a *ProductPriceStoreAdapter) Update(
 		in.CategoryID,
 	)
 	if err != nil {
-		return nil, err
+		if isNoRows(err) {
+			return nil, priceuc.ErrNotFound
+		}
+		return nil, mapPriceWriteErr(err)
 	}
 
 	return mapProductPriceRowToUC(row), nil
 }
 
+func (a *ProductPriceStoreAdapter) ListUpcoming(ctx context.Context, q priceuc.UpcomingQuery) ([]priceuc.UpcomingPrice, error) {
+	rows, err := a.repo.ListUpcoming(ctx, q.ProductID, q.Days)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]priceuc.UpcomingPrice, 0, len(rows))
+	for i := range rows {
+		out = append(out, priceuc.UpcomingPrice{
+			ProductPrice:   *mapProductPriceRowToUC(&rows[i].ProductPriceRow),
+			ProductSKU:     rows[i].ProductSKU,
+			ProductName:    rows[i].ProductName,
+			CurrentPriceID: rows[i].CurrentPriceID,
+			CurrentAmount:  rows[i].CurrentAmount,
+		})
+	}
+	return out, nil
+}
+
+// schedulePrice inserts a price inside tx, closing (or splitting) the price of the
+// same product/category/tier it supersedes.
+func schedulePrice(ctx context.Context, tx pgx.Tx, productID string, in priceuc.CreateInput) (*ProductPriceRow, error) {
+	if err := lockProductForPricing(ctx, tx, productID); err != nil {
+		if isNoRows(err) {
+			return nil, priceuc.ErrProductMissing
+		}
+		return nil, err
+	}
+
+	validFrom := *in.ValidFrom
+	validTo := in.ValidTo
+
+	// close the price of the same tier in effect at validFrom; a temporary price resumes it afterwards
+	cover, err := lockCoveringPrice(ctx, tx, productID, in.CategoryID, *in.MinQty, validFrom)
+	if err != nil {
+		return nil, err
+	}
+	if cover != nil {
+		before, err := auditpg.Snapshot(ctx, tx, auditpg.EntityProductPrices, cover.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := closePriceAt(ctx, tx, cover.ID, validFrom); err != nil {
+			return nil, err
+		}
+		if err := recordPriceChange(ctx, tx, auditpg.ActionUpdate, cover.ID, before); err != nil {
+			return nil, err
+		}
+		if validTo != nil && (cover.ValidTo == nil || cover.ValidTo.After(*validTo)) {
+			resumed, err := insertPrice(ctx, tx, productID, in.CategoryID, cover.Currency, cover.Amount, validTo, cover.ValidTo, *in.MinQty, cover.QtyBasis)
+			if err != nil {
+				return nil, mapPriceWriteErr(err)
+			}
+			if err := recordPriceChange(ctx, tx, auditpg.ActionCreate, resumed.ID, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// an open-ended price runs until the next already scheduled change
+	if validTo == nil {
+		next, err := nextScheduledStart(ctx, tx, productID, in.CategoryID, *in.MinQty, validFrom)
+		if err != nil {
+			return nil, err
+		}
+		validTo = next
+	}
+
+	row, err := insertPrice(ctx, tx, productID, in.CategoryID, in.Currency, in.Amount, &validFrom, validTo, *in.MinQty, *in.QtyBasis)
+	if err != nil {
+		return nil, mapPriceWriteErr(err)
+	}
+	if err := recordPriceChange(ctx, tx, auditpg.ActionCreate, row.ID, nil); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// recordPriceChange logs the write in the audit log, in tx.
+func recordPriceChange(ctx context.Context, tx pgx.Tx, action, id string, before []byte) error {
+	after, err := auditpg.Snapshot(ctx, tx, auditpg.EntityProductPrices, id)
+	if err != nil {
+		return err
+	}
+	return auditpg.Record(ctx, tx, auditpg.Change{
+		Action:   action,
+		Entity:   auditpg.EntityProductPrices,
+		EntityID: id,
+		Before:   before,
+		After:    after,
+	})
+}
+
+func mapPriceWriteErr(err error) error {
+	switch {
+	case isExclusionViolation(err):
+		return priceuc.ErrPriceOverlap
+	case isCheckViolation(err):
+		return priceuc.ErrInvalidWindow
+	default:
+		return err
+	}
+}
+
 func mapProductPriceRowToUC(r *ProductPriceRow) *priceuc.ProductPrice {
 	return &priceuc.ProductPrice{
 		ID:         r.ID,
@@ -82,6 +193,8 @@ func mapProductPriceRowToUC(r *ProductPriceRow) *priceuc.ProductPrice {
 		Amount:     r.Amount,
 		ValidFrom:  r.ValidFrom,
 		ValidTo:    r.ValidTo,
+		MinQty:     r.MinQty,
+		QtyBasis:   r.QtyBasis,
 		CreatedAt:  r.CreatedAt,
 		UpdatedAt:  r.UpdatedAt,
 	}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	priceuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/product_price"
+)
+
+var (
+	errUnknownSKU      = errors.New("unknown sku")
+	errUnknownCategory = errors.New("unknown category code")
+)
+
+// BulkCreate runs every row in its own savepoint inside one DB transaction, so a
+// failing row is reported without aborting the others. The transaction is only
+// committed when apply is true and every row succeeded.
+func (a *ProductPriceStoreAdapter) BulkCreate(ctx context.Context, validFrom time.Time, rows []priceuc.BulkRow, apply bool) ([]priceuc.BulkRowResult, error) {
+	tx, err := a.repo.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	categories := map[string]string{}
+	out := make([]priceuc.BulkRowResult, 0, len(rows))
+	failed := false
+
+	for _, r := range rows {
+		res := priceuc.BulkRowResult{
+			Line:         r.Line,
+			SKU:          r.SKU,
+			CategoryCode: r.CategoryCode,
+			MinQty:       *r.MinQty,
+			Currency:     r.Currency,
+			NewAmount:    r.Amount,
+		}
+
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		price, old, err := scheduleBulkRow(ctx, sp, categories, validFrom, r)
+		if old != nil {
+			res.OldAmount = &old.Amount
+		}
+		switch {
+		case err == nil:
+			if err := sp.Commit(ctx); err != nil {
+				return nil, err
+			}
+			if price == nil {
+				res.Action = priceuc.BulkActionUnchanged
+			} else {
+				res.Action = priceuc.BulkActionCreate
+				res.ProductID = &price.ProductID
+				res.PriceID = &price.ID
+			}
+		case isRowError(err):
+			if err := sp.Rollback(ctx); err != nil {
+				return nil, err
+			}
+			msg := err.Error()
+			res.Action = priceuc.BulkActionError
+			res.Error = &msg
+			failed = true
+		default:
+			return nil, err
+		}
+
+		out = append(out, res)
+	}
+
+	if apply && !failed {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// scheduleBulkRow returns (nil, current, nil) when the row does not change the current price.
+func scheduleBulkRow(ctx context.Context, tx pgx.Tx, categories map[string]string, validFrom time.Time, r priceuc.BulkRow) (*ProductPriceRow, *PriceWindowRow, error) {
+	productID, err := getProductIDBySKU(ctx, tx, r.SKU)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, nil, errUnknownSKU
+		}
+		return nil, nil, err
+	}
+
+	var categoryID *string
+	if r.CategoryCode != nil {
+		id, ok := categories[*r.CategoryCode]
+		if !ok {
+			id, err = getCategoryIDByCode(ctx, tx, *r.CategoryCode)
+			if err != nil {
+				if isNoRows(err) {
+					return nil, nil, errUnknownCategory
+				}
+				return nil, nil, err
+			}
+			categories[*r.CategoryCode] = id
+		}
+		categoryID = &id
+	}
+
+	current, err := getPriceAt(ctx, tx, productID, categoryID, *r.MinQty, validFrom)
+	if err != nil {
+		return nil, nil, err
+	}
+	if current != nil && current.Currency == r.Currency && current.Amount == r.Amount && current.QtyBasis == *r.QtyBasis {
+		return nil, current, nil
+	}
+
+	row, err := schedulePrice(ctx, tx, productID, priceuc.CreateInput{
+		CategoryID: categoryID,
+		Currency:   r.Currency,
+		Amount:     r.Amount,
+		ValidFrom:  &validFrom,
+		MinQty:     r.MinQty,
+		QtyBasis:   r.QtyBasis,
+	})
+	if err != nil {
+		return nil, current, err
+	}
+	return row, current, nil
+}
+
+func isRowError(err error) bool {
+	return errors.Is(err, errUnknownSKU) ||
+		errors.Is(err, errUnknownCategory) ||
+		errors.Is(err, priceuc.ErrPriceOverlap) ||
+		errors.Is(err, priceuc.ErrInvalidWindow) ||
+		errors.Is(err, priceuc.ErrProductMissing)
+}
@@ -2,9 +2,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	auditpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/audit"
 )
 
 type ProductPriceRow struct {
@@ -15,10 +20,29 @@ type ProductPriceRow struct {
 	Amount     string
 	ValidFrom  time.Time
 	ValidTo    *time.Time
+	MinQty     int
+	QtyBasis   string
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
 
+type UpcomingPriceRow struct {
+	ProductPriceRow
+	ProductSKU     string
+	ProductName    string
+	CurrentPriceID *string
+	CurrentAmount  *string
+}
+
+// PriceWindowRow is the price covering a moment, locked for rescheduling.
+type PriceWindowRow struct {
+	ID       string
+	Currency string
+	Amount   string
+	QtyBasis string
+	ValidTo  *time.Time
+}
+
 type ProductPriceRepo struct {
 	db *pgxpool.Pool
 }
@@ -27,16 +51,113 @@ func NewProductPriceRepo(db *pgxpool.Pool) *ProductPriceRepo {
 	return &ProductPriceRepo{db: db}
 }
 
-func (r *ProductPriceRepo) Create(ctx context.Context, productID string, categoryID *string, currency string, amount string, validFrom *time.Time, validTo *time.Time) (*ProductPriceRow, error) {
+func (r *ProductPriceRepo) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.db.BeginTx(ctx, pgx.TxOptions{})
+}
+
+// lockProductForPricing serializes price scheduling per product.
+func lockProductForPricing(ctx context.Context, tx pgx.Tx, productID string) error {
+	const q = `SELECT 1 FROM products WHERE id = $1::uuid FOR UPDATE`
+	var one int
+	return tx.QueryRow(ctx, q, productID).Scan(&one)
+}
+
+// lockCoveringPrice returns the price of the same product/category/tier that started
+// before `at` and is still valid at `at` (nil if none).
+func lockCoveringPrice(ctx context.Context, tx pgx.Tx, productID string, categoryID *string, minQty int, at time.Time) (*PriceWindowRow, error) {
+	const q = `
+SELECT id::text, currency, amount::text, qty_basis, valid_to
+FROM product_prices
+WHERE product_id = $1::uuid
+  AND category_id IS NOT DISTINCT FROM $2::uuid
+  AND min_qty = $3::int
+  AND valid_from < $4::timestamptz
+  AND (valid_to IS NULL OR valid_to > $4::timestamptz)
+FOR UPDATE;
+`
+	var out PriceWindowRow
+	if err := tx.QueryRow(ctx, q, productID, categoryID, minQty, at).Scan(&out.ID, &out.Currency, &out.Amount, &out.QtyBasis, &out.ValidTo); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+// nextScheduledStart returns the earliest valid_from after `at` for the same product/category/tier.
+func nextScheduledStart(ctx context.Context, tx pgx.Tx, productID string, categoryID *string, minQty int, at time.Time) (*time.Time, error) {
+	const q = `
+SELECT MIN(valid_from)
+FROM product_prices
+WHERE product_id = $1::uuid
+  AND category_id IS NOT DISTINCT FROM $2::uuid
+  AND min_qty = $3::int
+  AND valid_from > $4::timestamptz;
+`
+	var out *time.Time
+	if err := tx.QueryRow(ctx, q, productID, categoryID, minQty, at).Scan(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// getPriceAt returns the price of the same product/category/tier valid at `at` (nil if none).
+func getPriceAt(ctx context.Context, tx pgx.Tx, productID string, categoryID *string, minQty int, at time.Time) (*PriceWindowRow, error) {
+	const q = `
+SELECT id::text, currency, amount::text, qty_basis, valid_to
+FROM product_prices
+WHERE product_id = $1::uuid
+  AND category_id IS NOT DISTINCT FROM $2::uuid
+  AND min_qty = $3::int
+  AND valid_from <= $4::timestamptz
+  AND (valid_to IS NULL OR valid_to > $4::timestamptz);
+`
+	var out PriceWindowRow
+	if err := tx.QueryRow(ctx, q, productID, categoryID, minQty, at).Scan(&out.ID, &out.Currency, &out.Amount, &out.QtyBasis, &out.ValidTo); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+func getProductIDBySKU(ctx context.Context, tx pgx.Tx, sku string) (string, error) {
+	const q = `SELECT id::text FROM products WHERE sku = $1::text`
+	var id string
+	err := tx.QueryRow(ctx, q, sku).Scan(&id)
+	return id, err
+}
+
+func getCategoryIDByCode(ctx context.Context, tx pgx.Tx, code string) (string, error) {
+	const q = `SELECT id::text FROM customer_categories WHERE code = $1::text`
+	var id string
+	err := tx.QueryRow(ctx, q, code).Scan(&id)
+	return id, err
+}
+
+func closePriceAt(ctx context.Context, tx pgx.Tx, priceID string, at time.Time) error {
 	const q = `
-INSERT INTO product_prices (product_id, category_id, currency, amount, valid_from, valid_to)
-VALUES ($1::uuid, $2::uuid, $3, $4::numeric, COALESCE($5, now()), $6)
-RETURNING id::text, product_id::text, category_id::text, currency, amount::text, valid_from, valid_to, created_at, updated_at;
+UPDATE product_prices
+SET valid_to = $2::timestamptz,
+    updated_at = now()
+WHERE id = $1::uuid;
+`
+	_, err := tx.Exec(ctx, q, priceID, at)
+	return err
+}
+
+func insertPrice(ctx context.Context, tx pgx.Tx, productID string, categoryID *string, currency string, amount string, validFrom *time.Time, validTo *time.Time, minQty int, qtyBasis string) (*ProductPriceRow, error) {
+	const q = `
+INSERT INTO product_prices (product_id, category_id, currency, amount, valid_from, valid_to, min_qty, qty_basis)
+VALUES ($1::uuid, $2::uuid, $3, $4::numeric, COALESCE($5, now()), $6, $7::int, $8::text)
+RETURNING id::text, product_id::text, category_id::text, currency, amount::text, valid_from, valid_to, min_qty, qty_basis, created_at, updated_at;
 `
-	row := r.db.QueryRow(ctx, q, productID, categoryID, currency, amount, validFrom, validTo)
+	row := tx.QueryRow(ctx, q, productID, categoryID, currency, amount, validFrom, validTo, minQty, qtyBasis)
 
 	var out ProductPriceRow
-	if err := row.Scan(&out.ID, &out.ProductID, &out.CategoryID, &out.Currency, &out.Amount, &out.ValidFrom, &out.ValidTo, &out.CreatedAt, &out.UpdatedAt); err != nil {
+	if err := row.Scan(&out.ID, &out.ProductID, &out.CategoryID, &out.Currency, &out.Amount, &out.ValidFrom, &out.ValidTo, &out.MinQty, &out.QtyBasis, &out.CreatedAt, &out.UpdatedAt); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -44,7 +165,7 @@ RETURNING id::text, product_id::text, category_id::text, currency, amount::text,
 
 func (r *ProductPriceRepo) ListByProduct(ctx context.Context, productID string) ([]ProductPriceRow, error) {
 	const q = `
-SELECT id::text, product_id::text, category_id::text, currency, amount::text, valid_from, valid_to, created_at, updated_at
+SELECT id::text, product_id::text, category_id::text, currency, amount::text, valid_from, valid_to, min_qty, qty_basis, created_at, updated_at
 FROM product_prices
 WHERE product_id = $1::uuid
 ORDER BY created_at DESC;
@@ -58,7 +179,7 @@ ORDER BY created_at DESC;
 	var out []ProductPriceRow
 	for rows.Next() {
 		var p ProductPriceRow
-		if err := rows.Scan(&p.ID, &p.ProductID, &p.CategoryID, &p.Currency, &p.Amount, &p.ValidFrom, &p.ValidTo, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.ProductID, &p.CategoryID, &p.Currency, &p.Amount, &p.ValidFrom, &p.ValidTo, &p.MinQty, &p.QtyBasis, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return nil, err
 		}
 		out = append(out, p)
@@ -77,13 +198,99 @@ SET
   category_id = COALESCE($6::uuid, category_id),
   updated_at = now()
 WHERE id = $1::uuid
-RETURNING id::text, product_id::text, category_id::text, currency, amount::text, valid_from, valid_to, created_at, updated_at;
+RETURNING id::text, product_id::text, category_id::text, currency, amount::text, valid_from, valid_to, min_qty, qty_basis, created_at, updated_at;
 `
-	row := r.db.QueryRow(ctx, q, id, currency, amount, validFrom, validTo, categoryID)
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := auditpg.Snapshot(ctx, tx, auditpg.EntityProductPrices, id)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(ctx, q, id, currency, amount, validFrom, validTo, categoryID)
 
 	var out ProductPriceRow
-	if err := row.Scan(&out.ID, &out.ProductID, &out.CategoryID, &out.Currency, &out.Amount, &out.ValidFrom, &out.ValidTo, &out.CreatedAt, &out.UpdatedAt); err != nil {
+	if err := row.Scan(&out.ID, &out.ProductID, &out.CategoryID, &out.Currency, &out.Amount, &out.ValidFrom, &out.ValidTo, &out.MinQty, &out.QtyBasis, &out.CreatedAt, &out.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := recordPriceChange(ctx, tx, auditpg.ActionUpdate, id, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
+
+func (r *ProductPriceRepo) ListUpcoming(ctx context.Context, productID *string, days int) ([]UpcomingPriceRow, error) {
+	const q = `
+SELECT
+  p.id::text, p.product_id::text, p.category_id::text, p.currency, p.amount::text,
+  p.valid_from, p.valid_to, p.min_qty, p.qty_basis, p.created_at, p.updated_at,
+  pr.sku, pr.name,
+  cur.id::text, cur.amount::text
+FROM product_prices p
+JOIN products pr ON pr.id = p.product_id
+LEFT JOIN LATERAL (
+  SELECT c.id, c.amount
+  FROM product_prices c
+  WHERE c.product_id = p.product_id
+    AND c.category_id IS NOT DISTINCT FROM p.category_id
+    AND c.min_qty = p.min_qty
+    AND c.valid_from <= now()
+    AND (c.valid_to IS NULL OR now() < c.valid_to)
+  LIMIT 1
+) cur ON true
+WHERE p.valid_from > now()
+  AND p.valid_from <= now() + make_interval(days => $2::int)
+  AND ($1::uuid IS NULL OR p.product_id = $1::uuid)
+ORDER BY p.valid_from ASC, pr.sku ASC;
+`
+	rows, err := r.db.Query(ctx, q, productID, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []UpcomingPriceRow
+	for rows.Next() {
+		var u UpcomingPriceRow
+		if err := rows.Scan(
+			&u.ID, &u.ProductID, &u.CategoryID, &u.Currency, &u.Amount,
+			&u.ValidFrom, &u.ValidTo, &u.MinQty, &u.QtyBasis, &u.CreatedAt, &u.UpdatedAt,
+			&u.ProductSKU, &u.ProductName,
+			&u.CurrentPriceID, &u.CurrentAmount,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+// isExclusionViolation: ex_product_prices_no_overlap
+func isExclusionViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23P01"
+	}
+	return false
+}
+
+func isCheckViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23514"
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	priceuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/product_price"
+)
+
+func TestProductPrice_SchedulingClosesAndSplitsWindows(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	prodID := testutil.MustInsertProduct(t, db, "SKU-PW-1", "Kopi 200g", nil, 10, 0)
+
+	uc := priceuc.New(NewProductPriceStoreAdapter(NewProductPriceRepo(db)))
+
+	start := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	base, err := uc.CreateForProduct(ctx, prodID, priceuc.CreateInput{Amount: "20000.00", ValidFrom: &start})
+	if err != nil {
+		t.Fatalf("create base: %v", err)
+	}
+
+	// scheduled change next week closes the open price
+	nextWeek := time.Now().AddDate(0, 0, 7).Truncate(time.Second)
+	if _, err := uc.CreateForProduct(ctx, prodID, priceuc.CreateInput{Amount: "22000.00", ValidFrom: &nextWeek}); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+
+	// temporary price for two days splits the current one
+	promoFrom := time.Now().Add(time.Hour).Truncate(time.Second)
+	promoTo := promoFrom.Add(48 * time.Hour)
+	if _, err := uc.CreateForProduct(ctx, prodID, priceuc.CreateInput{Amount: "18000.00", ValidFrom: &promoFrom, ValidTo: &promoTo}); err != nil {
+		t.Fatalf("temporary price: %v", err)
+	}
+
+	// same start as an existing price -> overlap
+	if _, err := uc.CreateForProduct(ctx, prodID, priceuc.CreateInput{Amount: "1.00", ValidFrom: &nextWeek}); err != priceuc.ErrPriceOverlap {
+		t.Fatalf("expected ErrPriceOverlap got=%v", err)
+	}
+
+	items, err := uc.ListForProduct(ctx, prodID)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 4 {
+		t.Fatalf("expected 4 price rows got=%d", len(items))
+	}
+
+	byAmount := map[string]priceuc.ProductPrice{}
+	for _, p := range items {
+		if p.ID == base.ID {
+			if p.ValidTo == nil || !p.ValidTo.Equal(promoFrom) {
+				t.Fatalf("expected base closed at promo start got=%v", p.ValidTo)
+			}
+			continue
+		}
+		byAmount[p.Amount] = p
+	}
+	resumed := byAmount["20000.00"]
+	if !resumed.ValidFrom.Equal(promoTo) || resumed.ValidTo == nil || !resumed.ValidTo.Equal(nextWeek) {
+		t.Fatalf("expected base amount to resume between promo end and next week got=%+v", resumed)
+	}
+
+	upcoming, err := uc.ListUpcoming(ctx, priceuc.UpcomingQuery{ProductID: &prodID, Days: 30})
+	if err != nil {
+		t.Fatalf("upcoming: %v", err)
+	}
+	if len(upcoming) != 3 || upcoming[0].Amount != "18000.00" || upcoming[0].CurrentAmount == nil || *upcoming[0].CurrentAmount != "20000.00" {
+		t.Fatalf("unexpected upcoming: %+v", upcoming)
+	}
+}
+
+func TestProductPrice_BulkImportDryRunAndApply(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	testutil.MustInsertCategory(t, db, "WHOLESALE", "Wholesale")
+	p1 := testutil.MustInsertProduct(t, db, "SKU-B-1", "Teh 25s", nil, 10, 0)
+	p2 := testutil.MustInsertProduct(t, db, "SKU-B-2", "Sabun", nil, 10, 0)
+	testutil.MustInsertPrice(t, db, p1, nil, "IDR", "8000.00")
+	testutil.MustInsertPrice(t, db, p2, nil, "IDR", "4000.00")
+
+	uc := priceuc.New(NewProductPriceStoreAdapter(NewProductPriceRepo(db)))
+	validFrom := time.Now().AddDate(0, 0, 1).Truncate(time.Second)
+
+	csv := []byte("sku,category,amount\nSKU-B-1,,8500\nSKU-B-1,WHOLESALE,7800\nSKU-B-2,,4000.00\n")
+
+	dry, err := uc.ImportCSV(ctx, csv, &validFrom, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if dry.Applied || dry.Created != 2 || dry.Unchanged != 1 || dry.Failed != 0 {
+		t.Fatalf("unexpected dry run: %+v", dry)
+	}
+	if dry.Rows[0].OldAmount == nil || *dry.Rows[0].OldAmount != "8000.00" || dry.Rows[0].NewAmount != "8500.00" {
+		t.Fatalf("unexpected diff row: %+v", dry.Rows[0])
+	}
+	if items, _ := uc.ListForProduct(ctx, p1); len(items) != 1 {
+		t.Fatalf("dry run must not write, got %d prices", len(items))
+	}
+
+	// one bad row rolls back the whole batch
+	bad, err := uc.ImportCSV(ctx, append(csv, []byte("SKU-NOPE,,1000\n")...), &validFrom, false)
+	if err != nil {
+		t.Fatalf("import with error: %v", err)
+	}
+	if bad.Applied || bad.Failed != 1 || bad.Rows[3].Error == nil || bad.Rows[3].Line != 5 {
+		t.Fatalf("unexpected failed import: %+v", bad)
+	}
+	if items, _ := uc.ListForProduct(ctx, p1); len(items) != 1 {
+		t.Fatalf("failed import must not write, got %d prices", len(items))
+	}
+
+	applied, err := uc.ImportCSV(ctx, csv, &validFrom, false)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if !applied.Applied || applied.Created != 2 {
+		t.Fatalf("unexpected apply: %+v", applied)
+	}
+	if items, _ := uc.ListForProduct(ctx, p1); len(items) != 3 {
+		t.Fatalf("expected 3 prices for %s got=%d", p1, len(items))
+	}
+}
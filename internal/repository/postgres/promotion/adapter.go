@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	promouc "github.com/riolentius/cahaya-gading-backend/internal/usecase/promotion"
+)
+
+type PromotionStoreAdapter struct {
+	repo *PromotionRepo
+}
+
+func NewPromotionStoreAdapter(repo *PromotionRepo) *PromotionStoreAdapter {
+	return &PromotionStoreAdapter{repo: repo}
+}
+
+func (a *PromotionStoreAdapter) Create(ctx context.Context, in promouc.CreateInput) (*promouc.Promotion, error) {
+	tx, err := a.repo.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	id, err := insertPromotion(ctx, tx, PromotionRow{
+		Code:      in.Code,
+		Name:      in.Name,
+		Kind:      in.Kind,
+		Percent:   in.Percent,
+		Amount:    in.Amount,
+		Currency:  in.Currency,
+		BuyQty:    in.BuyQty,
+		GetQty:    in.GetQty,
+		Scope:     in.Scope,
+		StartsAt:  *in.StartsAt,
+		EndsAt:    in.EndsAt,
+		Stackable: in.Stackable,
+		Priority:  in.Priority,
+	})
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return nil, promouc.ErrCodeConflict
+		case isCheckViolation(err):
+			return nil, promouc.ErrInvalidInput
+		}
+		return nil, err
+	}
+
+	if len(in.ProductIDs) > 0 {
+		if err := insertPromotionProducts(ctx, tx, id, in.ProductIDs); err != nil {
+			if isForeignKeyViolation(err) {
+				return nil, promouc.ErrProductMissing
+			}
+			return nil, err
+		}
+	}
+	if len(in.ProductCategories) > 0 {
+		if err := insertPromotionProductCategories(ctx, tx, id, in.ProductCategories); err != nil {
+			return nil, err
+		}
+	}
+	if len(in.CustomerCategoryIDs) > 0 {
+		if err := insertPromotionCustomerCategories(ctx, tx, id, in.CustomerCategoryIDs); err != nil {
+			if isForeignKeyViolation(err) {
+				return nil, promouc.ErrCategoryMissing
+			}
+			return nil, err
+		}
+	}
+
+	row, err := getPromotionByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	out := mapPromotionRow(row)
+	return &out, nil
+}
+
+func (a *PromotionStoreAdapter) GetByID(ctx context.Context, id string) (*promouc.Promotion, error) {
+	row, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, promouc.ErrNotFound
+		}
+		return nil, err
+	}
+	out := mapPromotionRow(row)
+	return &out, nil
+}
+
+func (a *PromotionStoreAdapter) List(ctx context.Context, q promouc.ListQuery) ([]promouc.Promotion, error) {
+	rows, err := a.repo.List(ctx, q.ActiveAt, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return mapPromotionRows(rows), nil
+}
+
+func (a *PromotionStoreAdapter) Update(ctx context.Context, id string, in promouc.UpdateInput) (*promouc.Promotion, error) {
+	row, err := a.repo.Update(ctx, id, in.Name, in.StartsAt, in.EndsAt, in.Stackable, in.Priority, in.IsActive)
+	if err != nil {
+		switch {
+		case isNoRows(err):
+			return nil, promouc.ErrNotFound
+		case isCheckViolation(err):
+			return nil, promouc.ErrInvalidInput
+		}
+		return nil, err
+	}
+	out := mapPromotionRow(row)
+	return &out, nil
+}
+
+func (a *PromotionStoreAdapter) ListApplicable(ctx context.Context, customerID string, at time.Time) ([]promouc.Promotion, error) {
+	categoryID, err := a.repo.GetCustomerCategoryID(ctx, customerID)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, promouc.ErrCustomerMissing
+		}
+		return nil, err
+	}
+
+	rows, err := a.repo.ListApplicable(ctx, categoryID, at)
+	if err != nil {
+		return nil, err
+	}
+	return mapPromotionRows(rows), nil
+}
+
+func mapPromotionRow(r *PromotionRow) promouc.Promotion {
+	return promouc.Promotion{
+		ID:                  r.ID,
+		Code:                r.Code,
+		Name:                r.Name,
+		Kind:                r.Kind,
+		Percent:             r.Percent,
+		Amount:              r.Amount,
+		Currency:            r.Currency,
+		BuyQty:              r.BuyQty,
+		GetQty:              r.GetQty,
+		Scope:               r.Scope,
+		ProductIDs:          r.ProductIDs,
+		ProductCategories:   r.ProductCategories,
+		CustomerCategoryIDs: r.CustomerCategoryIDs,
+		StartsAt:            r.StartsAt,
+		EndsAt:              r.EndsAt,
+		Stackable:           r.Stackable,
+		Priority:            r.Priority,
+		IsActive:            r.IsActive,
+		CreatedAt:           r.CreatedAt,
+		UpdatedAt:           r.UpdatedAt,
+	}
+}
+
+func mapPromotionRows(rows []PromotionRow) []promouc.Promotion {
+	out := make([]promouc.Promotion, 0, len(rows))
+	for i := range rows {
+		out = append(out, mapPromotionRow(&rows[i]))
+	}
+	return out
+}
+
+// Compile-time check
+var _ promouc.Store = (*PromotionStoreAdapter)(nil)
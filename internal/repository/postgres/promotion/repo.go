@@ -0,0 +1,263 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PromotionRow struct {
+	ID                  string
+	Code                string
+	Name                string
+	Kind                string
+	Percent             *string
+	Amount              *string
+	Currency            *string
+	BuyQty              *int
+	GetQty              *int
+	Scope               string
+	StartsAt            time.Time
+	EndsAt              *time.Time
+	Stackable           bool
+	Priority            int
+	IsActive            bool
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	ProductIDs          []string
+	ProductCategories   []string
+	CustomerCategoryIDs []string
+}
+
+type PromotionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPromotionRepo(db *pgxpool.Pool) *PromotionRepo {
+	return &PromotionRepo{db: db}
+}
+
+func (r *PromotionRepo) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.db.BeginTx(ctx, pgx.TxOptions{})
+}
+
+type queryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+const promotionColumns = `
+  p.id::text,
+  p.code,
+  p.name,
+  p.kind,
+  p.percent::text,
+  p.amount::text,
+  p.currency,
+  p.buy_qty,
+  p.get_qty,
+  p.scope,
+  p.starts_at,
+  p.ends_at,
+  p.stackable,
+  p.priority,
+  p.is_active,
+  p.created_at,
+  p.updated_at,
+  COALESCE((SELECT array_agg(pp.product_id::text ORDER BY pp.product_id)
+            FROM promotion_products pp WHERE pp.promotion_id = p.id), '{}'),
+  COALESCE((SELECT array_agg(pc.category ORDER BY pc.category)
+            FROM promotion_product_categories pc WHERE pc.promotion_id = p.id), '{}'),
+  COALESCE((SELECT array_agg(cc.category_id::text ORDER BY cc.category_id)
+            FROM promotion_customer_categories cc WHERE cc.promotion_id = p.id), '{}')
+`
+
+func scanPromotionRow(row pgx.Row) (*PromotionRow, error) {
+	var out PromotionRow
+	if err := row.Scan(
+		&out.ID,
+		&out.Code,
+		&out.Name,
+		&out.Kind,
+		&out.Percent,
+		&out.Amount,
+		&out.Currency,
+		&out.BuyQty,
+		&out.GetQty,
+		&out.Scope,
+		&out.StartsAt,
+		&out.EndsAt,
+		&out.Stackable,
+		&out.Priority,
+		&out.IsActive,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+		&out.ProductIDs,
+		&out.ProductCategories,
+		&out.CustomerCategoryIDs,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func scanPromotionRows(rows pgx.Rows) ([]PromotionRow, error) {
+	defer rows.Close()
+
+	out := make([]PromotionRow, 0, 10)
+	for rows.Next() {
+		p, err := scanPromotionRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+func insertPromotion(ctx context.Context, tx pgx.Tx, in PromotionRow) (string, error) {
+	const q = `
+INSERT INTO promotions (code, name, kind, percent, amount, buy_qty, get_qty, scope, starts_at, ends_at, stackable, priority, currency)
+VALUES ($1, $2, $3, $4::numeric, $5::numeric, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING id::text;
+`
+	var id string
+	err := tx.QueryRow(ctx, q,
+		in.Code, in.Name, in.Kind, in.Percent, in.Amount, in.BuyQty, in.GetQty,
+		in.Scope, in.StartsAt, in.EndsAt, in.Stackable, in.Priority, in.Currency,
+	).Scan(&id)
+	return id, err
+}
+
+func insertPromotionProducts(ctx context.Context, tx pgx.Tx, promotionID string, productIDs []string) error {
+	const q = `
+INSERT INTO promotion_products (promotion_id, product_id)
+SELECT $1::uuid, unnest($2::uuid[])
+ON CONFLICT DO NOTHING;
+`
+	_, err := tx.Exec(ctx, q, promotionID, productIDs)
+	return err
+}
+
+func insertPromotionProductCategories(ctx context.Context, tx pgx.Tx, promotionID string, categories []string) error {
+	const q = `
+INSERT INTO promotion_product_categories (promotion_id, category)
+SELECT $1::uuid, unnest($2::text[])
+ON CONFLICT DO NOTHING;
+`
+	_, err := tx.Exec(ctx, q, promotionID, categories)
+	return err
+}
+
+func insertPromotionCustomerCategories(ctx context.Context, tx pgx.Tx, promotionID string, categoryIDs []string) error {
+	const q = `
+INSERT INTO promotion_customer_categories (promotion_id, category_id)
+SELECT $1::uuid, unnest($2::uuid[])
+ON CONFLICT DO NOTHING;
+`
+	_, err := tx.Exec(ctx, q, promotionID, categoryIDs)
+	return err
+}
+
+func getPromotionByID(ctx context.Context, q queryer, id string) (*PromotionRow, error) {
+	sql := `SELECT ` + promotionColumns + ` FROM promotions p WHERE p.id = $1::uuid;`
+	return scanPromotionRow(q.QueryRow(ctx, sql, id))
+}
+
+func (r *PromotionRepo) GetByID(ctx context.Context, id string) (*PromotionRow, error) {
+	return getPromotionByID(ctx, r.db, id)
+}
+
+// List returns promotions newest first; activeAt limits to those running at that moment.
+func (r *PromotionRepo) List(ctx context.Context, activeAt *time.Time, limit, offset int) ([]PromotionRow, error) {
+	q := `
+SELECT ` + promotionColumns + `
+FROM promotions p
+WHERE ($1::timestamptz IS NULL
+       OR (p.is_active AND p.starts_at <= $1 AND (p.ends_at IS NULL OR p.ends_at > $1)))
+ORDER BY p.created_at DESC
+LIMIT $2 OFFSET $3;
+`
+	rows, err := r.db.Query(ctx, q, activeAt, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanPromotionRows(rows)
+}
+
+func (r *PromotionRepo) Update(ctx context.Context, id string, name *string, startsAt, endsAt *time.Time, stackable *bool, priority *int, isActive *bool) (*PromotionRow, error) {
+	const q = `
+UPDATE promotions
+SET name = COALESCE($2, name),
+    starts_at = COALESCE($3, starts_at),
+    ends_at = COALESCE($4, ends_at),
+    stackable = COALESCE($5, stackable),
+    priority = COALESCE($6, priority),
+    is_active = COALESCE($7, is_active),
+    updated_at = now()
+WHERE id = $1::uuid
+RETURNING id::text;
+`
+	var out string
+	if err := r.db.QueryRow(ctx, q, id, name, startsAt, endsAt, stackable, priority, isActive).Scan(&out); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, out)
+}
+
+// GetCustomerCategoryID returns the customer's category (nil when uncategorised).
+func (r *PromotionRepo) GetCustomerCategoryID(ctx context.Context, customerID string) (*string, error) {
+	const q = `SELECT category_id::text FROM customers WHERE id = $1::uuid;`
+	var out *string
+	if err := r.db.QueryRow(ctx, q, customerID).Scan(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListApplicable returns promotions running at `at` for a customer category, in
+// evaluation order. Promotions without customer categories apply to everyone.
+func (r *PromotionRepo) ListApplicable(ctx context.Context, customerCategoryID *string, at time.Time) ([]PromotionRow, error) {
+	q := `
+SELECT ` + promotionColumns + `
+FROM promotions p
+WHERE p.is_active
+  AND p.starts_at <= $1
+  AND (p.ends_at IS NULL OR p.ends_at > $1)
+  AND (
+    NOT EXISTS (SELECT 1 FROM promotion_customer_categories cc WHERE cc.promotion_id = p.id)
+    OR EXISTS (
+      SELECT 1 FROM promotion_customer_categories cc
+      WHERE cc.promotion_id = p.id AND cc.category_id = $2::uuid
+    )
+  )
+ORDER BY p.priority DESC, p.starts_at ASC, p.code ASC;
+`
+	rows, err := r.db.Query(ctx, q, at, customerCategoryID)
+	if err != nil {
+		return nil, err
+	}
+	return scanPromotionRows(rows)
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+func isCheckViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23514"
+}
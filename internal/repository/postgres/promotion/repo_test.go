@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+	promouc "github.com/riolentius/cahaya-gading-backend/internal/usecase/promotion"
+	trxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
+)
+
+func TestPromotion_AppliedOnTransactionCreate(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	catID := testutil.MustInsertCategory(t, db, "MEMBER", "Member")
+	memberID := testutil.MustInsertCustomer(t, db, "Toko", "Member", "member@test.local", &catID)
+	walkInID := testutil.MustInsertCustomer(t, db, "Walk", "In", "walkin@test.local", nil)
+
+	drinkID := testutil.MustInsertProduct(t, db, "SKU-PROMO-1", "Teh Botol", nil, 100, 0)
+	riceID := testutil.MustInsertProduct(t, db, "SKU-PROMO-2", "Beras 5kg", nil, 100, 0)
+	testutil.MustInsertPrice(t, db, drinkID, nil, "IDR", "5000.00")
+	testutil.MustInsertPrice(t, db, riceID, nil, "IDR", "70000.00")
+	if _, err := db.Exec(ctx, `UPDATE products SET category = 'minuman' WHERE id = $1::uuid`, drinkID); err != nil {
+		t.Fatalf("set category: %v", err)
+	}
+
+	promoUC := promouc.New(NewPromotionStoreAdapter(NewPromotionRepo(db)))
+	starts := time.Now().Add(-time.Hour)
+	pct := "10"
+	buy, get := 2, 1
+
+	// members: buy 2 get 1 on drinks, exclusive and evaluated first
+	if _, err := promoUC.Create(ctx, promouc.CreateInput{
+		Code: "drink-b2g1", Name: "Drinks B2G1", Kind: promouc.KindBuyXGetY, BuyQty: &buy, GetQty: &get,
+		Scope: promouc.ScopeProductCategories, ProductCategories: []string{"Minuman"},
+		CustomerCategoryIDs: []string{catID}, StartsAt: &starts, Priority: 10,
+	}); err != nil {
+		t.Fatalf("create b2g1: %v", err)
+	}
+	// everyone: 10% off, stackable
+	if _, err := promoUC.Create(ctx, promouc.CreateInput{
+		Code: "all10", Name: "All 10%", Kind: promouc.KindPercentOff, Percent: &pct,
+		StartsAt: &starts, Stackable: true,
+	}); err != nil {
+		t.Fatalf("create percent: %v", err)
+	}
+	if _, err := promoUC.Create(ctx, promouc.CreateInput{
+		Code: "ALL10", Name: "dup", Kind: promouc.KindPercentOff, Percent: &pct,
+	}); err != promouc.ErrCodeConflict {
+		t.Fatalf("expected ErrCodeConflict got=%v", err)
+	}
+
+	trxUC := trxuc.New(trxpg.NewTransactionStoreAdapter(trxpg.NewTransactionRepo(db), db)).WithPromotions(promoUC)
+	items := []trxuc.CreateItemIn{{ProductID: drinkID, Qty: 6}, {ProductID: riceID, Qty: 1}}
+
+	// member: drinks 30000 - 10000 (2 free, exclusive); rice 70000 - 7000
+	trx, err := trxUC.Create(ctx, trxuc.CreateInput{CustomerID: memberID, Items: items})
+	if err != nil {
+		t.Fatalf("create member trx: %v", err)
+	}
+	if trx.SubtotalAmount != "100000.00" || trx.DiscountAmount != "17000.00" || trx.TotalAmount != "83000.00" {
+		t.Fatalf("unexpected member totals: subtotal=%s discount=%s total=%s", trx.SubtotalAmount, trx.DiscountAmount, trx.TotalAmount)
+	}
+	if len(trx.Discounts) != 2 || trx.Discounts[0].PromotionCode != "DRINK-B2G1" || trx.Discounts[1].PromotionCode != "ALL10" {
+		t.Fatalf("unexpected member discounts: %+v", trx.Discounts)
+	}
+	if trx.Discounts[0].TransactionItemID == nil || *trx.Discounts[0].TransactionItemID != trx.Items[0].ID {
+		t.Fatalf("drink discount not linked to its line: %+v", trx.Discounts[0])
+	}
+
+	// walk-in: only the 10% promotion
+	trx, err = trxUC.Create(ctx, trxuc.CreateInput{CustomerID: walkInID, Items: items})
+	if err != nil {
+		t.Fatalf("create walk-in trx: %v", err)
+	}
+	if trx.DiscountAmount != "10000.00" || trx.TotalAmount != "90000.00" || len(trx.Discounts) != 2 {
+		t.Fatalf("unexpected walk-in result: discount=%s total=%s discounts=%+v", trx.DiscountAmount, trx.TotalAmount, trx.Discounts)
+	}
+
+	view, err := trxUC.GetViewByID(ctx, trx.ID)
+	if err != nil {
+		t.Fatalf("view: %v", err)
+	}
+	if view.Subtotal != "100000.00" || len(view.Discounts) != 2 {
+		t.Fatalf("unexpected view: subtotal=%s discounts=%+v", view.Subtotal, view.Discounts)
+	}
+
+	// ended promotions no longer apply
+	active, err := promoUC.List(ctx, promouc.ListQuery{})
+	if err != nil || len(active) != 2 {
+		t.Fatalf("list: n=%d err=%v", len(active), err)
+	}
+	for _, p := range active {
+		ended := time.Now().Add(-time.Minute)
+		if _, err := promoUC.Update(ctx, p.ID, promouc.UpdateInput{EndsAt: &ended}); err != nil {
+			t.Fatalf("end promotion: %v", err)
+		}
+	}
+	trx, err = trxUC.Create(ctx, trxuc.CreateInput{CustomerID: memberID, Items: items})
+	if err != nil {
+		t.Fatalf("create after end: %v", err)
+	}
+	if trx.DiscountAmount != "0.00" || trx.TotalAmount != "100000.00" || len(trx.Discounts) != 0 {
+		t.Fatalf("expected no discounts after promotions ended: %+v", trx)
+	}
+}
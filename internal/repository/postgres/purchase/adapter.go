@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	invpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/inventory"
+	purchaseuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/purchase"
+)
+
+type PurchaseStoreAdapter struct {
+	repo *PurchaseRepo
+}
+
+func NewPurchaseStoreAdapter(repo *PurchaseRepo) *PurchaseStoreAdapter {
+	return &PurchaseStoreAdapter{repo: repo}
+}
+
+func (a *PurchaseStoreAdapter) Create(ctx context.Context, in purchaseuc.CreateInput) (*purchaseuc.PurchaseOrder, error) {
+	lines := make([]LineInputRow, 0, len(in.Lines))
+	for _, l := range in.Lines {
+		lines = append(lines, LineInputRow{ProductID: l.ProductID, Qty: l.Qty, UnitCost: l.UnitCost})
+	}
+
+	order, ls, err := a.repo.Create(ctx, in.SupplierID, in.LocationID, in.Reference, in.ExpectedAt, in.Note, lines)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapOrder(order, ls), nil
+}
+
+func (a *PurchaseStoreAdapter) GetByID(ctx context.Context, id string) (*purchaseuc.PurchaseOrder, error) {
+	order, lines, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapOrder(order, lines), nil
+}
+
+func (a *PurchaseStoreAdapter) List(ctx context.Context, q purchaseuc.ListQuery) ([]purchaseuc.PurchaseOrder, error) {
+	rows, err := a.repo.List(ctx, q.SupplierID, q.Status, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]purchaseuc.PurchaseOrder, 0, len(rows))
+	for i := range rows {
+		out = append(out, *mapOrder(&rows[i], nil))
+	}
+	return out, nil
+}
+
+func (a *PurchaseStoreAdapter) SetStatus(ctx context.Context, id string, from []string, to string) (*purchaseuc.PurchaseOrder, error) {
+	order, lines, err := a.repo.SetStatus(ctx, id, from, to)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapOrder(order, lines), nil
+}
+
+func (a *PurchaseStoreAdapter) Receive(ctx context.Context, id string, in purchaseuc.ReceiveInput) (*purchaseuc.ReceiveResult, error) {
+	lines := make([]ReceiveLineRow, 0, len(in.Lines))
+	for _, l := range in.Lines {
+		lines = append(lines, ReceiveLineRow{LineID: l.LineID, Qty: l.Qty, LotNumber: l.LotNumber, ExpiresAt: l.ExpiresAt})
+	}
+
+	order, ls, receipts, err := a.repo.Receive(ctx, id, lines, in.Reference, in.Note, *in.ReceivedAt)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	out := &purchaseuc.ReceiveResult{
+		Order:    mapOrder(order, ls),
+		Receipts: make([]purchaseuc.Receipt, 0, len(receipts)),
+	}
+	for _, r := range receipts {
+		out.Receipts = append(out.Receipts, purchaseuc.Receipt{
+			ID:             r.ID,
+			LineID:         r.LineID,
+			ProductID:      r.ProductID,
+			Qty:            r.Qty,
+			StockProductID: r.StockProductID,
+			BaseQty:        r.BaseQty,
+			AvgCostAfter:   r.AvgCostAfter,
+		})
+	}
+	return out, nil
+}
+
+func mapErr(err error) error {
+	switch {
+	case isNoRows(err):
+		return purchaseuc.ErrNotFound
+	case errors.Is(err, errSupplierMissing):
+		return purchaseuc.ErrSupplierMissing
+	case errors.Is(err, errProductMissing):
+		return purchaseuc.ErrProductMissing
+	case errors.Is(err, errLineMissing):
+		return purchaseuc.ErrLineMissing
+	case errors.Is(err, errInvalidTransition):
+		return purchaseuc.ErrInvalidTransition
+	case errors.Is(err, errOverReceipt):
+		return purchaseuc.ErrOverReceipt
+	case errors.Is(err, invpg.ErrFractionalStock):
+		return purchaseuc.ErrFractionalStock
+	case errors.Is(err, invpg.ErrLocationMissing):
+		return purchaseuc.ErrLocationMissing
+	}
+	return err
+}
+
+func mapOrder(r *PurchaseOrderRow, lines []LineRow) *purchaseuc.PurchaseOrder {
+	out := &purchaseuc.PurchaseOrder{
+		ID:           r.ID,
+		SupplierID:   r.SupplierID,
+		SupplierName: r.SupplierName,
+		LocationID:   r.LocationID,
+		Status:       r.Status,
+		Reference:    r.Reference,
+		ExpectedAt:   r.ExpectedAt,
+		Note:         r.Note,
+		TotalAmount:  r.TotalAmount,
+		OrderedAt:    r.OrderedAt,
+		ReceivedAt:   r.ReceivedAt,
+		CancelledAt:  r.CancelledAt,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+	if lines != nil {
+		out.Lines = make([]purchaseuc.Line, 0, len(lines))
+		for _, l := range lines {
+			out.Lines = append(out.Lines, purchaseuc.Line{
+				ID:           l.ID,
+				ProductID:    l.ProductID,
+				ProductName:  l.ProductName,
+				Qty:          l.Qty,
+				UnitCost:     l.UnitCost,
+				LineTotal:    l.LineTotal,
+				ReceivedQty:  l.ReceivedQty,
+				RemainingQty: l.Qty - l.ReceivedQty,
+			})
+		}
+	}
+	return out
+}
+
+// Compile-time check
+var _ purchaseuc.Store = (*PurchaseStoreAdapter)(nil)
@@ -0,0 +1,433 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	invpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/inventory"
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+)
+
+type PurchaseOrderRow struct {
+	ID           string
+	SupplierID   string
+	SupplierName string
+	LocationID   *string
+	Status       string
+	Reference    *string
+	ExpectedAt   *time.Time
+	Note         *string
+	TotalAmount  string
+	OrderedAt    *time.Time
+	ReceivedAt   *time.Time
+	CancelledAt  *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type LineRow struct {
+	ID          string
+	ProductID   string
+	ProductName string
+	Qty         int
+	UnitCost    string
+	LineTotal   string
+	ReceivedQty int
+}
+
+type LineInputRow struct {
+	ProductID string
+	Qty       int
+	UnitCost  string
+}
+
+type ReceiveLineRow struct {
+	LineID    string
+	Qty       int
+	LotNumber *string
+	ExpiresAt *time.Time
+}
+
+type ReceiptRow struct {
+	ID             string
+	LineID         string
+	ProductID      string
+	Qty            int
+	StockProductID string
+	BaseQty        int
+	AvgCostAfter   string
+}
+
+var (
+	errSupplierMissing   = errors.New("supplier missing")
+	errProductMissing    = errors.New("product missing")
+	errLineMissing       = errors.New("line missing")
+	errInvalidTransition = errors.New("invalid transition")
+	errOverReceipt       = errors.New("over receipt")
+)
+
+type PurchaseRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPurchaseRepo(db *pgxpool.Pool) *PurchaseRepo {
+	return &PurchaseRepo{db: db}
+}
+
+const orderColumns = `
+  po.id::text,
+  po.supplier_id::text,
+  s.name,
+  po.location_id::text,
+  po.status,
+  po.reference,
+  po.expected_at::timestamptz,
+  po.note,
+  po.total_amount::text,
+  po.ordered_at,
+  po.received_at,
+  po.cancelled_at,
+  po.created_at,
+  po.updated_at
+`
+
+func scanOrderRow(row pgx.Row) (*PurchaseOrderRow, error) {
+	var out PurchaseOrderRow
+	if err := row.Scan(
+		&out.ID, &out.SupplierID, &out.SupplierName, &out.LocationID, &out.Status, &out.Reference,
+		&out.ExpectedAt, &out.Note, &out.TotalAmount, &out.OrderedAt, &out.ReceivedAt,
+		&out.CancelledAt, &out.CreatedAt, &out.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type queryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func getOrder(ctx context.Context, q queryer, id string) (*PurchaseOrderRow, error) {
+	sql := `
+SELECT ` + orderColumns + `
+FROM purchase_orders po
+JOIN suppliers s ON s.id = po.supplier_id
+WHERE po.id = $1::uuid;
+`
+	return scanOrderRow(q.QueryRow(ctx, sql, id))
+}
+
+func listLines(ctx context.Context, q queryer, orderID string) ([]LineRow, error) {
+	const sql = `
+SELECT l.id::text, l.product_id::text, p.name, l.qty, l.unit_cost::text, l.line_total::text, l.received_qty
+FROM purchase_order_lines l
+JOIN products p ON p.id = l.product_id
+WHERE l.purchase_order_id = $1::uuid
+ORDER BY l.created_at, l.id;
+`
+	rows, err := q.Query(ctx, sql, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]LineRow, 0, 8)
+	for rows.Next() {
+		var l LineRow
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.ProductName, &l.Qty, &l.UnitCost, &l.LineTotal, &l.ReceivedQty); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+func (r *PurchaseRepo) Create(ctx context.Context, supplierID string, locationID *string, reference *string, expectedAt *time.Time, note *string, lines []LineInputRow) (*PurchaseOrderRow, []LineRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var active bool
+	if err := tx.QueryRow(ctx, `SELECT is_active FROM suppliers WHERE id = $1::uuid;`, supplierID).Scan(&active); err != nil {
+		if isNoRows(err) {
+			return nil, nil, errSupplierMissing
+		}
+		return nil, nil, err
+	}
+	if !active {
+		return nil, nil, errSupplierMissing
+	}
+	if locationID != nil {
+		if _, err := trxpg.ResolveLocation(ctx, tx, locationID); err != nil {
+			if isNoRows(err) {
+				return nil, nil, invpg.ErrLocationMissing
+			}
+			return nil, nil, err
+		}
+	}
+
+	var id string
+	if err := tx.QueryRow(ctx, `
+INSERT INTO purchase_orders (supplier_id, reference, expected_at, note, location_id)
+VALUES ($1::uuid, $2, $3::date, $4, $5::uuid)
+RETURNING id::text;
+`, supplierID, reference, expectedAt, note, locationID).Scan(&id); err != nil {
+		return nil, nil, err
+	}
+
+	for _, l := range lines {
+		ct, err := tx.Exec(ctx, `
+INSERT INTO purchase_order_lines (purchase_order_id, product_id, qty, unit_cost, line_total)
+SELECT $1::uuid, p.id, $3, $4::numeric, round($3 * $4::numeric, 2)
+FROM products p
+WHERE p.id = $2::uuid;
+`, id, l.ProductID, l.Qty, l.UnitCost)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ct.RowsAffected() == 0 {
+			return nil, nil, errProductMissing
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE purchase_orders
+SET total_amount = (SELECT COALESCE(SUM(line_total), 0) FROM purchase_order_lines WHERE purchase_order_id = $1::uuid),
+    updated_at = now()
+WHERE id = $1::uuid;
+`, id); err != nil {
+		return nil, nil, err
+	}
+
+	order, err := getOrder(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	ls, err := listLines(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return order, ls, nil
+}
+
+func (r *PurchaseRepo) GetByID(ctx context.Context, id string) (*PurchaseOrderRow, []LineRow, error) {
+	order, err := getOrder(ctx, r.db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := listLines(ctx, r.db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return order, lines, nil
+}
+
+func (r *PurchaseRepo) List(ctx context.Context, supplierID, status *string, limit, offset int) ([]PurchaseOrderRow, error) {
+	q := `
+SELECT ` + orderColumns + `
+FROM purchase_orders po
+JOIN suppliers s ON s.id = po.supplier_id
+WHERE ($1::uuid IS NULL OR po.supplier_id = $1::uuid)
+  AND ($2::text IS NULL OR po.status = $2)
+ORDER BY po.created_at DESC
+LIMIT $3 OFFSET $4;
+`
+	rows, err := r.db.Query(ctx, q, supplierID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]PurchaseOrderRow, 0, 16)
+	for rows.Next() {
+		o, err := scanOrderRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *o)
+	}
+	return out, rows.Err()
+}
+
+func lockOrderStatus(ctx context.Context, tx pgx.Tx, id string) (string, error) {
+	var status string
+	if err := tx.QueryRow(ctx, `SELECT status FROM purchase_orders WHERE id = $1::uuid FOR UPDATE;`, id).Scan(&status); err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+func (r *PurchaseRepo) SetStatus(ctx context.Context, id string, from []string, to string) (*PurchaseOrderRow, []LineRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, err := lockOrderStatus(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !contains(from, status) {
+		return nil, nil, errInvalidTransition
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE purchase_orders
+SET status = $2,
+    ordered_at = CASE WHEN $2 = 'ordered' THEN now() ELSE ordered_at END,
+    cancelled_at = CASE WHEN $2 = 'cancelled' THEN now() ELSE cancelled_at END,
+    updated_at = now()
+WHERE id = $1::uuid;
+`, id, to); err != nil {
+		return nil, nil, err
+	}
+
+	order, err := getOrder(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := listLines(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return order, lines, nil
+}
+
+// Receive books each line through invpg.ReceiveStock (stock_on_hand on the stock
+// product at the order's location plus weighted-average cost) and advances the
+// order status.
+func (r *PurchaseRepo) Receive(ctx context.Context, id string, lines []ReceiveLineRow, reference, note *string, receivedAt time.Time) (*PurchaseOrderRow, []LineRow, []ReceiptRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, err := lockOrderStatus(ctx, tx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if status != "ordered" && status != "partially_received" {
+		return nil, nil, nil, errInvalidTransition
+	}
+	var locationID *string
+	if err := tx.QueryRow(ctx, `SELECT location_id::text FROM purchase_orders WHERE id = $1::uuid;`, id).Scan(&locationID); err != nil {
+		return nil, nil, nil, err
+	}
+
+	receipts := make([]ReceiptRow, 0, len(lines))
+	for _, in := range lines {
+		var productID, unitCost string
+		var qty, received int
+		if err := tx.QueryRow(ctx, `
+SELECT product_id::text, unit_cost::text, qty, received_qty
+FROM purchase_order_lines
+WHERE id = $1::uuid
+  AND purchase_order_id = $2::uuid
+FOR UPDATE;
+`, in.LineID, id).Scan(&productID, &unitCost, &qty, &received); err != nil {
+			if isNoRows(err) {
+				return nil, nil, nil, errLineMissing
+			}
+			return nil, nil, nil, err
+		}
+		if received+in.Qty > qty {
+			return nil, nil, nil, errOverReceipt
+		}
+
+		lineID := in.LineID
+		g, err := invpg.ReceiveStock(ctx, tx, invpg.ReceiveParams{
+			ProductID:           productID,
+			LocationID:          locationID,
+			Qty:                 in.Qty,
+			UnitCost:            unitCost,
+			Reference:           reference,
+			Note:                note,
+			ReceivedAt:          receivedAt,
+			LotNumber:           in.LotNumber,
+			ExpiresAt:           in.ExpiresAt,
+			PurchaseOrderLineID: &lineID,
+		})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if _, err := tx.Exec(ctx, `
+UPDATE purchase_order_lines
+SET received_qty = received_qty + $2,
+    updated_at = now()
+WHERE id = $1::uuid;
+`, in.LineID, in.Qty); err != nil {
+			return nil, nil, nil, err
+		}
+
+		receipts = append(receipts, ReceiptRow{
+			ID:             g.ID,
+			LineID:         in.LineID,
+			ProductID:      productID,
+			Qty:            in.Qty,
+			StockProductID: g.StockProductID,
+			BaseQty:        g.BaseQty,
+			AvgCostAfter:   g.AvgCostAfter,
+		})
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE purchase_orders po
+SET status = CASE WHEN open.n = 0 THEN 'received' ELSE 'partially_received' END,
+    received_at = CASE WHEN open.n = 0 THEN now() ELSE NULL END,
+    updated_at = now()
+FROM (
+  SELECT COUNT(*) AS n
+  FROM purchase_order_lines
+  WHERE purchase_order_id = $1::uuid
+    AND received_qty < qty
+) open
+WHERE po.id = $1::uuid;
+`, id); err != nil {
+		return nil, nil, nil, err
+	}
+
+	order, err := getOrder(ctx, tx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ls, err := listLines(ctx, tx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+	return order, ls, receipts, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	supplierpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/supplier"
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	purchaseuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/purchase"
+	supplieruc "github.com/riolentius/cahaya-gading-backend/internal/usecase/supplier"
+)
+
+func TestPurchase_PartialReceivingIntoStockProduct(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	sup, err := supplieruc.New(supplierpg.NewSupplierStoreAdapter(supplierpg.NewSupplierRepo(db))).
+		Create(ctx, supplieruc.CreateInput{Code: "sup-1", Name: "PT Sumber Makmur"})
+	if err != nil {
+		t.Fatalf("create supplier: %v", err)
+	}
+
+	unitID := testutil.MustInsertProduct(t, db, "SKU-PO-1", "Mie Instan", nil, 0, 0)
+	boxID := testutil.MustInsertProduct(t, db, "SKU-PO-1-BOX", "Mie Instan (dus 40)", nil, 0, 0)
+	if _, err := db.Exec(ctx, `UPDATE products SET base_product_id = $2::uuid, pack_size = 40 WHERE id = $1::uuid`, boxID, unitID); err != nil {
+		t.Fatalf("set pack: %v", err)
+	}
+
+	puc := purchaseuc.New(NewPurchaseStoreAdapter(NewPurchaseRepo(db)))
+
+	po, err := puc.Create(ctx, purchaseuc.CreateInput{
+		SupplierID: sup.ID,
+		Lines: []purchaseuc.LineInput{
+			{ProductID: boxID, Qty: 2, UnitCost: "100000"},
+			{ProductID: unitID, Qty: 20, UnitCost: "2600"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create po: %v", err)
+	}
+	if po.Status != purchaseuc.StatusDraft || po.TotalAmount != "252000.00" || len(po.Lines) != 2 {
+		t.Fatalf("unexpected po: %+v", po)
+	}
+	boxLine, unitLine := po.Lines[0].ID, po.Lines[1].ID
+
+	// drafts cannot be received
+	if _, err := puc.Receive(ctx, po.ID, purchaseuc.ReceiveInput{Lines: []purchaseuc.ReceiveLineInput{{LineID: boxLine, Qty: 1}}}); err != purchaseuc.ErrInvalidTransition {
+		t.Fatalf("expected ErrInvalidTransition got=%v", err)
+	}
+	if _, err := puc.Order(ctx, po.ID); err != nil {
+		t.Fatalf("order: %v", err)
+	}
+
+	// one box = 40 units at 2500 each
+	res, err := puc.Receive(ctx, po.ID, purchaseuc.ReceiveInput{Lines: []purchaseuc.ReceiveLineInput{{LineID: boxLine, Qty: 1}}})
+	if err != nil {
+		t.Fatalf("receive 1: %v", err)
+	}
+	if res.Order.Status != purchaseuc.StatusPartiallyReceived || len(res.Receipts) != 1 ||
+		res.Receipts[0].StockProductID != unitID || res.Receipts[0].BaseQty != 40 || res.Receipts[0].AvgCostAfter != "2500.0000" {
+		t.Fatalf("unexpected first receipt: %+v", res)
+	}
+
+	if _, err := puc.Receive(ctx, po.ID, purchaseuc.ReceiveInput{Lines: []purchaseuc.ReceiveLineInput{{LineID: boxLine, Qty: 2}}}); err != purchaseuc.ErrOverReceipt {
+		t.Fatalf("expected ErrOverReceipt got=%v", err)
+	}
+	if _, err := puc.Cancel(ctx, po.ID); err != purchaseuc.ErrInvalidTransition {
+		t.Fatalf("expected ErrInvalidTransition on cancel got=%v", err)
+	}
+
+	res, err = puc.Receive(ctx, po.ID, purchaseuc.ReceiveInput{Lines: []purchaseuc.ReceiveLineInput{
+		{LineID: boxLine, Qty: 1},
+		{LineID: unitLine, Qty: 20},
+	}})
+	if err != nil {
+		t.Fatalf("receive 2: %v", err)
+	}
+	if res.Order.Status != purchaseuc.StatusReceived || res.Order.ReceivedAt == nil {
+		t.Fatalf("expected received order: %+v", res.Order)
+	}
+
+	// 40 + 40 + 20 units; (80 * 2500 + 20 * 2600) / 100 = 2520
+	var onHand int
+	var avg string
+	if err := db.QueryRow(ctx, `SELECT stock_on_hand, avg_cost::text FROM products WHERE id = $1::uuid`, unitID).Scan(&onHand, &avg); err != nil {
+		t.Fatalf("read stock: %v", err)
+	}
+	if onHand != 100 || avg != "2520.0000" {
+		t.Fatalf("unexpected stock on_hand=%d avg=%s", onHand, avg)
+	}
+}
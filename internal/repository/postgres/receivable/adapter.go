@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	recvuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/receivable"
+)
+
+type ReceivableStoreAdapter struct {
+	repo *ReceivableRepo
+}
+
+func NewReceivableStoreAdapter(repo *ReceivableRepo) *ReceivableStoreAdapter {
+	return &ReceivableStoreAdapter{repo: repo}
+}
+
+func (a *ReceivableStoreAdapter) ListAging(ctx context.Context, q recvuc.AgingQuery) ([]recvuc.AgingRow, error) {
+	rows, err := a.repo.ListAging(ctx, q.AsOf, q.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]recvuc.AgingRow, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, recvuc.AgingRow{
+			CustomerID:   r.CustomerID,
+			CustomerName: r.CustomerName,
+			Currency:     r.Currency,
+			AgingBuckets: recvuc.AgingBuckets{
+				Current:    r.Current,
+				Days1To30:  r.Days1To30,
+				Days31To60: r.Days31To60,
+				Days61To90: r.Days61To90,
+				Days90Plus: r.Days90Plus,
+				Total:      r.Total,
+			},
+		})
+	}
+	return out, nil
+}
+
+func (a *ReceivableStoreAdapter) GetExchangeRate(ctx context.Context, from, to string, at time.Time) (string, bool, error) {
+	rate, err := a.repo.GetExchangeRate(ctx, from, to, at)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return rate.Rate, true, nil
+}
+
+func (a *ReceivableStoreAdapter) GetCustomerName(ctx context.Context, customerID string) (string, error) {
+	name, err := a.repo.GetCustomerName(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", recvuc.ErrCustomerMissing
+		}
+		return "", err
+	}
+	return name, nil
+}
+
+func (a *ReceivableStoreAdapter) GetStatementOpeningBalance(ctx context.Context, q recvuc.StatementQuery) (string, error) {
+	return a.repo.GetStatementOpeningBalance(ctx, q.CustomerID, q.Currency, q.From)
+}
+
+func (a *ReceivableStoreAdapter) ListStatementLines(ctx context.Context, q recvuc.StatementQuery) ([]recvuc.StatementLine, error) {
+	rows, err := a.repo.ListStatementLines(ctx, q.CustomerID, q.Currency, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]recvuc.StatementLine, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, recvuc.StatementLine{
+			Kind:          r.Kind,
+			RefID:         r.RefID,
+			TransactionID: r.TransactionID,
+			OccurredAt:    r.OccurredAt,
+			DueDate:       r.DueDate,
+			Method:        r.Method,
+			Reference:     r.Reference,
+			Amount:        r.Amount,
+		})
+	}
+	return out, nil
+}
+
+// Compile-time check
+var _ recvuc.Store = (*ReceivableStoreAdapter)(nil)
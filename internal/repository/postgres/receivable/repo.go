@@ -0,0 +1,235 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	fxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/exchangerate"
+)
+
+type AgingRow struct {
+	CustomerID   string
+	CustomerName string
+	Currency     string
+	Current      string
+	Days1To30    string
+	Days31To60   string
+	Days61To90   string
+	Days90Plus   string
+	Total        string
+}
+
+type StatementLineRow struct {
+	Kind          string
+	RefID         string
+	TransactionID *string
+	OccurredAt    time.Time
+	DueDate       *time.Time
+	Method        *string
+	Reference     *string
+	Amount        string
+}
+
+type ReceivableRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewReceivableRepo(db *pgxpool.Pool) *ReceivableRepo {
+	return &ReceivableRepo{db: db}
+}
+
+// ListAging computes outstanding balances as they were at asOf:
+// total - posted payments up to asOf + overpayment already converted to credit up to asOf.
+func (r *ReceivableRepo) ListAging(ctx context.Context, asOf time.Time, customerID *string) ([]AgingRow, error) {
+	const q = `
+WITH open_items AS (
+  SELECT
+    t.customer_id,
+    t.currency,
+    t.due_date,
+    t.total_amount
+      - COALESCE(p.paid, 0)
+      + COALESCE(cr.converted, 0) AS outstanding
+  FROM transactions t
+  LEFT JOIN LATERAL (
+    SELECT SUM(amount) AS paid
+    FROM payments
+    WHERE transaction_id = t.id
+      AND status = 'posted'
+      AND paid_at <= $1::timestamptz
+  ) p ON true
+  LEFT JOIN LATERAL (
+    SELECT SUM(amount) AS converted
+    FROM customer_credit_entries
+    WHERE transaction_id = t.id
+      AND kind = 'overpayment'
+      AND created_at <= $1
+  ) cr ON true
+  WHERE t.status IN ('pending', 'completed')
+    AND t.created_at <= $1
+    AND ($2::uuid IS NULL OR t.customer_id = $2::uuid)
+)
+SELECT
+  c.id::text,
+  COALESCE(c.first_name,'') || CASE WHEN c.last_name IS NULL OR c.last_name='' THEN '' ELSE ' '||c.last_name END AS customer_name,
+  o.currency,
+  COALESCE(SUM(o.outstanding) FILTER (WHERE o.due_date >= $1::timestamptz::date), 0)::numeric(18,2)::text,
+  COALESCE(SUM(o.outstanding) FILTER (WHERE $1::timestamptz::date - o.due_date BETWEEN 1 AND 30), 0)::numeric(18,2)::text,
+  COALESCE(SUM(o.outstanding) FILTER (WHERE $1::timestamptz::date - o.due_date BETWEEN 31 AND 60), 0)::numeric(18,2)::text,
+  COALESCE(SUM(o.outstanding) FILTER (WHERE $1::timestamptz::date - o.due_date BETWEEN 61 AND 90), 0)::numeric(18,2)::text,
+  COALESCE(SUM(o.outstanding) FILTER (WHERE $1::timestamptz::date - o.due_date > 90), 0)::numeric(18,2)::text,
+  SUM(o.outstanding)::numeric(18,2)::text
+FROM open_items o
+JOIN customers c ON c.id = o.customer_id
+WHERE o.outstanding > 0
+GROUP BY c.id, c.first_name, c.last_name, o.currency
+ORDER BY SUM(o.outstanding) DESC, customer_name ASC;
+`
+	rows, err := r.db.Query(ctx, q, asOf, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]AgingRow, 0, 20)
+	for rows.Next() {
+		var a AgingRow
+		if err := rows.Scan(
+			&a.CustomerID,
+			&a.CustomerName,
+			&a.Currency,
+			&a.Current,
+			&a.Days1To30,
+			&a.Days31To60,
+			&a.Days61To90,
+			&a.Days90Plus,
+			&a.Total,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// GetExchangeRate resolves the from -> to rate effective at `at` (pgx.ErrNoRows if none).
+func (r *ReceivableRepo) GetExchangeRate(ctx context.Context, from, to string, at time.Time) (*fxpg.ResolvedRateRow, error) {
+	return fxpg.ResolveRate(ctx, r.db, from, to, at)
+}
+
+func (r *ReceivableRepo) GetCustomerName(ctx context.Context, customerID string) (string, error) {
+	const q = `
+SELECT COALESCE(first_name,'') || CASE WHEN last_name IS NULL OR last_name='' THEN '' ELSE ' '||last_name END
+FROM customers
+WHERE id = $1::uuid;
+`
+	var name string
+	if err := r.db.QueryRow(ctx, q, customerID).Scan(&name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// statementEntries: $1 customer, $2 currency.
+// Invoices add to the balance, payments subtract, overpayment converted to credit adds back
+// (that part of the payment left receivables and went to the customer's store credit).
+const statementEntries = `
+SELECT
+  'invoice' AS kind,
+  t.id::text AS ref_id,
+  t.id::text AS transaction_id,
+  t.created_at AS occurred_at,
+  t.due_date AS due_date,
+  NULL::text AS method,
+  NULL::text AS reference,
+  t.total_amount AS amount
+FROM transactions t
+WHERE t.customer_id = $1::uuid
+  AND t.currency = $2
+  AND t.status IN ('pending', 'completed')
+
+UNION ALL
+
+SELECT
+  'payment',
+  p.id::text,
+  p.transaction_id::text,
+  p.paid_at,
+  NULL::date,
+  p.method,
+  p.reference,
+  -p.amount
+FROM payments p
+JOIN transactions t ON t.id = p.transaction_id
+WHERE t.customer_id = $1::uuid
+  AND p.currency = $2
+  AND p.status = 'posted'
+  AND t.status IN ('pending', 'completed')
+
+UNION ALL
+
+SELECT
+  'credit_conversion',
+  e.id::text,
+  e.transaction_id::text,
+  e.created_at,
+  NULL::date,
+  NULL::text,
+  NULL::text,
+  e.amount
+FROM customer_credit_entries e
+JOIN transactions t ON t.id = e.transaction_id
+WHERE e.customer_id = $1::uuid
+  AND e.currency = $2
+  AND e.kind = 'overpayment'
+  AND t.status IN ('pending', 'completed')
+`
+
+func (r *ReceivableRepo) GetStatementOpeningBalance(ctx context.Context, customerID, currency string, from time.Time) (string, error) {
+	q := `
+SELECT COALESCE(SUM(amount), 0)::numeric(18,2)::text
+FROM (` + statementEntries + `) e
+WHERE e.occurred_at < $3;
+`
+	var out string
+	if err := r.db.QueryRow(ctx, q, customerID, currency, from).Scan(&out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+func (r *ReceivableRepo) ListStatementLines(ctx context.Context, customerID, currency string, from, to time.Time) ([]StatementLineRow, error) {
+	q := `
+SELECT kind, ref_id, transaction_id, occurred_at, due_date, method, reference, amount::numeric(18,2)::text
+FROM (` + statementEntries + `) e
+WHERE e.occurred_at >= $3
+  AND e.occurred_at < $4
+ORDER BY e.occurred_at ASC, e.kind ASC;
+`
+	rows, err := r.db.Query(ctx, q, customerID, currency, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]StatementLineRow, 0, 20)
+	for rows.Next() {
+		var l StatementLineRow
+		if err := rows.Scan(
+			&l.Kind,
+			&l.RefID,
+			&l.TransactionID,
+			&l.OccurredAt,
+			&l.DueDate,
+			&l.Method,
+			&l.Reference,
+			&l.Amount,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
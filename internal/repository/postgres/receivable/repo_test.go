@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	trxrepo "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+	recvuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/receivable"
+	trxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
+)
+
+func TestReceivable_AgingAndStatement(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	catID := testutil.MustInsertCategory(t, db, "WHOLESALE", "Wholesale")
+	if _, err := db.Exec(ctx, `UPDATE customer_categories SET payment_terms_days = 30 WHERE id = $1::uuid`, catID); err != nil {
+		t.Fatalf("set terms: %v", err)
+	}
+	custID := testutil.MustInsertCustomer(t, db, "Toko", "Maju", "toko.maju@test.local", &catID)
+	prodID := testutil.MustInsertProduct(t, db, "SKU-AR-1", "Beras 5kg", nil, 100, 0)
+	testutil.MustInsertPrice(t, db, prodID, nil, "IDR", "50000.00")
+
+	trxStore := trxrepo.NewTransactionStoreAdapter(trxrepo.NewTransactionRepo(db), db)
+
+	// defaulted from category: due in 30 days -> current
+	current, err := trxStore.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Status:     trxuc.StatusPending,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 1}},
+	})
+	if err != nil {
+		t.Fatalf("create current: %v", err)
+	}
+	if current.PaymentTermsDays != 30 {
+		t.Fatalf("expected payment terms 30 got=%d", current.PaymentTermsDays)
+	}
+
+	// explicit due date 45 days ago -> 31-60 bucket
+	past := time.Now().AddDate(0, 0, -45)
+	if _, err := trxStore.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Status:     trxuc.StatusPending,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 2}},
+		DueDate:    &past,
+	}); err != nil {
+		t.Fatalf("create overdue: %v", err)
+	}
+
+	uc := recvuc.New(NewReceivableStoreAdapter(NewReceivableRepo(db)))
+
+	report, err := uc.Aging(ctx, recvuc.AgingQuery{CustomerID: &custID})
+	if err != nil {
+		t.Fatalf("aging: %v", err)
+	}
+	if len(report.Rows) != 1 {
+		t.Fatalf("expected 1 aging row got=%d", len(report.Rows))
+	}
+	row := report.Rows[0]
+	if row.Current != "50000.00" || row.Days31To60 != "100000.00" || row.Total != "150000.00" {
+		t.Fatalf("unexpected buckets: %+v", row.AgingBuckets)
+	}
+
+	st, err := uc.Statement(ctx, recvuc.StatementQuery{
+		CustomerID: custID,
+		From:       time.Now().Add(-time.Hour),
+		To:         time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("statement: %v", err)
+	}
+	if len(st.Lines) != 2 || st.ClosingBalance != "150000.00" {
+		t.Fatalf("unexpected statement: lines=%d closing=%s", len(st.Lines), st.ClosingBalance)
+	}
+}
@@ -0,0 +1,241 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	recuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/reconciliation"
+)
+
+type ReconciliationStoreAdapter struct {
+	repo *ReconciliationRepo
+}
+
+func NewReconciliationStoreAdapter(repo *ReconciliationRepo) *ReconciliationStoreAdapter {
+	return &ReconciliationStoreAdapter{repo: repo}
+}
+
+// CreateImport stores the import header and its lines in one DB transaction.
+// Lines already imported for the same bank account (same date, direction,
+// amount and reference) are skipped, so re-importing an overlapping statement
+// only adds the new mutations.
+func (a *ReconciliationStoreAdapter) CreateImport(ctx context.Context, in recuc.Import, lines []recuc.ParsedLine) (*recuc.Import, []recuc.Line, error) {
+	tx, err := a.repo.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// concurrent imports of one account would not see each other's lines
+	if err := lockAccount(ctx, tx, in.Bank, in.AccountNo); err != nil {
+		return nil, nil, err
+	}
+
+	fresh := make([]recuc.ParsedLine, 0, len(lines))
+	seen := map[string]int{}
+	for _, l := range lines {
+		key := lineKey(l)
+		seen[key]++
+		existing, err := countImportedLines(ctx, tx, in.Bank, in.AccountNo, toNewLineRow(l))
+		if err != nil {
+			return nil, nil, err
+		}
+		// identical mutations within one statement are kept as long as the
+		// earlier imports hold fewer of them
+		if seen[key] <= existing {
+			continue
+		}
+		fresh = append(fresh, l)
+	}
+
+	imp, err := insertImport(ctx, tx, ImportRow{
+		Bank:      in.Bank,
+		AccountNo: in.AccountNo,
+		FileName:  in.FileName,
+		Currency:  in.Currency,
+		LineCount: len(fresh),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, l := range fresh {
+		if err := insertLine(ctx, tx, imp.ID, toNewLineRow(l)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	out := mapImportRow(imp)
+	stored, err := a.ListLines(ctx, imp.ID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return &out, stored, nil
+}
+
+func (a *ReconciliationStoreAdapter) GetImport(ctx context.Context, id string) (*recuc.Import, error) {
+	r, err := a.repo.GetImport(ctx, id)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	out := mapImportRow(r)
+	return &out, nil
+}
+
+func (a *ReconciliationStoreAdapter) ListLines(ctx context.Context, importID string, status string) ([]recuc.Line, error) {
+	rows, err := a.repo.ListLines(ctx, importID, status)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]recuc.Line, 0, len(rows))
+	for i := range rows {
+		out = append(out, mapLineRow(&rows[i]))
+	}
+	return out, nil
+}
+
+func (a *ReconciliationStoreAdapter) GetLine(ctx context.Context, id string) (*recuc.Line, error) {
+	r, err := a.repo.GetLine(ctx, id)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	out := mapLineRow(r)
+	return &out, nil
+}
+
+func (a *ReconciliationStoreAdapter) FindCandidates(ctx context.Context, q recuc.CandidateQuery) ([]recuc.Candidate, error) {
+	rows, err := a.repo.FindCandidates(ctx, q.Amount, q.Currency, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]recuc.Candidate, 0, len(rows))
+	for i := range rows {
+		out = append(out, mapCandidateRow(&rows[i]))
+	}
+	return out, nil
+}
+
+func (a *ReconciliationStoreAdapter) GetTarget(ctx context.Context, kind, id string) (*recuc.Candidate, error) {
+	r, ok, err := a.repo.GetTarget(ctx, kind, id)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, recuc.ErrNotTransfer
+	}
+	out := mapCandidateRow(r)
+	return &out, nil
+}
+
+func (a *ReconciliationStoreAdapter) SuggestMatch(ctx context.Context, lineID string, c recuc.Candidate, score int) error {
+	paymentID, receiptID := targetIDs(c)
+	return a.repo.SuggestMatch(ctx, lineID, paymentID, receiptID, score)
+}
+
+func (a *ReconciliationStoreAdapter) ConfirmLine(ctx context.Context, lineID string, c recuc.Candidate, note *string) (*recuc.Line, error) {
+	paymentID, receiptID := targetIDs(c)
+	r, err := a.repo.ConfirmLine(ctx, lineID, paymentID, receiptID, note)
+	if err != nil {
+		// no rows: the line got confirmed concurrently; unique violation: the target
+		// is already confirmed on another line
+		if isNoRows(err) || isUniqueViolation(err) {
+			return nil, recuc.ErrAlreadyReconciled
+		}
+		return nil, err
+	}
+	out := mapLineRow(r)
+	return &out, nil
+}
+
+func (a *ReconciliationStoreAdapter) FlagLine(ctx context.Context, lineID string, note *string) (*recuc.Line, error) {
+	r, err := a.repo.FlagLine(ctx, lineID, note)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, recuc.ErrAlreadyReconciled
+	}
+	out := mapLineRow(r)
+	return &out, nil
+}
+
+func targetIDs(c recuc.Candidate) (paymentID, receiptID *string) {
+	id := c.ID
+	if c.Kind == recuc.TargetReceipt {
+		return nil, &id
+	}
+	return &id, nil
+}
+
+func toNewLineRow(l recuc.ParsedLine) NewLineRow {
+	return NewLineRow{
+		LineNo:      l.LineNo,
+		BookedAt:    l.BookedAt,
+		Description: l.Description,
+		Reference:   l.Reference,
+		Direction:   l.Direction,
+		Amount:      l.Amount,
+		Balance:     l.Balance,
+	}
+}
+
+// lineKey mirrors the columns countImportedLines compares.
+func lineKey(l recuc.ParsedLine) string {
+	ref := l.Description
+	if l.Reference != nil {
+		ref = *l.Reference
+	}
+	return strings.Join([]string{l.BookedAt.Format("2006-01-02"), l.Direction, l.Amount, ref}, "|")
+}
+
+func mapImportRow(r *ImportRow) recuc.Import {
+	return recuc.Import{
+		ID:         r.ID,
+		Bank:       r.Bank,
+		AccountNo:  r.AccountNo,
+		FileName:   r.FileName,
+		Currency:   r.Currency,
+		LineCount:  r.LineCount,
+		ImportedAt: r.ImportedAt,
+	}
+}
+
+func mapLineRow(r *LineRow) recuc.Line {
+	return recuc.Line{
+		ID:          r.ID,
+		ImportID:    r.ImportID,
+		LineNo:      r.LineNo,
+		BookedAt:    r.BookedAt,
+		Description: r.Description,
+		Reference:   r.Reference,
+		Direction:   r.Direction,
+		Amount:      r.Amount,
+		Balance:     r.Balance,
+		Status:      r.Status,
+		PaymentID:   r.PaymentID,
+		ReceiptID:   r.ReceiptID,
+		MatchScore:  r.MatchScore,
+		Note:        r.Note,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+func mapCandidateRow(r *CandidateRow) recuc.Candidate {
+	return recuc.Candidate{
+		Kind:       r.Kind,
+		ID:         r.ID,
+		Amount:     r.Amount,
+		Currency:   r.Currency,
+		PaidAt:     r.PaidAt,
+		SenderName: r.SenderName,
+		Reference:  r.Reference,
+	}
+}
+
+// Compile-time check
+var _ recuc.Store = (*ReconciliationStoreAdapter)(nil)
@@ -0,0 +1,432 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ImportRow struct {
+	ID         string
+	Bank       string
+	AccountNo  *string
+	FileName   *string
+	Currency   string
+	LineCount  int
+	ImportedAt time.Time
+}
+
+type LineRow struct {
+	ID          string
+	ImportID    string
+	LineNo      int
+	BookedAt    time.Time
+	Description string
+	Reference   *string
+	Direction   string
+	Amount      string
+	Balance     *string
+	Status      string
+	PaymentID   *string
+	ReceiptID   *string
+	MatchScore  *int
+	Note        *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type NewLineRow struct {
+	LineNo      int
+	BookedAt    time.Time
+	Description string
+	Reference   *string
+	Direction   string
+	Amount      string
+	Balance     *string
+}
+
+type CandidateRow struct {
+	Kind       string // payment | receipt
+	ID         string
+	Amount     string
+	Currency   string
+	PaidAt     time.Time
+	SenderName *string
+	Reference  *string
+}
+
+type ReconciliationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewReconciliationRepo(db *pgxpool.Pool) *ReconciliationRepo {
+	return &ReconciliationRepo{db: db}
+}
+
+func (r *ReconciliationRepo) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.db.BeginTx(ctx, pgx.TxOptions{})
+}
+
+const importColumns = `
+  id::text,
+  bank,
+  account_no,
+  file_name,
+  currency,
+  line_count,
+  imported_at`
+
+const lineColumns = `
+  id::text,
+  import_id::text,
+  line_no,
+  booked_at,
+  description,
+  reference,
+  direction,
+  amount::text,
+  balance::text,
+  status,
+  payment_id::text,
+  receipt_id::text,
+  match_score,
+  note,
+  created_at,
+  updated_at`
+
+func scanImportRow(row pgx.Row) (*ImportRow, error) {
+	var out ImportRow
+	if err := row.Scan(
+		&out.ID,
+		&out.Bank,
+		&out.AccountNo,
+		&out.FileName,
+		&out.Currency,
+		&out.LineCount,
+		&out.ImportedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func scanLineRow(row pgx.Row) (*LineRow, error) {
+	var out LineRow
+	if err := row.Scan(
+		&out.ID,
+		&out.ImportID,
+		&out.LineNo,
+		&out.BookedAt,
+		&out.Description,
+		&out.Reference,
+		&out.Direction,
+		&out.Amount,
+		&out.Balance,
+		&out.Status,
+		&out.PaymentID,
+		&out.ReceiptID,
+		&out.MatchScore,
+		&out.Note,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func insertImport(ctx context.Context, tx pgx.Tx, in ImportRow) (*ImportRow, error) {
+	q := `
+INSERT INTO bank_statement_imports (bank, account_no, file_name, currency, line_count)
+VALUES ($1::text, $2::text, $3::text, $4::text, $5::int)
+RETURNING` + importColumns + `;`
+
+	return scanImportRow(tx.QueryRow(ctx, q, in.Bank, in.AccountNo, in.FileName, in.Currency, in.LineCount))
+}
+
+func insertLine(ctx context.Context, tx pgx.Tx, importID string, in NewLineRow) error {
+	const q = `
+INSERT INTO bank_statement_lines (
+  import_id, line_no, booked_at, description, reference, direction, amount, balance
+) VALUES (
+  $1::uuid, $2::int, $3::date, $4::text, $5::text, $6::text, $7::numeric, $8::numeric
+);
+`
+	_, err := tx.Exec(ctx, q,
+		importID,
+		in.LineNo,
+		in.BookedAt,
+		in.Description,
+		in.Reference,
+		in.Direction,
+		in.Amount,
+		in.Balance,
+	)
+	return err
+}
+
+// lockAccount serializes imports of one bank account until the transaction ends.
+func lockAccount(ctx context.Context, tx pgx.Tx, bank string, accountNo *string) error {
+	const q = `SELECT pg_advisory_xact_lock(hashtext('bank_statement:' || $1::text || ':' || COALESCE($2::text, '')));`
+	_, err := tx.Exec(ctx, q, bank, accountNo)
+	return err
+}
+
+// countImportedLines counts the stored lines of the same bank account with the
+// same date, direction, amount and reference (the description when the bank
+// sends no reference).
+func countImportedLines(ctx context.Context, tx pgx.Tx, bank string, accountNo *string, in NewLineRow) (int, error) {
+	const q = `
+SELECT count(*)
+FROM bank_statement_lines l
+JOIN bank_statement_imports i ON i.id = l.import_id
+WHERE i.bank = $1::text
+  AND i.account_no IS NOT DISTINCT FROM $2::text
+  AND l.booked_at = $3::date
+  AND l.direction = $4::text
+  AND l.amount = $5::numeric
+  AND COALESCE(l.reference, l.description) = COALESCE($6::text, $7::text);
+`
+	var n int
+	err := tx.QueryRow(ctx, q, bank, accountNo, in.BookedAt, in.Direction, in.Amount, in.Reference, in.Description).Scan(&n)
+	return n, err
+}
+
+func (r *ReconciliationRepo) GetImport(ctx context.Context, id string) (*ImportRow, error) {
+	q := `SELECT` + importColumns + ` FROM bank_statement_imports WHERE id = $1::uuid;`
+
+	out, err := scanImportRow(r.db.QueryRow(ctx, q, id))
+	if err != nil {
+		if isNoRows(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *ReconciliationRepo) ListLines(ctx context.Context, importID string, status string) ([]LineRow, error) {
+	q := `
+SELECT` + lineColumns + `
+FROM bank_statement_lines
+WHERE import_id = $1::uuid
+  AND ($2::text = '' OR status = $2::text)
+ORDER BY line_no ASC;
+`
+	rows, err := r.db.Query(ctx, q, importID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]LineRow, 0, 32)
+	for rows.Next() {
+		l, err := scanLineRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *l)
+	}
+	return out, rows.Err()
+}
+
+func (r *ReconciliationRepo) GetLine(ctx context.Context, id string) (*LineRow, error) {
+	q := `SELECT` + lineColumns + ` FROM bank_statement_lines WHERE id = $1::uuid;`
+
+	out, err := scanLineRow(r.db.QueryRow(ctx, q, id))
+	if err != nil {
+		if isNoRows(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindCandidates lists posted transfers with the exact amount inside [from, to).
+// Payments that belong to a receipt are reconciled through the receipt instead.
+// Targets already suggested for / confirmed against another line are excluded.
+func (r *ReconciliationRepo) FindCandidates(ctx context.Context, amount, currency string, from, to time.Time) ([]CandidateRow, error) {
+	const q = `
+SELECT 'payment', p.id::text, p.amount::text, p.currency, p.paid_at, p.sender_name, p.reference
+FROM payments p
+WHERE p.method = 'transfer'
+  AND p.status = 'posted'
+  AND p.receipt_id IS NULL
+  AND p.amount = $1::numeric
+  AND p.currency = $2::text
+  AND p.paid_at >= $3::timestamptz
+  AND p.paid_at < $4::timestamptz
+  AND NOT EXISTS (
+    SELECT 1 FROM bank_statement_lines l
+    WHERE l.payment_id = p.id
+      AND l.status IN ('suggested', 'confirmed')
+  )
+UNION ALL
+SELECT 'receipt', rc.id::text, rc.amount::text, rc.currency, rc.received_at, rc.sender_name, rc.reference
+FROM receipts rc
+WHERE rc.method = 'transfer'
+  AND rc.status = 'posted'
+  AND rc.amount = $1::numeric
+  AND rc.currency = $2::text
+  AND rc.received_at >= $3::timestamptz
+  AND rc.received_at < $4::timestamptz
+  AND NOT EXISTS (
+    SELECT 1 FROM bank_statement_lines l
+    WHERE l.receipt_id = rc.id
+      AND l.status IN ('suggested', 'confirmed')
+  )
+ORDER BY 5 ASC, 2 ASC;
+`
+	rows, err := r.db.Query(ctx, q, amount, currency, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]CandidateRow, 0, 4)
+	for rows.Next() {
+		var c CandidateRow
+		if err := rows.Scan(&c.Kind, &c.ID, &c.Amount, &c.Currency, &c.PaidAt, &c.SenderName, &c.Reference); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// GetTarget loads a payment/receipt (nil if missing). The bool reports whether it is
+// a posted transfer that can be reconciled.
+func (r *ReconciliationRepo) GetTarget(ctx context.Context, kind, id string) (*CandidateRow, bool, error) {
+	var q string
+	switch kind {
+	case "payment":
+		q = `
+SELECT 'payment', id::text, amount::text, currency, paid_at, sender_name, reference,
+       (method = 'transfer' AND status = 'posted' AND receipt_id IS NULL)
+FROM payments
+WHERE id = $1::uuid;
+`
+	case "receipt":
+		q = `
+SELECT 'receipt', id::text, amount::text, currency, received_at, sender_name, reference,
+       (method = 'transfer' AND status = 'posted')
+FROM receipts
+WHERE id = $1::uuid;
+`
+	default:
+		return nil, false, nil
+	}
+
+	var c CandidateRow
+	var ok bool
+	if err := r.db.QueryRow(ctx, q, id).Scan(&c.Kind, &c.ID, &c.Amount, &c.Currency, &c.PaidAt, &c.SenderName, &c.Reference, &ok); err != nil {
+		if isNoRows(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return &c, ok, nil
+}
+
+// SuggestMatch only touches lines that are still unmatched, so a concurrent
+// confirm/flag is never overwritten.
+func (r *ReconciliationRepo) SuggestMatch(ctx context.Context, lineID string, paymentID, receiptID *string, score int) error {
+	const q = `
+UPDATE bank_statement_lines
+SET status = 'suggested',
+    payment_id = $2::uuid,
+    receipt_id = $3::uuid,
+    match_score = $4::int,
+    updated_at = now()
+WHERE id = $1::uuid
+  AND status = 'unmatched';
+`
+	_, err := r.db.Exec(ctx, q, lineID, paymentID, receiptID, score)
+	return err
+}
+
+// ConfirmLine reconciles the line and, in the same DB transaction, releases any
+// suggestion of the same target on another line.
+func (r *ReconciliationRepo) ConfirmLine(ctx context.Context, lineID string, paymentID, receiptID *string, note *string) (*LineRow, error) {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := `
+UPDATE bank_statement_lines
+SET status = 'confirmed',
+    payment_id = $2::uuid,
+    receipt_id = $3::uuid,
+    note = COALESCE($4::text, note),
+    updated_at = now()
+WHERE id = $1::uuid
+  AND status <> 'confirmed'
+RETURNING` + lineColumns + `;`
+
+	out, err := scanLineRow(tx.QueryRow(ctx, q, lineID, paymentID, receiptID, note))
+	if err != nil {
+		return nil, err
+	}
+
+	const release = `
+UPDATE bank_statement_lines
+SET status = 'unmatched',
+    payment_id = NULL,
+    receipt_id = NULL,
+    match_score = NULL,
+    updated_at = now()
+WHERE id <> $1::uuid
+  AND status = 'suggested'
+  AND (payment_id = $2::uuid OR receipt_id = $3::uuid);
+`
+	if _, err := tx.Exec(ctx, release, lineID, paymentID, receiptID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *ReconciliationRepo) FlagLine(ctx context.Context, lineID string, note *string) (*LineRow, error) {
+	q := `
+UPDATE bank_statement_lines
+SET status = 'flagged',
+    payment_id = NULL,
+    receipt_id = NULL,
+    match_score = NULL,
+    note = COALESCE($2::text, note),
+    updated_at = now()
+WHERE id = $1::uuid
+  AND status <> 'confirmed'
+RETURNING` + lineColumns + `;`
+
+	out, err := scanLineRow(r.db.QueryRow(ctx, q, lineID, note))
+	if err != nil {
+		if isNoRows(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	payrepo "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/payment"
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	trxrepo "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+	payuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/payment"
+	recuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/reconciliation"
+	trxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
+)
+
+func TestReconciliation_ImportSuggestAndConfirm(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	custID := testutil.MustInsertCustomer(t, db, "Budi", "Santoso", "budi@test.local", nil)
+	prodID := testutil.MustInsertProduct(t, db, "SKU-REC-1", "Gula 1kg", nil, 100, 0)
+	testutil.MustInsertPrice(t, db, prodID, nil, "IDR", "750000.00")
+
+	trxStore := trxrepo.NewTransactionStoreAdapter(trxrepo.NewTransactionRepo(db), db)
+	trx, err := trxStore.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 2}},
+	})
+	if err != nil {
+		t.Fatalf("create trx: %v", err)
+	}
+
+	paidAt := time.Now().AddDate(0, 0, -1)
+	sender := "BUDI SANTOSO"
+	ref := "INV-0042"
+	payStore := payrepo.NewPaymentStoreAdapter(payrepo.NewPaymentRepo(db))
+	p, _, err := payStore.Create(ctx, payuc.CreateInput{
+		TransactionID: trx.ID,
+		Method:        payuc.MethodTransfer,
+		Amount:        "1500000.00",
+		SenderName:    &sender,
+		Reference:     &ref,
+		PaidAt:        &paidAt,
+	})
+	if err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+
+	csv := fmt.Sprintf(`Informasi Rekening - Mutasi Rekening
+No. rekening : ,'1234567890
+Tanggal Transaksi,Keterangan,Cabang,Jumlah,,Saldo
+'%s,TRSF E-BANKING CR INV0042 BUDI SANTOSO,'0000,"1,500,000.00",CR,"2,500,000.00"
+'%s,BIAYA ADM,'0000,"10,000.00",DB,"2,490,000.00"
+'PEND,TRSF PENDING,'0000,"5,000.00",CR,
+Saldo Awal,"1,000,000.00"
+`, paidAt.Format("02/01"), time.Now().Format("02/01"))
+
+	uc := recuc.New(NewReconciliationStoreAdapter(NewReconciliationRepo(db)))
+
+	res, err := uc.Import(ctx, recuc.ImportInput{
+		Bank: recuc.BankBCA,
+		Year: paidAt.Year(),
+		Data: []byte(csv),
+	})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if res.Import.LineCount != 2 || res.Credits != 1 || res.Suggested != 1 {
+		t.Fatalf("unexpected import result: lines=%d credits=%d suggested=%d", res.Import.LineCount, res.Credits, res.Suggested)
+	}
+
+	line := res.Lines[0]
+	if line.Status != recuc.StatusSuggested || line.PaymentID == nil || *line.PaymentID != p.ID {
+		t.Fatalf("expected suggestion for payment %s got=%+v", p.ID, line)
+	}
+	if res.Lines[1].Direction != recuc.DirectionDebit || res.Lines[1].Status != recuc.StatusUnmatched {
+		t.Fatalf("expected unmatched debit line got=%+v", res.Lines[1])
+	}
+
+	confirmed, err := uc.Confirm(ctx, line.ID, recuc.ConfirmInput{})
+	if err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if confirmed.Status != recuc.StatusConfirmed {
+		t.Fatalf("expected confirmed got=%s", confirmed.Status)
+	}
+
+	// re-importing the same statement adds nothing
+	again, err := uc.Import(ctx, recuc.ImportInput{
+		Bank: recuc.BankBCA,
+		Year: paidAt.Year(),
+		Data: []byte(csv),
+	})
+	if err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	if again.Import.LineCount != 0 || again.Duplicates != 2 || len(again.Lines) != 0 {
+		t.Fatalf("expected only duplicates on re-import got lines=%d duplicates=%d", again.Import.LineCount, again.Duplicates)
+	}
+
+	// the same payment cannot be reconciled twice
+	other := fmt.Sprintf(`Tanggal Transaksi,Keterangan,Cabang,Jumlah,,Saldo
+'%s,TRSF E-BANKING CR BUDI S,'0000,"1,500,000.00",CR,"3,990,000.00"
+'%s,BIAYA TRANSFER,'0000,"6,500.00",DB,"3,983,500.00"
+`, paidAt.Format("02/01"), time.Now().Format("02/01"))
+	again, err = uc.Import(ctx, recuc.ImportInput{
+		Bank: recuc.BankBCA,
+		Year: paidAt.Year(),
+		Data: []byte(other),
+	})
+	if err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	if again.Import.LineCount != 2 || again.Suggested != 0 {
+		t.Fatalf("expected 2 lines and no suggestion got lines=%d suggested=%d", again.Import.LineCount, again.Suggested)
+	}
+	if _, err := uc.Confirm(ctx, again.Lines[0].ID, recuc.ConfirmInput{PaymentID: &p.ID}); err != recuc.ErrAlreadyReconciled {
+		t.Fatalf("expected ErrAlreadyReconciled got=%v", err)
+	}
+
+	flagged, err := uc.Flag(ctx, again.Lines[1].ID, recuc.FlagInput{})
+	if err != nil {
+		t.Fatalf("flag: %v", err)
+	}
+	if flagged.Status != recuc.StatusFlagged {
+		t.Fatalf("expected flagged got=%s", flagged.Status)
+	}
+}
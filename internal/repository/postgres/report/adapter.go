@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+
+	reportuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/report"
+)
+
+type ReportStoreAdapter struct {
+	repo *ReportRepo
+}
+
+func NewReportStoreAdapter(repo *ReportRepo) *ReportStoreAdapter {
+	return &ReportStoreAdapter{repo: repo}
+}
+
+func (a *ReportStoreAdapter) SalesByPeriod(ctx context.Context, q reportuc.SalesQuery) ([]reportuc.SalesRow, error) {
+	rows, err := a.repo.SalesByPeriod(ctx, q.Interval, q.From, q.To, q.Currency)
+	return mapSalesRows(rows), err
+}
+
+func (a *ReportStoreAdapter) SalesByProduct(ctx context.Context, q reportuc.SalesQuery) ([]reportuc.SalesRow, error) {
+	rows, err := a.repo.SalesByProduct(ctx, q.Interval, q.From, q.To, q.Currency)
+	return mapSalesRows(rows), err
+}
+
+func (a *ReportStoreAdapter) SalesByCustomerCategory(ctx context.Context, q reportuc.SalesQuery) ([]reportuc.SalesRow, error) {
+	rows, err := a.repo.SalesByCustomerCategory(ctx, q.Interval, q.From, q.To, q.Currency)
+	return mapSalesRows(rows), err
+}
+
+func (a *ReportStoreAdapter) SalesByPaymentMethod(ctx context.Context, q reportuc.SalesQuery) ([]reportuc.SalesRow, error) {
+	rows, err := a.repo.SalesByPaymentMethod(ctx, q.Interval, q.From, q.To, q.Currency)
+	return mapSalesRows(rows), err
+}
+
+func (a *ReportStoreAdapter) MarginByProduct(ctx context.Context, q reportuc.MarginQuery) ([]reportuc.MarginRow, error) {
+	rows, err := a.repo.MarginByProduct(ctx, q.From, q.To, q.Currency)
+	return mapMarginRows(rows), err
+}
+
+func (a *ReportStoreAdapter) MarginByTransaction(ctx context.Context, q reportuc.MarginQuery) ([]reportuc.MarginRow, error) {
+	rows, err := a.repo.MarginByTransaction(ctx, q.From, q.To, q.Currency)
+	return mapMarginRows(rows), err
+}
+
+func mapSalesRows(rows []SalesRow) []reportuc.SalesRow {
+	if rows == nil {
+		return nil
+	}
+	out := make([]reportuc.SalesRow, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, reportuc.SalesRow{
+			Period:         r.Period.Format("2006-01-02"),
+			Key:            r.Key,
+			Label:          r.Label,
+			Currency:       r.Currency,
+			Count:          r.Count,
+			Qty:            r.Qty,
+			GrossAmount:    r.GrossAmount,
+			DiscountAmount: r.DiscountAmount,
+			NetAmount:      r.NetAmount,
+		})
+	}
+	return out
+}
+
+func mapMarginRows(rows []MarginRow) []reportuc.MarginRow {
+	if rows == nil {
+		return nil
+	}
+	out := make([]reportuc.MarginRow, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, reportuc.MarginRow{
+			Key:           r.Key,
+			Label:         r.Label,
+			At:            r.At,
+			Qty:           r.Qty,
+			Revenue:       r.Revenue,
+			COGS:          r.COGS,
+			UncostedLines: r.UncostedLines,
+		})
+	}
+	return out
+}
+
+// Compile-time check
+var _ reportuc.Store = (*ReportStoreAdapter)(nil)
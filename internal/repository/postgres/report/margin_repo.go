@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+type MarginRow struct {
+	Key           string
+	Label         string
+	At            *time.Time
+	Qty           int
+	Revenue       string
+	COGS          string
+	UncostedLines int
+}
+
+// Common parameters of the margin queries: $1 from (inclusive), $2 to (exclusive), $3 currency.
+
+func (r *ReportRepo) MarginByProduct(ctx context.Context, from, to time.Time, currency string) ([]MarginRow, error) {
+	const q = `
+WITH item_discounts AS (
+  SELECT transaction_item_id, SUM(amount) AS amount
+  FROM transaction_discounts
+  WHERE transaction_item_id IS NOT NULL
+  GROUP BY transaction_item_id
+)
+SELECT
+  p.id::text,
+  p.name,
+  NULL::timestamptz,
+  SUM(ti.qty)::int,
+  (SUM(ti.line_total) - COALESCE(SUM(d.amount), 0))::text,
+  COALESCE(SUM(ti.cogs_amount), 0)::text,
+  COUNT(*) FILTER (WHERE ti.cogs_amount IS NULL)::int
+FROM transaction_items ti
+JOIN transactions t ON t.id = ti.transaction_id
+JOIN products p ON p.id = ti.product_id
+LEFT JOIN item_discounts d ON d.transaction_item_id = ti.id
+WHERE t.status = 'completed'
+  AND t.created_at >= $1 AND t.created_at < $2
+  AND t.currency = $3
+GROUP BY p.id, p.name
+ORDER BY SUM(ti.line_total) - COALESCE(SUM(d.amount), 0) - COALESCE(SUM(ti.cogs_amount), 0) DESC, p.name;
+`
+	return r.queryMargin(ctx, q, from, to, currency)
+}
+
+func (r *ReportRepo) MarginByTransaction(ctx context.Context, from, to time.Time, currency string) ([]MarginRow, error) {
+	const q = `
+SELECT
+  t.id::text,
+  trim(c.first_name || ' ' || COALESCE(c.last_name, '')),
+  t.created_at,
+  items.qty::int,
+  t.total_amount::text,
+  items.cogs::text,
+  items.uncosted::int
+FROM transactions t
+JOIN customers c ON c.id = t.customer_id
+JOIN LATERAL (
+  SELECT
+    SUM(ti.qty) AS qty,
+    COALESCE(SUM(ti.cogs_amount), 0) AS cogs,
+    COUNT(*) FILTER (WHERE ti.cogs_amount IS NULL) AS uncosted
+  FROM transaction_items ti
+  WHERE ti.transaction_id = t.id
+) items ON true
+WHERE t.status = 'completed'
+  AND t.created_at >= $1 AND t.created_at < $2
+  AND t.currency = $3
+ORDER BY t.created_at, t.id;
+`
+	return r.queryMargin(ctx, q, from, to, currency)
+}
+
+func (r *ReportRepo) queryMargin(ctx context.Context, q string, args ...any) ([]MarginRow, error) {
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]MarginRow, 0, 32)
+	for rows.Next() {
+		var m MarginRow
+		if err := rows.Scan(&m.Key, &m.Label, &m.At, &m.Qty, &m.Revenue, &m.COGS, &m.UncostedLines); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
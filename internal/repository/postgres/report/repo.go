@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SalesRow is one aggregated bucket; Period is the date_trunc'ed bucket start
+// in the database session time zone.
+type SalesRow struct {
+	Period         time.Time
+	Key            *string
+	Label          string
+	Currency       string
+	Count          int
+	Qty            int
+	GrossAmount    string
+	DiscountAmount string
+	NetAmount      string
+}
+
+type ReportRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewReportRepo(db *pgxpool.Pool) *ReportRepo {
+	return &ReportRepo{db: db}
+}
+
+// Common parameters of every sales query:
+// $1 interval (day|week|month), $2 from (inclusive), $3 to (exclusive), $4 currency (nullable).
+
+func (r *ReportRepo) SalesByPeriod(ctx context.Context, interval string, from, to time.Time, currency *string) ([]SalesRow, error) {
+	const q = `
+SELECT
+  date_trunc($1, t.created_at)::date AS period,
+  NULL::text,
+  '',
+  t.currency,
+  COUNT(*)::int,
+  COALESCE(SUM(items.qty), 0)::int,
+  SUM(t.subtotal_amount)::text,
+  SUM(t.discount_amount)::text,
+  SUM(t.total_amount)::text
+FROM transactions t
+LEFT JOIN LATERAL (
+  SELECT SUM(ti.qty) AS qty FROM transaction_items ti WHERE ti.transaction_id = t.id
+) items ON true
+WHERE t.status = 'completed'
+  AND t.created_at >= $2 AND t.created_at < $3
+  AND ($4::text IS NULL OR t.currency = $4)
+GROUP BY 1, t.currency
+ORDER BY 1, t.currency;
+`
+	return r.querySales(ctx, q, interval, from, to, currency)
+}
+
+func (r *ReportRepo) SalesByProduct(ctx context.Context, interval string, from, to time.Time, currency *string) ([]SalesRow, error) {
+	const q = `
+WITH item_discounts AS (
+  SELECT transaction_item_id, SUM(amount) AS amount
+  FROM transaction_discounts
+  WHERE transaction_item_id IS NOT NULL
+  GROUP BY transaction_item_id
+)
+SELECT
+  date_trunc($1, t.created_at)::date AS period,
+  p.id::text,
+  p.name,
+  t.currency,
+  COUNT(DISTINCT t.id)::int,
+  SUM(ti.qty)::int,
+  SUM(ti.line_total)::text,
+  COALESCE(SUM(d.amount), 0)::text,
+  (SUM(ti.line_total) - COALESCE(SUM(d.amount), 0))::text
+FROM transaction_items ti
+JOIN transactions t ON t.id = ti.transaction_id
+JOIN products p ON p.id = ti.product_id
+LEFT JOIN item_discounts d ON d.transaction_item_id = ti.id
+WHERE t.status = 'completed'
+  AND t.created_at >= $2 AND t.created_at < $3
+  AND ($4::text IS NULL OR t.currency = $4)
+GROUP BY 1, p.id, p.name, t.currency
+ORDER BY 1, SUM(ti.line_total) - COALESCE(SUM(d.amount), 0) DESC, p.name;
+`
+	return r.querySales(ctx, q, interval, from, to, currency)
+}
+
+func (r *ReportRepo) SalesByCustomerCategory(ctx context.Context, interval string, from, to time.Time, currency *string) ([]SalesRow, error) {
+	const q = `
+SELECT
+  date_trunc($1, t.created_at)::date AS period,
+  cc.id::text,
+  COALESCE(cc.name, 'Uncategorized'),
+  t.currency,
+  COUNT(*)::int,
+  0,
+  SUM(t.subtotal_amount)::text,
+  SUM(t.discount_amount)::text,
+  SUM(t.total_amount)::text
+FROM transactions t
+JOIN customers c ON c.id = t.customer_id
+LEFT JOIN customer_categories cc ON cc.id = c.category_id
+WHERE t.status = 'completed'
+  AND t.created_at >= $2 AND t.created_at < $3
+  AND ($4::text IS NULL OR t.currency = $4)
+GROUP BY 1, cc.id, cc.name, t.currency
+ORDER BY 1, SUM(t.total_amount) DESC, 3;
+`
+	return r.querySales(ctx, q, interval, from, to, currency)
+}
+
+func (r *ReportRepo) SalesByPaymentMethod(ctx context.Context, interval string, from, to time.Time, currency *string) ([]SalesRow, error) {
+	const q = `
+SELECT
+  date_trunc($1, p.paid_at)::date AS period,
+  p.method,
+  p.method,
+  p.currency,
+  COUNT(*)::int,
+  0,
+  SUM(p.amount)::text,
+  '0.00',
+  SUM(p.amount)::text
+FROM payments p
+JOIN transactions t ON t.id = p.transaction_id
+WHERE p.status = 'posted'
+  AND t.status = 'completed'
+  AND p.paid_at >= $2 AND p.paid_at < $3
+  AND ($4::text IS NULL OR p.currency = $4)
+GROUP BY 1, p.method, p.currency
+ORDER BY 1, SUM(p.amount) DESC, p.method;
+`
+	return r.querySales(ctx, q, interval, from, to, currency)
+}
+
+func (r *ReportRepo) querySales(ctx context.Context, q string, args ...any) ([]SalesRow, error) {
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanSalesRows(rows)
+}
+
+func scanSalesRows(rows pgx.Rows) ([]SalesRow, error) {
+	defer rows.Close()
+
+	out := make([]SalesRow, 0, 32)
+	for rows.Next() {
+		var s SalesRow
+		if err := rows.Scan(
+			&s.Period,
+			&s.Key,
+			&s.Label,
+			&s.Currency,
+			&s.Count,
+			&s.Qty,
+			&s.GrossAmount,
+			&s.DiscountAmount,
+			&s.NetAmount,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
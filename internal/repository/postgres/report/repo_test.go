@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	paypg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/payment"
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+	payuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/payment"
+	reportuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/report"
+	trxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
+)
+
+func TestReport_SalesAggregations(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	catID := testutil.MustInsertCategory(t, db, "RETAIL", "Retail")
+	custID := testutil.MustInsertCustomer(t, db, "Sales", "Report", "sales@test.local", &catID)
+	walkInID := testutil.MustInsertCustomer(t, db, "Walk", "In", "walkin@test.local", nil)
+
+	sugarID := testutil.MustInsertProduct(t, db, "SKU-RPT-1", "Gula 1kg", nil, 100, 0)
+	oilID := testutil.MustInsertProduct(t, db, "SKU-RPT-2", "Minyak 2L", nil, 100, 0)
+	testutil.MustInsertPrice(t, db, sugarID, nil, "IDR", "15000.00")
+	testutil.MustInsertPrice(t, db, oilID, nil, "IDR", "35000.00")
+
+	trxUC := trxuc.New(trxpg.NewTransactionStoreAdapter(trxpg.NewTransactionRepo(db), db))
+	payUC := payuc.New(paypg.NewPaymentStoreAdapter(paypg.NewPaymentRepo(db)))
+
+	create := func(customerID string, items []trxuc.CreateItemIn, status string) *trxuc.Transaction {
+		t.Helper()
+		// a cancelled sale is created as pending and cancelled like at the till
+		initial := status
+		if status == trxuc.StatusCancelled {
+			initial = trxuc.StatusPending
+		}
+		trx, err := trxUC.Create(ctx, trxuc.CreateInput{CustomerID: customerID, Status: initial, Items: items})
+		if err != nil {
+			t.Fatalf("create transaction: %v", err)
+		}
+		if initial != status {
+			if trx, err = trxUC.UpdateStatus(ctx, trx.ID, trxuc.UpdateStatusInput{Status: status}); err != nil {
+				t.Fatalf("set status: %v", err)
+			}
+		}
+		return trx
+	}
+
+	// 2x sugar + 1x oil = 65000, paid cash 65000
+	t1 := create(custID, []trxuc.CreateItemIn{{ProductID: sugarID, Qty: 2}, {ProductID: oilID, Qty: 1}}, trxuc.StatusCompleted)
+	// 1x sugar = 15000, paid by transfer
+	t2 := create(walkInID, []trxuc.CreateItemIn{{ProductID: sugarID, Qty: 1}}, trxuc.StatusCompleted)
+	// cancelled sales never count
+	create(custID, []trxuc.CreateItemIn{{ProductID: oilID, Qty: 5}}, trxuc.StatusCancelled)
+
+	if _, _, err := payUC.Create(ctx, payuc.CreateInput{TransactionID: t1.ID, Method: "cash", Amount: "65000.00"}); err != nil {
+		t.Fatalf("pay t1: %v", err)
+	}
+	if _, _, err := payUC.Create(ctx, payuc.CreateInput{TransactionID: t2.ID, Method: "transfer", Amount: "15000.00"}); err != nil {
+		t.Fatalf("pay t2: %v", err)
+	}
+
+	uc := reportuc.New(NewReportStoreAdapter(NewReportRepo(db)))
+
+	byPeriod, err := uc.Sales(ctx, reportuc.SalesQuery{Interval: reportuc.IntervalMonth})
+	if err != nil {
+		t.Fatalf("sales by period: %v", err)
+	}
+	if len(byPeriod.Totals) != 1 || byPeriod.Totals[0].Count != 2 || byPeriod.Totals[0].Qty != 4 || byPeriod.Totals[0].NetAmount != "80000.00" {
+		t.Fatalf("unexpected period totals: %+v", byPeriod.Totals)
+	}
+
+	byProduct, err := uc.Sales(ctx, reportuc.SalesQuery{By: reportuc.ByProduct})
+	if err != nil {
+		t.Fatalf("sales by product: %v", err)
+	}
+	got := map[string]reportuc.SalesRow{}
+	for _, r := range byProduct.Rows {
+		got[*r.Key] = r
+	}
+	if got[sugarID].Qty != 3 || got[sugarID].NetAmount != "45000.00" || got[oilID].Qty != 1 || got[oilID].NetAmount != "35000.00" {
+		t.Fatalf("unexpected product rows: %+v", byProduct.Rows)
+	}
+
+	byCategory, err := uc.Sales(ctx, reportuc.SalesQuery{By: reportuc.ByCustomerCategory})
+	if err != nil {
+		t.Fatalf("sales by category: %v", err)
+	}
+	if len(byCategory.Rows) != 2 || byCategory.Rows[0].Label != "Retail" || byCategory.Rows[1].Key != nil {
+		t.Fatalf("unexpected category rows: %+v", byCategory.Rows)
+	}
+
+	byMethod, err := uc.Sales(ctx, reportuc.SalesQuery{By: reportuc.ByPaymentMethod})
+	if err != nil {
+		t.Fatalf("sales by payment method: %v", err)
+	}
+	if len(byMethod.Rows) != 2 || byMethod.Rows[0].Label != "cash" || byMethod.Rows[0].NetAmount != "65000.00" {
+		t.Fatalf("unexpected payment method rows: %+v", byMethod.Rows)
+	}
+
+	data, err := reportuc.SalesCSV(byMethod)
+	if err != nil || len(data) == 0 {
+		t.Fatalf("csv: %v", err)
+	}
+
+	if _, err := uc.Sales(ctx, reportuc.SalesQuery{Interval: "year"}); err != reportuc.ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput got=%v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	shiftuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/shift"
+)
+
+type ShiftStoreAdapter struct {
+	repo *ShiftRepo
+}
+
+func NewShiftStoreAdapter(repo *ShiftRepo) *ShiftStoreAdapter {
+	return &ShiftStoreAdapter{repo: repo}
+}
+
+func (a *ShiftStoreAdapter) Open(ctx context.Context, in shiftuc.OpenInput) (*shiftuc.Shift, error) {
+	row, err := a.repo.Open(ctx, in.AdminID, in.Currency, in.OpeningFloat, in.Note)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, shiftuc.ErrShiftAlreadyOpen
+		}
+		if isForeignKeyViolation(err) {
+			return nil, shiftuc.ErrAdminMissing
+		}
+		return nil, err
+	}
+	return mapShift(row), nil
+}
+
+func (a *ShiftStoreAdapter) GetByID(ctx context.Context, id string) (*shiftuc.Shift, error) {
+	row, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, shiftuc.ErrNotFound
+		}
+		return nil, err
+	}
+	return mapShift(row), nil
+}
+
+func (a *ShiftStoreAdapter) GetOpenByAdmin(ctx context.Context, adminID string) (*shiftuc.Shift, error) {
+	row, err := a.repo.GetOpenByAdmin(ctx, adminID)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, shiftuc.ErrNoOpenShift
+		}
+		return nil, err
+	}
+	return mapShift(row), nil
+}
+
+func (a *ShiftStoreAdapter) List(ctx context.Context, q shiftuc.ListQuery) ([]shiftuc.Shift, error) {
+	rows, err := a.repo.List(ctx, q.AdminID, q.Status, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]shiftuc.Shift, 0, len(rows))
+	for i := range rows {
+		out = append(out, *mapShift(&rows[i]))
+	}
+	return out, nil
+}
+
+func (a *ShiftStoreAdapter) Close(ctx context.Context, id string, in shiftuc.CloseInput) (*shiftuc.Shift, error) {
+	row, err := a.repo.Close(ctx, id, in.AdminID, in.CountedCash, in.Note)
+	if err != nil {
+		switch {
+		case isNoRows(err):
+			return nil, shiftuc.ErrNotFound
+		case errors.Is(err, errShiftClosed):
+			return nil, shiftuc.ErrShiftClosed
+		case errors.Is(err, errNotOwner):
+			return nil, shiftuc.ErrNotShiftOwner
+		}
+		return nil, err
+	}
+	return mapShift(row), nil
+}
+
+func (a *ShiftStoreAdapter) CashReceived(ctx context.Context, id string) (string, error) {
+	out, err := a.repo.CashReceived(ctx, id)
+	if err != nil && isNoRows(err) {
+		return "", shiftuc.ErrNotFound
+	}
+	return out, err
+}
+
+func (a *ShiftStoreAdapter) MethodTotals(ctx context.Context, id string) ([]shiftuc.MethodTotal, error) {
+	rows, err := a.repo.MethodTotals(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]shiftuc.MethodTotal, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, shiftuc.MethodTotal{
+			Method:   r.Method,
+			Currency: r.Currency,
+			Count:    r.Count,
+			Amount:   r.Amount,
+		})
+	}
+	return out, nil
+}
+
+func mapShift(r *ShiftRow) *shiftuc.Shift {
+	return &shiftuc.Shift{
+		ID:           r.ID,
+		AdminID:      r.AdminID,
+		Status:       r.Status,
+		Currency:     r.Currency,
+		OpeningFloat: r.OpeningFloat,
+		ExpectedCash: r.ExpectedCash,
+		CountedCash:  r.CountedCash,
+		Variance:     r.Variance,
+		Note:         r.Note,
+		CloseNote:    r.CloseNote,
+		OpenedAt:     r.OpenedAt,
+		ClosedAt:     r.ClosedAt,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+}
+
+// Compile-time check
+var _ shiftuc.Store = (*ShiftStoreAdapter)(nil)
@@ -0,0 +1,267 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ShiftRow struct {
+	ID           string
+	AdminID      string
+	Status       string
+	Currency     string
+	OpeningFloat string
+	ExpectedCash *string
+	CountedCash  *string
+	Variance     *string
+	Note         *string
+	CloseNote    *string
+	OpenedAt     time.Time
+	ClosedAt     *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type MethodTotalRow struct {
+	Method   string
+	Currency string
+	Count    int
+	Amount   string
+}
+
+var (
+	errShiftClosed = errors.New("shift closed")
+	errNotOwner    = errors.New("shift owned by another admin")
+)
+
+type ShiftRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewShiftRepo(db *pgxpool.Pool) *ShiftRepo {
+	return &ShiftRepo{db: db}
+}
+
+const shiftColumns = `
+  id::text,
+  admin_id::text,
+  status,
+  currency,
+  opening_float::text,
+  expected_cash::text,
+  counted_cash::text,
+  variance::text,
+  note,
+  close_note,
+  opened_at,
+  closed_at,
+  created_at,
+  updated_at
+`
+
+func scanShiftRow(row pgx.Row) (*ShiftRow, error) {
+	var out ShiftRow
+	if err := row.Scan(
+		&out.ID, &out.AdminID, &out.Status, &out.Currency, &out.OpeningFloat,
+		&out.ExpectedCash, &out.CountedCash, &out.Variance, &out.Note, &out.CloseNote,
+		&out.OpenedAt, &out.ClosedAt, &out.CreatedAt, &out.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *ShiftRepo) Open(ctx context.Context, adminID, currency, openingFloat string, note *string) (*ShiftRow, error) {
+	q := `
+INSERT INTO cash_shifts (admin_id, currency, opening_float, note)
+VALUES ($1::uuid, $2, $3::numeric, $4)
+RETURNING ` + shiftColumns + `;
+`
+	return scanShiftRow(r.db.QueryRow(ctx, q, adminID, currency, openingFloat, note))
+}
+
+func (r *ShiftRepo) GetByID(ctx context.Context, id string) (*ShiftRow, error) {
+	q := `SELECT ` + shiftColumns + ` FROM cash_shifts WHERE id = $1::uuid;`
+	return scanShiftRow(r.db.QueryRow(ctx, q, id))
+}
+
+func (r *ShiftRepo) GetOpenByAdmin(ctx context.Context, adminID string) (*ShiftRow, error) {
+	q := `SELECT ` + shiftColumns + ` FROM cash_shifts WHERE admin_id = $1::uuid AND status = 'open';`
+	return scanShiftRow(r.db.QueryRow(ctx, q, adminID))
+}
+
+func (r *ShiftRepo) List(ctx context.Context, adminID, status *string, limit, offset int) ([]ShiftRow, error) {
+	q := `
+SELECT ` + shiftColumns + `
+FROM cash_shifts
+WHERE ($1::uuid IS NULL OR admin_id = $1::uuid)
+  AND ($2::text IS NULL OR status = $2)
+ORDER BY opened_at DESC
+LIMIT $3 OFFSET $4;
+`
+	rows, err := r.db.Query(ctx, q, adminID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]ShiftRow, 0, 16)
+	for rows.Next() {
+		s, err := scanShiftRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	return out, rows.Err()
+}
+
+// Close locks the shift row so no payment can be posted into it mid-close
+// (payments take FOR SHARE on the same row), then stores the counted drawer.
+func (r *ShiftRepo) Close(ctx context.Context, id, adminID, countedCash string, note *string) (*ShiftRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var owner, status string
+	if err := tx.QueryRow(ctx, `
+SELECT admin_id::text, status
+FROM cash_shifts
+WHERE id = $1::uuid
+FOR UPDATE;
+`, id).Scan(&owner, &status); err != nil {
+		return nil, err
+	}
+	if status != "open" {
+		return nil, errShiftClosed
+	}
+	if owner != adminID {
+		return nil, errNotOwner
+	}
+
+	received, err := cashReceived(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+UPDATE cash_shifts
+SET status = 'closed',
+    expected_cash = opening_float + $2::numeric,
+    counted_cash = $3::numeric,
+    variance = $3::numeric - (opening_float + $2::numeric),
+    close_note = $4,
+    closed_at = now(),
+    updated_at = now()
+WHERE id = $1::uuid
+RETURNING ` + shiftColumns + `;
+`
+	out, err := scanShiftRow(tx.QueryRow(ctx, q, id, received, countedCash, note))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *ShiftRepo) CashReceived(ctx context.Context, id string) (string, error) {
+	return cashReceived(ctx, r.db, id)
+}
+
+type queryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// cashReceived sums cash tendered in the shift currency. Receipts count once as a
+// whole; their allocation rows in payments (receipt_id set) are skipped. Payments
+// converted from another currency count in the currency actually handed over.
+func cashReceived(ctx context.Context, q queryer, id string) (string, error) {
+	const sql = `
+SELECT (
+  COALESCE((
+    SELECT SUM(COALESCE(p.original_amount, p.amount))
+    FROM payments p
+    WHERE p.shift_id = s.id
+      AND p.receipt_id IS NULL
+      AND p.status = 'posted'
+      AND p.method = 'cash'
+      AND COALESCE(p.original_currency, p.currency) = s.currency
+  ), 0)
+  +
+  COALESCE((
+    SELECT SUM(rc.amount)
+    FROM receipts rc
+    WHERE rc.shift_id = s.id
+      AND rc.status = 'posted'
+      AND rc.method = 'cash'
+      AND rc.currency = s.currency
+  ), 0)
+)::numeric(18, 2)::text
+FROM cash_shifts s
+WHERE s.id = $1::uuid;
+`
+	var out string
+	if err := q.QueryRow(ctx, sql, id).Scan(&out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+func (r *ShiftRepo) MethodTotals(ctx context.Context, id string) ([]MethodTotalRow, error) {
+	const q = `
+SELECT method, currency, COUNT(*)::int, SUM(amount)::numeric(18, 2)::text
+FROM (
+  SELECT p.method, COALESCE(p.original_currency, p.currency) AS currency,
+         COALESCE(p.original_amount, p.amount) AS amount
+  FROM payments p
+  WHERE p.shift_id = $1::uuid
+    AND p.receipt_id IS NULL
+    AND p.status = 'posted'
+  UNION ALL
+  SELECT rc.method, rc.currency, rc.amount
+  FROM receipts rc
+  WHERE rc.shift_id = $1::uuid
+    AND rc.status = 'posted'
+) t
+GROUP BY method, currency
+ORDER BY method, currency;
+`
+	rows, err := r.db.Query(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]MethodTotalRow, 0, 4)
+	for rows.Next() {
+		var m MethodTotalRow
+		if err := rows.Scan(&m.Method, &m.Currency, &m.Count, &m.Amount); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	paypg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/payment"
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	trxrepo "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+	payuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/payment"
+	shiftuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/shift"
+	trxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
+)
+
+func TestShift_CloseComputesVariance(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	adminID := testutil.MustInsertAdmin(t, db, "cashier@test.local")
+	otherID := testutil.MustInsertAdmin(t, db, "other@test.local")
+	custID := testutil.MustInsertCustomer(t, db, "Rio", "Test", "rio@test.local", nil)
+	prodID := testutil.MustInsertProduct(t, db, "SKU-1", "Knee Volley", nil, 10, 0)
+	testutil.MustInsertPrice(t, db, prodID, nil, "IDR", "5000.00")
+
+	trxStore := trxrepo.NewTransactionStoreAdapter(trxrepo.NewTransactionRepo(db), db)
+	trx, err := trxStore.Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 2}},
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+
+	payUC := payuc.New(paypg.NewPaymentStoreAdapter(paypg.NewPaymentRepo(db))).WithShiftsRequired(true)
+	uc := shiftuc.New(NewShiftStoreAdapter(NewShiftRepo(db)))
+
+	// no shift yet: posting is refused
+	if _, _, err := payUC.Create(ctx, payuc.CreateInput{TransactionID: trx.ID, Method: "cash", Amount: "1000", PostedBy: &adminID}); err != payuc.ErrNoOpenShift {
+		t.Fatalf("expected ErrNoOpenShift got=%v", err)
+	}
+
+	s, err := uc.Open(ctx, shiftuc.OpenInput{AdminID: adminID, OpeningFloat: "100000"})
+	if err != nil {
+		t.Fatalf("open shift: %v", err)
+	}
+	if _, err := uc.Open(ctx, shiftuc.OpenInput{AdminID: adminID}); err != shiftuc.ErrShiftAlreadyOpen {
+		t.Fatalf("expected ErrShiftAlreadyOpen got=%v", err)
+	}
+
+	if _, _, err := payUC.Create(ctx, payuc.CreateInput{TransactionID: trx.ID, Method: "cash", Amount: "6000", PostedBy: &adminID}); err != nil {
+		t.Fatalf("cash payment: %v", err)
+	}
+	p, _, err := payUC.Create(ctx, payuc.CreateInput{TransactionID: trx.ID, Method: "transfer", Amount: "4000", PostedBy: &adminID})
+	if err != nil {
+		t.Fatalf("transfer payment: %v", err)
+	}
+	if p.ShiftID == nil || *p.ShiftID != s.ID {
+		t.Fatalf("payment not linked to shift: %+v", p)
+	}
+
+	if _, err := uc.Close(ctx, s.ID, shiftuc.CloseInput{AdminID: otherID, CountedCash: "0"}); err != shiftuc.ErrNotShiftOwner {
+		t.Fatalf("expected ErrNotShiftOwner got=%v", err)
+	}
+
+	closed, err := uc.Close(ctx, s.ID, shiftuc.CloseInput{AdminID: adminID, CountedCash: "105500"})
+	if err != nil {
+		t.Fatalf("close shift: %v", err)
+	}
+	if closed.ExpectedCash == nil || *closed.ExpectedCash != "106000.00" {
+		t.Fatalf("unexpected expected cash: %+v", closed.ExpectedCash)
+	}
+	if closed.Variance == nil || *closed.Variance != "-500.00" {
+		t.Fatalf("unexpected variance: %+v", closed.Variance)
+	}
+	if _, err := uc.Close(ctx, s.ID, shiftuc.CloseInput{AdminID: adminID, CountedCash: "0"}); err != shiftuc.ErrShiftClosed {
+		t.Fatalf("expected ErrShiftClosed got=%v", err)
+	}
+
+	z, err := uc.ZReport(ctx, s.ID)
+	if err != nil {
+		t.Fatalf("z-report: %v", err)
+	}
+	if len(z.Totals) != 2 || z.CashReceived != "6000.00" {
+		t.Fatalf("unexpected z-report: %+v", z)
+	}
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+	shipmentuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/shipment"
+)
+
+type ShipmentStoreAdapter struct {
+	repo *ShipmentRepo
+}
+
+func NewShipmentStoreAdapter(repo *ShipmentRepo) *ShipmentStoreAdapter {
+	return &ShipmentStoreAdapter{repo: repo}
+}
+
+func (a *ShipmentStoreAdapter) Create(ctx context.Context, transactionID string, in shipmentuc.CreateInput) (*shipmentuc.Shipment, error) {
+	lines := make([]LineInputRow, 0, len(in.Lines))
+	for _, l := range in.Lines {
+		lines = append(lines, LineInputRow{TransactionItemID: l.TransactionItemID, Qty: l.Qty})
+	}
+	var shipTo *AddressRow
+	if in.ShipTo != nil {
+		v := AddressRow(*in.ShipTo)
+		shipTo = &v
+	}
+
+	s, ls, err := a.repo.Create(ctx, transactionID, lines, in.AddressID, shipTo, in.Carrier, in.Note, in.CreatedBy)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapShipment(s, ls), nil
+}
+
+func (a *ShipmentStoreAdapter) GetByID(ctx context.Context, id string) (*shipmentuc.Shipment, error) {
+	s, ls, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapShipment(s, ls), nil
+}
+
+func (a *ShipmentStoreAdapter) ListByTransaction(ctx context.Context, transactionID string) ([]shipmentuc.Shipment, error) {
+	rows, err := a.repo.ListByTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]shipmentuc.Shipment, 0, len(rows))
+	for i := range rows {
+		out = append(out, *mapShipment(&rows[i], nil))
+	}
+	return out, nil
+}
+
+func (a *ShipmentStoreAdapter) Pack(ctx context.Context, id string) (*shipmentuc.Shipment, error) {
+	s, ls, err := a.repo.Pack(ctx, id)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapShipment(s, ls), nil
+}
+
+func (a *ShipmentStoreAdapter) Ship(ctx context.Context, id string, in shipmentuc.ShipInput) (*shipmentuc.Shipment, error) {
+	s, ls, err := a.repo.Ship(ctx, id, in.Carrier, in.TrackingNumber)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapShipment(s, ls), nil
+}
+
+func (a *ShipmentStoreAdapter) Deliver(ctx context.Context, id string) (*shipmentuc.Shipment, error) {
+	s, ls, err := a.repo.Deliver(ctx, id)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapShipment(s, ls), nil
+}
+
+func (a *ShipmentStoreAdapter) Cancel(ctx context.Context, id string) (*shipmentuc.Shipment, error) {
+	s, ls, err := a.repo.Cancel(ctx, id)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapShipment(s, ls), nil
+}
+
+func (a *ShipmentStoreAdapter) CustomerName(ctx context.Context, transactionID string) (string, error) {
+	name, err := a.repo.CustomerName(ctx, transactionID)
+	if err != nil {
+		if isNoRows(err) {
+			return "", shipmentuc.ErrTransactionMissing
+		}
+		return "", err
+	}
+	return name, nil
+}
+
+func mapErr(err error) error {
+	switch {
+	case isNoRows(err):
+		return shipmentuc.ErrNotFound
+	case errors.Is(err, errTransactionMissing):
+		return shipmentuc.ErrTransactionMissing
+	case errors.Is(err, errItemMissing):
+		return shipmentuc.ErrItemMissing
+	case errors.Is(err, errAddressMissing):
+		return shipmentuc.ErrAddressMissing
+	case errors.Is(err, errInvalidTransition):
+		return shipmentuc.ErrInvalidTransition
+	case errors.Is(err, errOverShipped), errors.Is(err, trxpg.ErrOverShipped):
+		return fmt.Errorf("%w: %v", shipmentuc.ErrOverShipped, err)
+	case errors.Is(err, errNothingToShip):
+		return shipmentuc.ErrNothingToShip
+	case errors.Is(err, trxpg.ErrExpiredStock):
+		return fmt.Errorf("%w: %v", shipmentuc.ErrExpiredStock, err)
+	}
+	return err
+}
+
+func mapShipment(r *ShipmentRow, lines []LineRow) *shipmentuc.Shipment {
+	out := &shipmentuc.Shipment{
+		ID:                 r.ID,
+		TransactionID:      r.TransactionID,
+		DeliveryNoteNumber: r.DeliveryNoteNumber,
+		Status:             r.Status,
+		AddressID:          r.AddressID,
+		ShipTo:             shipmentuc.Address(r.ShipTo),
+		Carrier:            r.Carrier,
+		TrackingNumber:     r.TrackingNumber,
+		Note:               r.Note,
+		CreatedBy:          r.CreatedBy,
+		PackedAt:           r.PackedAt,
+		ShippedAt:          r.ShippedAt,
+		DeliveredAt:        r.DeliveredAt,
+		CancelledAt:        r.CancelledAt,
+		CreatedAt:          r.CreatedAt,
+		UpdatedAt:          r.UpdatedAt,
+	}
+	for _, l := range lines {
+		out.Lines = append(out.Lines, shipmentuc.Line{
+			ID:                l.ID,
+			TransactionItemID: l.TransactionItemID,
+			ProductID:         l.ProductID,
+			SKU:               l.SKU,
+			ProductName:       l.ProductName,
+			Qty:               l.Qty,
+		})
+	}
+	return out
+}
+
+// Compile-time check
+var _ shipmentuc.Store = (*ShipmentStoreAdapter)(nil)
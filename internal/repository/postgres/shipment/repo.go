@@ -0,0 +1,542 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+)
+
+type ShipmentRow struct {
+	ID                 string
+	TransactionID      string
+	DeliveryNoteNumber string
+	Status             string
+	AddressID          *string
+	ShipTo             AddressRow
+	Carrier            *string
+	TrackingNumber     *string
+	Note               *string
+	CreatedBy          *string
+	PackedAt           *time.Time
+	ShippedAt          *time.Time
+	DeliveredAt        *time.Time
+	CancelledAt        *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+type AddressRow struct {
+	Name       string
+	Phone      *string
+	Line1      string
+	Line2      *string
+	City       *string
+	Province   *string
+	PostalCode *string
+	Country    string
+}
+
+type LineRow struct {
+	ID                string
+	TransactionItemID string
+	ProductID         string
+	SKU               *string
+	ProductName       string
+	Qty               int
+}
+
+type LineInputRow struct {
+	TransactionItemID string
+	Qty               int
+}
+
+var (
+	errTransactionMissing = errors.New("transaction missing")
+	errItemMissing        = errors.New("item missing")
+	errAddressMissing     = errors.New("address missing")
+	errInvalidTransition  = errors.New("invalid transition")
+	errOverShipped        = errors.New("over shipped")
+	errNothingToShip      = errors.New("nothing to ship")
+)
+
+type ShipmentRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewShipmentRepo(db *pgxpool.Pool) *ShipmentRepo {
+	return &ShipmentRepo{db: db}
+}
+
+const shipmentColumns = `
+  id::text,
+  transaction_id::text,
+  delivery_note_number,
+  status,
+  address_id::text,
+  ship_to_name,
+  ship_to_phone,
+  ship_to_line1,
+  ship_to_line2,
+  ship_to_city,
+  ship_to_province,
+  ship_to_postal_code,
+  ship_to_country,
+  carrier,
+  tracking_number,
+  note,
+  created_by::text,
+  packed_at,
+  shipped_at,
+  delivered_at,
+  cancelled_at,
+  created_at,
+  updated_at
+`
+
+func scanShipmentRow(row pgx.Row) (*ShipmentRow, error) {
+	var out ShipmentRow
+	a := &out.ShipTo
+	if err := row.Scan(
+		&out.ID, &out.TransactionID, &out.DeliveryNoteNumber, &out.Status, &out.AddressID,
+		&a.Name, &a.Phone, &a.Line1, &a.Line2, &a.City, &a.Province, &a.PostalCode, &a.Country,
+		&out.Carrier, &out.TrackingNumber, &out.Note, &out.CreatedBy,
+		&out.PackedAt, &out.ShippedAt, &out.DeliveredAt, &out.CancelledAt, &out.CreatedAt, &out.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type queryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func getShipment(ctx context.Context, q queryer, id string) (*ShipmentRow, error) {
+	sql := `SELECT ` + shipmentColumns + ` FROM shipments WHERE id = $1::uuid;`
+	return scanShipmentRow(q.QueryRow(ctx, sql, id))
+}
+
+func listLines(ctx context.Context, q queryer, shipmentID string) ([]LineRow, error) {
+	const sql = `
+SELECT l.id::text, l.transaction_item_id::text, p.id::text, p.sku, p.name, l.qty
+FROM shipment_lines l
+JOIN transaction_items ti ON ti.id = l.transaction_item_id
+JOIN products p ON p.id = ti.product_id
+WHERE l.shipment_id = $1::uuid
+ORDER BY ti.created_at, ti.id;
+`
+	rows, err := q.Query(ctx, sql, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]LineRow, 0, 8)
+	for rows.Next() {
+		var l LineRow
+		if err := rows.Scan(&l.ID, &l.TransactionItemID, &l.ProductID, &l.SKU, &l.ProductName, &l.Qty); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// resolveShipTo picks the inline address, else the customer's address
+// addressID, else the customer's default (or only) address.
+func resolveShipTo(ctx context.Context, tx pgx.Tx, transactionID string, addressID *string, inline *AddressRow) (*string, AddressRow, error) {
+	var name string
+	var phone *string
+	if err := tx.QueryRow(ctx, `
+SELECT
+  c.first_name || CASE WHEN c.last_name IS NULL OR c.last_name = '' THEN '' ELSE ' ' || c.last_name END,
+  c.phone
+FROM transactions t
+JOIN customers c ON c.id = t.customer_id
+WHERE t.id = $1::uuid;
+`, transactionID).Scan(&name, &phone); err != nil {
+		return nil, AddressRow{}, err
+	}
+
+	if inline != nil {
+		out := *inline
+		if out.Name == "" {
+			out.Name = name
+		}
+		if out.Phone == nil {
+			out.Phone = phone
+		}
+		return nil, out, nil
+	}
+
+	out := AddressRow{Name: name, Phone: phone}
+	var id string
+	err := tx.QueryRow(ctx, `
+SELECT a.id::text, a.address_line1, a.address_line2, a.city, a.province, a.postal_code, a.country
+FROM customer_addresses a
+JOIN transactions t ON t.customer_id = a.customer_id
+WHERE t.id = $1::uuid
+  AND ($2::uuid IS NULL OR a.id = $2::uuid)
+ORDER BY a.is_default DESC, a.created_at DESC
+LIMIT 1;
+`, transactionID, addressID).Scan(&id, &out.Line1, &out.Line2, &out.City, &out.Province, &out.PostalCode, &out.Country)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, AddressRow{}, errAddressMissing
+		}
+		return nil, AddressRow{}, err
+	}
+	return &id, out, nil
+}
+
+// unallotted returns per transaction item what is neither shipped nor in an
+// open (picking or packed) shipment.
+func unallotted(ctx context.Context, tx pgx.Tx, transactionID string) (map[string]int, []string, error) {
+	rows, err := tx.Query(ctx, `
+SELECT
+  ti.id::text,
+  ti.qty - ti.qty_shipped - COALESCE((
+    SELECT SUM(sl.qty)
+    FROM shipment_lines sl
+    JOIN shipments s ON s.id = sl.shipment_id
+    WHERE sl.transaction_item_id = ti.id
+      AND s.status IN ('picking', 'packed')
+  ), 0)
+FROM transaction_items ti
+WHERE ti.transaction_id = $1::uuid
+ORDER BY ti.created_at, ti.id;
+`, transactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	left := map[string]int{}
+	order := make([]string, 0, 8)
+	for rows.Next() {
+		var id string
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, nil, err
+		}
+		left[id] = n
+		order = append(order, id)
+	}
+	return left, order, rows.Err()
+}
+
+func (r *ShipmentRepo) Create(ctx context.Context, transactionID string, lines []LineInputRow, addressID *string, shipTo *AddressRow, carrier, note, createdBy *string) (*ShipmentRow, []LineRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, err := trxpg.LockTransactionStatus(ctx, tx, transactionID)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, nil, errTransactionMissing
+		}
+		return nil, nil, err
+	}
+	// stock is reserved only while pending
+	if status != "pending" {
+		return nil, nil, errInvalidTransition
+	}
+
+	left, order, err := unallotted(ctx, tx, transactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(lines) == 0 {
+		for _, id := range order {
+			if left[id] > 0 {
+				lines = append(lines, LineInputRow{TransactionItemID: id, Qty: left[id]})
+			}
+		}
+		if len(lines) == 0 {
+			return nil, nil, errNothingToShip
+		}
+	}
+	for _, l := range lines {
+		n, ok := left[l.TransactionItemID]
+		if !ok {
+			return nil, nil, errItemMissing
+		}
+		if l.Qty > n {
+			return nil, nil, fmt.Errorf("%w: item=%s left=%d requested=%d", errOverShipped, l.TransactionItemID, n, l.Qty)
+		}
+	}
+
+	resolvedAddressID, a, err := resolveShipTo(ctx, tx, transactionID, addressID, shipTo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var id string
+	if err := tx.QueryRow(ctx, `
+INSERT INTO shipments (
+  transaction_id, address_id,
+  ship_to_name, ship_to_phone, ship_to_line1, ship_to_line2, ship_to_city,
+  ship_to_province, ship_to_postal_code, ship_to_country,
+  carrier, note, created_by
+)
+VALUES ($1::uuid, $2::uuid, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::uuid)
+RETURNING id::text;
+`, transactionID, resolvedAddressID,
+		a.Name, a.Phone, a.Line1, a.Line2, a.City,
+		a.Province, a.PostalCode, a.Country,
+		carrier, note, createdBy,
+	).Scan(&id); err != nil {
+		return nil, nil, err
+	}
+
+	for _, l := range lines {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO shipment_lines (shipment_id, transaction_item_id, qty)
+VALUES ($1::uuid, $2::uuid, $3);
+`, id, l.TransactionItemID, l.Qty); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	out, err := getShipment(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := recordShipmentEvent(ctx, tx, out, trxpg.EventShipmentCreated); err != nil {
+		return nil, nil, err
+	}
+	ls, err := listLines(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return out, ls, nil
+}
+
+func (r *ShipmentRepo) GetByID(ctx context.Context, id string) (*ShipmentRow, []LineRow, error) {
+	out, err := getShipment(ctx, r.db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := listLines(ctx, r.db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, lines, nil
+}
+
+func (r *ShipmentRepo) ListByTransaction(ctx context.Context, transactionID string) ([]ShipmentRow, error) {
+	q := `
+SELECT ` + shipmentColumns + `
+FROM shipments
+WHERE transaction_id = $1::uuid
+ORDER BY created_at;
+`
+	rows, err := r.db.Query(ctx, q, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]ShipmentRow, 0, 4)
+	for rows.Next() {
+		s, err := scanShipmentRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	return out, rows.Err()
+}
+
+// lockShipment locks the shipment's transaction, then the shipment, the same
+// order Create and the transaction status changes use.
+func lockShipment(ctx context.Context, tx pgx.Tx, id string) (status, transactionID, trxStatus string, err error) {
+	if err = tx.QueryRow(ctx, `SELECT transaction_id::text FROM shipments WHERE id = $1::uuid`, id).Scan(&transactionID); err != nil {
+		return "", "", "", err
+	}
+	if trxStatus, err = trxpg.LockTransactionStatus(ctx, tx, transactionID); err != nil {
+		return "", "", "", err
+	}
+	err = tx.QueryRow(ctx, `SELECT status FROM shipments WHERE id = $1::uuid FOR UPDATE`, id).Scan(&status)
+	return status, transactionID, trxStatus, err
+}
+
+// transition runs apply on the locked shipment when its status is one of from,
+// records event on the transaction's timeline and returns the shipment.
+func (r *ShipmentRepo) transition(ctx context.Context, id string, from []string, event string, apply func(tx pgx.Tx, transactionID, trxStatus string) error) (*ShipmentRow, []LineRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, transactionID, trxStatus, err := lockShipment(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	allowed := false
+	for _, s := range from {
+		allowed = allowed || s == status
+	}
+	if !allowed {
+		return nil, nil, errInvalidTransition
+	}
+	if err := apply(tx, transactionID, trxStatus); err != nil {
+		return nil, nil, err
+	}
+
+	out, err := getShipment(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := recordShipmentEvent(ctx, tx, out, event); err != nil {
+		return nil, nil, err
+	}
+	lines, err := listLines(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return out, lines, nil
+}
+
+func (r *ShipmentRepo) Pack(ctx context.Context, id string) (*ShipmentRow, []LineRow, error) {
+	return r.transition(ctx, id, []string{"picking"}, trxpg.EventShipmentPacked, func(tx pgx.Tx, _, _ string) error {
+		_, err := tx.Exec(ctx, `
+UPDATE shipments
+SET status = 'packed', packed_at = now(), updated_at = now()
+WHERE id = $1::uuid;
+`, id)
+		return err
+	})
+}
+
+// Ship commits the reserved stock of the shipment's lines and completes the
+// transaction once nothing is left to ship.
+func (r *ShipmentRepo) Ship(ctx context.Context, id string, carrier, trackingNumber *string) (*ShipmentRow, []LineRow, error) {
+	return r.transition(ctx, id, []string{"picking", "packed"}, trxpg.EventShipmentShipped, func(tx pgx.Tx, transactionID, trxStatus string) error {
+		if trxStatus != "pending" {
+			return errInvalidTransition
+		}
+
+		lines, err := listLines(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		ship := make([]trxpg.ShipLine, 0, len(lines))
+		for _, l := range lines {
+			ship = append(ship, trxpg.ShipLine{ItemID: l.TransactionItemID, Qty: l.Qty})
+		}
+		done, err := trxpg.ShipItems(ctx, tx, transactionID, ship)
+		if err != nil {
+			return err
+		}
+		if done {
+			if err := trxpg.CompleteTransaction(ctx, tx, transactionID); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Exec(ctx, `
+UPDATE shipments
+SET status = 'shipped',
+    carrier = COALESCE($2, carrier),
+    tracking_number = COALESCE($3, tracking_number),
+    packed_at = COALESCE(packed_at, now()),
+    shipped_at = now(),
+    updated_at = now()
+WHERE id = $1::uuid;
+`, id, carrier, trackingNumber)
+		return err
+	})
+}
+
+// Deliver marks the shipment delivered; the transaction is delivered when
+// everything shipped and no shipment is still on its way.
+func (r *ShipmentRepo) Deliver(ctx context.Context, id string) (*ShipmentRow, []LineRow, error) {
+	return r.transition(ctx, id, []string{"shipped"}, trxpg.EventShipmentDelivered, func(tx pgx.Tx, transactionID, _ string) error {
+		if _, err := tx.Exec(ctx, `
+UPDATE shipments
+SET status = 'delivered', delivered_at = now(), updated_at = now()
+WHERE id = $1::uuid;
+`, id); err != nil {
+			return err
+		}
+
+		var delivered bool
+		if err := tx.QueryRow(ctx, `
+SELECT
+  NOT EXISTS (
+    SELECT 1 FROM transaction_items
+    WHERE transaction_id = $1::uuid AND qty > qty_shipped
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM shipments
+    WHERE transaction_id = $1::uuid AND status IN ('picking', 'packed', 'shipped')
+  );
+`, transactionID).Scan(&delivered); err != nil {
+			return err
+		}
+		if !delivered {
+			return nil
+		}
+		return trxpg.SetFulfillmentStatus(ctx, tx, transactionID, "delivered")
+	})
+}
+
+func (r *ShipmentRepo) Cancel(ctx context.Context, id string) (*ShipmentRow, []LineRow, error) {
+	return r.transition(ctx, id, []string{"picking", "packed"}, trxpg.EventShipmentCancelled, func(tx pgx.Tx, _, _ string) error {
+		_, err := tx.Exec(ctx, `
+UPDATE shipments
+SET status = 'cancelled', cancelled_at = now(), updated_at = now()
+WHERE id = $1::uuid;
+`, id)
+		return err
+	})
+}
+
+func recordShipmentEvent(ctx context.Context, tx pgx.Tx, s *ShipmentRow, kind string) error {
+	data := map[string]any{
+		"shipmentId":         s.ID,
+		"deliveryNoteNumber": s.DeliveryNoteNumber,
+	}
+	if s.TrackingNumber != nil {
+		data["trackingNumber"] = *s.TrackingNumber
+	}
+	return trxpg.RecordEvent(ctx, tx, s.TransactionID, kind, nil, &s.Status, data)
+}
+
+// CustomerName is the transaction customer's full name.
+func (r *ShipmentRepo) CustomerName(ctx context.Context, transactionID string) (string, error) {
+	const q = `
+SELECT c.first_name || CASE WHEN c.last_name IS NULL OR c.last_name = '' THEN '' ELSE ' ' || c.last_name END
+FROM transactions t
+JOIN customers c ON c.id = t.customer_id
+WHERE t.id = $1::uuid;
+`
+	var name string
+	if err := r.db.QueryRow(ctx, q, transactionID).Scan(&name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
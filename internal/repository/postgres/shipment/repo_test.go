@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+	shipmentuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/shipment"
+	trxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
+)
+
+func TestShipment_PartialShipmentsCommitStockAndComplete(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	custID := testutil.MustInsertCustomer(t, db, "Sinta", "Dewi", "sinta@ship.local", nil)
+	prodID := testutil.MustInsertProduct(t, db, "SKU-SHIP-1", "Kopi 200g", nil, 10, 0)
+	testutil.MustInsertPrice(t, db, prodID, nil, "IDR", "3000.00")
+	if _, err := db.Exec(ctx, `
+INSERT INTO customer_addresses (customer_id, address_line1, city, is_default)
+VALUES ($1::uuid, 'Jl. Melati 5', 'Bandung', true);
+`, custID); err != nil {
+		t.Fatalf("insert address: %v", err)
+	}
+
+	trxStore := trxpg.NewTransactionStoreAdapter(trxpg.NewTransactionRepo(db), db)
+	trx, err := trxuc.New(trxStore).Create(ctx, trxuc.CreateInput{
+		CustomerID: custID,
+		Status:     trxuc.StatusPending,
+		Items:      []trxuc.CreateItemIn{{ProductID: prodID, Qty: 5}},
+	})
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	itemID := trx.Items[0].ID
+
+	uc := shipmentuc.New(NewShipmentStoreAdapter(NewShipmentRepo(db)))
+
+	first, err := uc.Create(ctx, trx.ID, shipmentuc.CreateInput{
+		Lines: []shipmentuc.LineInput{{TransactionItemID: itemID, Qty: 2}},
+	})
+	if err != nil {
+		t.Fatalf("create shipment: %v", err)
+	}
+	if first.Status != shipmentuc.StatusPicking || first.ShipTo.Name != "Sinta Dewi" || first.ShipTo.Line1 != "Jl. Melati 5" {
+		t.Fatalf("unexpected shipment: %+v", first)
+	}
+	// 3 left, 2 of which are already being picked
+	if _, err := uc.Create(ctx, trx.ID, shipmentuc.CreateInput{
+		Lines: []shipmentuc.LineInput{{TransactionItemID: itemID, Qty: 4}},
+	}); err == nil || !strings.Contains(err.Error(), shipmentuc.ErrOverShipped.Error()) {
+		t.Fatalf("expected ErrOverShipped got=%v", err)
+	}
+
+	if _, err := uc.Pack(ctx, first.ID); err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+	tracking := "JNE-1"
+	shipped, err := uc.Ship(ctx, first.ID, shipmentuc.ShipInput{TrackingNumber: &tracking})
+	if err != nil {
+		t.Fatalf("ship: %v", err)
+	}
+	if shipped.Status != shipmentuc.StatusShipped || shipped.ShippedAt == nil {
+		t.Fatalf("unexpected shipped: %+v", shipped)
+	}
+
+	stock := func() (onHand, reserved int, status, fulfillment string) {
+		t.Helper()
+		if err := db.QueryRow(ctx, `
+SELECT p.stock_on_hand, p.stock_reserved, t.status, t.fulfillment_status
+FROM products p, transactions t
+WHERE p.id = $1::uuid AND t.id = $2::uuid;
+`, prodID, trx.ID).Scan(&onHand, &reserved, &status, &fulfillment); err != nil {
+			t.Fatalf("read stock: %v", err)
+		}
+		return
+	}
+	if onHand, reserved, status, f := stock(); onHand != 8 || reserved != 3 || status != "pending" || f != "partially_shipped" {
+		t.Fatalf("after first shipment on_hand=%d reserved=%d status=%s fulfillment=%s", onHand, reserved, status, f)
+	}
+
+	// no lines: the remainder
+	rest, err := uc.Create(ctx, trx.ID, shipmentuc.CreateInput{})
+	if err != nil {
+		t.Fatalf("create rest: %v", err)
+	}
+	if len(rest.Lines) != 1 || rest.Lines[0].Qty != 3 {
+		t.Fatalf("unexpected rest lines: %+v", rest.Lines)
+	}
+	if _, err := uc.Ship(ctx, rest.ID, shipmentuc.ShipInput{}); err != nil {
+		t.Fatalf("ship rest: %v", err)
+	}
+	if onHand, reserved, status, f := stock(); onHand != 5 || reserved != 0 || status != "completed" || f != "shipped" {
+		t.Fatalf("after last shipment on_hand=%d reserved=%d status=%s fulfillment=%s", onHand, reserved, status, f)
+	}
+
+	for _, id := range []string{first.ID, rest.ID} {
+		if _, err := uc.Deliver(ctx, id); err != nil {
+			t.Fatalf("deliver: %v", err)
+		}
+	}
+	if _, _, _, f := stock(); f != "delivered" {
+		t.Fatalf("expected delivered got=%s", f)
+	}
+
+	note, err := uc.DeliveryNote(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("delivery note: %v", err)
+	}
+	text := shipmentuc.RenderDeliveryNote(note)
+	if !strings.Contains(text, first.DeliveryNoteNumber) || !strings.Contains(text, "Jl. Melati 5") || !strings.Contains(text, "JNE-1") {
+		t.Fatalf("unexpected delivery note:\n%s", text)
+	}
+}
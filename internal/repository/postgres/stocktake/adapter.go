@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	stocktakeuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/stocktake"
+)
+
+type StockTakeStoreAdapter struct {
+	repo *StockTakeRepo
+}
+
+func NewStockTakeStoreAdapter(repo *StockTakeRepo) *StockTakeStoreAdapter {
+	return &StockTakeStoreAdapter{repo: repo}
+}
+
+func (a *StockTakeStoreAdapter) Create(ctx context.Context, in stocktakeuc.CreateInput) (*stocktakeuc.StockTake, error) {
+	s, ls, err := a.repo.Create(ctx, in.LocationID, in.ProductIDs, in.Note, in.CreatedBy)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapStockTake(s, ls), nil
+}
+
+func (a *StockTakeStoreAdapter) GetByID(ctx context.Context, id string) (*stocktakeuc.StockTake, error) {
+	s, ls, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapStockTake(s, ls), nil
+}
+
+func (a *StockTakeStoreAdapter) List(ctx context.Context, q stocktakeuc.ListQuery) ([]stocktakeuc.StockTake, error) {
+	rows, err := a.repo.List(ctx, q.LocationID, q.Status, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]stocktakeuc.StockTake, 0, len(rows))
+	for i := range rows {
+		out = append(out, *mapStockTake(&rows[i], nil))
+	}
+	return out, nil
+}
+
+func (a *StockTakeStoreAdapter) RecordCounts(ctx context.Context, id string, in stocktakeuc.CountInput) error {
+	counts := make([]CountInputRow, 0, len(in.Counts))
+	for _, c := range in.Counts {
+		counts = append(counts, CountInputRow{ProductID: c.ProductID, Qty: c.Qty})
+	}
+	return mapErr(a.repo.RecordCounts(ctx, id, in.CounterID, counts))
+}
+
+func (a *StockTakeStoreAdapter) Approve(ctx context.Context, id string, approvedBy *string) (*stocktakeuc.ApproveResult, error) {
+	s, ls, adj, err := a.repo.Approve(ctx, id, approvedBy)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	out := &stocktakeuc.ApproveResult{
+		StockTake:   mapStockTake(s, ls),
+		Adjustments: make([]stocktakeuc.Adjustment, 0, len(adj)),
+	}
+	for _, r := range adj {
+		out.Adjustments = append(out.Adjustments, stocktakeuc.Adjustment{
+			ID:           r.ID,
+			ProductID:    r.ProductID,
+			LocationID:   r.LocationID,
+			QtyDelta:     r.QtyDelta,
+			OnHandBefore: r.OnHandBefore,
+			OnHandAfter:  r.OnHandAfter,
+			CreatedAt:    r.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+func (a *StockTakeStoreAdapter) Cancel(ctx context.Context, id string) (*stocktakeuc.StockTake, error) {
+	s, ls, err := a.repo.Cancel(ctx, id)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapStockTake(s, ls), nil
+}
+
+func mapErr(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case isNoRows(err):
+		return stocktakeuc.ErrNotFound
+	case errors.Is(err, errLocationMissing):
+		return stocktakeuc.ErrLocationMissing
+	case errors.Is(err, errProductMissing):
+		return stocktakeuc.ErrProductMissing
+	case errors.Is(err, errSessionOpen):
+		return stocktakeuc.ErrSessionOpen
+	case errors.Is(err, errInvalidTransition):
+		return stocktakeuc.ErrInvalidTransition
+	case errors.Is(err, errBelowReserved):
+		return stocktakeuc.ErrBelowReserved
+	}
+	return err
+}
+
+func mapStockTake(r *StockTakeRow, lines []LineRow) *stocktakeuc.StockTake {
+	out := &stocktakeuc.StockTake{
+		ID:           r.ID,
+		LocationID:   r.LocationID,
+		LocationName: r.LocationName,
+		Status:       r.Status,
+		Note:         r.Note,
+		CreatedBy:    r.CreatedBy,
+		ApprovedBy:   r.ApprovedBy,
+		ApprovedAt:   r.ApprovedAt,
+		CancelledAt:  r.CancelledAt,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+	if lines == nil {
+		return out
+	}
+	out.Lines = make([]stocktakeuc.Line, 0, len(lines))
+	for _, l := range lines {
+		line := stocktakeuc.Line{
+			ID:          l.ID,
+			ProductID:   l.ProductID,
+			SKU:         l.SKU,
+			Name:        l.Name,
+			ExpectedQty: l.ExpectedQty,
+		}
+		for _, c := range l.Counts {
+			line.Counts = append(line.Counts, stocktakeuc.Count{CounterID: c.CounterID, Qty: c.Qty, UpdatedAt: c.UpdatedAt})
+		}
+		out.Lines = append(out.Lines, line)
+	}
+	return out
+}
+
+// Compile-time check
+var _ stocktakeuc.Store = (*StockTakeStoreAdapter)(nil)
@@ -0,0 +1,467 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+)
+
+type StockTakeRow struct {
+	ID           string
+	LocationID   string
+	LocationName string
+	Status       string
+	Note         *string
+	CreatedBy    *string
+	ApprovedBy   *string
+	ApprovedAt   *time.Time
+	CancelledAt  *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type LineRow struct {
+	ID          string
+	ProductID   string
+	SKU         *string
+	Name        string
+	ExpectedQty int
+	Counts      []CountRow
+}
+
+type CountRow struct {
+	CounterID string
+	Qty       int
+	UpdatedAt time.Time
+}
+
+type CountInputRow struct {
+	ProductID string
+	Qty       int
+}
+
+type AdjustmentRow struct {
+	ID           string
+	ProductID    string
+	LocationID   string
+	QtyDelta     int
+	OnHandBefore int
+	OnHandAfter  int
+	CreatedAt    time.Time
+}
+
+var (
+	errLocationMissing   = errors.New("location missing")
+	errProductMissing    = errors.New("product missing")
+	errSessionOpen       = errors.New("stock take already open")
+	errInvalidTransition = errors.New("invalid transition")
+	errBelowReserved     = errors.New("below reserved")
+)
+
+type StockTakeRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewStockTakeRepo(db *pgxpool.Pool) *StockTakeRepo {
+	return &StockTakeRepo{db: db}
+}
+
+const stockTakeColumns = `
+  s.id::text,
+  s.location_id::text,
+  l.name,
+  s.status,
+  s.note,
+  s.created_by::text,
+  s.approved_by::text,
+  s.approved_at,
+  s.cancelled_at,
+  s.created_at,
+  s.updated_at
+`
+
+const stockTakeFrom = `
+FROM stock_takes s
+JOIN locations l ON l.id = s.location_id
+`
+
+func scanStockTakeRow(row pgx.Row) (*StockTakeRow, error) {
+	var out StockTakeRow
+	if err := row.Scan(
+		&out.ID, &out.LocationID, &out.LocationName, &out.Status, &out.Note,
+		&out.CreatedBy, &out.ApprovedBy, &out.ApprovedAt, &out.CancelledAt,
+		&out.CreatedAt, &out.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type queryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func getStockTake(ctx context.Context, q queryer, id string) (*StockTakeRow, error) {
+	sql := `SELECT ` + stockTakeColumns + stockTakeFrom + `WHERE s.id = $1::uuid;`
+	return scanStockTakeRow(q.QueryRow(ctx, sql, id))
+}
+
+// listLines returns the lines ordered by product id, the order Approve locks
+// stock rows in, each with its counts.
+func listLines(ctx context.Context, q queryer, stockTakeID string) ([]LineRow, error) {
+	rows, err := q.Query(ctx, `
+SELECT l.id::text, l.product_id::text, p.sku, p.name, l.expected_qty
+FROM stock_take_lines l
+JOIN products p ON p.id = l.product_id
+WHERE l.stock_take_id = $1::uuid
+ORDER BY l.product_id;
+`, stockTakeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]LineRow, 0, 32)
+	index := map[string]int{}
+	for rows.Next() {
+		var l LineRow
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.SKU, &l.Name, &l.ExpectedQty); err != nil {
+			return nil, err
+		}
+		index[l.ID] = len(out)
+		out = append(out, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	crows, err := q.Query(ctx, `
+SELECT c.stock_take_line_id::text, c.counter_id::text, c.qty, c.updated_at
+FROM stock_take_counts c
+JOIN stock_take_lines l ON l.id = c.stock_take_line_id
+WHERE l.stock_take_id = $1::uuid
+ORDER BY c.created_at, c.id;
+`, stockTakeID)
+	if err != nil {
+		return nil, err
+	}
+	defer crows.Close()
+
+	for crows.Next() {
+		var lineID string
+		var c CountRow
+		if err := crows.Scan(&lineID, &c.CounterID, &c.Qty, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if i, ok := index[lineID]; ok {
+			out[i].Counts = append(out[i].Counts, c)
+		}
+	}
+	return out, crows.Err()
+}
+
+// Create opens a session and snapshots the location's on_hand of each product
+// as its expected quantity. Without productIDs every active stock product is
+// included.
+func (r *StockTakeRepo) Create(ctx context.Context, locationID *string, productIDs []string, note, createdBy *string) (*StockTakeRow, []LineRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	locID, err := trxpg.ResolveLocation(ctx, tx, locationID)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, nil, errLocationMissing
+		}
+		return nil, nil, err
+	}
+
+	var id string
+	if err := tx.QueryRow(ctx, `
+INSERT INTO stock_takes (location_id, note, created_by)
+VALUES ($1::uuid, $2, $3::uuid)
+RETURNING id::text;
+`, locID, note, createdBy).Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return nil, nil, errSessionOpen
+		}
+		return nil, nil, err
+	}
+
+	if productIDs == nil {
+		productIDs = []string{}
+	}
+	ct, err := tx.Exec(ctx, `
+INSERT INTO stock_take_lines (stock_take_id, product_id, expected_qty)
+SELECT $1::uuid, p.id, COALESCE(sl.on_hand, 0)
+FROM products p
+LEFT JOIN stock_levels sl ON sl.product_id = p.id AND sl.location_id = $2::uuid
+WHERE p.base_product_id IS NULL
+  AND (
+    (cardinality($3::uuid[]) = 0 AND p.is_active)
+    OR p.id = ANY($3::uuid[])
+  );
+`, id, locID, productIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(productIDs) > 0 && int(ct.RowsAffected()) != len(productIDs) {
+		return nil, nil, errProductMissing
+	}
+
+	out, err := getStockTake(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := listLines(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return out, lines, nil
+}
+
+func (r *StockTakeRepo) GetByID(ctx context.Context, id string) (*StockTakeRow, []LineRow, error) {
+	out, err := getStockTake(ctx, r.db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := listLines(ctx, r.db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, lines, nil
+}
+
+func (r *StockTakeRepo) List(ctx context.Context, locationID, status *string, limit, offset int) ([]StockTakeRow, error) {
+	q := `
+SELECT ` + stockTakeColumns + stockTakeFrom + `
+WHERE ($1::uuid IS NULL OR s.location_id = $1::uuid)
+  AND ($2::text IS NULL OR s.status = $2)
+ORDER BY s.created_at DESC
+LIMIT $3 OFFSET $4;
+`
+	rows, err := r.db.Query(ctx, q, locationID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]StockTakeRow, 0, 16)
+	for rows.Next() {
+		s, err := scanStockTakeRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	return out, rows.Err()
+}
+
+// lockStockTake returns the session's status and location. Count submissions
+// take a share lock and approval an update lock, so no count lands after the
+// variances were posted.
+func lockStockTake(ctx context.Context, tx pgx.Tx, id string, forUpdate bool) (status, locationID string, err error) {
+	q := `
+SELECT status, location_id::text
+FROM stock_takes
+WHERE id = $1::uuid
+FOR SHARE;
+`
+	if forUpdate {
+		q = `
+SELECT status, location_id::text
+FROM stock_takes
+WHERE id = $1::uuid
+FOR UPDATE;
+`
+	}
+	err = tx.QueryRow(ctx, q, id).Scan(&status, &locationID)
+	return status, locationID, err
+}
+
+// RecordCounts upserts the counter's count of each product.
+func (r *StockTakeRepo) RecordCounts(ctx context.Context, id, counterID string, counts []CountInputRow) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, _, err := lockStockTake(ctx, tx, id, false)
+	if err != nil {
+		return err
+	}
+	if status != "open" {
+		return errInvalidTransition
+	}
+
+	for _, c := range counts {
+		ct, err := tx.Exec(ctx, `
+INSERT INTO stock_take_counts (stock_take_line_id, counter_id, qty)
+SELECT l.id, $3::uuid, $4
+FROM stock_take_lines l
+WHERE l.stock_take_id = $1::uuid
+  AND l.product_id = $2::uuid
+ON CONFLICT (stock_take_line_id, counter_id)
+DO UPDATE SET qty = EXCLUDED.qty, updated_at = now();
+`, id, c.ProductID, counterID, c.Qty)
+		if err != nil {
+			return err
+		}
+		if ct.RowsAffected() == 0 {
+			return errProductMissing
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Approve posts each counted line's variance (counted - expected) against the
+// location's current on_hand rather than overwriting it with the count, so
+// sales and receipts made while the count ran are kept. A decrease takes lots
+// first-expiry-first-out, expired lots included, since those are the likeliest
+// to have been discarded.
+func (r *StockTakeRepo) Approve(ctx context.Context, id string, approvedBy *string) (*StockTakeRow, []LineRow, []AdjustmentRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, locID, err := lockStockTake(ctx, tx, id, true)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if status != "open" {
+		return nil, nil, nil, errInvalidTransition
+	}
+
+	lines, err := listLines(ctx, tx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	adjustments := make([]AdjustmentRow, 0, 8)
+	for _, l := range lines {
+		if len(l.Counts) == 0 {
+			continue
+		}
+		counted := 0
+		for _, c := range l.Counts {
+			counted += c.Qty
+		}
+		delta := counted - l.ExpectedQty
+		if delta == 0 {
+			continue
+		}
+
+		lvl, err := trxpg.LockStockLevel(ctx, tx, l.ProductID, locID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		after := lvl.OnHand + delta
+		if after < lvl.Reserved {
+			return nil, nil, nil, fmt.Errorf("%w: product=%s on_hand=%d reserved=%d", errBelowReserved, l.ProductID, after, lvl.Reserved)
+		}
+		if delta < 0 {
+			if _, err := trxpg.TakeLots(ctx, tx, l.ProductID, locID, -delta, true); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		if err := trxpg.AdjustStockLevel(ctx, tx, l.ProductID, locID, delta, 0); err != nil {
+			return nil, nil, nil, err
+		}
+		if err := trxpg.RecordStockThreshold(ctx, tx, l.ProductID, lvl.TotalAvailable); err != nil {
+			return nil, nil, nil, err
+		}
+
+		a := AdjustmentRow{ProductID: l.ProductID, LocationID: locID, QtyDelta: delta, OnHandBefore: lvl.OnHand, OnHandAfter: after}
+		if err := tx.QueryRow(ctx, `
+INSERT INTO stock_adjustments (product_id, location_id, qty_delta, on_hand_before, on_hand_after, reason, stock_take_id, created_by)
+VALUES ($1::uuid, $2::uuid, $3, $4, $5, 'stock_take', $6::uuid, $7::uuid)
+RETURNING id::text, created_at;
+`, a.ProductID, a.LocationID, a.QtyDelta, a.OnHandBefore, a.OnHandAfter, id, approvedBy).Scan(&a.ID, &a.CreatedAt); err != nil {
+			return nil, nil, nil, err
+		}
+		adjustments = append(adjustments, a)
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE stock_takes
+SET status = 'approved', approved_by = $2::uuid, approved_at = now(), updated_at = now()
+WHERE id = $1::uuid;
+`, id, approvedBy); err != nil {
+		return nil, nil, nil, err
+	}
+
+	out, err := getStockTake(ctx, tx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+	return out, lines, adjustments, nil
+}
+
+func (r *StockTakeRepo) Cancel(ctx context.Context, id string) (*StockTakeRow, []LineRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, _, err := lockStockTake(ctx, tx, id, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if status != "open" {
+		return nil, nil, errInvalidTransition
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE stock_takes
+SET status = 'cancelled', cancelled_at = now(), updated_at = now()
+WHERE id = $1::uuid;
+`, id); err != nil {
+		return nil, nil, err
+	}
+
+	out, err := getStockTake(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := listLines(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return out, lines, nil
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	stocktakeuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/stocktake"
+)
+
+func TestStockTake_ApprovePostsVarianceAboveReservations(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	productID := testutil.MustInsertProduct(t, db, "SKU-ST-1", "Minyak 1L", nil, 10, 2)
+	alice := testutil.MustInsertAdmin(t, db, "alice@example.com")
+	bob := testutil.MustInsertAdmin(t, db, "bob@example.com")
+
+	uc := stocktakeuc.New(NewStockTakeStoreAdapter(NewStockTakeRepo(db)))
+
+	st, err := uc.Create(ctx, stocktakeuc.CreateInput{ProductIDs: []string{productID}, CreatedBy: &alice})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(st.Lines) != 1 || st.Lines[0].ExpectedQty != 10 {
+		t.Fatalf("unexpected snapshot: %+v", st.Lines)
+	}
+	if _, err := uc.Create(ctx, stocktakeuc.CreateInput{ProductIDs: []string{productID}}); err != stocktakeuc.ErrSessionOpen {
+		t.Fatalf("expected ErrSessionOpen got=%v", err)
+	}
+
+	// a count that leaves less on hand than the 2 reserved units is refused
+	if _, err := uc.RecordCounts(ctx, st.ID, stocktakeuc.CountInput{CounterID: alice, Counts: []stocktakeuc.CountLine{{ProductID: productID, Qty: 1}}}); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if _, err := uc.Approve(ctx, st.ID, &alice); err != stocktakeuc.ErrBelowReserved {
+		t.Fatalf("expected ErrBelowReserved got=%v", err)
+	}
+
+	// two counters split the shelf; alice's recount replaces her first count
+	if _, err := uc.RecordCounts(ctx, st.ID, stocktakeuc.CountInput{CounterID: alice, Counts: []stocktakeuc.CountLine{{ProductID: productID, Qty: 4}}}); err != nil {
+		t.Fatalf("recount: %v", err)
+	}
+	got, err := uc.RecordCounts(ctx, st.ID, stocktakeuc.CountInput{CounterID: bob, Counts: []stocktakeuc.CountLine{{ProductID: productID, Qty: 3}}})
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	l := got.Lines[0]
+	if len(l.Counts) != 2 || l.CountedQty == nil || *l.CountedQty != 7 || *l.Variance != -3 {
+		t.Fatalf("unexpected line: %+v", l)
+	}
+	if got.Summary == nil || got.Summary.VarianceLines != 1 || got.Summary.NetVariance != -3 {
+		t.Fatalf("unexpected summary: %+v", got.Summary)
+	}
+
+	out, err := uc.Approve(ctx, st.ID, &alice)
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if out.StockTake.Status != stocktakeuc.StatusApproved || len(out.Adjustments) != 1 {
+		t.Fatalf("unexpected result: %+v", out)
+	}
+	if a := out.Adjustments[0]; a.QtyDelta != -3 || a.OnHandBefore != 10 || a.OnHandAfter != 7 {
+		t.Fatalf("unexpected adjustment: %+v", a)
+	}
+	if _, err := uc.RecordCounts(ctx, st.ID, stocktakeuc.CountInput{CounterID: bob, Counts: []stocktakeuc.CountLine{{ProductID: productID, Qty: 1}}}); err != stocktakeuc.ErrInvalidTransition {
+		t.Fatalf("expected ErrInvalidTransition got=%v", err)
+	}
+
+	var onHand, reserved, levelOnHand int
+	if err := db.QueryRow(ctx, `
+SELECT p.stock_on_hand, p.stock_reserved, sl.on_hand
+FROM products p
+JOIN stock_levels sl ON sl.product_id = p.id
+WHERE p.id = $1::uuid;
+`, productID).Scan(&onHand, &reserved, &levelOnHand); err != nil {
+		t.Fatalf("read stock: %v", err)
+	}
+	if onHand != 7 || reserved != 2 || levelOnHand != 7 {
+		t.Fatalf("unexpected stock on_hand=%d reserved=%d level=%d", onHand, reserved, levelOnHand)
+	}
+}
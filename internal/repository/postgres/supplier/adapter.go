@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+
+	supplieruc "github.com/riolentius/cahaya-gading-backend/internal/usecase/supplier"
+)
+
+type SupplierStoreAdapter struct {
+	repo *SupplierRepo
+}
+
+func NewSupplierStoreAdapter(repo *SupplierRepo) *SupplierStoreAdapter {
+	return &SupplierStoreAdapter{repo: repo}
+}
+
+func (a *SupplierStoreAdapter) Create(ctx context.Context, in supplieruc.CreateInput) (*supplieruc.Supplier, error) {
+	row, err := a.repo.Create(ctx, SupplierRow{
+		Code:        in.Code,
+		Name:        in.Name,
+		ContactName: in.ContactName,
+		Phone:       in.Phone,
+		Email:       in.Email,
+		Address:     in.Address,
+		Note:        in.Note,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, supplieruc.ErrCodeConflict
+		}
+		return nil, err
+	}
+	return mapSupplier(row), nil
+}
+
+func (a *SupplierStoreAdapter) GetByID(ctx context.Context, id string) (*supplieruc.Supplier, error) {
+	row, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, supplieruc.ErrNotFound
+		}
+		return nil, err
+	}
+	return mapSupplier(row), nil
+}
+
+func (a *SupplierStoreAdapter) List(ctx context.Context, q supplieruc.ListQuery) ([]supplieruc.Supplier, error) {
+	rows, err := a.repo.List(ctx, q.Search, q.IsActive, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]supplieruc.Supplier, 0, len(rows))
+	for i := range rows {
+		out = append(out, *mapSupplier(&rows[i]))
+	}
+	return out, nil
+}
+
+func (a *SupplierStoreAdapter) Update(ctx context.Context, id string, in supplieruc.UpdateInput) (*supplieruc.Supplier, error) {
+	row, err := a.repo.Update(ctx, id, in.Code, in.Name, in.ContactName, in.Phone, in.Email, in.Address, in.Note, in.IsActive)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, supplieruc.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, supplieruc.ErrCodeConflict
+		}
+		return nil, err
+	}
+	return mapSupplier(row), nil
+}
+
+func mapSupplier(r *SupplierRow) *supplieruc.Supplier {
+	return &supplieruc.Supplier{
+		ID:          r.ID,
+		Code:        r.Code,
+		Name:        r.Name,
+		ContactName: r.ContactName,
+		Phone:       r.Phone,
+		Email:       r.Email,
+		Address:     r.Address,
+		Note:        r.Note,
+		IsActive:    r.IsActive,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+// Compile-time check
+var _ supplieruc.Store = (*SupplierStoreAdapter)(nil)
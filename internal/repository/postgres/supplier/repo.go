@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SupplierRow struct {
+	ID          string
+	Code        string
+	Name        string
+	ContactName *string
+	Phone       *string
+	Email       *string
+	Address     *string
+	Note        *string
+	IsActive    bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type SupplierRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSupplierRepo(db *pgxpool.Pool) *SupplierRepo {
+	return &SupplierRepo{db: db}
+}
+
+const supplierColumns = `
+  id::text,
+  code,
+  name,
+  contact_name,
+  phone,
+  email,
+  address,
+  note,
+  is_active,
+  created_at,
+  updated_at
+`
+
+func scanSupplierRow(row pgx.Row) (*SupplierRow, error) {
+	var out SupplierRow
+	if err := row.Scan(
+		&out.ID, &out.Code, &out.Name, &out.ContactName, &out.Phone,
+		&out.Email, &out.Address, &out.Note, &out.IsActive, &out.CreatedAt, &out.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *SupplierRepo) Create(ctx context.Context, in SupplierRow) (*SupplierRow, error) {
+	q := `
+INSERT INTO suppliers (code, name, contact_name, phone, email, address, note)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING ` + supplierColumns + `;
+`
+	return scanSupplierRow(r.db.QueryRow(ctx, q, in.Code, in.Name, in.ContactName, in.Phone, in.Email, in.Address, in.Note))
+}
+
+func (r *SupplierRepo) GetByID(ctx context.Context, id string) (*SupplierRow, error) {
+	q := `SELECT ` + supplierColumns + ` FROM suppliers WHERE id = $1::uuid;`
+	return scanSupplierRow(r.db.QueryRow(ctx, q, id))
+}
+
+func (r *SupplierRepo) List(ctx context.Context, search *string, isActive *bool, limit, offset int) ([]SupplierRow, error) {
+	q := `
+SELECT ` + supplierColumns + `
+FROM suppliers
+WHERE ($1::text IS NULL OR code ILIKE '%' || $1 || '%' OR name ILIKE '%' || $1 || '%')
+  AND ($2::boolean IS NULL OR is_active = $2)
+ORDER BY name, code
+LIMIT $3 OFFSET $4;
+`
+	rows, err := r.db.Query(ctx, q, search, isActive, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]SupplierRow, 0, 16)
+	for rows.Next() {
+		s, err := scanSupplierRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	return out, rows.Err()
+}
+
+func (r *SupplierRepo) Update(ctx context.Context, id string, code, name, contactName, phone, email, address, note *string, isActive *bool) (*SupplierRow, error) {
+	q := `
+UPDATE suppliers
+SET
+  code = COALESCE($2, code),
+  name = COALESCE($3, name),
+  contact_name = COALESCE($4, contact_name),
+  phone = COALESCE($5, phone),
+  email = COALESCE($6, email),
+  address = COALESCE($7, address),
+  note = COALESCE($8, note),
+  is_active = COALESCE($9, is_active),
+  updated_at = now()
+WHERE id = $1::uuid
+RETURNING ` + supplierColumns + `;
+`
+	return scanSupplierRow(r.db.QueryRow(ctx, q, id, code, name, contactName, phone, email, address, note, isActive))
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
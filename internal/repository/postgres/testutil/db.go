@@ -58,7 +58,21 @@ TRUNCATE
   products,
   customer_addresses,
   customers,
-  customer_categories
+  customer_categories,
+  idempotency_keys,
+  bank_statement_imports,
+  promotions,
+  exchange_rates,
+  cash_shifts,
+  goods_receipts,
+  stock_events,
+  purchase_orders,
+  suppliers,
+  stock_transfers,
+  stock_takes,
+  stock_adjustments,
+  shipments,
+  audit_log
 RESTART IDENTITY CASCADE;
 `)
 	if err != nil {
@@ -47,10 +47,17 @@ func MustInsertProduct(t *testing.T, db *pgxpool.Pool, sku, name string, descrip
 	t.Helper()
 
 	var id string
+	// the stock is held at the default location
 	err := db.QueryRow(context.Background(), `
-		INSERT INTO products (sku, name, description, is_active, stock_on_hand, stock_reserved)
-		VALUES ($1, $2, $3, true, $4, $5)
-		RETURNING id::text
+		WITH p AS (
+			INSERT INTO products (sku, name, description, is_active, stock_on_hand, stock_reserved)
+			VALUES ($1, $2, $3, true, $4, $5)
+			RETURNING id, stock_on_hand, stock_reserved
+		), sl AS (
+			INSERT INTO stock_levels (product_id, location_id, on_hand, reserved)
+			SELECT p.id, l.id, p.stock_on_hand, p.stock_reserved FROM p, locations l WHERE l.is_default
+		)
+		SELECT id::text FROM p
 	`, sku, name, description, stockOnHand, stockReserved).Scan(&id)
 
 	require.NoError(t, err)
@@ -72,3 +79,21 @@ func MustInsertPrice(t *testing.T, db *pgxpool.Pool, productID string, categoryI
 	require.NotEmpty(t, id)
 	return id
 }
+
+func MustInsertAdmin(t *testing.T, db *pgxpool.Pool, email string) string {
+	t.Helper()
+
+	uniq := fmt.Sprintf("%d", time.Now().UnixNano())
+	emailUniq := fmt.Sprintf("%s.%s", uniq, email)
+
+	var id string
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO admins (email, password_hash)
+		VALUES ($1, 'x')
+		RETURNING id::text
+	`, emailUniq).Scan(&id)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	return id
+}
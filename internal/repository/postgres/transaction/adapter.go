@@ -3,12 +3,15 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/big"
 	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	pricingpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/pricing"
 	trxuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
 )
 
@@ -39,24 +42,58 @@ func (a *TransactionStoreAdapter) Create(ctx context.Context, in trxuc.CreateInp
 		return nil, err
 	}
 
+	// payment terms: explicit override, else the customer category default
+	termsDays := 0
+	if in.PaymentTermsDays != nil {
+		termsDays = *in.PaymentTermsDays
+	} else {
+		termsDays, err = getCustomerPaymentTermsDays(ctx, tx, in.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	locationID, err := ResolveLocation(ctx, tx, in.LocationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, trxuc.ErrLocationMissing
+		}
+		return nil, err
+	}
+
+	status := in.Status
+	if status == "" {
+		status = trxuc.StatusDraft
+	}
+
 	// create transaction
-	trxRow, err := insertTransaction(ctx, tx, in.CustomerID, in.Notes)
+	trxRow, err := insertTransaction(ctx, tx, in.CustomerID, locationID, status, in.Notes, termsDays, in.DueDate)
 	if err != nil {
 		return nil, err
 	}
+	if err := RecordEvent(ctx, tx, trxRow.ID, EventCreated, nil, &trxRow.Status, nil); err != nil {
+		return nil, err
+	}
 
 	var (
 		items      []trxuc.Item
+		priced     []trxuc.PricedLine
 		totalCents float64
 		currency   string
 	)
+	if in.Currency != nil {
+		currency = *in.Currency
+	}
 
 	customerCategoryID, err := getCustomerCategoryID(ctx, tx, in.CustomerID)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, it := range in.Items {
+	// base units per stock product across all items, for 'base_units' price tiers
+	stockProductIDs := make([]string, len(in.Items))
+	baseUnits := map[string]float64{}
+	for i, it := range in.Items {
 		if err := ensureProductExists(ctx, tx, it.ProductID); err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
 				return nil, trxuc.ErrProductMissing
@@ -64,19 +101,30 @@ func (a *TransactionStoreAdapter) Create(ctx context.Context, in trxuc.CreateInp
 			return nil, err
 		}
 
-		cur, unitStr, err := getEffectivePriceAmount(ctx, tx, it.ProductID, customerCategoryID)
+		stockProductID, packSize, err := getStockRule(ctx, tx, it.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		stockProductIDs[i] = stockProductID
+		baseUnits[stockProductID] += float64(it.Qty) * packSize
+	}
+
+	for i, it := range in.Items {
+		qty := pricingpg.PriceQty{Line: it.Qty, BaseUnits: baseUnits[stockProductIDs[i]]}
+		price, err := pricingpg.ResolveEffectivePrice(ctx, tx, it.ProductID, customerCategoryID, qty, nil)
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
 				return nil, trxuc.ErrPriceMissing
 			}
 			return nil, err
 		}
+		cur, unitStr := price.Currency, price.Amount
 
-		// enforce single-currency for v1
+		// one transaction, one currency: foreign money is handled at payment time
 		if currency == "" {
 			currency = cur
 		} else if currency != cur {
-			return nil, errors.New("multi-currency not supported")
+			return nil, fmt.Errorf("%w: product=%s currency=%s transaction=%s", trxuc.ErrMixedCurrency, it.ProductID, cur, currency)
 		}
 
 		unit, err := strconv.ParseFloat(unitStr, 64)
@@ -93,10 +141,64 @@ func (a *TransactionStoreAdapter) Create(ctx context.Context, in trxuc.CreateInp
 		}
 
 		items = append(items, mapTrxItemRow(itemRow))
+
+		if in.Discounter != nil {
+			category, err := getProductCategory(ctx, tx, it.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			priced = append(priced, trxuc.PricedLine{
+				Index:           i,
+				ProductID:       it.ProductID,
+				ProductCategory: category,
+				Qty:             it.Qty,
+				UnitAmount:      itemRow.UnitAmount,
+				LineTotal:       itemRow.LineTotal,
+			})
+		}
 	}
 
-	// update total
-	finalRow, err := updateTransactionTotal(ctx, tx, trxRow.ID, currency, formatMoney(totalCents))
+	// promotions: one discount row per (line, promotion)
+	var discounts []trxuc.Discount
+	discountTotal := new(big.Rat)
+	if in.Discounter != nil {
+		applied, err := in.Discounter(currency, priced)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range applied {
+			amt, ok := new(big.Rat).SetString(d.Amount)
+			if !ok || d.LineIndex < 0 || d.LineIndex >= len(items) {
+				return nil, trxuc.ErrInvalidInput
+			}
+			promotionID := d.PromotionID
+			row, err := insertTransactionDiscount(ctx, tx, trxRow.ID, &items[d.LineIndex].ID, &promotionID, d.PromotionCode, d.Description, d.Amount)
+			if err != nil {
+				return nil, err
+			}
+			discountTotal.Add(discountTotal, amt)
+			discounts = append(discounts, mapTrxDiscountRow(row))
+		}
+	}
+
+	// created as pending -> reserve stock; as completed -> reserve + commit.
+	// Same DB transaction, so a failure leaves no header behind.
+	if status == trxuc.StatusPending || status == trxuc.StatusCompleted {
+		if err := reserveStockForTx(ctx, tx, trxRow.ID); err != nil {
+			return nil, err
+		}
+	}
+	if status == trxuc.StatusCompleted {
+		if err := commitStockForTx(ctx, tx, trxRow.ID); err != nil {
+			if errors.Is(err, ErrExpiredStock) {
+				return nil, fmt.Errorf("%w: %v", trxuc.ErrExpiredStock, err)
+			}
+			return nil, err
+		}
+	}
+
+	// update totals
+	finalRow, err := updateTransactionTotal(ctx, tx, trxRow.ID, currency, formatMoney(totalCents), discountTotal.FloatString(2))
 	if err != nil {
 		return nil, err
 	}
@@ -107,6 +209,7 @@ func (a *TransactionStoreAdapter) Create(ctx context.Context, in trxuc.CreateInp
 
 	out := mapTrxRow(finalRow)
 	out.Items = items
+	out.Discounts = discounts
 	return out, nil
 }
 
@@ -133,14 +236,31 @@ func (a *TransactionStoreAdapter) ProductExists(ctx context.Context, productID s
 	return err == nil, err
 }
 
-func (a *TransactionStoreAdapter) GetAvailableStock(ctx context.Context, productID string) (int, error) {
+// GetAvailableStock reads the level at the location, less stock in expired
+// lots; no stock_levels row means the product was never stocked there.
+func (a *TransactionStoreAdapter) GetAvailableStock(ctx context.Context, productID string, locationID *string) (int, error) {
 	const q = `
-SELECT stock_on_hand - stock_reserved
-FROM products
-WHERE id = $1::uuid;
+SELECT COALESCE((
+  SELECT sl.on_hand - sl.reserved - COALESCE((
+    SELECT SUM(sk.qty_on_hand)
+    FROM stock_lots sk
+    WHERE sk.product_id = sl.product_id
+      AND sk.location_id = sl.location_id
+      AND sk.expires_at < CURRENT_DATE
+  ), 0)
+  FROM stock_levels sl
+  WHERE sl.product_id = $1::uuid
+    AND sl.location_id = l.id
+), 0)
+FROM locations l
+WHERE l.is_active = true
+  AND (($2::uuid IS NULL AND l.is_default) OR l.id = $2::uuid);
 `
 	var avail int
-	if err := a.db.QueryRow(ctx, q, productID).Scan(&avail); err != nil {
+	if err := a.db.QueryRow(ctx, q, productID, locationID).Scan(&avail); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, trxuc.ErrLocationMissing
+		}
 		return 0, err
 	}
 	return avail, nil
@@ -175,7 +295,7 @@ func (a *TransactionStoreAdapter) Fulfill(ctx context.Context, transactionID str
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	status, err := lockTransactionStatus(ctx, tx, transactionID)
+	status, err := LockTransactionStatus(ctx, tx, transactionID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, trxuc.ErrTransactionMissing
@@ -193,7 +313,25 @@ func (a *TransactionStoreAdapter) Fulfill(ctx context.Context, transactionID str
 		return nil, trxuc.ErrInvalidTransition
 	}
 
+	// shipments in progress ship through their own documents
+	var open bool
+	if err := tx.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1 FROM shipments
+  WHERE transaction_id = $1::uuid
+    AND status IN ('picking', 'packed')
+);
+`, transactionID).Scan(&open); err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, trxuc.ErrShipmentsOpen
+	}
+
 	if err := commitStockForTx(ctx, tx, transactionID); err != nil {
+		if errors.Is(err, ErrExpiredStock) {
+			return nil, fmt.Errorf("%w: %v", trxuc.ErrExpiredStock, err)
+		}
 		return nil, err
 	}
 
@@ -211,17 +349,25 @@ func (a *TransactionStoreAdapter) Fulfill(ctx context.Context, transactionID str
 
 func mapTrxRow(r *TransactionRow) *trxuc.Transaction {
 	return &trxuc.Transaction{
-		ID:          r.ID,
-		CustomerID:  r.CustomerID,
-		Status:      r.Status,
-		Currency:    r.Currency,
-		TotalAmount: r.TotalAmount,
-		Notes:       r.Notes,
-		CreatedAt:   mustTime(r.CreatedAt),
-		UpdatedAt:   mustTime(r.UpdatedAt),
+		ID:                r.ID,
+		CustomerID:        r.CustomerID,
+		LocationID:        r.LocationID,
+		Status:            r.Status,
+		FulfillmentStatus: r.FulfillmentStatus,
+		Currency:          r.Currency,
+		SubtotalAmount:    r.SubtotalAmount,
+		DiscountAmount:    r.DiscountAmount,
+		TotalAmount:       r.TotalAmount,
+		Notes:             r.Notes,
+		PaymentTermsDays:  r.PaymentTermsDays,
+		DueDate:           r.DueDate.Format(dateLayout),
+		CreatedAt:         mustTime(r.CreatedAt),
+		UpdatedAt:         mustTime(r.UpdatedAt),
 	}
 }
 
+const dateLayout = "2006-01-02"
+
 func mapTrxItemRow(r *TransactionItemRow) trxuc.Item {
 	return trxuc.Item{
 		ID:            r.ID,
@@ -235,6 +381,18 @@ func mapTrxItemRow(r *TransactionItemRow) trxuc.Item {
 	}
 }
 
+func mapTrxDiscountRow(r *TransactionDiscountRow) trxuc.Discount {
+	return trxuc.Discount{
+		ID:                r.ID,
+		TransactionItemID: r.TransactionItemID,
+		PromotionID:       r.PromotionID,
+		PromotionCode:     r.PromotionCode,
+		Description:       r.Description,
+		Amount:            r.Amount,
+		CreatedAt:         r.CreatedAt,
+	}
+}
+
 func mustTime(v any) time.Time {
 	t, ok := v.(time.Time)
 	if ok {
@@ -255,7 +413,7 @@ func (a *TransactionStoreAdapter) ReserveStockForTx(ctx context.Context, transac
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	status, err := lockTransactionStatus(ctx, tx, transactionID)
+	status, err := LockTransactionStatus(ctx, tx, transactionID)
 	if err != nil {
 		return err
 	}
@@ -278,7 +436,7 @@ func (a *TransactionStoreAdapter) ReleaseStockForTx(ctx context.Context, transac
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	status, err := lockTransactionStatus(ctx, tx, transactionID)
+	status, err := LockTransactionStatus(ctx, tx, transactionID)
 	if err != nil {
 		return err
 	}
@@ -294,6 +452,14 @@ func (a *TransactionStoreAdapter) ReleaseStockForTx(ctx context.Context, transac
 	return tx.Commit(ctx)
 }
 
+func (a *TransactionStoreAdapter) ListExpiredReservations(ctx context.Context, cutoff time.Time, limit int) ([]string, error) {
+	return a.repo.ListExpiredReservations(ctx, cutoff, limit)
+}
+
+func (a *TransactionStoreAdapter) ExpireReservation(ctx context.Context, transactionID string) (bool, error) {
+	return a.repo.ExpireReservation(ctx, transactionID)
+}
+
 func (a *TransactionStoreAdapter) CommitStockForTx(ctx context.Context, transactionID string) error {
 	tx, err := a.repo.Begin(ctx)
 	if err != nil {
@@ -301,7 +467,7 @@ func (a *TransactionStoreAdapter) CommitStockForTx(ctx context.Context, transact
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	status, err := lockTransactionStatus(ctx, tx, transactionID)
+	status, err := LockTransactionStatus(ctx, tx, transactionID)
 	if err != nil {
 		return err
 	}
@@ -311,6 +477,9 @@ func (a *TransactionStoreAdapter) CommitStockForTx(ctx context.Context, transact
 	}
 
 	if err := commitStockForTx(ctx, tx, transactionID); err != nil {
+		if errors.Is(err, ErrExpiredStock) {
+			return fmt.Errorf("%w: %v", trxuc.ErrExpiredStock, err)
+		}
 		return err
 	}
 
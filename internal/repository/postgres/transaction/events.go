@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/riolentius/cahaya-gading-backend/internal/actor"
+)
+
+// Transaction event kinds (transaction_events.kind).
+const (
+	EventCreated            = "created"
+	EventStatusChanged      = "status_changed"
+	EventFulfillmentChanged = "fulfillment_changed"
+	EventPaymentPosted      = "payment_posted"
+	EventShipmentCreated    = "shipment_created"
+	EventShipmentPacked     = "shipment_packed"
+	EventShipmentShipped    = "shipment_shipped"
+	EventShipmentDelivered  = "shipment_delivered"
+	EventShipmentCancelled  = "shipment_cancelled"
+	EventReservationExpired = "reservation_expired"
+)
+
+// RecordEvent appends to the transaction's timeline in the caller's DB
+// transaction. The actor is the admin in ctx (actor.AdminID), none for
+// background jobs. data is stored as JSON and may be nil.
+func RecordEvent(ctx context.Context, db Execer, transactionID, kind string, from, to *string, data map[string]any) error {
+	var actorID *string
+	if id := actor.AdminID(ctx); id != "" {
+		actorID = &id
+	}
+
+	const q = `
+INSERT INTO transaction_events (transaction_id, kind, from_value, to_value, actor_id, data)
+VALUES ($1::uuid, $2, $3, $4, $5::uuid, $6);
+`
+	var payload any
+	if data != nil {
+		payload = data
+	}
+	_, err := db.Exec(ctx, q, transactionID, kind, from, to, actorID, payload)
+	return err
+}
@@ -6,20 +6,27 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type TransactionRow struct {
-	ID          string
-	CustomerID  string
-	Status      string
-	Currency    string
-	TotalAmount string
-	Notes       *string
-	CreatedAt   interface{}
-	UpdatedAt   interface{}
+	ID                string
+	CustomerID        string
+	LocationID        string
+	Status            string
+	FulfillmentStatus string
+	Currency          string
+	SubtotalAmount    string
+	DiscountAmount    string
+	TotalAmount       string
+	Notes             *string
+	PaymentTermsDays  int
+	DueDate           time.Time
+	CreatedAt         interface{}
+	UpdatedAt         interface{}
 }
 
 type TrxItemForFulfill struct {
@@ -57,25 +64,23 @@ type queryer interface {
 
 func (r *TransactionRepo) Create(ctx context.Context, customerID string, notes *string) (*TransactionRow, error) {
 	const q = `
-INSERT INTO transactions (customer_id, notes)
-VALUES ($1::uuid, $2)
-RETURNING id::text, customer_id::text, status, currency, total_amount::text, notes, created_at, updated_at;
+INSERT INTO transactions (customer_id, notes, location_id)
+VALUES ($1::uuid, $2, (SELECT id FROM locations WHERE is_default))
+RETURNING id::text, customer_id::text, location_id::text, status, fulfillment_status, currency, subtotal_amount::text, discount_amount::text, total_amount::text, notes, payment_terms_days, due_date, created_at, updated_at;
 `
 	row := r.db.QueryRow(ctx, q, customerID, notes)
 
-	var out TransactionRow
-	if err := row.Scan(&out.ID, &out.CustomerID, &out.Status, &out.Currency, &out.TotalAmount, &out.Notes, &out.CreatedAt, &out.UpdatedAt); err != nil {
-		return nil, err
-	}
-	return &out, nil
+	return scanTransactionRow(row)
 }
 
 func (r *TransactionRepo) Begin(ctx context.Context) (pgx.Tx, error) {
 	return r.db.BeginTx(ctx, pgx.TxOptions{})
 }
 
+// ensureCustomerExists and ensureProductExists treat archived rows as missing:
+// they cannot be used on new transactions.
 func ensureCustomerExists(ctx context.Context, q queryer, customerID string) error {
-	const sql = `SELECT 1 FROM customers WHERE id = $1::uuid`
+	const sql = `SELECT 1 FROM customers WHERE id = $1::uuid AND deleted_at IS NULL`
 	var one int
 	if err := q.QueryRow(ctx, sql, customerID).Scan(&one); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -87,7 +92,7 @@ func ensureCustomerExists(ctx context.Context, q queryer, customerID string) err
 }
 
 func ensureProductExists(ctx context.Context, q queryer, productID string) error {
-	const sql = `SELECT 1 FROM products WHERE id = $1::uuid`
+	const sql = `SELECT 1 FROM products WHERE id = $1::uuid AND deleted_at IS NULL`
 	var one int
 	if err := q.QueryRow(ctx, sql, productID).Scan(&one); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -98,45 +103,16 @@ func ensureProductExists(ctx context.Context, q queryer, productID string) error
 	return nil
 }
 
-func getEffectivePriceAmount(
-	ctx context.Context,
-	tx pgx.Tx,
-	productID string,
-	categoryID *string, // can be nil
-) (currency string, amount string, err error) {
-	const q = `
-SELECT currency, amount::text
-FROM product_prices
-WHERE product_id = $1::uuid
-  AND (
-    ($2::uuid IS NOT NULL AND category_id = $2::uuid)
-    OR category_id IS NULL
-  )
-  AND valid_from <= now()
-  AND (valid_to IS NULL OR now() < valid_to)
-ORDER BY (category_id IS NULL) ASC, valid_from DESC, created_at DESC
-LIMIT 1;
-`
-	// note: if categoryID is nil, $2::uuid becomes NULL, query falls back to category_id IS NULL.
-	if err := tx.QueryRow(ctx, q, productID, categoryID).Scan(&currency, &amount); err != nil {
-		return "", "", err
-	}
-	return currency, amount, nil
-}
-
-func insertTransaction(ctx context.Context, tx pgx.Tx, customerID string, notes *string) (*TransactionRow, error) {
+// insertTransaction creates the header; due_date defaults to today + paymentTermsDays.
+func insertTransaction(ctx context.Context, tx pgx.Tx, customerID string, locationID string, status string, notes *string, paymentTermsDays int, dueDate *time.Time) (*TransactionRow, error) {
 	const q = `
-INSERT INTO transactions (customer_id, notes)
-VALUES ($1::uuid, $2)
-RETURNING id::text, customer_id::text, status, currency, total_amount::text, notes, created_at, updated_at;
+INSERT INTO transactions (customer_id, notes, payment_terms_days, due_date, location_id, status)
+VALUES ($1::uuid, $2, $3, COALESCE($4::date, CURRENT_DATE + $3::int), $5::uuid, $6)
+RETURNING id::text, customer_id::text, location_id::text, status, fulfillment_status, currency, subtotal_amount::text, discount_amount::text, total_amount::text, notes, payment_terms_days, due_date, created_at, updated_at;
 `
-	row := tx.QueryRow(ctx, q, customerID, notes)
+	row := tx.QueryRow(ctx, q, customerID, notes, paymentTermsDays, dueDate, locationID, status)
 
-	var out TransactionRow
-	if err := row.Scan(&out.ID, &out.CustomerID, &out.Status, &out.Currency, &out.TotalAmount, &out.Notes, &out.CreatedAt, &out.UpdatedAt); err != nil {
-		return nil, err
-	}
-	return &out, nil
+	return scanTransactionRow(row)
 }
 
 func insertTransactionItem(ctx context.Context, tx pgx.Tx, transactionID string, productID string, qty int, unitAmount string, lineTotal string) (*TransactionItemRow, error) {
@@ -154,24 +130,120 @@ RETURNING id::text, transaction_id::text, product_id::text, qty, unit_amount::te
 	return &out, nil
 }
 
-func updateTransactionTotal(ctx context.Context, tx pgx.Tx, transactionID string, currency string, totalAmount string) (*TransactionRow, error) {
+// updateTransactionTotal stores the priced header; total_amount = subtotal - discount.
+func updateTransactionTotal(ctx context.Context, tx pgx.Tx, transactionID string, currency string, subtotalAmount string, discountAmount string) (*TransactionRow, error) {
 	const q = `
 UPDATE transactions
 SET currency = $2,
-    total_amount = $3::numeric,
+    subtotal_amount = $3::numeric,
+    discount_amount = $4::numeric,
+    total_amount = $3::numeric - $4::numeric,
     updated_at = now()
 WHERE id = $1::uuid
-RETURNING id::text, customer_id::text, status, currency, total_amount::text, notes, created_at, updated_at;
+RETURNING id::text, customer_id::text, location_id::text, status, fulfillment_status, currency, subtotal_amount::text, discount_amount::text, total_amount::text, notes, payment_terms_days, due_date, created_at, updated_at;
+`
+	row := tx.QueryRow(ctx, q, transactionID, currency, subtotalAmount, discountAmount)
+
+	return scanTransactionRow(row)
+}
+
+type TransactionDiscountRow struct {
+	ID                string
+	TransactionItemID *string
+	PromotionID       *string
+	PromotionCode     string
+	Description       string
+	Amount            string
+	CreatedAt         time.Time
+}
+
+func insertTransactionDiscount(ctx context.Context, tx pgx.Tx, transactionID string, itemID *string, promotionID *string, code, description, amount string) (*TransactionDiscountRow, error) {
+	const q = `
+INSERT INTO transaction_discounts (transaction_id, transaction_item_id, promotion_id, promotion_code, description, amount)
+VALUES ($1::uuid, $2::uuid, $3::uuid, $4, $5, $6::numeric)
+RETURNING id::text, transaction_item_id::text, promotion_id::text, promotion_code, description, amount::text, created_at;
+`
+	var out TransactionDiscountRow
+	if err := tx.QueryRow(ctx, q, transactionID, itemID, promotionID, code, description, amount).Scan(
+		&out.ID, &out.TransactionItemID, &out.PromotionID, &out.PromotionCode, &out.Description, &out.Amount, &out.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *TransactionRepo) ListDiscounts(ctx context.Context, transactionID string) ([]TransactionDiscountRow, error) {
+	const q = `
+SELECT id::text, transaction_item_id::text, promotion_id::text, promotion_code, description, amount::text, created_at
+FROM transaction_discounts
+WHERE transaction_id = $1::uuid
+ORDER BY created_at ASC, id ASC;
 `
-	row := tx.QueryRow(ctx, q, transactionID, currency, totalAmount)
+	rows, err := r.db.Query(ctx, q, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]TransactionDiscountRow, 0, 4)
+	for rows.Next() {
+		var d TransactionDiscountRow
+		if err := rows.Scan(&d.ID, &d.TransactionItemID, &d.PromotionID, &d.PromotionCode, &d.Description, &d.Amount, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// getProductCategory returns the product's category code (nil when unset).
+func getProductCategory(ctx context.Context, q queryer, productID string) (*string, error) {
+	const sql = `SELECT category FROM products WHERE id = $1::uuid`
+	var out *string
+	if err := q.QueryRow(ctx, sql, productID).Scan(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
+func scanTransactionRow(row pgx.Row) (*TransactionRow, error) {
 	var out TransactionRow
-	if err := row.Scan(&out.ID, &out.CustomerID, &out.Status, &out.Currency, &out.TotalAmount, &out.Notes, &out.CreatedAt, &out.UpdatedAt); err != nil {
+	if err := row.Scan(
+		&out.ID,
+		&out.CustomerID,
+		&out.LocationID,
+		&out.Status,
+		&out.FulfillmentStatus,
+		&out.Currency,
+		&out.SubtotalAmount,
+		&out.DiscountAmount,
+		&out.TotalAmount,
+		&out.Notes,
+		&out.PaymentTermsDays,
+		&out.DueDate,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
+// getCustomerPaymentTermsDays returns the default tempo of the customer's category (0 = cash).
+func getCustomerPaymentTermsDays(ctx context.Context, tx pgx.Tx, customerID string) (int, error) {
+	const q = `
+SELECT COALESCE(cc.payment_terms_days, 0)
+FROM customers c
+LEFT JOIN customer_categories cc ON cc.id = c.category_id
+WHERE c.id = $1::uuid
+`
+	var days int
+	if err := tx.QueryRow(ctx, q, customerID).Scan(&days); err != nil {
+		return 0, err
+	}
+	return days, nil
+}
+
 func getCustomerCategoryID(ctx context.Context, tx pgx.Tx, customerID string) (*string, error) {
 	const q = `
 SELECT category_id::text
@@ -185,7 +257,8 @@ WHERE id = $1::uuid
 	return cat, nil // can be nil (customer without category)
 }
 
-func lockTransactionStatus(ctx context.Context, tx pgx.Tx, transactionID string) (string, error) {
+// LockTransactionStatus locks the transaction row and returns its status.
+func LockTransactionStatus(ctx context.Context, tx pgx.Tx, transactionID string) (string, error) {
 	const q = `
 SELECT status
 FROM transactions
@@ -199,14 +272,17 @@ FOR UPDATE;
 	return status, nil
 }
 
+// listTransactionStockMoves returns the base units still to ship per line;
+// shipped quantities are no longer reserved.
 func listTransactionStockMoves(ctx context.Context, tx pgx.Tx, transactionID string) ([]TrxStockMove, error) {
 	const q = `
 SELECT
   COALESCE(p.base_product_id, p.id)::text AS stock_product_id,
-  (ti.qty * p.pack_size)::numeric::text AS base_qty
+  ((ti.qty - ti.qty_shipped) * p.pack_size)::numeric::text AS base_qty
 FROM transaction_items ti
 JOIN products p ON p.id = ti.product_id
-WHERE ti.transaction_id = $1::uuid;
+WHERE ti.transaction_id = $1::uuid
+  AND ti.qty > ti.qty_shipped;
 `
 	rows, err := tx.Query(ctx, q, transactionID)
 	if err != nil {
@@ -238,45 +314,32 @@ WHERE ti.transaction_id = $1::uuid;
 	return out, rows.Err()
 }
 
-func lockProductStock(ctx context.Context, tx pgx.Tx, productID string) (onHand int, reserved int, err error) {
-	const q = `
-SELECT stock_on_hand, stock_reserved
-FROM products
-WHERE id = $1::uuid
-FOR UPDATE;
-`
-	if err := tx.QueryRow(ctx, q, productID).Scan(&onHand, &reserved); err != nil {
-		return 0, 0, err
+// updateTransactionStatus changes the status and records the transition on
+// the timeline. Callers hold the row lock.
+func updateTransactionStatus(ctx context.Context, tx pgx.Tx, transactionID string, status string) (*TransactionRow, error) {
+	var from string
+	if err := tx.QueryRow(ctx, `SELECT status FROM transactions WHERE id = $1::uuid`, transactionID).Scan(&from); err != nil {
+		return nil, err
 	}
-	return onHand, reserved, nil
-}
-
-func deductStockOnHand(ctx context.Context, tx pgx.Tx, productID string, qty int) error {
-	const q = `
-UPDATE products
-SET stock_on_hand = stock_on_hand - $2,
-    updated_at = now()
-WHERE id = $1::uuid;
-`
-	_, err := tx.Exec(ctx, q, productID, qty)
-	return err
-}
 
-func updateTransactionStatus(ctx context.Context, tx pgx.Tx, transactionID string, status string) (*TransactionRow, error) {
 	const q = `
 UPDATE transactions
 SET status = $2,
     updated_at = now()
 WHERE id = $1::uuid
-RETURNING id::text, customer_id::text, status, currency, total_amount::text, notes, created_at, updated_at;
+RETURNING id::text, customer_id::text, location_id::text, status, fulfillment_status, currency, subtotal_amount::text, discount_amount::text, total_amount::text, notes, payment_terms_days, due_date, created_at, updated_at;
 `
-	row := tx.QueryRow(ctx, q, transactionID, status)
-
-	var out TransactionRow
-	if err := row.Scan(&out.ID, &out.CustomerID, &out.Status, &out.Currency, &out.TotalAmount, &out.Notes, &out.CreatedAt, &out.UpdatedAt); err != nil {
+	out, err := scanTransactionRow(tx.QueryRow(ctx, q, transactionID, status))
+	if err != nil {
 		return nil, err
 	}
-	return &out, nil
+
+	if from != status {
+		if err := RecordEvent(ctx, tx, transactionID, EventStatusChanged, &from, &status, nil); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
 }
 
 func getStockRule(ctx context.Context, q queryer, productID string) (stockProductID string, packSize float64, err error) {
@@ -302,77 +365,20 @@ WHERE id = $1::uuid;
 	return stockProductID, ps, nil
 }
 
-func reserveStock(ctx context.Context, tx pgx.Tx, productID string, qty int) error {
-	const q = `
-UPDATE products
-SET stock_reserved = stock_reserved + $2,
-    updated_at = now()
-WHERE id = $1::uuid;
-`
-	_, err := tx.Exec(ctx, q, productID, qty)
-	return err
-}
-
-func releaseReservedStock(ctx context.Context, tx pgx.Tx, productID string, qty int) error {
-	const q = `
-UPDATE products
-SET stock_reserved = stock_reserved - $2,
-    updated_at = now()
-WHERE id = $1::uuid
-  AND stock_reserved >= $2;
-`
-	ct, err := tx.Exec(ctx, q, productID, qty)
-	if err != nil {
-		return err
-	}
-	if ct.RowsAffected() == 0 {
-		return fmt.Errorf("reserved stock insufficient: stock_product=%s required=%d", productID, qty)
-	}
-	return nil
-}
-
+// commitStockForTx ships everything not shipped yet in one go (a counter sale
+// or a fulfillment without shipment documents) and marks it delivered.
 func commitStockForTx(ctx context.Context, tx pgx.Tx, transactionID string) error {
-	moves, err := listTransactionStockMoves(ctx, tx, transactionID)
+	lines, err := listUnshippedItems(ctx, tx, transactionID)
 	if err != nil {
 		return err
 	}
-	if len(moves) == 0 {
+	if len(lines) == 0 {
 		return pgx.ErrNoRows
 	}
-
-	need := map[string]int{}
-	for _, m := range moves {
-		need[m.StockProductID] += m.BaseQty
-	}
-
-	for stockID, qty := range need {
-		onHand, reserved, err := lockProductStock(ctx, tx, stockID)
-		if err != nil {
-			return err
-		}
-
-		// since we are committing reserved stock, ensure reservation exists
-		if reserved < qty {
-			return fmt.Errorf("reserved stock insufficient: stock_product=%s reserved=%d required=%d", stockID, reserved, qty)
-		}
-		if onHand < qty {
-			return fmt.Errorf("on_hand insufficient: stock_product=%s on_hand=%d required=%d", stockID, onHand, qty)
-		}
-
-		// commit: on_hand -= qty, reserved -= qty
-		const q = `
-UPDATE products
-SET stock_on_hand = stock_on_hand - $2,
-    stock_reserved = stock_reserved - $2,
-    updated_at = now()
-WHERE id = $1::uuid;
-`
-		if _, err := tx.Exec(ctx, q, stockID, qty); err != nil {
-			return err
-		}
+	if _, err := ShipItems(ctx, tx, transactionID, lines); err != nil {
+		return err
 	}
-
-	return nil
+	return SetFulfillmentStatus(ctx, tx, transactionID, "delivered")
 }
 
 func reserveStockForTx(ctx context.Context, tx pgx.Tx, transactionID string) error {
@@ -383,6 +389,10 @@ func reserveStockForTx(ctx context.Context, tx pgx.Tx, transactionID string) err
 	if len(moves) == 0 {
 		return pgx.ErrNoRows
 	}
+	locationID, err := getTransactionLocation(ctx, tx, transactionID)
+	if err != nil {
+		return err
+	}
 
 	need := map[string]int{}
 	for _, m := range moves {
@@ -390,22 +400,29 @@ func reserveStockForTx(ctx context.Context, tx pgx.Tx, transactionID string) err
 	}
 
 	for stockID, qty := range need {
-		onHand, reserved, err := lockProductStock(ctx, tx, stockID)
+		lvl, err := LockStockLevel(ctx, tx, stockID, locationID)
 		if err != nil {
 			return err
 		}
 
-		available := onHand - reserved
+		// only the transaction's location counts, not stock held elsewhere,
+		// and stock in expired lots cannot be sold
+		available := lvl.OnHand - lvl.Reserved - lvl.Expired
 		if available < qty {
-			return fmt.Errorf("insufficient stock: stock_product=%s available=%d required=%d", stockID, available, qty)
+			return fmt.Errorf("insufficient stock: stock_product=%s location=%s available=%d required=%d", stockID, locationID, available, qty)
 		}
 
-		if err := reserveStock(ctx, tx, stockID, qty); err != nil {
+		if err := AdjustStockLevel(ctx, tx, stockID, locationID, 0, qty); err != nil {
+			return err
+		}
+		if err := RecordStockThreshold(ctx, tx, stockID, lvl.TotalAvailable); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	// starts the reservation TTL
+	_, err = tx.Exec(ctx, `UPDATE transactions SET reserved_at = now() WHERE id = $1::uuid`, transactionID)
+	return err
 }
 
 func releaseStockForTx(ctx context.Context, tx pgx.Tx, transactionID string) error {
@@ -416,6 +433,10 @@ func releaseStockForTx(ctx context.Context, tx pgx.Tx, transactionID string) err
 	if len(moves) == 0 {
 		return pgx.ErrNoRows
 	}
+	locationID, err := getTransactionLocation(ctx, tx, transactionID)
+	if err != nil {
+		return err
+	}
 
 	need := map[string]int{}
 	for _, m := range moves {
@@ -424,15 +445,28 @@ func releaseStockForTx(ctx context.Context, tx pgx.Tx, transactionID string) err
 
 	for stockID, qty := range need {
 		// lock to serialize concurrent operations
-		_, _, err := lockProductStock(ctx, tx, stockID)
+		lvl, err := LockStockLevel(ctx, tx, stockID, locationID)
 		if err != nil {
 			return err
 		}
+		if lvl.Reserved < qty {
+			return fmt.Errorf("reserved stock insufficient: stock_product=%s required=%d", stockID, qty)
+		}
 
-		if err := releaseReservedStock(ctx, tx, stockID, qty); err != nil {
+		if err := AdjustStockLevel(ctx, tx, stockID, locationID, 0, -qty); err != nil {
+			return err
+		}
+		if err := RecordStockThreshold(ctx, tx, stockID, lvl.TotalAvailable); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	// shipments still being picked or packed have nothing left to ship
+	_, err = tx.Exec(ctx, `
+UPDATE shipments
+SET status = 'cancelled', cancelled_at = now(), updated_at = now()
+WHERE transaction_id = $1::uuid
+  AND status IN ('picking', 'packed');
+`, transactionID)
+	return err
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/require"
 
+	"github.com/riolentius/cahaya-gading-backend/internal/actor"
 	txuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
 )
 
@@ -44,7 +45,7 @@ func mustQueryStr(t *testing.T, pool *pgxpool.Pool, q string, args ...any) strin
 // seed minimal dataset:
 // - customer_categories (optional)
 // - customers
-// - products (+ stock_on_hand)
+// - products (+ stock_on_hand, held at the default location)
 // - product_prices (default price)
 func seedCustomerProductPrice(t *testing.T, pool *pgxpool.Pool) (customerID string, productID string) {
 	t.Helper()
@@ -64,9 +65,15 @@ func seedCustomerProductPrice(t *testing.T, pool *pgxpool.Pool) (customerID stri
 	`, categoryID)
 
 	productID = mustQueryStr(t, pool, `
-		INSERT INTO products (sku, name, description, is_active, stock_on_hand, stock_reserved)
-		VALUES ('SKU-TX-001', 'Teh Botol', 'Drink', true, 10, 0)
-		RETURNING id::text;
+		WITH p AS (
+			INSERT INTO products (sku, name, description, is_active, stock_on_hand, stock_reserved)
+			VALUES ('SKU-TX-001', 'Teh Botol', 'Drink', true, 10, 0)
+			RETURNING id, stock_on_hand
+		), sl AS (
+			INSERT INTO stock_levels (product_id, location_id, on_hand)
+			SELECT p.id, l.id, p.stock_on_hand FROM p, locations l WHERE l.is_default
+		)
+		SELECT id::text FROM p;
 	`)
 
 	// default price (category_id NULL)
@@ -206,3 +213,107 @@ func TestTransaction_InsufficientStock(t *testing.T) {
 	require.Error(t, err)
 	require.ErrorIs(t, err, txuc.ErrInsufficientStock)
 }
+
+// Pending transactions reserved longer than the TTL are cancelled and their
+// reserved stock released; paid and partially shipped ones are kept.
+func TestTransaction_ExpireReservations(t *testing.T) {
+	pool := mustTestPool(t)
+	repo := NewTransactionRepo(pool)
+	store := NewTransactionStoreAdapter(repo, pool)
+	uc := txuc.New(store)
+	ctx := context.Background()
+
+	customerID, productID := seedCustomerProductPrice(t, pool)
+
+	newPending := func(qty int) string {
+		t.Helper()
+		tx, err := uc.Create(ctx, txuc.CreateInput{
+			CustomerID: customerID,
+			Status:     txuc.StatusPending,
+			Items:      []txuc.CreateItemIn{{ProductID: productID, Qty: qty}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, txuc.StatusPending, tx.Status)
+		// backdate the reservation past the TTL
+		mustExec(t, pool, `
+			UPDATE transactions SET reserved_at = now() - interval '2 hours'
+			WHERE id = $1::uuid
+		`, tx.ID)
+		return tx.ID
+	}
+
+	abandoned := newPending(3)
+	paid := newPending(2)
+	mustExec(t, pool, `
+		INSERT INTO payments (transaction_id, method, amount) VALUES ($1::uuid, 'cash', 1000)
+	`, paid)
+
+	// one of two units has left the warehouse
+	partial := newPending(2)
+	itemID := mustQueryStr(t, pool, `SELECT id::text FROM transaction_items WHERE transaction_id = $1::uuid`, partial)
+	dbtx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	_, err = ShipItems(ctx, dbtx, partial, []ShipLine{{ItemID: itemID, Qty: 1}})
+	require.NoError(t, err)
+	require.NoError(t, dbtx.Commit(ctx))
+
+	n, err := uc.ExpireReservations(ctx, time.Hour, 50)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, 1)
+
+	require.Equal(t, txuc.StatusCancelled, mustQueryStr(t, pool, `SELECT status FROM transactions WHERE id = $1::uuid`, abandoned))
+	require.Equal(t, txuc.StatusPending, mustQueryStr(t, pool, `SELECT status FROM transactions WHERE id = $1::uuid`, paid))
+	require.Equal(t, txuc.StatusPending, mustQueryStr(t, pool, `SELECT status FROM transactions WHERE id = $1::uuid`, partial))
+
+	// 2 held for the paid one, 1 still to ship on the partial one
+	var reserved int
+	err = pool.QueryRow(ctx, `SELECT stock_reserved FROM products WHERE id = $1::uuid`, productID).Scan(&reserved)
+	require.NoError(t, err)
+	require.Equal(t, 3, reserved)
+
+	// already cancelled: nothing left to expire
+	ok, err := store.ExpireReservation(ctx, abandoned)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+// Every transition lands in the timeline with the admin who made it.
+func TestTransaction_Timeline(t *testing.T) {
+	pool := mustTestPool(t)
+	repo := NewTransactionRepo(pool)
+	store := NewTransactionStoreAdapter(repo, pool)
+	uc := txuc.New(store)
+
+	customerID, productID := seedCustomerProductPrice(t, pool)
+	adminID := mustQueryStr(t, pool, `
+		INSERT INTO admins (email, password_hash)
+		VALUES ('timeline.`+time.Now().Format("150405.000")+`@example.com', 'x')
+		RETURNING id::text;
+	`)
+	ctx := actor.WithAdminID(context.Background(), adminID)
+
+	tx, err := uc.Create(ctx, txuc.CreateInput{
+		CustomerID: customerID,
+		Status:     txuc.StatusPending,
+		Items:      []txuc.CreateItemIn{{ProductID: productID, Qty: 2}},
+	})
+	require.NoError(t, err)
+
+	_, err = uc.Fulfill(ctx, tx.ID)
+	require.NoError(t, err)
+
+	view, err := uc.GetViewByID(context.Background(), tx.ID)
+	require.NoError(t, err)
+
+	kinds := make([]string, 0, len(view.Timeline))
+	for _, e := range view.Timeline {
+		kinds = append(kinds, e.Kind)
+		require.NotNil(t, e.ActorID)
+		require.Equal(t, adminID, *e.ActorID)
+	}
+	require.Equal(t, []string{EventCreated, EventFulfillmentChanged, EventFulfillmentChanged, EventStatusChanged}, kinds)
+
+	last := view.Timeline[len(view.Timeline)-1]
+	require.Equal(t, txuc.StatusPending, *last.From)
+	require.Equal(t, txuc.StatusCompleted, *last.To)
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+// ListExpiredReservations returns pending transactions reserved before cutoff,
+// oldest first. Transactions with a posted payment are left alone: the
+// customer came back, so their stock stays held. So are transactions the
+// warehouse has started on (anything shipped or an open shipment): goods have
+// left or are being picked, and the receivable stays open.
+func (r *TransactionRepo) ListExpiredReservations(ctx context.Context, cutoff time.Time, limit int) ([]string, error) {
+	const q = `
+SELECT t.id::text
+FROM transactions t
+WHERE t.status = 'pending'
+  AND t.fulfillment_status = 'unfulfilled'
+  AND t.reserved_at < $1
+  AND NOT EXISTS (
+    SELECT 1
+    FROM shipments s
+    WHERE s.transaction_id = t.id
+      AND s.status IN ('picking', 'packed')
+  )
+  AND NOT EXISTS (
+    SELECT 1
+    FROM payments p
+    WHERE p.transaction_id = t.id
+      AND p.status = 'posted'
+  )
+ORDER BY t.reserved_at
+LIMIT $2;
+`
+	rows, err := r.db.Query(ctx, q, cutoff, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]string, 0, limit)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// ExpireReservation releases a pending transaction's reserved stock and
+// cancels it in one DB transaction. False when it is no longer pending or
+// fulfillment has started since it was listed.
+func (r *TransactionRepo) ExpireReservation(ctx context.Context, transactionID string) (bool, error) {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, err := LockTransactionStatus(ctx, tx, transactionID)
+	if err != nil {
+		return false, err
+	}
+	if status != "pending" {
+		return false, nil
+	}
+	var started bool
+	if err := tx.QueryRow(ctx, `
+SELECT t.fulfillment_status <> 'unfulfilled'
+  OR EXISTS (
+    SELECT 1 FROM shipments s
+    WHERE s.transaction_id = t.id
+      AND s.status IN ('picking', 'packed')
+  )
+FROM transactions t
+WHERE t.id = $1::uuid;
+`, transactionID).Scan(&started); err != nil {
+		return false, err
+	}
+	if started {
+		return false, nil
+	}
+
+	if err := releaseStockForTx(ctx, tx, transactionID); err != nil {
+		return false, err
+	}
+	if err := RecordEvent(ctx, tx, transactionID, EventReservationExpired, nil, nil, nil); err != nil {
+		return false, err
+	}
+	if _, err := updateTransactionStatus(ctx, tx, transactionID, "cancelled"); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrOverShipped: a line would ship more than was ordered.
+var ErrOverShipped = errors.New("shipped quantity exceeds ordered quantity")
+
+// ShipLine is a quantity of one transaction item leaving the location.
+type ShipLine struct {
+	ItemID string
+	Qty    int
+}
+
+// ShipItems commits the reserved stock of the given item quantities: lots are
+// picked first-expiry-first-out, on_hand and reserved drop at the
+// transaction's location, COGS accrues at the current average cost and
+// qty_shipped grows. The fulfillment status becomes shipped once every line
+// is, else partially_shipped. Callers hold the transaction row lock.
+func ShipItems(ctx context.Context, tx pgx.Tx, transactionID string, lines []ShipLine) (fullyShipped bool, err error) {
+	locationID, err := getTransactionLocation(ctx, tx, transactionID)
+	if err != nil {
+		return false, err
+	}
+
+	need := map[string]int{}
+	for _, l := range lines {
+		var productID string
+		var remaining int
+		if err := tx.QueryRow(ctx, `
+SELECT product_id::text, qty - qty_shipped
+FROM transaction_items
+WHERE id = $1::uuid
+  AND transaction_id = $2::uuid;
+`, l.ItemID, transactionID).Scan(&productID, &remaining); err != nil {
+			return false, err
+		}
+		if l.Qty > remaining {
+			return false, fmt.Errorf("%w: item=%s remaining=%d requested=%d", ErrOverShipped, l.ItemID, remaining, l.Qty)
+		}
+
+		stockID, packSize, err := getStockRule(ctx, tx, productID)
+		if err != nil {
+			return false, err
+		}
+		if packSize != math.Trunc(packSize) {
+			return false, errors.New("non-integer base_qty not supported in v1")
+		}
+		need[stockID] += l.Qty * int(packSize)
+	}
+
+	// lock stock rows in a stable order
+	stockIDs := make([]string, 0, len(need))
+	for id := range need {
+		stockIDs = append(stockIDs, id)
+	}
+	sort.Strings(stockIDs)
+
+	for _, stockID := range stockIDs {
+		qty := need[stockID]
+		lvl, err := LockStockLevel(ctx, tx, stockID, locationID)
+		if err != nil {
+			return false, err
+		}
+
+		// since we are committing reserved stock, ensure reservation exists
+		if lvl.Reserved < qty {
+			return false, fmt.Errorf("reserved stock insufficient: stock_product=%s reserved=%d required=%d", stockID, lvl.Reserved, qty)
+		}
+		if lvl.OnHand < qty {
+			return false, fmt.Errorf("on_hand insufficient: stock_product=%s on_hand=%d required=%d", stockID, lvl.OnHand, qty)
+		}
+
+		// pick lots FEFO; expired lots are never sold
+		takes, err := TakeLots(ctx, tx, stockID, locationID, qty, false)
+		if err != nil {
+			return false, err
+		}
+		if err := recordLotAllocations(ctx, tx, transactionID, takes); err != nil {
+			return false, err
+		}
+
+		// commit: on_hand -= qty, reserved -= qty
+		if err := AdjustStockLevel(ctx, tx, stockID, locationID, -qty, -qty); err != nil {
+			return false, err
+		}
+	}
+
+	for _, l := range lines {
+		if err := accrueShippedCOGS(ctx, tx, l); err != nil {
+			return false, err
+		}
+	}
+
+	var open int
+	if err := tx.QueryRow(ctx, `
+SELECT COUNT(*)
+FROM transaction_items
+WHERE transaction_id = $1::uuid
+  AND qty > qty_shipped;
+`, transactionID).Scan(&open); err != nil {
+		return false, err
+	}
+
+	status := "partially_shipped"
+	if open == 0 {
+		status = "shipped"
+	}
+	if err := SetFulfillmentStatus(ctx, tx, transactionID, status); err != nil {
+		return false, err
+	}
+	return open == 0, nil
+}
+
+// accrueShippedCOGS adds the shipped quantity's cost at the stock product's
+// current average cost (per sold unit, so packs cost avg_cost * pack_size);
+// unit_cost is the average over everything shipped so far. Lines whose stock
+// product was never costed keep NULL. Callers hold the product row locks.
+func accrueShippedCOGS(ctx context.Context, tx pgx.Tx, l ShipLine) error {
+	const q = `
+UPDATE transaction_items ti
+SET unit_cost = CASE WHEN sp.avg_cost IS NULL THEN ti.unit_cost ELSE round(
+      (COALESCE(ti.unit_cost, 0) * ti.qty_shipped + $2 * p.pack_size * sp.avg_cost) / (ti.qty_shipped + $2), 4
+    ) END,
+    cogs_amount = CASE WHEN sp.avg_cost IS NULL THEN ti.cogs_amount ELSE
+      COALESCE(ti.cogs_amount, 0) + round($2 * p.pack_size * sp.avg_cost, 2)
+    END,
+    qty_shipped = ti.qty_shipped + $2,
+    updated_at = now()
+FROM products p
+JOIN products sp ON sp.id = COALESCE(p.base_product_id, p.id)
+WHERE ti.product_id = p.id
+  AND ti.id = $1::uuid;
+`
+	_, err := tx.Exec(ctx, q, l.ItemID, l.Qty)
+	return err
+}
+
+// listUnshippedItems returns every line's quantity still to ship.
+func listUnshippedItems(ctx context.Context, tx pgx.Tx, transactionID string) ([]ShipLine, error) {
+	rows, err := tx.Query(ctx, `
+SELECT id::text, qty - qty_shipped
+FROM transaction_items
+WHERE transaction_id = $1::uuid
+  AND qty > qty_shipped
+ORDER BY created_at, id;
+`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]ShipLine, 0, 8)
+	for rows.Next() {
+		var l ShipLine
+		if err := rows.Scan(&l.ItemID, &l.Qty); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// SetFulfillmentStatus records where the transaction's delivery stands, and
+// the change on its timeline.
+func SetFulfillmentStatus(ctx context.Context, tx pgx.Tx, transactionID, status string) error {
+	var from string
+	if err := tx.QueryRow(ctx, `
+SELECT fulfillment_status
+FROM transactions
+WHERE id = $1::uuid;
+`, transactionID).Scan(&from); err != nil {
+		return err
+	}
+	if from == status {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE transactions
+SET fulfillment_status = $2,
+    updated_at = now()
+WHERE id = $1::uuid;
+`, transactionID, status); err != nil {
+		return err
+	}
+	return RecordEvent(ctx, tx, transactionID, EventFulfillmentChanged, &from, &status, nil)
+}
+
+// CompleteTransaction marks a fully shipped transaction completed.
+func CompleteTransaction(ctx context.Context, tx pgx.Tx, transactionID string) error {
+	_, err := updateTransactionStatus(ctx, tx, transactionID, "completed")
+	return err
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Execer is satisfied by *pgxpool.Pool and pgx.Tx, so threshold events are
+// written in the same DB transaction as the stock change.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// RecordStockThreshold writes a stock event when the product's available stock
+// crossed its reorder point since availableBefore. Call it after the stock
+// update, while still holding the product row lock.
+func RecordStockThreshold(ctx context.Context, db Execer, stockProductID string, availableBefore int) error {
+	const q = `
+INSERT INTO stock_events (product_id, kind, available_before, available_after, reorder_point)
+SELECT
+  id,
+  CASE WHEN stock_on_hand - stock_reserved <= reorder_point THEN 'low_stock' ELSE 'restocked' END,
+  $2,
+  stock_on_hand - stock_reserved,
+  reorder_point
+FROM products
+WHERE id = $1::uuid
+  AND reorder_point IS NOT NULL
+  AND ($2 > reorder_point) <> (stock_on_hand - stock_reserved > reorder_point);
+`
+	_, err := db.Exec(ctx, q, stockProductID, availableBefore)
+	return err
+}
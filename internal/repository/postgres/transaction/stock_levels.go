@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// StockLevel is a stock product's position at one location. Expired is the
+// part of OnHand in expired lots. TotalAvailable is the product-wide
+// on_hand - reserved, which reorder points are checked against.
+type StockLevel struct {
+	OnHand         int
+	Reserved       int
+	Expired        int
+	TotalAvailable int
+}
+
+// ResolveLocation returns the active location's id, or the default location's
+// when locationID is nil. pgx.ErrNoRows when it does not exist or is inactive.
+func ResolveLocation(ctx context.Context, tx pgx.Tx, locationID *string) (string, error) {
+	const q = `
+SELECT id::text
+FROM locations
+WHERE is_active = true
+  AND (($1::uuid IS NULL AND is_default) OR id = $1::uuid);
+`
+	var id string
+	if err := tx.QueryRow(ctx, q, locationID).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// LockStockLevel locks the stock product row, which serializes every stock
+// change of the product across locations, then returns its level at the
+// location (creating an empty row on first use). The product row is locked
+// before the stock_levels insert so two callers never both hold its key-share
+// lock while waiting to upgrade it.
+func LockStockLevel(ctx context.Context, tx pgx.Tx, stockProductID, locationID string) (StockLevel, error) {
+	var out StockLevel
+	if err := tx.QueryRow(ctx, `
+SELECT stock_on_hand - stock_reserved
+FROM products
+WHERE id = $1::uuid
+FOR UPDATE;
+`, stockProductID).Scan(&out.TotalAvailable); err != nil {
+		return StockLevel{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+INSERT INTO stock_levels (product_id, location_id)
+VALUES ($1::uuid, $2::uuid)
+ON CONFLICT (product_id, location_id) DO NOTHING;
+`, stockProductID, locationID); err != nil {
+		return StockLevel{}, err
+	}
+
+	if err := tx.QueryRow(ctx, `
+SELECT
+  sl.on_hand,
+  sl.reserved,
+  COALESCE((
+    SELECT SUM(qty_on_hand)
+    FROM stock_lots
+    WHERE product_id = sl.product_id
+      AND location_id = sl.location_id
+      AND expires_at < CURRENT_DATE
+  ), 0)
+FROM stock_levels sl
+WHERE sl.product_id = $1::uuid
+  AND sl.location_id = $2::uuid
+FOR UPDATE OF sl;
+`, stockProductID, locationID).Scan(&out.OnHand, &out.Reserved, &out.Expired); err != nil {
+		return StockLevel{}, err
+	}
+	return out, nil
+}
+
+// AdjustStockLevel moves on_hand and reserved at one location by the given
+// deltas and keeps the product totals in step. Callers hold LockStockLevel.
+func AdjustStockLevel(ctx context.Context, tx pgx.Tx, stockProductID, locationID string, onHandDelta, reservedDelta int) error {
+	ct, err := tx.Exec(ctx, `
+UPDATE stock_levels
+SET on_hand = on_hand + $3,
+    reserved = reserved + $4,
+    updated_at = now()
+WHERE product_id = $1::uuid
+  AND location_id = $2::uuid;
+`, stockProductID, locationID, onHandDelta, reservedDelta)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return fmt.Errorf("stock level missing: stock_product=%s location=%s", stockProductID, locationID)
+	}
+
+	_, err = tx.Exec(ctx, `
+UPDATE products
+SET stock_on_hand = stock_on_hand + $2,
+    stock_reserved = stock_reserved + $3,
+    updated_at = now()
+WHERE id = $1::uuid;
+`, stockProductID, onHandDelta, reservedDelta)
+	return err
+}
+
+// getTransactionLocation returns the location the transaction ships from.
+func getTransactionLocation(ctx context.Context, tx pgx.Tx, transactionID string) (string, error) {
+	const q = `SELECT location_id::text FROM transactions WHERE id = $1::uuid`
+	var id string
+	if err := tx.QueryRow(ctx, q, transactionID).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
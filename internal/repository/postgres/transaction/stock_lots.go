@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrExpiredStock: what is left at the location to cover the quantity is in
+// expired lots, which cannot be sold or moved.
+var ErrExpiredStock = errors.New("expired stock")
+
+// LotTake is a quantity taken from one lot. LotID is nil for the part taken
+// from untracked stock (received without lot number or expiry date).
+type LotTake struct {
+	LotID     *string
+	LotNumber *string
+	ExpiresAt *time.Time
+	Qty       int
+}
+
+type lotRow struct {
+	id        string
+	lotNumber *string
+	expiresAt *time.Time
+	qty       int
+}
+
+// AddLot books qty base units into a new lot. sourceLotID is set when the lot
+// was moved in from another location.
+func AddLot(ctx context.Context, tx pgx.Tx, stockProductID, locationID string, lotNumber *string, expiresAt *time.Time, qty int, goodsReceiptID, sourceLotID *string) (string, error) {
+	const q = `
+INSERT INTO stock_lots (product_id, location_id, lot_number, expires_at, qty_received, qty_on_hand, goods_receipt_id, source_lot_id)
+VALUES ($1::uuid, $2::uuid, $3, $4::date, $5, $5, $6::uuid, $7::uuid)
+RETURNING id::text;
+`
+	var id string
+	if err := tx.QueryRow(ctx, q, stockProductID, locationID, lotNumber, expiresAt, qty, goodsReceiptID, sourceLotID).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// TakeLots picks qty base units at the location first-expiry-first-out: lots
+// by expiry date (undated lots last), then untracked stock. Expired lots are
+// skipped unless includeExpired, in which case they go first (a write-off).
+// Call it before the on_hand decrease, while holding LockStockLevel.
+func TakeLots(ctx context.Context, tx pgx.Tx, stockProductID, locationID string, qty int, includeExpired bool) ([]LotTake, error) {
+	var onHand, tracked int
+	if err := tx.QueryRow(ctx, `
+SELECT
+  sl.on_hand,
+  COALESCE((
+    SELECT SUM(qty_on_hand)
+    FROM stock_lots
+    WHERE product_id = sl.product_id
+      AND location_id = sl.location_id
+  ), 0)
+FROM stock_levels sl
+WHERE sl.product_id = $1::uuid
+  AND sl.location_id = $2::uuid;
+`, stockProductID, locationID).Scan(&onHand, &tracked); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+SELECT id::text, lot_number, expires_at::timestamptz, qty_on_hand
+FROM stock_lots
+WHERE product_id = $1::uuid
+  AND location_id = $2::uuid
+  AND qty_on_hand > 0
+  AND ($3::boolean OR expires_at IS NULL OR expires_at >= CURRENT_DATE)
+ORDER BY expires_at NULLS LAST, created_at, id
+FOR UPDATE;
+`, stockProductID, locationID, includeExpired)
+	if err != nil {
+		return nil, err
+	}
+	lots := make([]lotRow, 0, 4)
+	for rows.Next() {
+		var l lotRow
+		if err := rows.Scan(&l.id, &l.lotNumber, &l.expiresAt, &l.qty); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	remaining := qty
+	out := make([]LotTake, 0, len(lots)+1)
+	for _, l := range lots {
+		if remaining == 0 {
+			break
+		}
+		take := min(l.qty, remaining)
+		if _, err := tx.Exec(ctx, `
+UPDATE stock_lots
+SET qty_on_hand = qty_on_hand - $2,
+    updated_at = now()
+WHERE id = $1::uuid;
+`, l.id, take); err != nil {
+			return nil, err
+		}
+		id := l.id
+		out = append(out, LotTake{LotID: &id, LotNumber: l.lotNumber, ExpiresAt: l.expiresAt, Qty: take})
+		remaining -= take
+	}
+
+	if remaining > 0 {
+		if untracked := onHand - tracked; remaining > untracked {
+			if !includeExpired {
+				return nil, fmt.Errorf("%w: stock_product=%s location=%s required=%d", ErrExpiredStock, stockProductID, locationID, qty)
+			}
+			return nil, fmt.Errorf("on_hand insufficient: stock_product=%s location=%s required=%d", stockProductID, locationID, qty)
+		}
+		out = append(out, LotTake{Qty: remaining})
+	}
+	return out, nil
+}
+
+// recordLotAllocations keeps which lots a transaction's stock came from.
+func recordLotAllocations(ctx context.Context, tx pgx.Tx, transactionID string, takes []LotTake) error {
+	const q = `
+INSERT INTO stock_lot_allocations (transaction_id, lot_id, qty)
+VALUES ($1::uuid, $2::uuid, $3);
+`
+	for _, t := range takes {
+		if t.LotID == nil {
+			continue
+		}
+		if _, err := tx.Exec(ctx, q, transactionID, *t.LotID, t.Qty); err != nil {
+			return err
+		}
+	}
+	return nil
+}
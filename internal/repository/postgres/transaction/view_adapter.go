@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
 
 	"github.com/jackc/pgx/v5"
@@ -22,38 +23,56 @@ func (a *TransactionStoreAdapter) GetViewByID(ctx context.Context, id string) (*
 		return nil, err
 	}
 
+	discounts, err := a.repo.ListDiscounts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	pays, err := a.repo.GetViewPayments(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	events, err := a.repo.GetViewEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	// balanceDue = total - paid (string numeric)
 	balance := computeBalance(h.TotalAmount, h.PaidAmount)
 
 	out := &trxuc.TransactionView{
-		ID:            h.ID,
-		CustomerID:    h.CustomerID,
-		CustomerName:  h.CustomerName,
-		CategoryID:    h.CategoryID,
-		Status:        h.Status,
-		Currency:      h.Currency,
-		TotalAmount:   h.TotalAmount,
-		PaidAmount:    h.PaidAmount,
-		PaymentStatus: h.PaymentStatus,
-		BalanceDue:    balance,
-		Notes:         h.Notes,
-		CreatedAt:     h.CreatedAt,
-		UpdatedAt:     h.UpdatedAt,
-		Items:         make([]trxuc.ViewItem, 0, len(items)),
-		Payments:      make([]trxuc.ViewPay, 0, len(pays)),
+		ID:                h.ID,
+		CustomerID:        h.CustomerID,
+		CustomerName:      h.CustomerName,
+		CategoryID:        h.CategoryID,
+		Status:            h.Status,
+		FulfillmentStatus: h.FulfillmentStatus,
+		Currency:          h.Currency,
+		Subtotal:          h.Subtotal,
+		Discount:          h.Discount,
+		TotalAmount:       h.TotalAmount,
+		PaidAmount:        h.PaidAmount,
+		PaymentStatus:     h.PaymentStatus,
+		BalanceDue:        balance,
+		Notes:             h.Notes,
+		PaymentTerms:      h.PaymentTerms,
+		DueDate:           h.DueDate.Format(dateLayout),
+		CreatedAt:         h.CreatedAt,
+		UpdatedAt:         h.UpdatedAt,
+		Items:             make([]trxuc.ViewItem, 0, len(items)),
+		Discounts:         make([]trxuc.Discount, 0, len(discounts)),
+		Payments:          make([]trxuc.ViewPay, 0, len(pays)),
 	}
 
 	for _, it := range items {
 		out.Items = append(out.Items, trxuc.ViewItem{
+			ID:          it.ID,
 			ProductID:   it.ProductID,
 			SKU:         it.SKU,
 			ProductName: it.ProductName,
 			Qty:         it.Qty,
+			QtyShipped:  it.QtyShipped,
 			UnitAmount:  it.UnitAmount,
 			LineTotal:   it.LineTotal,
 
@@ -64,6 +83,10 @@ func (a *TransactionStoreAdapter) GetViewByID(ctx context.Context, id string) (*
 		})
 	}
 
+	for i := range discounts {
+		out.Discounts = append(out.Discounts, mapTrxDiscountRow(&discounts[i]))
+	}
+
 	for _, p := range pays {
 		out.Payments = append(out.Payments, trxuc.ViewPay{
 			ID:         p.ID,
@@ -78,6 +101,19 @@ func (a *TransactionStoreAdapter) GetViewByID(ctx context.Context, id string) (*
 		})
 	}
 
+	for _, e := range events {
+		out.Timeline = append(out.Timeline, trxuc.ViewEvent{
+			ID:         e.ID,
+			Kind:       e.Kind,
+			From:       e.FromValue,
+			To:         e.ToValue,
+			ActorID:    e.ActorID,
+			ActorEmail: e.ActorEmail,
+			Data:       json.RawMessage(e.Data),
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+
 	return out, nil
 }
 
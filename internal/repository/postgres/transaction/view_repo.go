@@ -6,25 +6,32 @@ import (
 )
 
 type TransactionViewHeaderRow struct {
-	ID            string
-	CustomerID    string
-	CustomerName  string
-	CategoryID    *string
-	Status        string
-	Currency      string
-	TotalAmount   string
-	PaidAmount    string
-	PaymentStatus string
-	Notes         *string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	ID                string
+	CustomerID        string
+	CustomerName      string
+	CategoryID        *string
+	Status            string
+	FulfillmentStatus string
+	Currency          string
+	Subtotal          string
+	Discount          string
+	TotalAmount       string
+	PaidAmount        string
+	PaymentStatus     string
+	Notes             *string
+	PaymentTerms      int
+	DueDate           time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
 }
 
 type TransactionViewItemRow struct {
+	ID          string
 	ProductID   string
 	SKU         *string
 	ProductName string
 	Qty         int
+	QtyShipped  int
 	UnitAmount  string
 	LineTotal   string
 
@@ -53,11 +60,16 @@ SELECT
   COALESCE(c.first_name,'') || CASE WHEN c.last_name IS NULL OR c.last_name='' THEN '' ELSE ' '||c.last_name END AS customer_name,
   c.category_id::text,
   t.status,
+  t.fulfillment_status,
   t.currency,
+  t.subtotal_amount::text,
+  t.discount_amount::text,
   t.total_amount::text,
   t.paid_amount::text,
   t.payment_status,
   t.notes,
+  t.payment_terms_days,
+  t.due_date,
   t.created_at,
   t.updated_at
 FROM transactions t
@@ -72,11 +84,16 @@ WHERE t.id = $1::uuid;
 		&out.CustomerName,
 		&out.CategoryID,
 		&out.Status,
+		&out.FulfillmentStatus,
 		&out.Currency,
+		&out.Subtotal,
+		&out.Discount,
 		&out.TotalAmount,
 		&out.PaidAmount,
 		&out.PaymentStatus,
 		&out.Notes,
+		&out.PaymentTerms,
+		&out.DueDate,
 		&out.CreatedAt,
 		&out.UpdatedAt,
 	); err != nil {
@@ -88,10 +105,12 @@ WHERE t.id = $1::uuid;
 func (r *TransactionRepo) GetViewItems(ctx context.Context, id string) ([]TransactionViewItemRow, error) {
 	const q = `
 SELECT
+  ti.id::text,
   ti.product_id::text,
   p.sku,
   p.name,
   ti.qty,
+  ti.qty_shipped,
   ti.unit_amount::text,
   ti.line_total::text,
   p.pack_size::text,
@@ -112,10 +131,12 @@ ORDER BY ti.created_at ASC;
 	for rows.Next() {
 		var it TransactionViewItemRow
 		if err := rows.Scan(
+			&it.ID,
 			&it.ProductID,
 			&it.SKU,
 			&it.ProductName,
 			&it.Qty,
+			&it.QtyShipped,
 			&it.UnitAmount,
 			&it.LineTotal,
 			&it.PackSize,
@@ -161,3 +182,40 @@ ORDER BY p.paid_at DESC, p.created_at DESC;
 	}
 	return out, rows.Err()
 }
+
+type TransactionViewEventRow struct {
+	ID         string
+	Kind       string
+	FromValue  *string
+	ToValue    *string
+	ActorID    *string
+	ActorEmail *string
+	Data       []byte
+	CreatedAt  time.Time
+}
+
+// GetViewEvents returns the timeline, oldest first.
+func (r *TransactionRepo) GetViewEvents(ctx context.Context, id string) ([]TransactionViewEventRow, error) {
+	const q = `
+SELECT e.id::text, e.kind, e.from_value, e.to_value, e.actor_id::text, a.email, e.data, e.created_at
+FROM transaction_events e
+LEFT JOIN admins a ON a.id = e.actor_id
+WHERE e.transaction_id = $1::uuid
+ORDER BY e.created_at ASC, e.seq;
+`
+	rows, err := r.db.Query(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]TransactionViewEventRow, 0, 10)
+	for rows.Next() {
+		var e TransactionViewEventRow
+		if err := rows.Scan(&e.ID, &e.Kind, &e.FromValue, &e.ToValue, &e.ActorID, &e.ActorEmail, &e.Data, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
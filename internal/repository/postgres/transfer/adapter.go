@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	transferuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transfer"
+)
+
+type TransferStoreAdapter struct {
+	repo *TransferRepo
+}
+
+func NewTransferStoreAdapter(repo *TransferRepo) *TransferStoreAdapter {
+	return &TransferStoreAdapter{repo: repo}
+}
+
+func (a *TransferStoreAdapter) Create(ctx context.Context, in transferuc.CreateInput) (*transferuc.Transfer, error) {
+	lines := make([]LineInputRow, 0, len(in.Lines))
+	for _, l := range in.Lines {
+		lines = append(lines, LineInputRow{ProductID: l.ProductID, Qty: l.Qty})
+	}
+
+	t, ls, err := a.repo.Create(ctx, in.FromLocationID, in.ToLocationID, in.Reference, in.Note, in.CreatedBy, lines)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapTransfer(t, ls), nil
+}
+
+func (a *TransferStoreAdapter) GetByID(ctx context.Context, id string) (*transferuc.Transfer, error) {
+	t, ls, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapTransfer(t, ls), nil
+}
+
+func (a *TransferStoreAdapter) List(ctx context.Context, q transferuc.ListQuery) ([]transferuc.Transfer, error) {
+	rows, err := a.repo.List(ctx, q.LocationID, q.Status, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]transferuc.Transfer, 0, len(rows))
+	for i := range rows {
+		out = append(out, *mapTransfer(&rows[i], nil))
+	}
+	return out, nil
+}
+
+func (a *TransferStoreAdapter) Complete(ctx context.Context, id string) (*transferuc.Transfer, error) {
+	t, ls, err := a.repo.Complete(ctx, id)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapTransfer(t, ls), nil
+}
+
+func (a *TransferStoreAdapter) Cancel(ctx context.Context, id string) (*transferuc.Transfer, error) {
+	t, ls, err := a.repo.Cancel(ctx, id)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return mapTransfer(t, ls), nil
+}
+
+func mapErr(err error) error {
+	switch {
+	case isNoRows(err):
+		return transferuc.ErrNotFound
+	case errors.Is(err, errLocationMissing):
+		return transferuc.ErrLocationMissing
+	case errors.Is(err, errProductMissing):
+		return transferuc.ErrProductMissing
+	case errors.Is(err, errInvalidTransition):
+		return transferuc.ErrInvalidTransition
+	case errors.Is(err, errInsufficientStock):
+		return transferuc.ErrInsufficientStock
+	}
+	return err
+}
+
+func mapTransfer(r *TransferRow, lines []LineRow) *transferuc.Transfer {
+	out := &transferuc.Transfer{
+		ID:               r.ID,
+		FromLocationID:   r.FromLocationID,
+		FromLocationName: r.FromLocationName,
+		ToLocationID:     r.ToLocationID,
+		ToLocationName:   r.ToLocationName,
+		Status:           r.Status,
+		Reference:        r.Reference,
+		Note:             r.Note,
+		CreatedBy:        r.CreatedBy,
+		CompletedAt:      r.CompletedAt,
+		CancelledAt:      r.CancelledAt,
+		CreatedAt:        r.CreatedAt,
+		UpdatedAt:        r.UpdatedAt,
+	}
+	for _, l := range lines {
+		out.Lines = append(out.Lines, transferuc.Line{
+			ID:          l.ID,
+			ProductID:   l.ProductID,
+			ProductName: l.ProductName,
+			Qty:         l.Qty,
+		})
+	}
+	return out
+}
+
+// Compile-time check
+var _ transferuc.Store = (*TransferStoreAdapter)(nil)
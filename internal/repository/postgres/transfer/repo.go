@@ -0,0 +1,351 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	trxpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/transaction"
+)
+
+type TransferRow struct {
+	ID               string
+	FromLocationID   string
+	FromLocationName string
+	ToLocationID     string
+	ToLocationName   string
+	Status           string
+	Reference        *string
+	Note             *string
+	CreatedBy        *string
+	CompletedAt      *time.Time
+	CancelledAt      *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+type LineRow struct {
+	ID          string
+	ProductID   string
+	ProductName string
+	Qty         int
+}
+
+type LineInputRow struct {
+	ProductID string
+	Qty       int
+}
+
+var (
+	errLocationMissing   = errors.New("location missing")
+	errProductMissing    = errors.New("product missing")
+	errInvalidTransition = errors.New("invalid transition")
+	errInsufficientStock = errors.New("insufficient stock")
+)
+
+type TransferRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTransferRepo(db *pgxpool.Pool) *TransferRepo {
+	return &TransferRepo{db: db}
+}
+
+const transferColumns = `
+  t.id::text,
+  t.from_location_id::text,
+  fl.name,
+  t.to_location_id::text,
+  tl.name,
+  t.status,
+  t.reference,
+  t.note,
+  t.created_by::text,
+  t.completed_at,
+  t.cancelled_at,
+  t.created_at,
+  t.updated_at
+`
+
+const transferFrom = `
+FROM stock_transfers t
+JOIN locations fl ON fl.id = t.from_location_id
+JOIN locations tl ON tl.id = t.to_location_id
+`
+
+func scanTransferRow(row pgx.Row) (*TransferRow, error) {
+	var out TransferRow
+	if err := row.Scan(
+		&out.ID, &out.FromLocationID, &out.FromLocationName, &out.ToLocationID, &out.ToLocationName,
+		&out.Status, &out.Reference, &out.Note, &out.CreatedBy, &out.CompletedAt,
+		&out.CancelledAt, &out.CreatedAt, &out.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type queryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func getTransfer(ctx context.Context, q queryer, id string) (*TransferRow, error) {
+	sql := `SELECT ` + transferColumns + transferFrom + `WHERE t.id = $1::uuid;`
+	return scanTransferRow(q.QueryRow(ctx, sql, id))
+}
+
+// listLines returns the lines ordered by product id, the order Complete locks
+// stock rows in.
+func listLines(ctx context.Context, q queryer, transferID string) ([]LineRow, error) {
+	const sql = `
+SELECT l.id::text, l.product_id::text, p.name, l.qty
+FROM stock_transfer_lines l
+JOIN products p ON p.id = l.product_id
+WHERE l.stock_transfer_id = $1::uuid
+ORDER BY l.product_id;
+`
+	rows, err := q.Query(ctx, sql, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]LineRow, 0, 8)
+	for rows.Next() {
+		var l LineRow
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.ProductName, &l.Qty); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+func (r *TransferRepo) Create(ctx context.Context, fromID, toID string, reference, note, createdBy *string, lines []LineInputRow) (*TransferRow, []LineRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, loc := range []string{fromID, toID} {
+		if _, err := trxpg.ResolveLocation(ctx, tx, &loc); err != nil {
+			if isNoRows(err) {
+				return nil, nil, errLocationMissing
+			}
+			return nil, nil, err
+		}
+	}
+
+	var id string
+	if err := tx.QueryRow(ctx, `
+INSERT INTO stock_transfers (from_location_id, to_location_id, reference, note, created_by)
+VALUES ($1::uuid, $2::uuid, $3, $4, $5::uuid)
+RETURNING id::text;
+`, fromID, toID, reference, note, createdBy).Scan(&id); err != nil {
+		return nil, nil, err
+	}
+
+	for _, l := range lines {
+		ct, err := tx.Exec(ctx, `
+INSERT INTO stock_transfer_lines (stock_transfer_id, product_id, qty)
+SELECT $1::uuid, p.id, $3
+FROM products p
+WHERE p.id = $2::uuid
+  AND p.base_product_id IS NULL;
+`, id, l.ProductID, l.Qty)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ct.RowsAffected() == 0 {
+			return nil, nil, errProductMissing
+		}
+	}
+
+	out, err := getTransfer(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	ls, err := listLines(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return out, ls, nil
+}
+
+func (r *TransferRepo) GetByID(ctx context.Context, id string) (*TransferRow, []LineRow, error) {
+	out, err := getTransfer(ctx, r.db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := listLines(ctx, r.db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, lines, nil
+}
+
+func (r *TransferRepo) List(ctx context.Context, locationID, status *string, limit, offset int) ([]TransferRow, error) {
+	q := `
+SELECT ` + transferColumns + transferFrom + `
+WHERE ($1::uuid IS NULL OR t.from_location_id = $1::uuid OR t.to_location_id = $1::uuid)
+  AND ($2::text IS NULL OR t.status = $2)
+ORDER BY t.created_at DESC
+LIMIT $3 OFFSET $4;
+`
+	rows, err := r.db.Query(ctx, q, locationID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]TransferRow, 0, 16)
+	for rows.Next() {
+		t, err := scanTransferRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *t)
+	}
+	return out, rows.Err()
+}
+
+func lockTransfer(ctx context.Context, tx pgx.Tx, id string) (status, fromID, toID string, err error) {
+	err = tx.QueryRow(ctx, `
+SELECT status, from_location_id::text, to_location_id::text
+FROM stock_transfers
+WHERE id = $1::uuid
+FOR UPDATE;
+`, id).Scan(&status, &fromID, &toID)
+	return status, fromID, toID, err
+}
+
+// Complete moves each line out of the source and into the destination. Lots are
+// picked first-expiry-first-out and recreated at the destination; expired lots
+// stay behind. Product totals do not change, so no reorder events are written.
+func (r *TransferRepo) Complete(ctx context.Context, id string) (*TransferRow, []LineRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, fromID, toID, err := lockTransfer(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if status != "draft" {
+		return nil, nil, errInvalidTransition
+	}
+	for _, loc := range []string{fromID, toID} {
+		if _, err := trxpg.ResolveLocation(ctx, tx, &loc); err != nil {
+			if isNoRows(err) {
+				return nil, nil, errLocationMissing
+			}
+			return nil, nil, err
+		}
+	}
+
+	lines, err := listLines(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, l := range lines {
+		src, err := trxpg.LockStockLevel(ctx, tx, l.ProductID, fromID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if src.OnHand-src.Reserved-src.Expired < l.Qty {
+			return nil, nil, errInsufficientStock
+		}
+		if _, err := trxpg.LockStockLevel(ctx, tx, l.ProductID, toID); err != nil {
+			return nil, nil, err
+		}
+		takes, err := trxpg.TakeLots(ctx, tx, l.ProductID, fromID, l.Qty, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, tk := range takes {
+			if tk.LotID == nil {
+				continue
+			}
+			if _, err := trxpg.AddLot(ctx, tx, l.ProductID, toID, tk.LotNumber, tk.ExpiresAt, tk.Qty, nil, tk.LotID); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := trxpg.AdjustStockLevel(ctx, tx, l.ProductID, fromID, -l.Qty, 0); err != nil {
+			return nil, nil, err
+		}
+		if err := trxpg.AdjustStockLevel(ctx, tx, l.ProductID, toID, l.Qty, 0); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE stock_transfers
+SET status = 'completed', completed_at = now(), updated_at = now()
+WHERE id = $1::uuid;
+`, id); err != nil {
+		return nil, nil, err
+	}
+
+	out, err := getTransfer(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return out, lines, nil
+}
+
+func (r *TransferRepo) Cancel(ctx context.Context, id string) (*TransferRow, []LineRow, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, _, _, err := lockTransfer(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if status != "draft" {
+		return nil, nil, errInvalidTransition
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE stock_transfers
+SET status = 'cancelled', cancelled_at = now(), updated_at = now()
+WHERE id = $1::uuid;
+`, id); err != nil {
+		return nil, nil, err
+	}
+
+	out, err := getTransfer(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := listLines(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return out, lines, nil
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
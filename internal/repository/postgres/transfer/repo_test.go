@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	locationpg "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/location"
+	testutil "github.com/riolentius/cahaya-gading-backend/internal/repository/postgres/testutil"
+	locationuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/location"
+	transferuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transfer"
+)
+
+func TestTransfer_CompleteMovesStockBetweenLocations(t *testing.T) {
+	db := testutil.MustOpenDB(t)
+	defer db.Close()
+
+	testutil.TruncateAll(t, db)
+
+	ctx := context.Background()
+
+	luc := locationuc.New(locationpg.NewLocationStoreAdapter(locationpg.NewLocationRepo(db)))
+	locs, err := luc.List(ctx, locationuc.ListQuery{})
+	if err != nil || len(locs) == 0 || !locs[0].IsDefault {
+		t.Fatalf("expected the default location first: %+v err=%v", locs, err)
+	}
+	home := locs[0]
+	wh, err := luc.Create(ctx, locationuc.CreateInput{Code: fmt.Sprintf("wh-%d", time.Now().UnixNano()), Name: "Gudang Timur"})
+	if err != nil {
+		t.Fatalf("create location: %v", err)
+	}
+
+	productID := testutil.MustInsertProduct(t, db, "SKU-TRF-1", "Beras 5kg", nil, 10, 2)
+
+	tuc := transferuc.New(NewTransferStoreAdapter(NewTransferRepo(db)))
+
+	// only the 8 unreserved units at the source can move
+	tooMuch, err := tuc.Create(ctx, transferuc.CreateInput{
+		FromLocationID: home.ID,
+		ToLocationID:   wh.ID,
+		Lines:          []transferuc.LineInput{{ProductID: productID, Qty: 9}},
+	})
+	if err != nil {
+		t.Fatalf("create transfer: %v", err)
+	}
+	if _, err := tuc.Complete(ctx, tooMuch.ID); err != transferuc.ErrInsufficientStock {
+		t.Fatalf("expected ErrInsufficientStock got=%v", err)
+	}
+	if _, err := tuc.Cancel(ctx, tooMuch.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	tr, err := tuc.Create(ctx, transferuc.CreateInput{
+		FromLocationID: home.ID,
+		ToLocationID:   wh.ID,
+		Lines:          []transferuc.LineInput{{ProductID: productID, Qty: 6}},
+	})
+	if err != nil {
+		t.Fatalf("create transfer: %v", err)
+	}
+	done, err := tuc.Complete(ctx, tr.ID)
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if done.Status != transferuc.StatusCompleted || done.CompletedAt == nil || len(done.Lines) != 1 {
+		t.Fatalf("unexpected transfer: %+v", done)
+	}
+	if _, err := tuc.Complete(ctx, tr.ID); err != transferuc.ErrInvalidTransition {
+		t.Fatalf("expected ErrInvalidTransition got=%v", err)
+	}
+
+	level := func(locationID string) locationuc.StockLevel {
+		t.Helper()
+		rows, err := luc.ListStock(ctx, locationID, locationuc.StockQuery{ProductID: &productID})
+		if err != nil || len(rows) != 1 {
+			t.Fatalf("stock at %s: %+v err=%v", locationID, rows, err)
+		}
+		return rows[0]
+	}
+	if src := level(home.ID); src.OnHand != 4 || src.Reserved != 2 {
+		t.Fatalf("unexpected source level: %+v", src)
+	}
+	if dst := level(wh.ID); dst.OnHand != 6 || dst.Reserved != 0 {
+		t.Fatalf("unexpected destination level: %+v", dst)
+	}
+
+	// product totals are unchanged by a transfer
+	var onHand, reserved int
+	if err := db.QueryRow(ctx, `SELECT stock_on_hand, stock_reserved FROM products WHERE id = $1::uuid`, productID).Scan(&onHand, &reserved); err != nil {
+		t.Fatalf("totals: %v", err)
+	}
+	if onHand != 10 || reserved != 2 {
+		t.Fatalf("unexpected totals on_hand=%d reserved=%d", onHand, reserved)
+	}
+}
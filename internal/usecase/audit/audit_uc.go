@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrNotFound     = errors.New("audit entry not found")
+)
+
+type Store interface {
+	Record(ctx context.Context, in RecordInput) error
+	GetByID(ctx context.Context, id string) (*Entry, error)
+	List(ctx context.Context, q ListQuery) ([]Entry, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+func (u *Usecase) Record(ctx context.Context, in RecordInput) error {
+	in.Action = strings.TrimSpace(in.Action)
+	in.Entity = strings.TrimSpace(in.Entity)
+	if in.Action == "" || in.Entity == "" {
+		return ErrInvalidInput
+	}
+	return u.store.Record(ctx, in)
+}
+
+func (u *Usecase) GetByID(ctx context.Context, id string) (*Entry, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetByID(ctx, id)
+}
+
+func (u *Usecase) List(ctx context.Context, q ListQuery) ([]Entry, error) {
+	if q.ActorID != nil {
+		if _, err := uuid.Parse(*q.ActorID); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return nil, ErrInvalidInput
+	}
+	q.Entity = trimmedOrNil(q.Entity)
+	q.EntityID = trimmedOrNil(q.EntityID)
+	q.Action = trimmedOrNil(q.Action)
+	q.RequestID = trimmedOrNil(q.RequestID)
+
+	if q.Limit <= 0 || q.Limit > 200 {
+		q.Limit = 50
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return u.store.List(ctx, q)
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type trailKey struct{}
+
+// TrailKey is the context value key of the request's Trail. The audit
+// middleware sets it as a fiber local before running the handler.
+var TrailKey any = trailKey{}
+
+// Trail tells the audit middleware whether the repositories already logged
+// the request inside their own DB transaction, so it is not logged twice.
+// Method and Path are the request's, for the repositories' entries.
+type Trail struct {
+	Method string
+	Path   string
+
+	recorded atomic.Bool
+}
+
+// TrailFrom returns the request's trail, or nil outside an audited request.
+func TrailFrom(ctx context.Context) *Trail {
+	t, _ := ctx.Value(TrailKey).(*Trail)
+	return t
+}
+
+func (t *Trail) MarkRecorded() {
+	if t != nil {
+		t.recorded.Store(true)
+	}
+}
+
+func (t *Trail) Recorded() bool {
+	return t != nil && t.recorded.Load()
+}
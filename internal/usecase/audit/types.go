@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Entry struct {
+	ID         string          `json:"id"`
+	ActorID    *string         `json:"actorId,omitempty"`
+	ActorEmail *string         `json:"actorEmail,omitempty"`
+	Action     string          `json:"action"`
+	Entity     string          `json:"entity"`
+	EntityID   *string         `json:"entityId,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Changes    json.RawMessage `json:"changes,omitempty"` // {"field": {"from": ..., "to": ...}}
+	RequestID  *string         `json:"requestId,omitempty"`
+	Method     *string         `json:"method,omitempty"`
+	Path       *string         `json:"path,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// RecordInput is a mutation logged from the HTTP layer, where only the
+// request is known. Actor and request id come from the context.
+type RecordInput struct {
+	Action   string
+	Entity   string
+	EntityID *string
+	After    json.RawMessage // request body, when it is JSON
+	Method   string
+	Path     string
+}
+
+type ListQuery struct {
+	ActorID   *string
+	Entity    *string
+	EntityID  *string
+	Action    *string
+	RequestID *string
+	From      *time.Time // inclusive
+	To        *time.Time // exclusive
+	Limit     int
+	Offset    int
+}
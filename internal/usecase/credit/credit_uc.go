@@ -0,0 +1,69 @@
+package credit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrCustomerMissing = errors.New("customer not found")
+)
+
+const (
+	KindOverpayment = "overpayment"
+	KindApplied     = "applied"
+	KindAdjustment  = "adjustment"
+)
+
+type Store interface {
+	CustomerExists(ctx context.Context, customerID string) (bool, error)
+	GetBalances(ctx context.Context, customerID string) ([]Balance, error)
+	ListEntries(ctx context.Context, customerID string, q HistoryQuery) ([]Entry, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+// GetForCustomer returns the current credit balance (per currency) and the ledger history.
+func (u *Usecase) GetForCustomer(ctx context.Context, customerID string, q HistoryQuery) (*CustomerCredit, error) {
+	if _, err := uuid.Parse(customerID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if q.Limit <= 0 || q.Limit > 200 {
+		q.Limit = 50
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	ok, err := u.store.CustomerExists(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrCustomerMissing
+	}
+
+	balances, err := u.store.GetBalances(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := u.store.ListEntries(ctx, customerID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CustomerCredit{
+		CustomerID: customerID,
+		Balances:   balances,
+		Entries:    entries,
+	}, nil
+}
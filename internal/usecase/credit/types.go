@@ -0,0 +1,31 @@
+package credit
+
+import "time"
+
+type Entry struct {
+	ID            string    `json:"id"`
+	CustomerID    string    `json:"customerId"`
+	TransactionID *string   `json:"transactionId,omitempty"`
+	PaymentID     *string   `json:"paymentId,omitempty"`
+	Kind          string    `json:"kind"`   // overpayment | applied | adjustment
+	Amount        string    `json:"amount"` // signed: + adds credit, - consumes it
+	Currency      string    `json:"currency"`
+	Note          *string   `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type Balance struct {
+	Currency string `json:"currency"`
+	Amount   string `json:"amount"`
+}
+
+type CustomerCredit struct {
+	CustomerID string    `json:"customerId"`
+	Balances   []Balance `json:"balances"`
+	Entries    []Entry   `json:"entries"`
+}
+
+type HistoryQuery struct {
+	Limit  int
+	Offset int
+}
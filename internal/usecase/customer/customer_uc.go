@@ -12,6 +12,8 @@ var (
 	ErrInvalidInput  = errors.New("invalid input")
 	ErrNotFound      = errors.New("not found")
 	ErrEmailConflict = errors.New("email already exists")
+	ErrAlreadyMerged = errors.New("customer was already merged")
+	ErrArchived      = errors.New("customer is archived")
 )
 
 type Store interface {
@@ -19,6 +21,8 @@ type Store interface {
 	GetByID(ctx context.Context, id string) (*Customer, error)
 	List(ctx context.Context, q ListQuery) ([]Customer, error)
 	Update(ctx context.Context, id string, in UpdateInput) (*Customer, error)
+	SetArchived(ctx context.Context, id string, archived bool) (*Customer, error)
+	Merge(ctx context.Context, survivorID, duplicateID string) (*MergeResult, error)
 }
 
 type Usecase struct {
@@ -31,9 +35,24 @@ func New(store Store) *Usecase {
 
 func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Customer, error) {
 	in.FirstName = strings.TrimSpace(in.FirstName)
-	in.Email = strings.TrimSpace(strings.ToLower(in.Email))
+	if in.FirstName == "" {
+		return nil, ErrInvalidInput
+	}
+
+	email, err := normalizeEmail(in.Email)
+	if err != nil {
+		return nil, err
+	}
+	in.Email = email
+
+	phone, err := normalizePhonePtr(in.Phone)
+	if err != nil {
+		return nil, err
+	}
+	in.Phone = phone
 
-	if in.FirstName == "" || in.Email == "" || !strings.Contains(in.Email, "@") {
+	// a customer must be reachable one way or the other
+	if in.Email == nil && in.Phone == nil {
 		return nil, ErrInvalidInput
 	}
 
@@ -55,6 +74,14 @@ func (u *Usecase) GetByID(ctx context.Context, id string) (*Customer, error) {
 }
 
 func (u *Usecase) List(ctx context.Context, q ListQuery) ([]Customer, error) {
+	if q.Search != nil {
+		v := strings.TrimSpace(*q.Search)
+		if v == "" {
+			q.Search = nil
+		} else {
+			q.Search = &v
+		}
+	}
 	if q.Limit <= 0 || q.Limit > 200 {
 		q.Limit = 50
 	}
@@ -77,6 +104,12 @@ func (u *Usecase) Update(ctx context.Context, id string, in UpdateInput) (*Custo
 		in.Email = &e
 	}
 
+	phone, err := normalizePhonePtr(in.Phone)
+	if err != nil {
+		return nil, err
+	}
+	in.Phone = phone
+
 	if in.FirstName != nil {
 		f := strings.TrimSpace(*in.FirstName)
 		if f == "" {
@@ -93,3 +126,62 @@ func (u *Usecase) Update(ctx context.Context, id string, in UpdateInput) (*Custo
 
 	return u.store.Update(ctx, id, in)
 }
+
+// Archive soft-deletes the customer: it is hidden from listings and cannot be
+// used on new transactions, but its history stays.
+func (u *Usecase) Archive(ctx context.Context, id string) (*Customer, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.SetArchived(ctx, id, true)
+}
+
+func (u *Usecase) Restore(ctx context.Context, id string) (*Customer, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.SetArchived(ctx, id, false)
+}
+
+// Merge folds the duplicate into the survivor: its transactions, addresses,
+// credit entries and receipts move over, contact details the survivor lacks
+// are copied, and the duplicate is archived pointing at the survivor. Neither
+// side may already be archived.
+func (u *Usecase) Merge(ctx context.Context, survivorID string, in MergeInput) (*MergeResult, error) {
+	if _, err := uuid.Parse(survivorID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if _, err := uuid.Parse(in.DuplicateID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if survivorID == in.DuplicateID {
+		return nil, ErrInvalidInput
+	}
+	return u.store.Merge(ctx, survivorID, in.DuplicateID)
+}
+
+// normalizeEmail lower-cases the email; an empty one is no email.
+func normalizeEmail(s *string) (*string, error) {
+	if s == nil {
+		return nil, nil
+	}
+	e := strings.TrimSpace(strings.ToLower(*s))
+	if e == "" {
+		return nil, nil
+	}
+	if !strings.Contains(e, "@") {
+		return nil, ErrInvalidInput
+	}
+	return &e, nil
+}
+
+func normalizePhonePtr(s *string) (*string, error) {
+	if s == nil || strings.TrimSpace(*s) == "" {
+		return nil, nil
+	}
+	p, err := NormalizePhone(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,41 @@
+package customer
+
+import "strings"
+
+// NormalizePhone returns the phone number in E.164. Numbers without a
+// country code are taken as Indonesian: "0812-3456-789", "812 3456 789" and
+// "62812345678" all become "+62812345678". It returns ErrInvalidInput for
+// anything that is not 8 to 15 digits.
+func NormalizePhone(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	international := strings.HasPrefix(s, "+")
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9': // ASCII only; other scripts' digits are not E.164
+			b.WriteRune(r)
+		case r == '+' || r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidInput
+		}
+	}
+	digits := b.String()
+
+	if !international {
+		switch {
+		case strings.HasPrefix(digits, "62"):
+		case strings.HasPrefix(digits, "0"):
+			digits = "62" + digits[1:]
+		case strings.HasPrefix(digits, "8"):
+			digits = "62" + digits
+		default:
+			return "", ErrInvalidInput
+		}
+	}
+
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", ErrInvalidInput
+	}
+	return "+" + digits, nil
+}
@@ -0,0 +1,43 @@
+package customer
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "0812-3456-789", want: "+628123456789"},
+		{in: "812 3456 789", want: "+628123456789"},
+		{in: "62812345678", want: "+62812345678"},
+		{in: " (0812) 3456.789 ", want: "+628123456789"},
+		{in: "+1 (415) 555-2671", want: "+14155552671"},
+		{in: "+12345678", want: "+12345678"},
+		{in: "+123456789012345", want: "+123456789012345"},
+		{in: "+1234567", err: true},
+		{in: "+1234567890123456", err: true},
+		{in: "+0812345678", err: true},
+		{in: "0812", err: true},
+		{in: "1234567890", err: true},
+		{in: "08123456789a", err: true},
+		{in: "٠٨١٢٣٤٥٦٧٨٩", err: true},
+		{in: "", err: true},
+	}
+	for _, c := range cases {
+		got, err := NormalizePhone(c.in)
+		if c.err {
+			if err == nil {
+				t.Errorf("NormalizePhone(%q) = %q, want error", c.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NormalizePhone(%q): %v", c.in, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -3,21 +3,23 @@ package customer
 import "time"
 
 type Customer struct {
-	ID                   string    `json:"id"`
-	FirstName            string    `json:"firstName"`
-	LastName             *string   `json:"lastName,omitempty"`
-	Email                string    `json:"email"`
-	Phone                *string   `json:"phone,omitempty"`
-	IdentificationNumber *string   `json:"identificationNumber,omitempty"`
-	CategoryID           *string   `json:"categoryId,omitempty"`
-	CreatedAt            time.Time `json:"createdAt"`
-	UpdatedAt            time.Time `json:"updatedAt"`
+	ID                   string     `json:"id"`
+	FirstName            string     `json:"firstName"`
+	LastName             *string    `json:"lastName,omitempty"`
+	Email                *string    `json:"email,omitempty"`
+	Phone                *string    `json:"phone,omitempty"`
+	IdentificationNumber *string    `json:"identificationNumber,omitempty"`
+	CategoryID           *string    `json:"categoryId,omitempty"`
+	CreatedAt            time.Time  `json:"createdAt"`
+	UpdatedAt            time.Time  `json:"updatedAt"`
+	DeletedAt            *time.Time `json:"deletedAt,omitempty"`    // archived
+	MergedIntoID         *string    `json:"mergedIntoId,omitempty"` // set on a merged duplicate
 }
 
 type CreateInput struct {
 	FirstName            string  `json:"firstName"`
 	LastName             *string `json:"lastName"`
-	Email                string  `json:"email"`
+	Email                *string `json:"email"` // optional for walk-in customers known by phone
 	Phone                *string `json:"phone"`
 	IdentificationNumber *string `json:"identificationNumber"`
 	CategoryID           *string `json:"categoryId"`
@@ -33,6 +35,22 @@ type UpdateInput struct {
 }
 
 type ListQuery struct {
-	Limit  int
-	Offset int
+	Search          *string // name, email or phone
+	IncludeArchived bool
+	Limit           int
+	Offset          int
+}
+
+type MergeInput struct {
+	DuplicateID string `json:"duplicateId"`
+}
+
+// MergeResult is the surviving customer and how many records moved over from
+// the duplicate.
+type MergeResult struct {
+	Customer      Customer `json:"customer"`
+	Transactions  int      `json:"transactions"`
+	Addresses     int      `json:"addresses"`
+	CreditEntries int      `json:"creditEntries"`
+	Receipts      int      `json:"receipts"`
 }
@@ -0,0 +1,135 @@
+package exchangerate
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrRateMissing  = errors.New("exchange rate not found")
+	ErrRateConflict = errors.New("exchange rate already recorded for this pair and time")
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type Store interface {
+	Create(ctx context.Context, in CreateInput) (*Rate, error)
+	List(ctx context.Context, q ListQuery) ([]Rate, error)
+	// Resolve returns the latest rate of the pair (or its inverse) effective at `at`,
+	// ErrRateMissing when there is none.
+	Resolve(ctx context.Context, from, to string, at time.Time) (*ResolvedRate, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Rate, error) {
+	in.FromCurrency = NormalizeCurrency(in.FromCurrency)
+	in.ToCurrency = NormalizeCurrency(in.ToCurrency)
+	if !ValidCurrency(in.FromCurrency) || !ValidCurrency(in.ToCurrency) || in.FromCurrency == in.ToCurrency {
+		return nil, ErrInvalidInput
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(in.Rate))
+	if !ok || r.Sign() <= 0 {
+		return nil, ErrInvalidInput
+	}
+	in.Rate = r.FloatString(10)
+	if in.EffectiveAt == nil {
+		now := time.Now()
+		in.EffectiveAt = &now
+	}
+	return u.store.Create(ctx, in)
+}
+
+func (u *Usecase) List(ctx context.Context, q ListQuery) ([]Rate, error) {
+	for _, c := range []*string{q.FromCurrency, q.ToCurrency} {
+		if c == nil {
+			continue
+		}
+		*c = NormalizeCurrency(*c)
+		if !ValidCurrency(*c) {
+			return nil, ErrInvalidInput
+		}
+	}
+	if q.Limit <= 0 || q.Limit > 100 {
+		q.Limit = 50
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return u.store.List(ctx, q)
+}
+
+// Convert converts an amount at the rate effective at q.At.
+func (u *Usecase) Convert(ctx context.Context, q ConvertQuery) (*Conversion, error) {
+	q.FromCurrency = NormalizeCurrency(q.FromCurrency)
+	q.ToCurrency = NormalizeCurrency(q.ToCurrency)
+	if !ValidCurrency(q.FromCurrency) || !ValidCurrency(q.ToCurrency) {
+		return nil, ErrInvalidInput
+	}
+	amount, ok := new(big.Rat).SetString(strings.TrimSpace(q.Amount))
+	if !ok || amount.Sign() < 0 {
+		return nil, ErrInvalidInput
+	}
+	at := time.Now()
+	if q.At != nil {
+		at = *q.At
+	}
+
+	out := &Conversion{
+		Amount:       amount.FloatString(2),
+		FromCurrency: q.FromCurrency,
+		ToCurrency:   q.ToCurrency,
+		Rate:         "1",
+		At:           at,
+	}
+	if q.FromCurrency == q.ToCurrency {
+		out.ConvertedAmount = out.Amount
+		return out, nil
+	}
+
+	rate, err := u.store.Resolve(ctx, q.FromCurrency, q.ToCurrency, at)
+	if err != nil {
+		return nil, err
+	}
+	converted, ok := ConvertAmount(out.Amount, rate.Rate)
+	if !ok {
+		return nil, ErrInvalidInput
+	}
+	out.Rate = rate.Rate
+	out.RateID = &rate.RateID
+	out.Inverted = rate.Inverted
+	out.ConvertedAmount = converted
+	return out, nil
+}
+
+// ConvertAmount multiplies amount by rate and rounds to 2 decimals.
+func ConvertAmount(amount, rate string) (string, bool) {
+	a, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return "", false
+	}
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return "", false
+	}
+	return a.Mul(a, r).FloatString(2), true
+}
+
+func NormalizeCurrency(c string) string {
+	return strings.ToUpper(strings.TrimSpace(c))
+}
+
+func ValidCurrency(c string) bool {
+	return currencyPattern.MatchString(c)
+}
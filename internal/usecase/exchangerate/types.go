@@ -0,0 +1,55 @@
+package exchangerate
+
+import "time"
+
+// Rate: 1 FromCurrency = Rate ToCurrency from EffectiveAt until the next rate of the pair.
+type Rate struct {
+	ID           string    `json:"id"`
+	FromCurrency string    `json:"fromCurrency"`
+	ToCurrency   string    `json:"toCurrency"`
+	Rate         string    `json:"rate"`
+	EffectiveAt  time.Time `json:"effectiveAt"`
+	Source       *string   `json:"source,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type CreateInput struct {
+	FromCurrency string     `json:"fromCurrency"`
+	ToCurrency   string     `json:"toCurrency"`
+	Rate         string     `json:"rate"`
+	EffectiveAt  *time.Time `json:"effectiveAt"` // default now
+	Source       *string    `json:"source"`
+}
+
+type ListQuery struct {
+	FromCurrency *string
+	ToCurrency   *string
+	Limit        int
+	Offset       int
+}
+
+type ConvertQuery struct {
+	Amount       string
+	FromCurrency string
+	ToCurrency   string
+	At           *time.Time // default now
+}
+
+type Conversion struct {
+	Amount          string    `json:"amount"`
+	FromCurrency    string    `json:"fromCurrency"`
+	ToCurrency      string    `json:"toCurrency"`
+	Rate            string    `json:"rate"`
+	RateID          *string   `json:"rateId,omitempty"` // nil when both currencies are equal
+	Inverted        bool      `json:"inverted"`         // rate derived from the reverse pair
+	At              time.Time `json:"at"`
+	ConvertedAmount string    `json:"convertedAmount"`
+}
+
+// ResolvedRate is the rate applying to a pair at a moment, possibly from the reverse pair.
+type ResolvedRate struct {
+	RateID      string
+	Rate        string
+	Inverted    bool
+	EffectiveAt time.Time
+}
@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidKey        = errors.New("invalid idempotency key")
+	ErrKeyReused         = errors.New("idempotency key reused with a different request")
+	ErrRequestInProgress = errors.New("request with this idempotency key is still in progress")
+)
+
+const maxKeyLength = 255
+
+type Store interface {
+	// Reserve claims (scope, key) for a new request. When the key is already
+	// taken by a non-expired record, reserved is false and existing is returned.
+	Reserve(ctx context.Context, in ReserveInput) (reserved bool, existing *Record, err error)
+	Complete(ctx context.Context, in CompleteInput) error
+	Release(ctx context.Context, scope, key string) error
+}
+
+type Usecase struct {
+	store Store
+	ttl   time.Duration
+}
+
+func New(store Store, ttl time.Duration) *Usecase {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &Usecase{store: store, ttl: ttl}
+}
+
+// Begin reserves the key for this request.
+// It returns (nil, nil) when the caller should run the request,
+// or the stored record when the response should be replayed.
+func (u *Usecase) Begin(ctx context.Context, in BeginInput) (*Record, error) {
+	key := strings.TrimSpace(in.Key)
+	if key == "" || len(key) > maxKeyLength {
+		return nil, ErrInvalidKey
+	}
+
+	hash := requestHash(in.Method, in.Path, in.Body)
+
+	reserved, existing, err := u.store.Reserve(ctx, ReserveInput{
+		Scope:       in.Scope,
+		Key:         key,
+		Method:      in.Method,
+		Path:        in.Path,
+		RequestHash: hash,
+		ExpiresAt:   time.Now().Add(u.ttl),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	// key exists (not expired): same request -> replay, different request -> conflict
+	if existing == nil || existing.StatusCode == nil {
+		if existing != nil && existing.RequestHash != hash {
+			return nil, ErrKeyReused
+		}
+		return nil, ErrRequestInProgress
+	}
+	if existing.RequestHash != hash {
+		return nil, ErrKeyReused
+	}
+	return existing, nil
+}
+
+func (u *Usecase) Complete(ctx context.Context, in CompleteInput) error {
+	in.Key = strings.TrimSpace(in.Key)
+	return u.store.Complete(ctx, in)
+}
+
+// Release drops an in-flight reservation so the client can retry with the same key
+// (used when the request failed before producing a response worth replaying).
+func (u *Usecase) Release(ctx context.Context, scope, key string) error {
+	return u.store.Release(ctx, scope, strings.TrimSpace(key))
+}
+
+func requestHash(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(path))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,41 @@
+package idempotency
+
+import "time"
+
+type Record struct {
+	Scope        string
+	Key          string
+	Method       string
+	Path         string
+	RequestHash  string
+	StatusCode   *int // nil while the original request is still running
+	ContentType  *string
+	ResponseBody []byte
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+type BeginInput struct {
+	Scope  string
+	Key    string
+	Method string
+	Path   string
+	Body   []byte
+}
+
+type ReserveInput struct {
+	Scope       string
+	Key         string
+	Method      string
+	Path        string
+	RequestHash string
+	ExpiresAt   time.Time
+}
+
+type CompleteInput struct {
+	Scope        string
+	Key          string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+}
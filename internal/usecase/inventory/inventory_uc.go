@@ -0,0 +1,124 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrNotFound        = errors.New("goods receipt not found")
+	ErrProductMissing  = errors.New("product not found")
+	ErrFractionalStock = errors.New("received quantity is not a whole number of stock units")
+	ErrLocationMissing = errors.New("location not found")
+)
+
+type Store interface {
+	// Receive adds BaseQty to the stock product and folds the cost into its
+	// weighted-average cost, in one DB transaction.
+	Receive(ctx context.Context, in ReceiveInput) (*GoodsReceipt, error)
+	GetReceipt(ctx context.Context, id string) (*GoodsReceipt, error)
+	ListReceipts(ctx context.Context, q ListQuery) ([]GoodsReceipt, error)
+	Valuation(ctx context.Context) ([]ValuationRow, error)
+
+	ReorderStore
+	LotStore
+}
+
+type Usecase struct {
+	store        Store
+	baseCurrency string
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store, baseCurrency: "IDR"}
+}
+
+// WithBaseCurrency sets the currency purchase costs are recorded in.
+func (u *Usecase) WithBaseCurrency(currency string) *Usecase {
+	if c := strings.ToUpper(strings.TrimSpace(currency)); c != "" {
+		u.baseCurrency = c
+	}
+	return u
+}
+
+func (u *Usecase) Receive(ctx context.Context, in ReceiveInput) (*GoodsReceipt, error) {
+	if _, err := uuid.Parse(in.ProductID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if in.Qty <= 0 {
+		return nil, ErrInvalidInput
+	}
+	cost, ok := new(big.Rat).SetString(strings.TrimSpace(in.UnitCost))
+	if !ok || cost.Sign() < 0 {
+		return nil, ErrInvalidInput
+	}
+	in.UnitCost = cost.FloatString(4)
+
+	if in.ReceivedAt == nil {
+		now := time.Now()
+		in.ReceivedAt = &now
+	}
+
+	var err error
+	if in.LotNumber, in.ExpiresAt, err = parseLot(in.LotNumber, in.ExpiresAtRaw); err != nil {
+		return nil, err
+	}
+
+	return u.store.Receive(ctx, in)
+}
+
+func (u *Usecase) GetReceipt(ctx context.Context, id string) (*GoodsReceipt, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetReceipt(ctx, id)
+}
+
+func (u *Usecase) ListReceipts(ctx context.Context, q ListQuery) ([]GoodsReceipt, error) {
+	if q.ProductID != nil {
+		if _, err := uuid.Parse(*q.ProductID); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	if q.Limit <= 0 || q.Limit > 200 {
+		q.Limit = 50
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return u.store.ListReceipts(ctx, q)
+}
+
+func (u *Usecase) Valuation(ctx context.Context) (*Valuation, error) {
+	rows, err := u.store.Valuation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []ValuationRow{}
+	}
+
+	out := &Valuation{Currency: u.baseCurrency, Rows: rows}
+	total := new(big.Rat)
+	for _, r := range rows {
+		if r.Value == nil {
+			if r.StockOnHand > 0 {
+				out.UncostedItems++
+			}
+			continue
+		}
+		v, ok := new(big.Rat).SetString(*r.Value)
+		if !ok {
+			return nil, errors.New("invalid stock value")
+		}
+		total.Add(total, v)
+	}
+	out.TotalValue = total.FloatString(2)
+	return out, nil
+}
@@ -0,0 +1,75 @@
+package inventory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultExpiringDays = 30
+	maxExpiringDays     = 730
+)
+
+// LotStore is the part of Store behind expiry tracking.
+type LotStore interface {
+	// ExpiringLots lists lots with stock left that expire within `days` of
+	// the DB's current date, already expired ones included.
+	ExpiringLots(ctx context.Context, days int, locationID *string) ([]ExpiringLot, error)
+}
+
+// Expiring lists lots expiring within q.Days (default 30) plus lots already
+// expired, which can no longer be sold.
+func (u *Usecase) Expiring(ctx context.Context, q ExpiringQuery) (*ExpiringReport, error) {
+	if q.Days == 0 {
+		q.Days = defaultExpiringDays
+	}
+	if q.Days < 0 || q.Days > maxExpiringDays {
+		return nil, ErrInvalidInput
+	}
+	if q.LocationID != nil {
+		if _, err := uuid.Parse(*q.LocationID); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+
+	lots, err := u.store.ExpiringLots(ctx, q.Days, q.LocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ExpiringReport{Days: q.Days, Until: time.Now().AddDate(0, 0, q.Days).Format(dateLayout), Lots: []ExpiringLot{}}
+	for _, l := range lots {
+		if l.Expired {
+			out.ExpiredQty += l.QtyOnHand
+		} else {
+			out.ExpiringQty += l.QtyOnHand
+		}
+		out.Lots = append(out.Lots, l)
+	}
+	return out, nil
+}
+
+// parseLot trims the lot number and parses the YYYY-MM-DD expiry date.
+func parseLot(lotNumber, expiresAtRaw *string) (*string, *time.Time, error) {
+	if lotNumber != nil {
+		n := strings.TrimSpace(*lotNumber)
+		if n == "" {
+			lotNumber = nil
+		} else {
+			lotNumber = &n
+		}
+	}
+	if expiresAtRaw == nil || strings.TrimSpace(*expiresAtRaw) == "" {
+		return lotNumber, nil, nil
+	}
+	d, err := time.Parse(dateLayout, strings.TrimSpace(*expiresAtRaw))
+	if err != nil {
+		return nil, nil, ErrInvalidInput
+	}
+	return lotNumber, &d, nil
+}
+
+const dateLayout = "2006-01-02"
@@ -0,0 +1,104 @@
+package inventory
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	EventLowStock  = "low_stock"
+	EventRestocked = "restocked"
+)
+
+const (
+	defaultWindowDays = 30
+	defaultCoverDays  = 14
+	maxWindowDays     = 365
+)
+
+// ReorderStore is the part of Store behind low-stock alerts and suggestions.
+type ReorderStore interface {
+	LowStock(ctx context.Context) ([]StockLevel, error)
+	StockLevelsWithSales(ctx context.Context, since time.Time) ([]StockLevel, error)
+	ListStockEvents(ctx context.Context, q StockEventQuery) ([]StockEvent, error)
+}
+
+func (u *Usecase) LowStock(ctx context.Context) ([]StockLevel, error) {
+	rows, err := u.store.LowStock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []StockLevel{}
+	}
+	return rows, nil
+}
+
+func (u *Usecase) ListStockEvents(ctx context.Context, q StockEventQuery) ([]StockEvent, error) {
+	if q.Kind != nil && *q.Kind != EventLowStock && *q.Kind != EventRestocked {
+		return nil, ErrInvalidInput
+	}
+	if q.Limit <= 0 || q.Limit > 200 {
+		q.Limit = 50
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return u.store.ListStockEvents(ctx, q)
+}
+
+// ReorderSuggestions lists stock products that are at their reorder point or
+// would run out within CoverDays at the trailing daily sales rate.
+func (u *Usecase) ReorderSuggestions(ctx context.Context, q SuggestionQuery) (*SuggestionReport, error) {
+	if q.WindowDays == 0 {
+		q.WindowDays = defaultWindowDays
+	}
+	if q.CoverDays == 0 {
+		q.CoverDays = defaultCoverDays
+	}
+	if q.WindowDays < 1 || q.WindowDays > maxWindowDays || q.CoverDays < 1 || q.CoverDays > maxWindowDays {
+		return nil, ErrInvalidInput
+	}
+
+	levels, err := u.store.StockLevelsWithSales(ctx, time.Now().AddDate(0, 0, -q.WindowDays))
+	if err != nil {
+		return nil, err
+	}
+
+	out := &SuggestionReport{WindowDays: q.WindowDays, CoverDays: q.CoverDays, Suggestions: []Suggestion{}}
+	for _, l := range levels {
+		velocity := float64(l.SoldQty) / float64(q.WindowDays)
+		point := 0
+		if l.ReorderPoint != nil {
+			point = *l.ReorderPoint
+		}
+
+		atPoint := l.ReorderPoint != nil && l.Available <= point
+		runsOut := velocity > 0 && float64(l.Available) < velocity*float64(q.CoverDays)
+		if !atPoint && !runsOut {
+			continue
+		}
+
+		qty := int(math.Ceil(float64(point)+velocity*float64(q.CoverDays))) - l.Available
+		if l.ReorderQty != nil && qty < *l.ReorderQty {
+			qty = *l.ReorderQty
+		}
+		if qty <= 0 {
+			continue
+		}
+
+		s := Suggestion{
+			StockLevel:    l,
+			DailyVelocity: strconv.FormatFloat(velocity, 'f', 2, 64),
+			SuggestedQty:  qty,
+		}
+		if velocity > 0 {
+			d := strconv.FormatFloat(float64(l.Available)/velocity, 'f', 1, 64)
+			s.DaysOfCover = &d
+		}
+		out.Suggestions = append(out.Suggestions, s)
+	}
+	return out, nil
+}
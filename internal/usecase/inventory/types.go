@@ -0,0 +1,147 @@
+package inventory
+
+import "time"
+
+// GoodsReceipt is stock received at a purchase cost. Qty/UnitCost are as received
+// (possibly a pack); BaseQty/BaseUnitCost are in units of the stock product.
+type GoodsReceipt struct {
+	ID             string    `json:"id"`
+	ProductID      string    `json:"productId"`
+	StockProductID string    `json:"stockProductId"`
+	LocationID     string    `json:"locationId"`
+	Qty            int       `json:"qty"`
+	UnitCost       string    `json:"unitCost"`
+	BaseQty        int       `json:"baseQty"`
+	BaseUnitCost   string    `json:"baseUnitCost"`
+	StockBefore    int       `json:"stockBefore"`
+	AvgCostBefore  *string   `json:"avgCostBefore,omitempty"`
+	AvgCostAfter   string    `json:"avgCostAfter"`
+	Reference      *string   `json:"reference,omitempty"`
+	Note           *string   `json:"note,omitempty"`
+	ReceivedAt     time.Time `json:"receivedAt"`
+	LotNumber      *string   `json:"lotNumber,omitempty"`
+	ExpiresAt      *string   `json:"expiresAt,omitempty"` // YYYY-MM-DD
+
+	PurchaseOrderLineID *string `json:"purchaseOrderLineId,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ReceiveInput struct {
+	ProductID  string     `json:"productId"`
+	Qty        int        `json:"qty"`
+	UnitCost   string     `json:"unitCost"` // per unit received, base currency
+	Reference  *string    `json:"reference"`
+	Note       *string    `json:"note"`
+	ReceivedAt *time.Time `json:"receivedAt"` // optional (default now)
+	LocationID *string    `json:"locationId"` // optional (default location)
+
+	// optional; either one puts the stock in its own lot for FEFO picking
+	LotNumber    *string    `json:"lotNumber"`
+	ExpiresAtRaw *string    `json:"expiresAt"` // YYYY-MM-DD
+	ExpiresAt    *time.Time `json:"-"`
+}
+
+type ListQuery struct {
+	ProductID *string // stock product
+	Limit     int
+	Offset    int
+}
+
+// ValuationRow is the stock value of one stock product at its average cost.
+// Value is nil when the product has never been costed.
+type ValuationRow struct {
+	ProductID   string  `json:"productId"`
+	SKU         *string `json:"sku,omitempty"`
+	Name        string  `json:"name"`
+	StockOnHand int     `json:"stockOnHand"`
+	AvgCost     *string `json:"avgCost,omitempty"`
+	Value       *string `json:"value,omitempty"`
+}
+
+type Valuation struct {
+	Currency      string         `json:"currency"`
+	Rows          []ValuationRow `json:"rows"`
+	TotalValue    string         `json:"totalValue"`
+	UncostedItems int            `json:"uncostedItems"` // products with stock but no cost
+}
+
+type StockEvent struct {
+	ID              string    `json:"id"`
+	ProductID       string    `json:"productId"`
+	ProductName     string    `json:"productName"`
+	Kind            string    `json:"kind"` // low_stock | restocked
+	AvailableBefore int       `json:"availableBefore"`
+	AvailableAfter  int       `json:"availableAfter"`
+	ReorderPoint    int       `json:"reorderPoint"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+type StockEventQuery struct {
+	Since  *time.Time // exclusive; poll with the last createdAt seen
+	Kind   *string
+	Limit  int
+	Offset int
+}
+
+// StockLevel is a stock product's availability against its reorder settings.
+// SoldQty (base units sold in the window) is filled for suggestions only.
+type StockLevel struct {
+	ProductID    string  `json:"productId"`
+	SKU          *string `json:"sku,omitempty"`
+	Name         string  `json:"name"`
+	StockOnHand  int     `json:"stockOnHand"`
+	Reserved     int     `json:"reserved"`
+	Available    int     `json:"available"`
+	ReorderPoint *int    `json:"reorderPoint,omitempty"`
+	ReorderQty   *int    `json:"reorderQty,omitempty"`
+	SoldQty      int     `json:"soldQty,omitempty"`
+}
+
+type SuggestionQuery struct {
+	WindowDays int // trailing sales window; default 30
+	CoverDays  int // days of sales the reorder should cover; default 14
+}
+
+// Suggestion proposes buying SuggestedQty so that available stock covers
+// CoverDays of average daily sales on top of the reorder point.
+type Suggestion struct {
+	StockLevel
+	DailyVelocity string  `json:"dailyVelocity"`
+	DaysOfCover   *string `json:"daysOfCover,omitempty"` // nil when nothing sold
+	SuggestedQty  int     `json:"suggestedQty"`
+}
+
+type SuggestionReport struct {
+	WindowDays  int          `json:"windowDays"`
+	CoverDays   int          `json:"coverDays"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+type ExpiringQuery struct {
+	Days       int     // window from today; default 30
+	LocationID *string // nil = all locations
+}
+
+// ExpiringLot is a lot with stock left; Expired lots are blocked from sale.
+type ExpiringLot struct {
+	LotID        string  `json:"lotId"`
+	ProductID    string  `json:"productId"`
+	SKU          *string `json:"sku,omitempty"`
+	Name         string  `json:"name"`
+	LocationID   string  `json:"locationId"`
+	LocationName string  `json:"locationName"`
+	LotNumber    *string `json:"lotNumber,omitempty"`
+	ExpiresAt    string  `json:"expiresAt"` // YYYY-MM-DD
+	DaysLeft     int     `json:"daysLeft"`  // negative once expired
+	QtyOnHand    int     `json:"qtyOnHand"` // base units
+	Expired      bool    `json:"expired"`
+}
+
+type ExpiringReport struct {
+	Days        int           `json:"days"`
+	Until       string        `json:"until"` // YYYY-MM-DD, inclusive
+	ExpiringQty int           `json:"expiringQty"`
+	ExpiredQty  int           `json:"expiredQty"`
+	Lots        []ExpiringLot `json:"lots"`
+}
@@ -0,0 +1,105 @@
+package location
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrNotFound        = errors.New("location not found")
+	ErrCodeConflict    = errors.New("location code already exists")
+	ErrDefaultLocation = errors.New("the default location cannot be deactivated or unset")
+)
+
+type Store interface {
+	Create(ctx context.Context, in CreateInput) (*Location, error)
+	GetByID(ctx context.Context, id string) (*Location, error)
+	List(ctx context.Context, q ListQuery) ([]Location, error)
+	Update(ctx context.Context, id string, in UpdateInput) (*Location, error)
+
+	ListStock(ctx context.Context, locationID string, q StockQuery) ([]StockLevel, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Location, error) {
+	in.Code = normalizeCode(in.Code)
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Code == "" || in.Name == "" {
+		return nil, ErrInvalidInput
+	}
+	return u.store.Create(ctx, in)
+}
+
+func (u *Usecase) GetByID(ctx context.Context, id string) (*Location, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetByID(ctx, id)
+}
+
+func (u *Usecase) List(ctx context.Context, q ListQuery) ([]Location, error) {
+	return u.store.List(ctx, q)
+}
+
+func (u *Usecase) Update(ctx context.Context, id string, in UpdateInput) (*Location, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if in.Code != nil {
+		c := normalizeCode(*in.Code)
+		if c == "" {
+			return nil, ErrInvalidInput
+		}
+		in.Code = &c
+	}
+	if in.Name != nil {
+		n := strings.TrimSpace(*in.Name)
+		if n == "" {
+			return nil, ErrInvalidInput
+		}
+		in.Name = &n
+	}
+	if in.IsDefault != nil && !*in.IsDefault {
+		return nil, ErrDefaultLocation
+	}
+	if in.IsDefault != nil && in.IsActive != nil && !*in.IsActive {
+		return nil, ErrDefaultLocation
+	}
+	return u.store.Update(ctx, id, in)
+}
+
+func (u *Usecase) ListStock(ctx context.Context, locationID string, q StockQuery) ([]StockLevel, error) {
+	if _, err := uuid.Parse(locationID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if q.ProductID != nil {
+		if _, err := uuid.Parse(*q.ProductID); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	if q.Limit <= 0 || q.Limit > 500 {
+		q.Limit = 100
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	if _, err := u.store.GetByID(ctx, locationID); err != nil {
+		return nil, err
+	}
+	return u.store.ListStock(ctx, locationID, q)
+}
+
+func normalizeCode(c string) string {
+	return strings.ToUpper(strings.TrimSpace(c))
+}
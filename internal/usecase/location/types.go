@@ -0,0 +1,54 @@
+package location
+
+import "time"
+
+// Location is a place stock is held: a store, a warehouse, a van. Exactly one
+// location is the default; it takes stock and transactions that name none.
+type Location struct {
+	ID        string    `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Address   *string   `json:"address,omitempty"`
+	IsDefault bool      `json:"isDefault"`
+	IsActive  bool      `json:"isActive"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateInput struct {
+	Code    string  `json:"code"`
+	Name    string  `json:"name"`
+	Address *string `json:"address"`
+}
+
+// UpdateInput: isDefault=true moves the default here; it cannot be unset
+// directly, only by making another location the default.
+type UpdateInput struct {
+	Code      *string `json:"code"`
+	Name      *string `json:"name"`
+	Address   *string `json:"address"`
+	IsDefault *bool   `json:"isDefault"`
+	IsActive  *bool   `json:"isActive"`
+}
+
+type ListQuery struct {
+	IsActive *bool
+}
+
+// StockLevel is one stock product's quantity at a location, in base units.
+type StockLevel struct {
+	ProductID string    `json:"productId"`
+	SKU       *string   `json:"sku,omitempty"`
+	Name      string    `json:"name"`
+	OnHand    int       `json:"onHand"`
+	Reserved  int       `json:"reserved"`
+	Available int       `json:"available"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type StockQuery struct {
+	ProductID   *string
+	InStockOnly bool
+	Limit       int
+	Offset      int
+}
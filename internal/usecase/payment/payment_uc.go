@@ -10,21 +10,39 @@ import (
 var (
 	ErrInvalidInput       = errors.New("invalid input")
 	ErrTransactionMissing = errors.New("transaction not found")
+	ErrInsufficientCredit = errors.New("insufficient customer credit")
+	ErrCreditExceedsDue   = errors.New("credit payment exceeds balance due")
+	ErrRateMissing        = errors.New("exchange rate not found")
+	ErrCurrencyMismatch   = errors.New("payment currency does not match transaction currency")
+	ErrNoOpenShift        = errors.New("no open cash shift for this admin")
+)
+
+const (
+	MethodCash     = "cash"
+	MethodTransfer = "transfer"
+	MethodCredit   = "credit" // customer store credit
 )
 
 type Payment struct {
-	ID            string    `json:"id"`
-	TransactionID string    `json:"transactionId"`
-	Method        string    `json:"method"` // cash | transfer
-	Amount        string    `json:"amount"` // keep as string (numeric) for now
-	Currency      string    `json:"currency"`
-	PaidAt        time.Time `json:"paidAt"`
-	SenderName    *string   `json:"senderName,omitempty"`
-	Reference     *string   `json:"reference,omitempty"`
-	Note          *string   `json:"note,omitempty"`
-	Status        string    `json:"status"` // posted | voided
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID            string  `json:"id"`
+	TransactionID string  `json:"transactionId"`
+	ReceiptID     *string `json:"receiptId,omitempty"`
+	Method        string  `json:"method"` // cash | transfer | credit
+	Amount        string  `json:"amount"` // keep as string (numeric) for now
+	Currency      string  `json:"currency"`
+	// tendered amount/currency and the rate used when paid in a foreign currency
+	OriginalAmount   *string   `json:"originalAmount,omitempty"`
+	OriginalCurrency *string   `json:"originalCurrency,omitempty"`
+	ExchangeRate     *string   `json:"exchangeRate,omitempty"`
+	PostedBy         *string   `json:"postedBy,omitempty"` // admin id
+	ShiftID          *string   `json:"shiftId,omitempty"`
+	PaidAt           time.Time `json:"paidAt"`
+	SenderName       *string   `json:"senderName,omitempty"`
+	Reference        *string   `json:"reference,omitempty"`
+	Note             *string   `json:"note,omitempty"`
+	Status           string    `json:"status"` // posted | voided
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
 }
 
 type TransactionPaymentState struct {
@@ -38,24 +56,41 @@ type TransactionPaymentState struct {
 type Store interface {
 	Create(ctx context.Context, in CreateInput) (*Payment, *TransactionPaymentState, error)
 	ListByTransaction(ctx context.Context, transactionID string) ([]Payment, error)
+
+	// CreateReceipt posts the receipt and all its allocations in one database transaction.
+	CreateReceipt(ctx context.Context, in CreateReceiptInput) (*Receipt, error)
+	GetReceipt(ctx context.Context, id string) (*Receipt, error)
+	ListReceiptsByCustomer(ctx context.Context, customerID string, limit, offset int) ([]Receipt, error)
 }
 
 type Usecase struct {
-	store Store
+	store        Store
+	requireShift bool
 }
 
 func New(store Store) *Usecase {
 	return &Usecase{store: store}
 }
 
+// WithShiftsRequired rejects payments and receipts posted by an admin without an open cash shift.
+func (u *Usecase) WithShiftsRequired(required bool) *Usecase {
+	u.requireShift = required
+	return u
+}
+
 type CreateInput struct {
 	TransactionID string     `json:"-"`
 	Method        string     `json:"method"`
 	Amount        string     `json:"amount"`
+	Currency      *string    `json:"currency"` // optional; default is the transaction currency
 	SenderName    *string    `json:"senderName"`
 	Reference     *string    `json:"reference"`
 	Note          *string    `json:"note"`
 	PaidAt        *time.Time `json:"paidAt"` // optional (default now)
+
+	// set by the handler / usecase: the posting admin and whether they must have an open shift
+	PostedBy     *string `json:"-"`
+	RequireShift bool    `json:"-"`
 }
 
 func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Payment, *TransactionPaymentState, error) {
@@ -63,7 +98,7 @@ func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Payment, *Transa
 		return nil, nil, ErrInvalidInput
 	}
 	m := strings.TrimSpace(in.Method)
-	if m != "cash" && m != "transfer" {
+	if m != MethodCash && m != MethodTransfer && m != MethodCredit {
 		return nil, nil, ErrInvalidInput
 	}
 	in.Method = m
@@ -71,6 +106,17 @@ func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Payment, *Transa
 	if strings.TrimSpace(in.Amount) == "" {
 		return nil, nil, ErrInvalidInput
 	}
+	if in.Currency != nil {
+		cur := strings.ToUpper(strings.TrimSpace(*in.Currency))
+		if len(cur) != 3 {
+			return nil, nil, ErrInvalidInput
+		}
+		in.Currency = &cur
+	}
+	if err := u.checkShift(in.PostedBy); err != nil {
+		return nil, nil, err
+	}
+	in.RequireShift = u.requireShift
 
 	return u.store.Create(ctx, in)
 }
@@ -81,3 +127,15 @@ func (u *Usecase) ListByTransaction(ctx context.Context, transactionID string) (
 	}
 	return u.store.ListByTransaction(ctx, transactionID)
 }
+
+// checkShift fails early when shifts are required but nobody is identified as poster;
+// the store verifies the open shift itself inside the posting transaction.
+func (u *Usecase) checkShift(postedBy *string) error {
+	if !u.requireShift {
+		return nil
+	}
+	if postedBy == nil || strings.TrimSpace(*postedBy) == "" {
+		return ErrNoOpenShift
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCustomerMissing          = errors.New("customer not found")
+	ErrReceiptMissing           = errors.New("receipt not found")
+	ErrInvalidAllocation        = errors.New("transaction cannot be allocated to this receipt")
+	ErrAllocationExceedsDue     = errors.New("allocation exceeds transaction balance due")
+	ErrAllocationExceedsReceipt = errors.New("allocations exceed receipt amount")
+)
+
+// Receipt is one sum of money received from a customer, allocated across
+// one or more of their transactions. Each allocation is posted as a Payment
+// with ReceiptID set; whatever is left unallocated becomes store credit.
+type Receipt struct {
+	ID                string              `json:"id"`
+	CustomerID        string              `json:"customerId"`
+	Method            string              `json:"method"` // cash | transfer
+	Amount            string              `json:"amount"`
+	Currency          string              `json:"currency"`
+	ReceivedAt        time.Time           `json:"receivedAt"`
+	SenderName        *string             `json:"senderName,omitempty"`
+	Reference         *string             `json:"reference,omitempty"`
+	Note              *string             `json:"note,omitempty"`
+	UnallocatedAmount string              `json:"unallocatedAmount"` // moved to customer store credit
+	PostedBy          *string             `json:"postedBy,omitempty"`
+	ShiftID           *string             `json:"shiftId,omitempty"`
+	Status            string              `json:"status"`
+	CreatedAt         time.Time           `json:"createdAt"`
+	UpdatedAt         time.Time           `json:"updatedAt"`
+	Allocations       []ReceiptAllocation `json:"allocations"`
+}
+
+type ReceiptAllocation struct {
+	PaymentID     string                   `json:"paymentId"`
+	TransactionID string                   `json:"transactionId"`
+	Amount        string                   `json:"amount"`
+	Transaction   *TransactionPaymentState `json:"transaction,omitempty"`
+}
+
+type CreateReceiptInput struct {
+	CustomerID string     `json:"-"`
+	Method     string     `json:"method"`
+	Amount     string     `json:"amount"`
+	Currency   string     `json:"currency"` // optional, default IDR
+	ReceivedAt *time.Time `json:"receivedAt"`
+	SenderName *string    `json:"senderName"`
+	Reference  *string    `json:"reference"`
+	Note       *string    `json:"note"`
+
+	// optional explicit allocations; empty = FIFO by due date over open transactions
+	Allocations []AllocationInput `json:"allocations"`
+
+	PostedBy     *string `json:"-"`
+	RequireShift bool    `json:"-"`
+}
+
+type AllocationInput struct {
+	TransactionID string `json:"transactionId"`
+	Amount        string `json:"amount"`
+}
+
+func (u *Usecase) CreateReceipt(ctx context.Context, in CreateReceiptInput) (*Receipt, error) {
+	if _, err := uuid.Parse(in.CustomerID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	m := strings.TrimSpace(in.Method)
+	if m != MethodCash && m != MethodTransfer {
+		return nil, ErrInvalidInput
+	}
+	in.Method = m
+
+	amount, ok := new(big.Rat).SetString(strings.TrimSpace(in.Amount))
+	if !ok || amount.Sign() <= 0 {
+		return nil, ErrInvalidInput
+	}
+	in.Amount = amount.FloatString(2)
+
+	in.Currency = strings.ToUpper(strings.TrimSpace(in.Currency))
+	if in.Currency == "" {
+		in.Currency = "IDR"
+	}
+
+	allocated := new(big.Rat)
+	seen := map[string]bool{}
+	for i, a := range in.Allocations {
+		if _, err := uuid.Parse(a.TransactionID); err != nil || seen[a.TransactionID] {
+			return nil, ErrInvalidInput
+		}
+		seen[a.TransactionID] = true
+
+		v, ok := new(big.Rat).SetString(strings.TrimSpace(a.Amount))
+		if !ok || v.Sign() <= 0 {
+			return nil, ErrInvalidInput
+		}
+		in.Allocations[i].Amount = v.FloatString(2)
+		allocated.Add(allocated, v)
+	}
+	if allocated.Cmp(amount) > 0 {
+		return nil, ErrAllocationExceedsReceipt
+	}
+	if err := u.checkShift(in.PostedBy); err != nil {
+		return nil, err
+	}
+	in.RequireShift = u.requireShift
+
+	return u.store.CreateReceipt(ctx, in)
+}
+
+func (u *Usecase) GetReceipt(ctx context.Context, id string) (*Receipt, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetReceipt(ctx, id)
+}
+
+func (u *Usecase) ListReceiptsByCustomer(ctx context.Context, customerID string, limit, offset int) ([]Receipt, error) {
+	if _, err := uuid.Parse(customerID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return u.store.ListReceiptsByCustomer(ctx, customerID, limit, offset)
+}
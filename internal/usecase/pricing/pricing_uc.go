@@ -0,0 +1,201 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrProductMissing  = errors.New("product not found")
+	ErrCustomerMissing = errors.New("customer not found")
+	ErrPriceMissing    = errors.New("price not found")
+)
+
+const (
+	RuleCategory = "category"
+	RuleDefault  = "default"
+)
+
+// max items per quote request
+const maxQuoteItems = 500
+
+type Store interface {
+	// GetStockRule returns ErrProductMissing if the product does not exist.
+	GetStockRule(ctx context.Context, productID string) (stockProductID string, packSize float64, err error)
+	// GetCustomerCategoryID returns ErrCustomerMissing if the customer does not exist.
+	GetCustomerCategoryID(ctx context.Context, customerID string) (*string, error)
+	// ResolvePrice returns nil when no price row applies.
+	ResolvePrice(ctx context.Context, productID string, categoryID *string, qty Qty, at time.Time) (*EffectivePrice, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+// EffectivePrice resolves the price a customer would pay for one product at a moment:
+// the category price if one is valid, otherwise the default price.
+func (u *Usecase) EffectivePrice(ctx context.Context, q EffectivePriceQuery) (*EffectivePriceResult, error) {
+	if _, err := uuid.Parse(q.ProductID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if q.Qty < 0 {
+		return nil, ErrInvalidInput
+	}
+	if q.Qty == 0 {
+		q.Qty = 1
+	}
+
+	at := time.Now()
+	if q.At != nil {
+		at = *q.At
+	}
+
+	categoryID, err := u.customerCategory(ctx, q.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, packSize, err := u.store.GetStockRule(ctx, q.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := u.store.ResolvePrice(ctx, q.ProductID, categoryID, Qty{Line: q.Qty, BaseUnits: float64(q.Qty) * packSize}, at)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrPriceMissing
+	}
+
+	return &EffectivePriceResult{
+		CustomerID:         q.CustomerID,
+		CustomerCategoryID: categoryID,
+		At:                 at,
+		Price:              p,
+	}, nil
+}
+
+// Quote prices a basket without creating a transaction. Items that cannot be priced
+// are reported per line instead of failing the whole quote.
+func (u *Usecase) Quote(ctx context.Context, in QuoteInput) (*Quote, error) {
+	if len(in.Items) == 0 || len(in.Items) > maxQuoteItems {
+		return nil, ErrInvalidInput
+	}
+	for _, it := range in.Items {
+		if _, err := uuid.Parse(it.ProductID); err != nil {
+			return nil, ErrInvalidInput
+		}
+		if it.Qty <= 0 {
+			return nil, ErrInvalidInput
+		}
+	}
+
+	at := time.Now()
+	if in.At != nil {
+		at = *in.At
+	}
+
+	categoryID, err := u.customerCategory(ctx, in.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Quote{
+		CustomerID:         in.CustomerID,
+		CustomerCategoryID: categoryID,
+		At:                 at,
+		Lines:              make([]QuoteLine, 0, len(in.Items)),
+		Totals:             []QuoteTotal{},
+	}
+
+	// base-unit tiers look at everything bought of the same stock product
+	type stockRule struct {
+		stockProductID string
+		packSize       float64
+	}
+	rules := make([]*stockRule, len(in.Items))
+	baseUnits := map[string]float64{}
+	for i, it := range in.Items {
+		stockProductID, packSize, err := u.store.GetStockRule(ctx, it.ProductID)
+		if err != nil {
+			if errors.Is(err, ErrProductMissing) {
+				continue
+			}
+			return nil, err
+		}
+		rules[i] = &stockRule{stockProductID: stockProductID, packSize: packSize}
+		baseUnits[stockProductID] += float64(it.Qty) * packSize
+	}
+
+	totals := map[string]*big.Rat{}
+	currencies := make([]string, 0, 1)
+
+	for i, it := range in.Items {
+		line := QuoteLine{ProductID: it.ProductID, Qty: it.Qty}
+
+		if rules[i] == nil {
+			line.Error = errString(ErrProductMissing)
+			out.Lines = append(out.Lines, line)
+			continue
+		}
+		line.BaseUnits = baseUnits[rules[i].stockProductID]
+
+		p, err := u.store.ResolvePrice(ctx, it.ProductID, categoryID, Qty{Line: it.Qty, BaseUnits: line.BaseUnits}, at)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			line.Error = errString(ErrPriceMissing)
+			out.Lines = append(out.Lines, line)
+			continue
+		}
+
+		unit, ok := new(big.Rat).SetString(p.UnitAmount)
+		if !ok {
+			return nil, errors.New("invalid price amount " + strconv.Quote(p.UnitAmount))
+		}
+		lt := new(big.Rat).Mul(unit, new(big.Rat).SetInt64(int64(it.Qty)))
+		lineTotal := lt.FloatString(2)
+
+		line.Price = p
+		line.LineTotal = &lineTotal
+		out.Lines = append(out.Lines, line)
+
+		if _, seen := totals[p.Currency]; !seen {
+			totals[p.Currency] = new(big.Rat)
+			currencies = append(currencies, p.Currency)
+		}
+		totals[p.Currency].Add(totals[p.Currency], lt)
+	}
+
+	for _, cur := range currencies {
+		out.Totals = append(out.Totals, QuoteTotal{Currency: cur, Amount: totals[cur].FloatString(2)})
+	}
+	return out, nil
+}
+
+func (u *Usecase) customerCategory(ctx context.Context, customerID *string) (*string, error) {
+	if customerID == nil || *customerID == "" {
+		return nil, nil
+	}
+	if _, err := uuid.Parse(*customerID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetCustomerCategoryID(ctx, *customerID)
+}
+
+func errString(err error) *string {
+	s := err.Error()
+	return &s
+}
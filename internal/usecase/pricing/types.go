@@ -0,0 +1,70 @@
+package pricing
+
+import "time"
+
+// EffectivePrice is the price row that applies to a product for a customer category at a moment.
+type EffectivePrice struct {
+	ProductID  string     `json:"productId"`
+	PriceID    string     `json:"priceId"`
+	Rule       string     `json:"rule"` // category | default
+	CategoryID *string    `json:"categoryId,omitempty"`
+	Currency   string     `json:"currency"`
+	UnitAmount string     `json:"unitAmount"`
+	ValidFrom  time.Time  `json:"validFrom"`
+	ValidTo    *time.Time `json:"validTo,omitempty"`
+	MinQty     int        `json:"minQty"`
+	QtyBasis   string     `json:"qtyBasis"` // line | base_units
+}
+
+// Qty is what quantity tiers are compared with.
+type Qty struct {
+	Line      int     // item qty, for qty_basis 'line'
+	BaseUnits float64 // base units across all items of the stock product, for 'base_units'
+}
+
+type EffectivePriceQuery struct {
+	ProductID  string
+	CustomerID *string    // optional: nil resolves the default price
+	Qty        int        // optional: 0 = 1
+	At         *time.Time // optional: nil = now
+}
+
+type EffectivePriceResult struct {
+	CustomerID         *string         `json:"customerId,omitempty"`
+	CustomerCategoryID *string         `json:"customerCategoryId,omitempty"`
+	At                 time.Time       `json:"at"`
+	Price              *EffectivePrice `json:"price"`
+}
+
+type QuoteItemIn struct {
+	ProductID string `json:"productId"`
+	Qty       int    `json:"qty"`
+}
+
+type QuoteInput struct {
+	CustomerID *string       `json:"customerId"`
+	At         *time.Time    `json:"at"`
+	Items      []QuoteItemIn `json:"items"`
+}
+
+type QuoteLine struct {
+	ProductID string          `json:"productId"`
+	Qty       int             `json:"qty"`
+	BaseUnits float64         `json:"baseUnits"` // aggregated over the quote for the stock product
+	Price     *EffectivePrice `json:"price,omitempty"`
+	LineTotal *string         `json:"lineTotal,omitempty"`
+	Error     *string         `json:"error,omitempty"` // product not found / no price
+}
+
+type QuoteTotal struct {
+	Currency string `json:"currency"`
+	Amount   string `json:"amount"`
+}
+
+type Quote struct {
+	CustomerID         *string      `json:"customerId,omitempty"`
+	CustomerCategoryID *string      `json:"customerCategoryId,omitempty"`
+	At                 time.Time    `json:"at"`
+	Lines              []QuoteLine  `json:"lines"`
+	Totals             []QuoteTotal `json:"totals"`
+}
@@ -4,24 +4,39 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
-var ErrInvalidInput = errors.New("invalid input")
+var (
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrNotFound        = errors.New("product not found")
+	ErrHasActivePacks  = errors.New("product is the base product of active packs")
+	ErrHasReservations = errors.New("product has stock reserved by open transactions")
+)
 
 type Product struct {
 	ID            string  `json:"id"`
 	SKU           *string `json:"sku,omitempty"`
 	Name          string  `json:"name"`
 	Description   *string `json:"description,omitempty"`
+	Category      *string `json:"category,omitempty"` // product category code, used by promotions
 	IsActive      bool    `json:"isActive"`
 	StockOnHand   int     `json:"stockOnHand"`
 	StockReserved int     `json:"stockReserved"`
+	AvgCost       *string `json:"avgCost,omitempty"`      // weighted-average unit cost, base currency; nil until first goods receipt
+	ReorderPoint  *int    `json:"reorderPoint,omitempty"` // low-stock threshold on available stock
+	ReorderQty    *int    `json:"reorderQty,omitempty"`   // minimum quantity to reorder
+
+	DeletedAt *time.Time `json:"deletedAt,omitempty"` // archived
 }
 
 type ProductStore interface {
-	Create(ctx context.Context, sku *string, name string, description *string, stockOnHand int) (*Product, error)
-	List(ctx context.Context, limit int, offset int) ([]Product, error)
-	Update(ctx context.Context, id string, sku *string, name *string, description *string, isActive *bool, stockOnHand *int) (*Product, error)
+	Create(ctx context.Context, sku *string, name string, description *string, category *string, stockOnHand int) (*Product, error)
+	List(ctx context.Context, limit int, offset int, includeArchived bool) ([]Product, error)
+	Update(ctx context.Context, id string, sku *string, name *string, description *string, category *string, isActive *bool, stockOnHand *int, reorderPoint *int, reorderQty *int) (*Product, error)
+	SetArchived(ctx context.Context, id string, archived bool) (*Product, error)
 }
 
 type Usecase struct {
@@ -36,6 +51,7 @@ type CreateInput struct {
 	SKU         *string `json:"sku"`
 	Name        string  `json:"name"`
 	Description *string `json:"description"`
+	Category    *string `json:"category"`
 	StockOnHand *int    `json:"stockOnHand"` // optional; default 0
 }
 
@@ -53,25 +69,29 @@ func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Product, error)
 		stock = *in.StockOnHand
 	}
 
-	return u.store.Create(ctx, in.SKU, name, in.Description, stock)
+	return u.store.Create(ctx, in.SKU, name, in.Description, normalizeCategory(in.Category), stock)
 }
 
-func (u *Usecase) List(ctx context.Context, limit, offset int) ([]Product, error) {
+func (u *Usecase) List(ctx context.Context, limit, offset int, includeArchived bool) ([]Product, error) {
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
 	if offset < 0 {
 		offset = 0
 	}
-	return u.store.List(ctx, limit, offset)
+	return u.store.List(ctx, limit, offset, includeArchived)
 }
 
 type UpdateInput struct {
 	SKU         *string `json:"sku"`
 	Name        *string `json:"name"`
 	Description *string `json:"description"`
+	Category    *string `json:"category"`
 	IsActive    *bool   `json:"isActive"`
 	StockOnHand *int    `json:"stockOnHand"`
+
+	ReorderPoint *int `json:"reorderPoint"`
+	ReorderQty   *int `json:"reorderQty"`
 }
 
 func (u *Usecase) Update(ctx context.Context, id string, in UpdateInput) (*Product, error) {
@@ -90,6 +110,39 @@ func (u *Usecase) Update(ctx context.Context, id string, in UpdateInput) (*Produ
 	if in.StockOnHand != nil && *in.StockOnHand < 0 {
 		return nil, ErrInvalidInput
 	}
+	if in.ReorderPoint != nil && *in.ReorderPoint < 0 {
+		return nil, ErrInvalidInput
+	}
+	if in.ReorderQty != nil && *in.ReorderQty <= 0 {
+		return nil, ErrInvalidInput
+	}
+
+	return u.store.Update(ctx, id, in.SKU, in.Name, in.Description, normalizeCategory(in.Category), in.IsActive, in.StockOnHand, in.ReorderPoint, in.ReorderQty)
+}
 
-	return u.store.Update(ctx, id, in.SKU, in.Name, in.Description, in.IsActive, in.StockOnHand)
+// Archive soft-deletes the product: it is hidden from listings and cannot be
+// sold on new transactions. Base products of active packs and products with
+// open reservations are refused.
+func (u *Usecase) Archive(ctx context.Context, id string) (*Product, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.SetArchived(ctx, id, true)
+}
+
+func (u *Usecase) Restore(ctx context.Context, id string) (*Product, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.SetArchived(ctx, id, false)
+}
+
+// normalizeCategory stores category codes lower-case so promotion scopes match
+// regardless of how they were typed.
+func normalizeCategory(c *string) *string {
+	if c == nil {
+		return nil
+	}
+	v := strings.ToLower(strings.TrimSpace(*c))
+	return &v
 }
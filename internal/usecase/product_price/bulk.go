@@ -0,0 +1,245 @@
+package product_price
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCSV = errors.New("invalid price CSV")
+
+const (
+	BulkActionCreate    = "create"
+	BulkActionUnchanged = "unchanged"
+	BulkActionError     = "error"
+)
+
+// max rows per bulk request / import file
+const maxBulkRows = 5000
+
+// BulkCreate schedules many prices at one validFrom in a single DB transaction.
+// Nothing is written when any row fails or when DryRun is set; the result then
+// shows what would change.
+func (u *Usecase) BulkCreate(ctx context.Context, in BulkInput) (*BulkResult, error) {
+	if len(in.Rows) == 0 || len(in.Rows) > maxBulkRows {
+		return nil, ErrInvalidInput
+	}
+	validFrom := time.Now()
+	if in.ValidFrom != nil {
+		validFrom = *in.ValidFrom
+	}
+
+	valid := make([]BulkRow, 0, len(in.Rows))
+	results := make([]BulkRowResult, 0, len(in.Rows))
+	seen := map[string]int{}
+
+	for i, r := range in.Rows {
+		if r.Line == 0 {
+			r.Line = i + 1
+		}
+		r.SKU = strings.TrimSpace(r.SKU)
+		r.Currency = strings.ToUpper(strings.TrimSpace(r.Currency))
+		if r.Currency == "" {
+			r.Currency = "IDR"
+		}
+		if r.CategoryCode != nil {
+			code := strings.TrimSpace(*r.CategoryCode)
+			r.CategoryCode = &code
+			if code == "" {
+				r.CategoryCode = nil
+			}
+		}
+		if r.MinQty == nil {
+			one := 1
+			r.MinQty = &one
+		}
+		if r.QtyBasis == nil || *r.QtyBasis == "" {
+			basis := QtyBasisLine
+			r.QtyBasis = &basis
+		}
+
+		if err := validateBulkRow(&r); err != nil {
+			results = append(results, bulkError(r, err))
+			continue
+		}
+
+		key := fmt.Sprintf("%s|%s|%d", r.SKU, strPtr(r.CategoryCode), *r.MinQty)
+		if prev, dup := seen[key]; dup {
+			results = append(results, bulkError(r, fmt.Errorf("duplicate of line %d", prev)))
+			continue
+		}
+		seen[key] = r.Line
+
+		valid = append(valid, r)
+	}
+
+	failedUpfront := len(results) > 0
+	apply := !in.DryRun && !failedUpfront
+
+	stored := []BulkRowResult{}
+	if len(valid) > 0 {
+		var err error
+		stored, err = u.store.BulkCreate(ctx, validFrom, valid, apply)
+		if err != nil {
+			return nil, err
+		}
+	}
+	results = append(results, stored...)
+	sort.SliceStable(results, func(a, b int) bool { return results[a].Line < results[b].Line })
+
+	out := &BulkResult{
+		ValidFrom: validFrom,
+		DryRun:    in.DryRun,
+		Rows:      results,
+	}
+	for _, r := range results {
+		switch r.Action {
+		case BulkActionCreate:
+			out.Created++
+		case BulkActionUnchanged:
+			out.Unchanged++
+		default:
+			out.Failed++
+		}
+	}
+	out.Applied = apply && out.Failed == 0
+	return out, nil
+}
+
+// ImportCSV reads rows from a CSV with header
+// sku, category (or category_code; empty = default), amount, [currency], [min_qty], [qty_basis]
+// and runs them through BulkCreate.
+func (u *Usecase) ImportCSV(ctx context.Context, data []byte, validFrom *time.Time, dryRun bool) (*BulkResult, error) {
+	rows, err := ParsePriceCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	return u.BulkCreate(ctx, BulkInput{ValidFrom: validFrom, DryRun: dryRun, Rows: rows})
+}
+
+func ParsePriceCSV(data []byte) ([]BulkRow, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	if first, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidCSV)
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == "category_code" {
+			h = "category"
+		}
+		cols[h] = i
+	}
+	if _, ok := cols["sku"]; !ok {
+		return nil, fmt.Errorf("%w: header must contain sku and amount", ErrInvalidCSV)
+	}
+	if _, ok := cols["amount"]; !ok {
+		return nil, fmt.Errorf("%w: header must contain sku and amount", ErrInvalidCSV)
+	}
+
+	get := func(rec []string, key string) string {
+		idx, ok := cols[key]
+		if !ok || idx >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[idx])
+	}
+
+	var out []BulkRow
+	line := 1
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidCSV, line, err)
+		}
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+
+		row := BulkRow{
+			Line:     line,
+			SKU:      get(rec, "sku"),
+			Currency: get(rec, "currency"),
+			Amount:   get(rec, "amount"),
+		}
+		if v := get(rec, "category"); v != "" {
+			row.CategoryCode = &v
+		}
+		if v := get(rec, "min_qty"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				n = 0 // reported by validation
+			}
+			row.MinQty = &n
+		}
+		if v := get(rec, "qty_basis"); v != "" {
+			row.QtyBasis = &v
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+func validateBulkRow(r *BulkRow) error {
+	if r.SKU == "" {
+		return errors.New("sku is required")
+	}
+	amt, ok := new(big.Rat).SetString(r.Amount)
+	if !ok || amt.Sign() < 0 {
+		return errors.New("invalid amount")
+	}
+	r.Amount = amt.FloatString(2)
+	if *r.MinQty < 1 {
+		return errors.New("min_qty must be >= 1")
+	}
+	if *r.QtyBasis != QtyBasisLine && *r.QtyBasis != QtyBasisBaseUnits {
+		return errors.New("qty_basis must be line or base_units")
+	}
+	return nil
+}
+
+func bulkError(r BulkRow, err error) BulkRowResult {
+	msg := err.Error()
+	minQty := 1
+	if r.MinQty != nil {
+		minQty = *r.MinQty
+	}
+	return BulkRowResult{
+		Line:         r.Line,
+		SKU:          r.SKU,
+		CategoryCode: r.CategoryCode,
+		MinQty:       minQty,
+		Currency:     r.Currency,
+		NewAmount:    r.Amount,
+		Action:       BulkActionError,
+		Error:        &msg,
+	}
+}
+
+func strPtr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -6,10 +6,29 @@ import (
 	"time"
 )
 
+var (
+	ErrInvalidInput   = errors.New("invalid input")
+	ErrInvalidWindow  = errors.New("validTo must be after validFrom")
+	ErrPriceOverlap   = errors.New("price window overlaps an existing price for this product and category")
+	ErrProductMissing = errors.New("product not found")
+	ErrNotFound       = errors.New("price not found")
+)
+
+const (
+	QtyBasisLine      = "line"
+	QtyBasisBaseUnits = "base_units"
+)
+
 type Store interface {
+	// CreateForProduct inserts the price and, in the same DB transaction, closes the
+	// open price it supersedes (see CreateInput).
 	CreateForProduct(ctx context.Context, productID string, in CreateInput) (*ProductPrice, error)
 	ListForProduct(ctx context.Context, productID string) ([]ProductPrice, error)
 	Update(ctx context.Context, priceID string, in UpdateInput) (*ProductPrice, error)
+	ListUpcoming(ctx context.Context, q UpcomingQuery) ([]UpcomingPrice, error)
+	// BulkCreate resolves SKUs / category codes and schedules every row in one DB
+	// transaction, committing only when apply is true and no row failed.
+	BulkCreate(ctx context.Context, validFrom time.Time, rows []BulkRow, apply bool) ([]BulkRowResult, error)
 }
 
 type Usecase struct {
@@ -22,7 +41,7 @@ func New(store Store) *Usecase {
 
 func (u *Usecase) CreateForProduct(ctx context.Context, productID string, in CreateInput) (*ProductPrice, error) {
 	if productID == "" {
-		return nil, errors.New("product id is required")
+		return nil, ErrInvalidInput
 	}
 	if in.Currency == "" {
 		in.Currency = "IDR"
@@ -32,21 +51,53 @@ func (u *Usecase) CreateForProduct(ctx context.Context, productID string, in Cre
 		in.ValidFrom = &now
 	}
 	if in.Amount == "" {
-		return nil, errors.New("amount is required")
+		return nil, ErrInvalidInput
+	}
+	if in.ValidTo != nil && !in.ValidTo.After(*in.ValidFrom) {
+		return nil, ErrInvalidWindow
+	}
+	if in.MinQty == nil {
+		one := 1
+		in.MinQty = &one
+	}
+	if *in.MinQty < 1 {
+		return nil, ErrInvalidInput
+	}
+	if in.QtyBasis == nil || *in.QtyBasis == "" {
+		basis := QtyBasisLine
+		in.QtyBasis = &basis
+	}
+	if *in.QtyBasis != QtyBasisLine && *in.QtyBasis != QtyBasisBaseUnits {
+		return nil, ErrInvalidInput
 	}
 	return u.store.CreateForProduct(ctx, productID, in)
 }
 
 func (u *Usecase) ListForProduct(ctx context.Context, productID string) ([]ProductPrice, error) {
 	if productID == "" {
-		return nil, errors.New("product id is required")
+		return nil, ErrInvalidInput
 	}
 	return u.store.ListForProduct(ctx, productID)
 }
 
 func (u *Usecase) Update(ctx context.Context, priceID string, in UpdateInput) (*ProductPrice, error) {
 	if priceID == "" {
-		return nil, errors.New("price id is required")
+		return nil, ErrInvalidInput
+	}
+	if in.ValidFrom != nil && in.ValidTo != nil && !in.ValidTo.After(*in.ValidFrom) {
+		return nil, ErrInvalidWindow
 	}
 	return u.store.Update(ctx, priceID, in)
 }
+
+// ListUpcoming returns prices scheduled to start within the next q.Days days,
+// together with the price each one replaces.
+func (u *Usecase) ListUpcoming(ctx context.Context, q UpcomingQuery) ([]UpcomingPrice, error) {
+	if q.Days <= 0 {
+		q.Days = 30
+	}
+	if q.Days > 366 {
+		return nil, ErrInvalidInput
+	}
+	return u.store.ListUpcoming(ctx, q)
+}
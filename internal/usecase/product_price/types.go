@@ -10,16 +10,26 @@ type ProductPrice struct {
 	Amount     string     `json:"amount"`
 	ValidFrom  time.Time  `json:"validFrom"`
 	ValidTo    *time.Time `json:"validTo,omitempty"`
+	MinQty     int        `json:"minQty"`
+	QtyBasis   string     `json:"qtyBasis"` // line | base_units
 	CreatedAt  time.Time  `json:"createdAt"`
 	UpdatedAt  time.Time  `json:"updatedAt"`
 }
 
+// CreateInput schedules a price. An open-ended price (no validTo) closes the open
+// price of the same product/category at validFrom; a bounded one (temporary price)
+// splits it, so the previous amount resumes at validTo.
+//
+// MinQty (default 1) makes the row a quantity-break tier; QtyBasis "base_units"
+// compares MinQty with the base units bought across all packs of the stock product.
 type CreateInput struct {
 	CategoryID *string    `json:"categoryId"`
 	Currency   string     `json:"currency"`
 	Amount     string     `json:"amount"`
 	ValidFrom  *time.Time `json:"validFrom"`
 	ValidTo    *time.Time `json:"validTo"`
+	MinQty     *int       `json:"minQty"`
+	QtyBasis   *string    `json:"qtyBasis"`
 }
 
 type UpdateInput struct {
@@ -29,3 +39,59 @@ type UpdateInput struct {
 	ValidFrom  *time.Time `json:"validFrom"`
 	ValidTo    *time.Time `json:"validTo"`
 }
+
+type UpcomingQuery struct {
+	ProductID *string
+	Days      int
+}
+
+type UpcomingPrice struct {
+	ProductPrice
+	ProductSKU  string `json:"productSku"`
+	ProductName string `json:"productName"`
+	// price currently in effect for the same product/category, if any
+	CurrentPriceID *string `json:"currentPriceId,omitempty"`
+	CurrentAmount  *string `json:"currentAmount,omitempty"`
+}
+
+// BulkRow is one price to schedule, addressed by SKU and customer category code
+// (empty = default price).
+type BulkRow struct {
+	Line         int     `json:"line"` // CSV line number; set from position for JSON input
+	SKU          string  `json:"sku"`
+	CategoryCode *string `json:"categoryCode"`
+	Currency     string  `json:"currency"`
+	Amount       string  `json:"amount"`
+	MinQty       *int    `json:"minQty"`
+	QtyBasis     *string `json:"qtyBasis"`
+}
+
+type BulkInput struct {
+	ValidFrom *time.Time `json:"validFrom"` // shared by all rows; nil = now
+	DryRun    bool       `json:"dryRun"`
+	Rows      []BulkRow  `json:"rows"`
+}
+
+type BulkRowResult struct {
+	Line         int     `json:"line"`
+	SKU          string  `json:"sku"`
+	CategoryCode *string `json:"categoryCode,omitempty"`
+	ProductID    *string `json:"productId,omitempty"`
+	MinQty       int     `json:"minQty"`
+	Currency     string  `json:"currency"`
+	OldAmount    *string `json:"oldAmount,omitempty"`
+	NewAmount    string  `json:"newAmount"`
+	Action       string  `json:"action"` // create | unchanged | error
+	PriceID      *string `json:"priceId,omitempty"`
+	Error        *string `json:"error,omitempty"`
+}
+
+type BulkResult struct {
+	ValidFrom time.Time       `json:"validFrom"`
+	DryRun    bool            `json:"dryRun"`
+	Applied   bool            `json:"applied"`
+	Created   int             `json:"created"`
+	Unchanged int             `json:"unchanged"`
+	Failed    int             `json:"failed"`
+	Rows      []BulkRowResult `json:"rows"`
+}
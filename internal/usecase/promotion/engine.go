@@ -0,0 +1,178 @@
+package promotion
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Evaluate applies promotions to the priced lines of a transaction in currency
+// and returns one Discount per (line, promotion) pair that produced a non-zero
+// amount.
+//
+// Rules:
+//   - promotions run by priority (higher first), then by start time
+//   - a line never discounts below zero; each promotion works on what is left
+//   - a non-stackable promotion skips lines that already have a discount, and
+//     lines it discounts accept no further promotions
+//   - amount_off is spent across eligible lines in order until exhausted, and
+//     is skipped when its currency is not the transaction's
+func Evaluate(promos []Promotion, currency string, lines []Line) []Discount {
+	ordered := make([]Promotion, len(promos))
+	copy(ordered, promos)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Priority != ordered[j].Priority {
+			return ordered[i].Priority > ordered[j].Priority
+		}
+		return ordered[i].StartsAt.Before(ordered[j].StartsAt)
+	})
+
+	remaining := make([]*big.Rat, len(lines))
+	discounted := make([]bool, len(lines))
+	exclusive := make([]bool, len(lines))
+	for i, l := range lines {
+		remaining[i] = ratOrZero(l.LineTotal)
+	}
+
+	var out []Discount
+	for _, p := range ordered {
+		if p.Kind == KindAmountOff && deref(p.Currency) != currency {
+			continue
+		}
+
+		var eligible []int
+		for i, l := range lines {
+			if exclusive[i] || remaining[i].Sign() <= 0 || !inScope(p, l) {
+				continue
+			}
+			if !p.Stackable && discounted[i] {
+				continue
+			}
+			eligible = append(eligible, i)
+		}
+		if len(eligible) == 0 {
+			continue
+		}
+
+		apply := func(i int, amt *big.Rat, desc string) {
+			amt = roundMoney(amt)
+			if amt.Cmp(remaining[i]) > 0 {
+				amt = new(big.Rat).Set(remaining[i])
+			}
+			if amt.Sign() <= 0 {
+				return
+			}
+			remaining[i].Sub(remaining[i], amt)
+			discounted[i] = true
+			if !p.Stackable {
+				exclusive[i] = true
+			}
+			out = append(out, Discount{
+				LineIndex:     lines[i].Index,
+				PromotionID:   p.ID,
+				PromotionCode: p.Code,
+				Description:   desc,
+				Amount:        amt.FloatString(2),
+			})
+		}
+
+		switch p.Kind {
+		case KindPercentOff:
+			pct := ratOrZero(deref(p.Percent))
+			desc := fmt.Sprintf("%s: %s%% off", p.Name, trimRat(pct))
+			for _, i := range eligible {
+				amt := new(big.Rat).Mul(remaining[i], pct)
+				apply(i, amt.Quo(amt, big.NewRat(100, 1)), desc)
+			}
+
+		case KindBuyXGetY:
+			buy, get := derefInt(p.BuyQty), derefInt(p.GetQty)
+			desc := fmt.Sprintf("%s: buy %d get %d", p.Name, buy, get)
+			for _, i := range eligible {
+				free := (lines[i].Qty / (buy + get)) * get
+				if free <= 0 {
+					continue
+				}
+				amt := new(big.Rat).Mul(ratOrZero(lines[i].UnitAmount), big.NewRat(int64(free), 1))
+				apply(i, amt, desc)
+			}
+
+		case KindAmountOff:
+			left := ratOrZero(deref(p.Amount))
+			desc := fmt.Sprintf("%s: %s off", p.Name, left.FloatString(2))
+			for _, i := range eligible {
+				if left.Sign() <= 0 {
+					break
+				}
+				amt := new(big.Rat).Set(left)
+				if amt.Cmp(remaining[i]) > 0 {
+					amt.Set(remaining[i])
+				}
+				left.Sub(left, amt)
+				apply(i, amt, desc)
+			}
+		}
+	}
+	return out
+}
+
+func inScope(p Promotion, l Line) bool {
+	switch p.Scope {
+	case ScopeAll:
+		return true
+	case ScopeProducts:
+		for _, id := range p.ProductIDs {
+			if id == l.ProductID {
+				return true
+			}
+		}
+	case ScopeProductCategories:
+		if l.Category == nil {
+			return false
+		}
+		for _, c := range p.ProductCategories {
+			if c == *l.Category {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func roundMoney(r *big.Rat) *big.Rat {
+	return ratOrZero(r.FloatString(2))
+}
+
+func ratOrZero(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// trimRat formats 10.00 as "10" and 12.50 as "12.5" for descriptions.
+func trimRat(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	s := r.FloatString(2)
+	if s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefInt(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
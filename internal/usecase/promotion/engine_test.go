@@ -0,0 +1,108 @@
+package promotion
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Now()
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+
+	percent := func(code, pct string, priority int, stackable bool) Promotion {
+		return Promotion{ID: code, Code: code, Name: code, Kind: KindPercentOff, Percent: str(pct),
+			Scope: ScopeAll, Priority: priority, Stackable: stackable, StartsAt: now}
+	}
+	amountOff := func(code, amount string) Promotion {
+		return Promotion{ID: code, Code: code, Name: code, Kind: KindAmountOff, Amount: str(amount), Currency: str("IDR"),
+			Scope: ScopeAll, Stackable: true, StartsAt: now}
+	}
+	line := func(i, qty int, unit string) Line {
+		total := ratOrZero(unit)
+		total.Mul(total, ratOrZero(fmt.Sprint(qty)))
+		return Line{Index: i, ProductID: fmt.Sprintf("p%d", i), Qty: qty, UnitAmount: unit, LineTotal: total.FloatString(2)}
+	}
+
+	later := percent("LATER", "10", 1, true)
+	later.StartsAt = now.Add(time.Hour)
+	drinks := Promotion{ID: "B2G1", Code: "B2G1", Name: "B2G1", Kind: KindBuyXGetY, BuyQty: num(2), GetQty: num(1),
+		Scope: ScopeProductCategories, ProductCategories: []string{"minuman"}, StartsAt: now}
+	drinkLine := line(0, 7, "5000")
+	drinkLine.Category = str("minuman")
+	pairLine := line(1, 2, "5000")
+	pairLine.Category = str("minuman")
+
+	cases := []struct {
+		name     string
+		promos   []Promotion
+		currency string // default IDR
+		lines    []Line
+		want     string // code:line:amount, in evaluation order
+	}{
+		{
+			name:   "higher priority first, each on what is left",
+			promos: []Promotion{percent("LOW", "10", 1, true), percent("HIGH", "50", 5, true)},
+			lines:  []Line{line(0, 1, "100000")},
+			want:   "HIGH:0:50000.00 LOW:0:5000.00",
+		},
+		{
+			name:   "equal priority runs by start time",
+			promos: []Promotion{later, percent("EARLY", "50", 1, true)},
+			lines:  []Line{line(0, 1, "100000")},
+			want:   "EARLY:0:50000.00 LATER:0:5000.00",
+		},
+		{
+			name:   "non-stackable line takes no further promotions",
+			promos: []Promotion{percent("EXCL", "10", 5, false), percent("ALL", "10", 1, true)},
+			lines:  []Line{line(0, 1, "10000")},
+			want:   "EXCL:0:1000.00",
+		},
+		{
+			name:   "non-stackable skips lines already discounted",
+			promos: []Promotion{percent("ALL", "10", 5, true), percent("EXCL", "20", 1, false)},
+			lines:  []Line{line(0, 1, "10000")},
+			want:   "ALL:0:1000.00",
+		},
+		{
+			name:   "buy 2 get 1 frees whole groups only",
+			promos: []Promotion{drinks},
+			lines:  []Line{drinkLine, pairLine, line(2, 3, "70000")},
+			want:   "B2G1:0:10000.00",
+		},
+		{
+			name:   "amount_off spreads across lines in order",
+			promos: []Promotion{amountOff("RP15K", "15000")},
+			lines:  []Line{line(0, 1, "10000"), line(1, 1, "8000")},
+			want:   "RP15K:0:10000.00 RP15K:1:5000.00",
+		},
+		{
+			name:   "amount_off is capped by what the lines hold",
+			promos: []Promotion{amountOff("RP50K", "50000")},
+			lines:  []Line{line(0, 1, "10000"), line(1, 1, "8000")},
+			want:   "RP50K:0:10000.00 RP50K:1:8000.00",
+		},
+		{
+			name:     "amount_off in another currency is skipped",
+			promos:   []Promotion{amountOff("RP10K", "10000"), percent("ALL", "10", 0, true)},
+			currency: "USD",
+			lines:    []Line{line(0, 2, "25")},
+			want:     "ALL:0:5.00",
+		},
+	}
+	for _, c := range cases {
+		currency := c.currency
+		if currency == "" {
+			currency = "IDR"
+		}
+		var got []string
+		for _, d := range Evaluate(c.promos, currency, c.lines) {
+			got = append(got, fmt.Sprintf("%s:%d:%s", d.PromotionCode, d.LineIndex, d.Amount))
+		}
+		if g := strings.Join(got, " "); g != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, g, c.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrNotFound        = errors.New("promotion not found")
+	ErrCodeConflict    = errors.New("promotion code already exists")
+	ErrCustomerMissing = errors.New("customer not found")
+	ErrProductMissing  = errors.New("product not found")
+	ErrCategoryMissing = errors.New("customer category not found")
+)
+
+const (
+	KindPercentOff = "percent_off"
+	KindAmountOff  = "amount_off"
+	KindBuyXGetY   = "buy_x_get_y"
+)
+
+const (
+	ScopeAll               = "all"
+	ScopeProducts          = "products"
+	ScopeProductCategories = "product_categories"
+)
+
+type Store interface {
+	Create(ctx context.Context, in CreateInput) (*Promotion, error)
+	GetByID(ctx context.Context, id string) (*Promotion, error)
+	List(ctx context.Context, q ListQuery) ([]Promotion, error)
+	Update(ctx context.Context, id string, in UpdateInput) (*Promotion, error)
+
+	// ListApplicable returns active promotions running at `at` that the customer's
+	// category is eligible for (ErrCustomerMissing if the customer does not exist).
+	ListApplicable(ctx context.Context, customerID string, at time.Time) ([]Promotion, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Promotion, error) {
+	in.Code = strings.ToUpper(strings.TrimSpace(in.Code))
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Code == "" || in.Name == "" {
+		return nil, ErrInvalidInput
+	}
+	if in.StartsAt == nil {
+		now := time.Now()
+		in.StartsAt = &now
+	}
+	if in.EndsAt != nil && !in.EndsAt.After(*in.StartsAt) {
+		return nil, ErrInvalidInput
+	}
+
+	switch in.Kind {
+	case KindPercentOff:
+		if !positiveAmount(in.Percent) || ratOrZero(strings.TrimSpace(*in.Percent)).Cmp(big.NewRat(100, 1)) > 0 {
+			return nil, ErrInvalidInput
+		}
+		in.Amount, in.Currency, in.BuyQty, in.GetQty = nil, nil, nil, nil
+	case KindAmountOff:
+		if !positiveAmount(in.Amount) {
+			return nil, ErrInvalidInput
+		}
+		cur := "IDR"
+		if in.Currency != nil {
+			cur = strings.ToUpper(strings.TrimSpace(*in.Currency))
+		}
+		if len(cur) != 3 {
+			return nil, ErrInvalidInput
+		}
+		in.Currency = &cur
+		in.Percent, in.BuyQty, in.GetQty = nil, nil, nil
+	case KindBuyXGetY:
+		if in.BuyQty == nil || in.GetQty == nil || *in.BuyQty <= 0 || *in.GetQty <= 0 {
+			return nil, ErrInvalidInput
+		}
+		in.Percent, in.Amount, in.Currency = nil, nil, nil
+	default:
+		return nil, ErrInvalidInput
+	}
+
+	if in.Scope == "" {
+		in.Scope = ScopeAll
+	}
+	switch in.Scope {
+	case ScopeAll:
+		in.ProductIDs, in.ProductCategories = nil, nil
+	case ScopeProducts:
+		if len(in.ProductIDs) == 0 {
+			return nil, ErrInvalidInput
+		}
+		for _, id := range in.ProductIDs {
+			if _, err := uuid.Parse(id); err != nil {
+				return nil, ErrInvalidInput
+			}
+		}
+		in.ProductCategories = nil
+	case ScopeProductCategories:
+		if len(in.ProductCategories) == 0 {
+			return nil, ErrInvalidInput
+		}
+		for i, c := range in.ProductCategories {
+			in.ProductCategories[i] = strings.ToLower(strings.TrimSpace(c))
+		}
+		in.ProductIDs = nil
+	default:
+		return nil, ErrInvalidInput
+	}
+	for _, id := range in.CustomerCategoryIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+
+	return u.store.Create(ctx, in)
+}
+
+func (u *Usecase) GetByID(ctx context.Context, id string) (*Promotion, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetByID(ctx, id)
+}
+
+func (u *Usecase) List(ctx context.Context, q ListQuery) ([]Promotion, error) {
+	if q.Limit <= 0 || q.Limit > 100 {
+		q.Limit = 20
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return u.store.List(ctx, q)
+}
+
+func (u *Usecase) Update(ctx context.Context, id string, in UpdateInput) (*Promotion, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if in.Name != nil {
+		n := strings.TrimSpace(*in.Name)
+		if n == "" {
+			return nil, ErrInvalidInput
+		}
+		in.Name = &n
+	}
+	return u.store.Update(ctx, id, in)
+}
+
+// Applicable lists the promotions a customer gets at a moment, in evaluation order.
+func (u *Usecase) Applicable(ctx context.Context, customerID string, at time.Time) ([]Promotion, error) {
+	if _, err := uuid.Parse(customerID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.ListApplicable(ctx, customerID, at)
+}
+
+func positiveAmount(s *string) bool {
+	if s == nil {
+		return false
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(*s))
+	return ok && r.Sign() > 0
+}
@@ -0,0 +1,81 @@
+package promotion
+
+import "time"
+
+type Promotion struct {
+	ID                  string     `json:"id"`
+	Code                string     `json:"code"`
+	Name                string     `json:"name"`
+	Kind                string     `json:"kind"` // percent_off | amount_off | buy_x_get_y
+	Percent             *string    `json:"percent,omitempty"`
+	Amount              *string    `json:"amount,omitempty"`
+	Currency            *string    `json:"currency,omitempty"` // amount_off only
+	BuyQty              *int       `json:"buyQty,omitempty"`
+	GetQty              *int       `json:"getQty,omitempty"`
+	Scope               string     `json:"scope"` // all | products | product_categories
+	ProductIDs          []string   `json:"productIds"`
+	ProductCategories   []string   `json:"productCategories"`
+	CustomerCategoryIDs []string   `json:"customerCategoryIds"` // empty = every customer
+	StartsAt            time.Time  `json:"startsAt"`
+	EndsAt              *time.Time `json:"endsAt,omitempty"`
+	Stackable           bool       `json:"stackable"`
+	Priority            int        `json:"priority"`
+	IsActive            bool       `json:"isActive"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+}
+
+type CreateInput struct {
+	Code                string     `json:"code"`
+	Name                string     `json:"name"`
+	Kind                string     `json:"kind"`
+	Percent             *string    `json:"percent"`
+	Amount              *string    `json:"amount"`
+	Currency            *string    `json:"currency"` // amount_off only, default IDR
+	BuyQty              *int       `json:"buyQty"`
+	GetQty              *int       `json:"getQty"`
+	Scope               string     `json:"scope"`
+	ProductIDs          []string   `json:"productIds"`
+	ProductCategories   []string   `json:"productCategories"`
+	CustomerCategoryIDs []string   `json:"customerCategoryIds"`
+	StartsAt            *time.Time `json:"startsAt"` // default now
+	EndsAt              *time.Time `json:"endsAt"`
+	Stackable           bool       `json:"stackable"`
+	Priority            int        `json:"priority"`
+}
+
+// UpdateInput changes scheduling/flags only; rule parameters are immutable so
+// recorded discounts keep describing what was applied.
+type UpdateInput struct {
+	Name      *string    `json:"name"`
+	StartsAt  *time.Time `json:"startsAt"`
+	EndsAt    *time.Time `json:"endsAt"`
+	Stackable *bool      `json:"stackable"`
+	Priority  *int       `json:"priority"`
+	IsActive  *bool      `json:"isActive"`
+}
+
+type ListQuery struct {
+	ActiveAt *time.Time // only promotions running at that moment
+	Limit    int
+	Offset   int
+}
+
+// Line is one priced transaction line the engine evaluates.
+type Line struct {
+	Index      int
+	ProductID  string
+	Category   *string // product category code
+	Qty        int
+	UnitAmount string
+	LineTotal  string
+}
+
+// Discount is one discount produced by one promotion on one line.
+type Discount struct {
+	LineIndex     int
+	PromotionID   string
+	PromotionCode string
+	Description   string
+	Amount        string
+}
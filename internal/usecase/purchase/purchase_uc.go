@@ -0,0 +1,164 @@
+package purchase
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrNotFound          = errors.New("purchase order not found")
+	ErrSupplierMissing   = errors.New("supplier not found or inactive")
+	ErrProductMissing    = errors.New("product not found")
+	ErrLineMissing       = errors.New("purchase order line not found")
+	ErrInvalidTransition = errors.New("invalid purchase order status transition")
+	ErrOverReceipt       = errors.New("received quantity exceeds what is outstanding")
+	ErrFractionalStock   = errors.New("received quantity is not a whole number of stock units")
+	ErrLocationMissing   = errors.New("location not found")
+)
+
+const (
+	StatusDraft             = "draft"
+	StatusOrdered           = "ordered"
+	StatusPartiallyReceived = "partially_received"
+	StatusReceived          = "received"
+	StatusCancelled         = "cancelled"
+)
+
+type Store interface {
+	Create(ctx context.Context, in CreateInput) (*PurchaseOrder, error)
+	GetByID(ctx context.Context, id string) (*PurchaseOrder, error)
+	List(ctx context.Context, q ListQuery) ([]PurchaseOrder, error)
+
+	// SetStatus moves the order to `to` if it is currently in one of `from`,
+	// otherwise ErrInvalidTransition.
+	SetStatus(ctx context.Context, id string, from []string, to string) (*PurchaseOrder, error)
+
+	// Receive books each line into stock through a goods receipt and advances
+	// the order to partially_received or received, in one DB transaction.
+	Receive(ctx context.Context, id string, in ReceiveInput) (*ReceiveResult, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+func (u *Usecase) Create(ctx context.Context, in CreateInput) (*PurchaseOrder, error) {
+	if _, err := uuid.Parse(in.SupplierID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if len(in.Lines) == 0 {
+		return nil, ErrInvalidInput
+	}
+	for i := range in.Lines {
+		l := &in.Lines[i]
+		if _, err := uuid.Parse(l.ProductID); err != nil {
+			return nil, ErrInvalidInput
+		}
+		if l.Qty <= 0 {
+			return nil, ErrInvalidInput
+		}
+		cost, ok := new(big.Rat).SetString(strings.TrimSpace(l.UnitCost))
+		if !ok || cost.Sign() < 0 {
+			return nil, ErrInvalidInput
+		}
+		l.UnitCost = cost.FloatString(4)
+	}
+	return u.store.Create(ctx, in)
+}
+
+func (u *Usecase) GetByID(ctx context.Context, id string) (*PurchaseOrder, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetByID(ctx, id)
+}
+
+func (u *Usecase) List(ctx context.Context, q ListQuery) ([]PurchaseOrder, error) {
+	if q.SupplierID != nil {
+		if _, err := uuid.Parse(*q.SupplierID); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	if q.Status != nil && !validStatus(*q.Status) {
+		return nil, ErrInvalidInput
+	}
+	if q.Limit <= 0 || q.Limit > 200 {
+		q.Limit = 50
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return u.store.List(ctx, q)
+}
+
+// Order sends a draft to the supplier; only ordered POs can be received.
+func (u *Usecase) Order(ctx context.Context, id string) (*PurchaseOrder, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.SetStatus(ctx, id, []string{StatusDraft}, StatusOrdered)
+}
+
+// Cancel is allowed until the first goods are received.
+func (u *Usecase) Cancel(ctx context.Context, id string) (*PurchaseOrder, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.SetStatus(ctx, id, []string{StatusDraft, StatusOrdered}, StatusCancelled)
+}
+
+func (u *Usecase) Receive(ctx context.Context, id string, in ReceiveInput) (*ReceiveResult, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if len(in.Lines) == 0 {
+		return nil, ErrInvalidInput
+	}
+	seen := map[string]bool{}
+	for i := range in.Lines {
+		l := &in.Lines[i]
+		if _, err := uuid.Parse(l.LineID); err != nil || l.Qty <= 0 || seen[l.LineID] {
+			return nil, ErrInvalidInput
+		}
+		seen[l.LineID] = true
+
+		if l.LotNumber != nil {
+			n := strings.TrimSpace(*l.LotNumber)
+			if n == "" {
+				l.LotNumber = nil
+			} else {
+				l.LotNumber = &n
+			}
+		}
+		if l.ExpiresAtRaw != nil && *l.ExpiresAtRaw != "" {
+			d, err := time.Parse("2006-01-02", *l.ExpiresAtRaw)
+			if err != nil {
+				return nil, ErrInvalidInput
+			}
+			l.ExpiresAt = &d
+		}
+	}
+	if in.ReceivedAt == nil {
+		now := time.Now()
+		in.ReceivedAt = &now
+	}
+	return u.store.Receive(ctx, id, in)
+}
+
+func validStatus(s string) bool {
+	switch s {
+	case StatusDraft, StatusOrdered, StatusPartiallyReceived, StatusReceived, StatusCancelled:
+		return true
+	}
+	return false
+}
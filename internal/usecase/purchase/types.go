@@ -0,0 +1,90 @@
+package purchase
+
+import "time"
+
+type PurchaseOrder struct {
+	ID           string     `json:"id"`
+	SupplierID   string     `json:"supplierId"`
+	SupplierName string     `json:"supplierName"`
+	LocationID   *string    `json:"locationId,omitempty"` // nil = default location
+	Status       string     `json:"status"`
+	Reference    *string    `json:"reference,omitempty"`
+	ExpectedAt   *time.Time `json:"expectedAt,omitempty"`
+	Note         *string    `json:"note,omitempty"`
+	TotalAmount  string     `json:"totalAmount"` // base currency
+	OrderedAt    *time.Time `json:"orderedAt,omitempty"`
+	ReceivedAt   *time.Time `json:"receivedAt,omitempty"`
+	CancelledAt  *time.Time `json:"cancelledAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+
+	Lines []Line `json:"lines,omitempty"`
+}
+
+// Line is ordered per product as sold by the supplier (possibly a pack);
+// receiving converts to the stock product.
+type Line struct {
+	ID           string `json:"id"`
+	ProductID    string `json:"productId"`
+	ProductName  string `json:"productName"`
+	Qty          int    `json:"qty"`
+	UnitCost     string `json:"unitCost"`
+	LineTotal    string `json:"lineTotal"`
+	ReceivedQty  int    `json:"receivedQty"`
+	RemainingQty int    `json:"remainingQty"`
+}
+
+type LineInput struct {
+	ProductID string `json:"productId"`
+	Qty       int    `json:"qty"`
+	UnitCost  string `json:"unitCost"`
+}
+
+type CreateInput struct {
+	SupplierID string      `json:"supplierId"`
+	LocationID *string     `json:"locationId"` // optional; where the goods are received
+	Reference  *string     `json:"reference"`
+	ExpectedAt *time.Time  `json:"expectedAt"`
+	Note       *string     `json:"note"`
+	Lines      []LineInput `json:"lines"`
+}
+
+type ListQuery struct {
+	SupplierID *string
+	Status     *string
+	Limit      int
+	Offset     int
+}
+
+type ReceiveLineInput struct {
+	LineID string `json:"lineId"`
+	Qty    int    `json:"qty"`
+
+	// optional; either one puts the received stock in its own lot
+	LotNumber    *string    `json:"lotNumber"`
+	ExpiresAtRaw *string    `json:"expiresAt"` // YYYY-MM-DD
+	ExpiresAt    *time.Time `json:"-"`
+}
+
+type ReceiveInput struct {
+	Lines      []ReceiveLineInput `json:"lines"`
+	Reference  *string            `json:"reference"` // delivery note
+	Note       *string            `json:"note"`
+	ReceivedAt *time.Time         `json:"receivedAt"` // optional (default now)
+}
+
+// Receipt is the goods receipt booked for one received line.
+type Receipt struct {
+	ID             string `json:"id"`
+	LineID         string `json:"lineId"`
+	ProductID      string `json:"productId"`
+	Qty            int    `json:"qty"`
+	StockProductID string `json:"stockProductId"`
+	BaseQty        int    `json:"baseQty"`
+	AvgCostAfter   string `json:"avgCostAfter"`
+}
+
+type ReceiveResult struct {
+	Order    *PurchaseOrder `json:"order"`
+	Receipts []Receipt      `json:"receipts"`
+}
@@ -0,0 +1,209 @@
+package receivable
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrCustomerMissing = errors.New("customer not found")
+)
+
+const defaultStatementDays = 90
+
+type Store interface {
+	// ListAging returns one row per customer + currency with outstanding > 0 as of asOf.
+	ListAging(ctx context.Context, q AgingQuery) ([]AgingRow, error)
+	GetCustomerName(ctx context.Context, customerID string) (string, error)
+	// GetStatementOpeningBalance sums all statement entries before q.From.
+	GetStatementOpeningBalance(ctx context.Context, q StatementQuery) (string, error)
+	// ListStatementLines returns entries in [q.From, q.To) ordered by time; Balance is left empty.
+	ListStatementLines(ctx context.Context, q StatementQuery) ([]StatementLine, error)
+	// GetExchangeRate returns the from -> to rate effective at `at`; ok=false when none is recorded.
+	GetExchangeRate(ctx context.Context, from, to string, at time.Time) (rate string, ok bool, err error)
+}
+
+type Usecase struct {
+	store        Store
+	baseCurrency string
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+// WithBaseCurrency makes Aging also report a total converted to the base currency.
+func (u *Usecase) WithBaseCurrency(currency string) *Usecase {
+	u.baseCurrency = strings.ToUpper(strings.TrimSpace(currency))
+	return u
+}
+
+func (u *Usecase) Aging(ctx context.Context, q AgingQuery) (*AgingReport, error) {
+	if q.AsOf.IsZero() {
+		q.AsOf = time.Now()
+	}
+	if q.CustomerID != nil {
+		if _, err := uuid.Parse(*q.CustomerID); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+
+	rows, err := u.store.ListAging(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	// totals per currency
+	order := make([]string, 0, 1)
+	sums := map[string]*[6]*big.Rat{}
+	for _, r := range rows {
+		s, ok := sums[r.Currency]
+		if !ok {
+			s = &[6]*big.Rat{new(big.Rat), new(big.Rat), new(big.Rat), new(big.Rat), new(big.Rat), new(big.Rat)}
+			sums[r.Currency] = s
+			order = append(order, r.Currency)
+		}
+		for i, v := range []string{r.Current, r.Days1To30, r.Days31To60, r.Days61To90, r.Days90Plus, r.Total} {
+			s[i].Add(s[i], parseMoney(v))
+		}
+	}
+
+	totals := make([]AgingTotal, 0, len(order))
+	for _, cur := range order {
+		s := sums[cur]
+		totals = append(totals, AgingTotal{
+			Currency: cur,
+			AgingBuckets: AgingBuckets{
+				Current:    s[0].FloatString(2),
+				Days1To30:  s[1].FloatString(2),
+				Days31To60: s[2].FloatString(2),
+				Days61To90: s[3].FloatString(2),
+				Days90Plus: s[4].FloatString(2),
+				Total:      s[5].FloatString(2),
+			},
+		})
+	}
+
+	if rows == nil {
+		rows = []AgingRow{}
+	}
+	out := &AgingReport{
+		AsOf:   q.AsOf.Format("2006-01-02"),
+		Rows:   rows,
+		Totals: totals,
+	}
+	if u.baseCurrency != "" {
+		if err := u.addBaseTotal(ctx, out, q.AsOf); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (u *Usecase) addBaseTotal(ctx context.Context, report *AgingReport, at time.Time) error {
+	var base [6]*big.Rat
+	for i := range base {
+		base[i] = new(big.Rat)
+	}
+
+	for _, t := range report.Totals {
+		rate := big.NewRat(1, 1)
+		if t.Currency != u.baseCurrency {
+			s, ok, err := u.store.GetExchangeRate(ctx, t.Currency, u.baseCurrency, at)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				report.MissingRates = append(report.MissingRates, t.Currency)
+				continue
+			}
+			rate = parseMoney(s)
+		}
+		for i, v := range []string{t.Current, t.Days1To30, t.Days31To60, t.Days61To90, t.Days90Plus, t.Total} {
+			converted := new(big.Rat).Mul(parseMoney(v), rate)
+			base[i].Add(base[i], parseMoney(converted.FloatString(2)))
+		}
+	}
+
+	report.BaseTotal = &AgingTotal{
+		Currency: u.baseCurrency,
+		AgingBuckets: AgingBuckets{
+			Current:    base[0].FloatString(2),
+			Days1To30:  base[1].FloatString(2),
+			Days31To60: base[2].FloatString(2),
+			Days61To90: base[3].FloatString(2),
+			Days90Plus: base[4].FloatString(2),
+			Total:      base[5].FloatString(2),
+		},
+	}
+	return nil
+}
+
+// Statement lists invoices, payments and credit conversions for one customer with a running balance.
+func (u *Usecase) Statement(ctx context.Context, q StatementQuery) (*Statement, error) {
+	if _, err := uuid.Parse(q.CustomerID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	q.Currency = strings.ToUpper(strings.TrimSpace(q.Currency))
+	if q.Currency == "" {
+		q.Currency = "IDR"
+	}
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.AddDate(0, 0, -defaultStatementDays)
+	}
+	if !q.From.Before(q.To) {
+		return nil, ErrInvalidInput
+	}
+
+	name, err := u.store.GetCustomerName(ctx, q.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	opening, err := u.store.GetStatementOpeningBalance(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := u.store.ListStatementLines(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	running := parseMoney(opening)
+	for i := range lines {
+		running.Add(running, parseMoney(lines[i].Amount))
+		lines[i].Balance = running.FloatString(2)
+	}
+
+	if lines == nil {
+		lines = []StatementLine{}
+	}
+	return &Statement{
+		CustomerID:     q.CustomerID,
+		CustomerName:   name,
+		Currency:       q.Currency,
+		From:           q.From,
+		To:             q.To,
+		OpeningBalance: parseMoney(opening).FloatString(2),
+		ClosingBalance: running.FloatString(2),
+		Lines:          lines,
+	}, nil
+}
+
+func parseMoney(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
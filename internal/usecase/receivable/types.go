@@ -0,0 +1,77 @@
+package receivable
+
+import "time"
+
+// AgingBuckets splits outstanding amounts by days past due date.
+type AgingBuckets struct {
+	Current    string `json:"current"` // not yet due
+	Days1To30  string `json:"days1To30"`
+	Days31To60 string `json:"days31To60"`
+	Days61To90 string `json:"days61To90"`
+	Days90Plus string `json:"days90Plus"`
+	Total      string `json:"total"`
+}
+
+type AgingRow struct {
+	CustomerID   string `json:"customerId"`
+	CustomerName string `json:"customerName"`
+	Currency     string `json:"currency"`
+	AgingBuckets
+}
+
+type AgingTotal struct {
+	Currency string `json:"currency"`
+	AgingBuckets
+}
+
+type AgingReport struct {
+	AsOf   string       `json:"asOf"` // YYYY-MM-DD
+	Rows   []AgingRow   `json:"rows"`
+	Totals []AgingTotal `json:"totals"`
+
+	// all currencies converted at the rates effective at AsOf; currencies
+	// without a rate are listed in MissingRates and left out of BaseTotal
+	BaseTotal    *AgingTotal `json:"baseTotal,omitempty"`
+	MissingRates []string    `json:"missingRates,omitempty"`
+}
+
+type AgingQuery struct {
+	AsOf       time.Time
+	CustomerID *string
+}
+
+const (
+	LineInvoice          = "invoice"
+	LinePayment          = "payment"
+	LineCreditConversion = "credit_conversion" // overpayment moved out of receivables into store credit
+)
+
+type StatementLine struct {
+	Kind          string     `json:"kind"`
+	RefID         string     `json:"refId"`
+	TransactionID *string    `json:"transactionId,omitempty"`
+	OccurredAt    time.Time  `json:"occurredAt"`
+	DueDate       *time.Time `json:"dueDate,omitempty"`
+	Method        *string    `json:"method,omitempty"`
+	Reference     *string    `json:"reference,omitempty"`
+	Amount        string     `json:"amount"`  // + increases what the customer owes, - decreases it
+	Balance       string     `json:"balance"` // running balance after this line
+}
+
+type Statement struct {
+	CustomerID     string          `json:"customerId"`
+	CustomerName   string          `json:"customerName"`
+	Currency       string          `json:"currency"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance string          `json:"openingBalance"`
+	ClosingBalance string          `json:"closingBalance"`
+	Lines          []StatementLine `json:"lines"`
+}
+
+type StatementQuery struct {
+	CustomerID string
+	Currency   string
+	From       time.Time
+	To         time.Time
+}
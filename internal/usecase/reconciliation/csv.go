@@ -0,0 +1,388 @@
+package reconciliation
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	BankBCA     = "bca"
+	BankMandiri = "mandiri"
+)
+
+// ParseStatement reads a bank mutation CSV export.
+//
+// bca:     Tanggal Transaksi, Keterangan, Cabang, Jumlah ("1,500,000.00 CR" or CR/DB in the next column), Saldo
+// mandiri: Tanggal/Date, Keterangan/Description, [Reference], Debit, Kredit/Credit, [Saldo/Balance]
+//
+// Preamble rows before the header and footer rows (Saldo Awal, etc.) are skipped.
+// BCA rows only carry dd/mm; their year comes from the "Periode" preamble row,
+// else from year, else from today (a statement never lists future dates).
+func ParseStatement(bank string, data []byte, year int) ([]ParsedLine, error) {
+	records, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch bank {
+	case BankBCA:
+		return parseBCA(records, year, time.Now())
+	case BankMandiri:
+		return parseMandiri(records)
+	default:
+		return nil, fmt.Errorf("%w: unsupported bank %q", ErrInvalidStatement, bank)
+	}
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM from Excel
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+
+	// some exports use ';' as separator
+	head := data
+	if len(head) > 2048 {
+		head = head[:2048]
+	}
+	if bytes.Count(head, []byte(";")) > bytes.Count(head, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	var out [][]string
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+		}
+		for i := range rec {
+			rec[i] = strings.TrimSpace(rec[i])
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+// findHeader returns the header row index and a column index per wanted key.
+// Each key accepts several aliases (lower-case).
+func findHeader(records [][]string, required map[string][]string, optional map[string][]string) (int, map[string]int, bool) {
+	for i, rec := range records {
+		cols := map[string]int{}
+		for key, aliases := range required {
+			if idx := indexOfAny(rec, aliases); idx >= 0 {
+				cols[key] = idx
+			}
+		}
+		if len(cols) != len(required) {
+			continue
+		}
+		for key, aliases := range optional {
+			if idx := indexOfAny(rec, aliases); idx >= 0 {
+				cols[key] = idx
+			}
+		}
+		return i, cols, true
+	}
+	return 0, nil, false
+}
+
+func indexOfAny(rec []string, aliases []string) int {
+	for i, cell := range rec {
+		c := strings.ToLower(strings.TrimSpace(cell))
+		for _, a := range aliases {
+			if c == a {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func cell(rec []string, cols map[string]int, key string) string {
+	idx, ok := cols[key]
+	if !ok || idx >= len(rec) {
+		return ""
+	}
+	return rec[idx]
+}
+
+func parseBCA(records [][]string, year int, now time.Time) ([]ParsedLine, error) {
+	hdr, cols, ok := findHeader(records,
+		map[string][]string{
+			"date":   {"tanggal transaksi", "tanggal"},
+			"desc":   {"keterangan"},
+			"amount": {"jumlah"},
+		},
+		map[string][]string{
+			"balance": {"saldo"},
+		},
+	)
+	if !ok {
+		return nil, fmt.Errorf("%w: BCA header (Tanggal, Keterangan, Jumlah) not found", ErrInvalidStatement)
+	}
+	// dd/mm rows are dated on or before end, the newest date the statement can hold
+	end, ok := bcaPeriodEnd(records[:hdr])
+	switch {
+	case ok:
+	case year > 0:
+		end = time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	default:
+		end = dateOnly(now)
+	}
+
+	out := make([]ParsedLine, 0, len(records)-hdr)
+	for i := hdr + 1; i < len(records); i++ {
+		rec := records[i]
+		rawDate := strings.TrimPrefix(cell(rec, cols, "date"), "'")
+		if !looksLikeDate(rawDate) {
+			continue // PEND rows, footer (Saldo Awal, Mutasi Kredit, ...)
+		}
+
+		date, err := parseDate(rawDate, end.Year())
+		if err == nil && date.After(end) {
+			// a December row in a statement that ends in January
+			date, err = parseDate(rawDate, end.Year()-1)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidStatement, i+1, err)
+		}
+
+		rawAmt := strings.ToUpper(cell(rec, cols, "amount"))
+		// newer exports put the CR/DB marker in its own column right after Jumlah
+		if next := cols["amount"] + 1; next < len(rec) {
+			if m := strings.ToUpper(rec[next]); m == "CR" || m == "DB" {
+				rawAmt += m
+			}
+		}
+		direction := ""
+		switch {
+		case strings.HasSuffix(rawAmt, "CR"):
+			direction = DirectionCredit
+			rawAmt = strings.TrimSpace(strings.TrimSuffix(rawAmt, "CR"))
+		case strings.HasSuffix(rawAmt, "DB"):
+			direction = DirectionDebit
+			rawAmt = strings.TrimSpace(strings.TrimSuffix(rawAmt, "DB"))
+		default:
+			return nil, fmt.Errorf("%w: row %d: amount %q has no CR/DB marker", ErrInvalidStatement, i+1, rawAmt)
+		}
+
+		amt, err := parseAmount(rawAmt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidStatement, i+1, err)
+		}
+
+		line := ParsedLine{
+			LineNo:      len(out) + 1,
+			BookedAt:    date,
+			Description: cell(rec, cols, "desc"),
+			Direction:   direction,
+			Amount:      amt,
+		}
+		if b := cell(rec, cols, "balance"); b != "" {
+			if v, err := parseAmount(b); err == nil {
+				line.Balance = &v
+			}
+		}
+		out = append(out, line)
+	}
+	return out, nil
+}
+
+// bcaPeriodEnd reads the last date of the "Periode : 01/12/2025 - 31/12/2025"
+// preamble row.
+func bcaPeriodEnd(preamble [][]string) (time.Time, bool) {
+	for _, rec := range preamble {
+		row := strings.Join(rec, " ")
+		if !strings.Contains(strings.ToLower(row), "periode") {
+			continue
+		}
+		dates := periodDate.FindAllString(row, -1)
+		if len(dates) == 0 {
+			continue
+		}
+		if t, err := parseDate(dates[len(dates)-1], 0); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+var periodDate = regexp.MustCompile(`\d{1,2}/\d{1,2}/\d{4}`)
+
+func parseMandiri(records [][]string) ([]ParsedLine, error) {
+	hdr, cols, ok := findHeader(records,
+		map[string][]string{
+			"date":   {"tanggal", "date", "posting date", "tanggal transaksi"},
+			"desc":   {"keterangan", "description", "remarks"},
+			"debit":  {"debit", "debet"},
+			"credit": {"credit", "kredit"},
+		},
+		map[string][]string{
+			"reference": {"reference", "reference no.", "reference no", "no. referensi", "referensi"},
+			"balance":   {"saldo", "balance"},
+		},
+	)
+	if !ok {
+		return nil, fmt.Errorf("%w: Mandiri header (Tanggal, Keterangan, Debit, Kredit) not found", ErrInvalidStatement)
+	}
+
+	out := make([]ParsedLine, 0, len(records)-hdr)
+	for i := hdr + 1; i < len(records); i++ {
+		rec := records[i]
+		rawDate := cell(rec, cols, "date")
+		if !looksLikeDate(rawDate) {
+			continue
+		}
+
+		date, err := parseDate(rawDate, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidStatement, i+1, err)
+		}
+
+		direction, rawAmt := DirectionCredit, cell(rec, cols, "credit")
+		if isZeroAmount(rawAmt) {
+			direction, rawAmt = DirectionDebit, cell(rec, cols, "debit")
+		}
+		if isZeroAmount(rawAmt) {
+			continue
+		}
+
+		amt, err := parseAmount(rawAmt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidStatement, i+1, err)
+		}
+
+		line := ParsedLine{
+			LineNo:      len(out) + 1,
+			BookedAt:    date,
+			Description: cell(rec, cols, "desc"),
+			Direction:   direction,
+			Amount:      amt,
+		}
+		if ref := cell(rec, cols, "reference"); ref != "" {
+			line.Reference = &ref
+		}
+		if b := cell(rec, cols, "balance"); b != "" {
+			if v, err := parseAmount(b); err == nil {
+				line.Balance = &v
+			}
+		}
+		out = append(out, line)
+	}
+	return out, nil
+}
+
+func looksLikeDate(s string) bool {
+	if s == "" || !strings.ContainsAny(s, "0123456789") {
+		return false
+	}
+	return strings.ContainsAny(s, "/-") || strings.Count(s, " ") == 2
+}
+
+var dateLayouts = []string{
+	"02/01/2006",
+	"2/1/2006",
+	"02/01/06",
+	"2006-01-02",
+	"02-01-2006",
+	"02 Jan 2006",
+	"2 Jan 2006",
+}
+
+// parseDate accepts the common bank formats; "dd/mm" (BCA) takes the given year.
+func parseDate(s string, year int) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if year > 0 && strings.Count(s, "/") == 1 {
+		s = fmt.Sprintf("%s/%d", s, year)
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// parseAmount normalizes "1,500,000.00", "1.500.000,00", "1.500.000" and
+// "1500000" to "1500000.00". A separator followed by exactly three digits, or
+// used more than once, groups thousands; a single one followed by one or two
+// digits is the decimal point. When both appear, the last one is the decimal
+// point.
+func parseAmount(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "Rp"), "IDR")
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	raw := s
+
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+
+	var thousands, decimal string
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		thousands, decimal = ",", "."
+		if lastComma > lastDot {
+			thousands, decimal = ".", ","
+		}
+	case lastDot >= 0:
+		thousands, decimal = separatorRole(s, ".")
+	case lastComma >= 0:
+		thousands, decimal = separatorRole(s, ",")
+	}
+
+	intPart, frac := s, ""
+	if decimal != "" {
+		i := strings.LastIndex(s, decimal)
+		intPart, frac = s[:i], s[i+1:]
+		if len(frac) == 0 || len(frac) > 2 || strings.Contains(intPart, decimal) {
+			return "", fmt.Errorf("invalid amount %q", raw)
+		}
+	}
+	if thousands != "" {
+		groups := strings.Split(intPart, thousands)
+		for i, g := range groups {
+			if g == "" || (i > 0 && len(g) != 3) || (i == 0 && len(g) > 3) {
+				return "", fmt.Errorf("invalid amount %q", raw)
+			}
+		}
+		intPart = strings.Join(groups, "")
+	}
+
+	s = intPart
+	if frac != "" {
+		s += "." + frac
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() < 0 {
+		return "", fmt.Errorf("invalid amount %q", raw)
+	}
+	return r.FloatString(2), nil
+}
+
+// separatorRole decides whether sep, the only separator in s, groups
+// thousands or marks the decimals.
+func separatorRole(s, sep string) (thousands, decimal string) {
+	if strings.Count(s, sep) > 1 || len(s)-strings.LastIndex(s, sep)-1 == 3 {
+		return sep, ""
+	}
+	return "", sep
+}
+
+func isZeroAmount(s string) bool {
+	if strings.TrimSpace(s) == "" || strings.TrimSpace(s) == "-" {
+		return true
+	}
+	v, err := parseAmount(s)
+	return err == nil && v == "0.00"
+}
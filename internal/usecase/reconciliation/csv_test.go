@@ -0,0 +1,101 @@
+package reconciliation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "150.000", want: "150000.00"},
+		{in: "1.500", want: "1500.00"},
+		{in: "1.500.000", want: "1500000.00"},
+		{in: "1.500.000,00", want: "1500000.00"},
+		{in: "1,500,000.00", want: "1500000.00"},
+		{in: "150000", want: "150000.00"},
+		{in: "1,500", want: "1500.00"},
+		{in: "150000.5", want: "150000.50"},
+		{in: "150000,50", want: "150000.50"},
+		{in: "Rp 1.250.000", want: "1250000.00"},
+		{in: "1.50.000", err: true},
+		{in: "1.500,000", err: true},
+		{in: "-5", err: true},
+	}
+	for _, c := range cases {
+		got, err := parseAmount(c.in)
+		if c.err {
+			if err == nil {
+				t.Errorf("parseAmount(%q) = %q, want error", c.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseAmount(%q): %v", c.in, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("parseAmount(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseBCAYear(t *testing.T) {
+	const rows = `Tanggal Transaksi,Keterangan,Cabang,Jumlah,,Saldo
+'30/12,TRSF E-BANKING CR BUDI,'0000,"150,000.00",CR,
+'02/01,TRSF E-BANKING CR SITI,'0000,"250,000.00",CR,
+`
+	cases := []struct {
+		name     string
+		preamble string
+		year     int
+		now      time.Time
+		want     []string
+	}{
+		{
+			name:     "period header spanning new year",
+			preamble: "Periode : ,15/12/2025 - 14/01/2026\n",
+			now:      time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			want:     []string{"2025-12-30", "2026-01-02"},
+		},
+		{
+			name:     "period header wins over year",
+			preamble: "Periode : ,01/12/2025 - 31/12/2025\n",
+			year:     2026,
+			now:      time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+			want:     []string{"2025-12-30", "2025-01-02"},
+		},
+		{
+			name: "explicit year",
+			year: 2024,
+			now:  time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+			want: []string{"2024-12-30", "2024-01-02"},
+		},
+		{
+			name: "december statement imported in january",
+			now:  time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+			want: []string{"2025-12-30", "2026-01-02"},
+		},
+	}
+	for _, c := range cases {
+		records, err := readCSV([]byte(c.preamble + rows))
+		if err != nil {
+			t.Fatalf("%s: read: %v", c.name, err)
+		}
+		lines, err := parseBCA(records, c.year, c.now)
+		if err != nil {
+			t.Fatalf("%s: parse: %v", c.name, err)
+		}
+		if len(lines) != len(c.want) {
+			t.Fatalf("%s: got %d lines, want %d", c.name, len(lines), len(c.want))
+		}
+		for i, l := range lines {
+			if got := l.BookedAt.Format("2006-01-02"); got != c.want[i] {
+				t.Errorf("%s: line %d booked %s, want %s", c.name, i+1, got, c.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package reconciliation
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// candidates are searched within this many days of the booking date
+	matchWindowDays = 3
+	// minimum score for an automatic suggestion
+	suggestThreshold = 60
+)
+
+// score rates how likely a bank line corresponds to a candidate. Amount is
+// already equal (candidates are filtered by exact amount), which gives the base 50;
+// date proximity adds up to 30, a reference found in the description 20 and
+// sender-name overlap up to 20.
+func score(l Line, c Candidate) int {
+	s := 50
+
+	days := int(absDuration(dateOnly(c.PaidAt).Sub(dateOnly(l.BookedAt))).Hours() / 24)
+	if d := 30 - 10*days; d > 0 {
+		s += d
+	}
+
+	haystack := normalize(l.Description)
+	if l.Reference != nil {
+		haystack += normalize(*l.Reference)
+	}
+	if c.Reference != nil {
+		if ref := normalize(*c.Reference); len(ref) >= 4 && strings.Contains(haystack, ref) {
+			s += 20
+		}
+	}
+
+	if c.SenderName != nil {
+		tokens := nameTokens(*c.SenderName)
+		if len(tokens) > 0 {
+			hit := 0
+			for _, t := range tokens {
+				if strings.Contains(haystack, t) {
+					hit++
+				}
+			}
+			s += 20 * hit / len(tokens)
+		}
+	}
+
+	if s > 100 {
+		s = 100
+	}
+	return s
+}
+
+type scoredPair struct {
+	line      int // index into lines
+	candidate Candidate
+	score     int
+}
+
+type suggestion struct {
+	Line      Line
+	Candidate Candidate
+	Score     int
+}
+
+// assign picks at most one candidate per line and uses each candidate at most
+// once, best scores first.
+func assign(lines []Line, candidates [][]Candidate) []suggestion {
+	pairs := make([]scoredPair, 0, len(lines))
+	for i := range lines {
+		for _, c := range candidates[i] {
+			if sc := score(lines[i], c); sc >= suggestThreshold {
+				pairs = append(pairs, scoredPair{line: i, candidate: c, score: sc})
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(a, b int) bool {
+		if pairs[a].score != pairs[b].score {
+			return pairs[a].score > pairs[b].score
+		}
+		return lines[pairs[a].line].LineNo < lines[pairs[b].line].LineNo
+	})
+
+	usedLine := map[int]bool{}
+	usedTarget := map[string]bool{}
+	out := make([]suggestion, 0, len(pairs))
+	for _, p := range pairs {
+		key := p.candidate.Kind + ":" + p.candidate.ID
+		if usedLine[p.line] || usedTarget[key] {
+			continue
+		}
+		usedLine[p.line] = true
+		usedTarget[key] = true
+		out = append(out, suggestion{Line: lines[p.line], Candidate: p.candidate, Score: p.score})
+	}
+	return out
+}
+
+// normalize keeps only upper-cased letters and digits, so "INV-0012" matches "INV0012".
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+func nameTokens(name string) []string {
+	out := make([]string, 0, 3)
+	for _, f := range strings.Fields(name) {
+		if t := normalize(f); len(t) >= 3 {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,308 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrInvalidStatement  = errors.New("invalid bank statement")
+	ErrEmptyStatement    = errors.New("bank statement has no mutation lines")
+	ErrImportMissing     = errors.New("bank statement import not found")
+	ErrLineMissing       = errors.New("bank statement line not found")
+	ErrTargetMissing     = errors.New("payment or receipt not found")
+	ErrNotTransfer       = errors.New("only posted transfer payments or receipts can be reconciled")
+	ErrNotCreditLine     = errors.New("only incoming (credit) lines can be matched")
+	ErrAmountMismatch    = errors.New("amount does not match bank line")
+	ErrAlreadyReconciled = errors.New("already reconciled")
+)
+
+const (
+	DirectionCredit = "credit"
+	DirectionDebit  = "debit"
+)
+
+const (
+	StatusUnmatched = "unmatched"
+	StatusSuggested = "suggested"
+	StatusConfirmed = "confirmed"
+	StatusFlagged   = "flagged"
+)
+
+const (
+	TargetPayment = "payment"
+	TargetReceipt = "receipt"
+)
+
+type Store interface {
+	CreateImport(ctx context.Context, in Import, lines []ParsedLine) (*Import, []Line, error)
+	GetImport(ctx context.Context, id string) (*Import, error)
+	ListLines(ctx context.Context, importID string, status string) ([]Line, error)
+	GetLine(ctx context.Context, id string) (*Line, error)
+
+	// FindCandidates returns posted transfer payments/receipts with the exact amount
+	// that are not yet suggested for or confirmed against another bank line.
+	FindCandidates(ctx context.Context, q CandidateQuery) ([]Candidate, error)
+	// GetTarget returns the payment/receipt as a candidate (nil if missing,
+	// ErrNotTransfer if it is not a posted transfer).
+	GetTarget(ctx context.Context, kind, id string) (*Candidate, error)
+
+	SuggestMatch(ctx context.Context, lineID string, c Candidate, score int) error
+	ConfirmLine(ctx context.Context, lineID string, c Candidate, note *string) (*Line, error)
+	FlagLine(ctx context.Context, lineID string, note *string) (*Line, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+// Import parses a bank mutation CSV, stores the lines not imported before in the
+// staging table and runs the automatic matcher over the incoming (credit) lines.
+func (u *Usecase) Import(ctx context.Context, in ImportInput) (*ImportResult, error) {
+	in.Bank = strings.ToLower(strings.TrimSpace(in.Bank))
+	in.Currency = strings.ToUpper(strings.TrimSpace(in.Currency))
+	if in.Currency == "" {
+		in.Currency = "IDR"
+	}
+	if in.Bank == "" || len(in.Data) == 0 {
+		return nil, ErrInvalidInput
+	}
+
+	parsed, err := ParseStatement(in.Bank, in.Data, in.Year)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed) == 0 {
+		return nil, ErrEmptyStatement
+	}
+
+	imp, lines, err := u.store.CreateImport(ctx, Import{
+		Bank:      in.Bank,
+		AccountNo: in.AccountNo,
+		FileName:  in.FileName,
+		Currency:  in.Currency,
+		LineCount: len(parsed),
+	}, parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := u.autoMatch(ctx, imp, lines); err != nil {
+		return nil, err
+	}
+	res, err := u.result(ctx, imp)
+	if err != nil {
+		return nil, err
+	}
+	res.Duplicates = len(parsed) - imp.LineCount
+	return res, nil
+}
+
+// Match re-runs the automatic matcher for the still unmatched lines of an import
+// (e.g. after missing transfer payments were posted).
+func (u *Usecase) Match(ctx context.Context, importID string) (*ImportResult, error) {
+	if _, err := uuid.Parse(importID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	imp, err := u.store.GetImport(ctx, importID)
+	if err != nil {
+		return nil, err
+	}
+	if imp == nil {
+		return nil, ErrImportMissing
+	}
+
+	lines, err := u.store.ListLines(ctx, importID, StatusUnmatched)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := u.autoMatch(ctx, imp, lines); err != nil {
+		return nil, err
+	}
+	return u.result(ctx, imp)
+}
+
+func (u *Usecase) ListLines(ctx context.Context, importID string, status string) ([]Line, error) {
+	if _, err := uuid.Parse(importID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	switch status {
+	case "", StatusUnmatched, StatusSuggested, StatusConfirmed, StatusFlagged:
+	default:
+		return nil, ErrInvalidInput
+	}
+
+	imp, err := u.store.GetImport(ctx, importID)
+	if err != nil {
+		return nil, err
+	}
+	if imp == nil {
+		return nil, ErrImportMissing
+	}
+	return u.store.ListLines(ctx, importID, status)
+}
+
+// Confirm reconciles a bank line with a payment or receipt. Without a target the
+// current suggestion is accepted.
+func (u *Usecase) Confirm(ctx context.Context, lineID string, in ConfirmInput) (*Line, error) {
+	if _, err := uuid.Parse(lineID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if in.PaymentID != nil && in.ReceiptID != nil {
+		return nil, ErrInvalidInput
+	}
+
+	line, err := u.store.GetLine(ctx, lineID)
+	if err != nil {
+		return nil, err
+	}
+	if line == nil {
+		return nil, ErrLineMissing
+	}
+	if line.Status == StatusConfirmed {
+		return nil, ErrAlreadyReconciled
+	}
+	if line.Direction != DirectionCredit {
+		return nil, ErrNotCreditLine
+	}
+
+	kind, id := TargetPayment, in.PaymentID
+	if in.ReceiptID != nil {
+		kind, id = TargetReceipt, in.ReceiptID
+	}
+	if id == nil {
+		// accept suggestion
+		switch {
+		case line.PaymentID != nil:
+			id = line.PaymentID
+		case line.ReceiptID != nil:
+			kind, id = TargetReceipt, line.ReceiptID
+		default:
+			return nil, ErrInvalidInput
+		}
+	}
+	if _, err := uuid.Parse(*id); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	target, err := u.store.GetTarget(ctx, kind, *id)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrTargetMissing
+	}
+
+	imp, err := u.store.GetImport(ctx, line.ImportID)
+	if err != nil {
+		return nil, err
+	}
+	if imp == nil {
+		return nil, ErrImportMissing
+	}
+	if !sameAmount(target.Amount, line.Amount) || target.Currency != imp.Currency {
+		return nil, ErrAmountMismatch
+	}
+
+	return u.store.ConfirmLine(ctx, lineID, *target, trimPtr(in.Note))
+}
+
+// Flag marks a line for follow-up (unknown sender, bank fee, refund, ...).
+func (u *Usecase) Flag(ctx context.Context, lineID string, in FlagInput) (*Line, error) {
+	if _, err := uuid.Parse(lineID); err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	line, err := u.store.GetLine(ctx, lineID)
+	if err != nil {
+		return nil, err
+	}
+	if line == nil {
+		return nil, ErrLineMissing
+	}
+	if line.Status == StatusConfirmed {
+		return nil, ErrAlreadyReconciled
+	}
+
+	return u.store.FlagLine(ctx, lineID, trimPtr(in.Note))
+}
+
+func (u *Usecase) autoMatch(ctx context.Context, imp *Import, lines []Line) (int, error) {
+	credits := make([]Line, 0, len(lines))
+	for _, l := range lines {
+		if l.Direction == DirectionCredit && l.Status == StatusUnmatched {
+			credits = append(credits, l)
+		}
+	}
+
+	candidates := make([][]Candidate, len(credits))
+	for i, l := range credits {
+		day := dateOnly(l.BookedAt)
+		cs, err := u.store.FindCandidates(ctx, CandidateQuery{
+			Amount:   l.Amount,
+			Currency: imp.Currency,
+			From:     day.AddDate(0, 0, -matchWindowDays),
+			To:       day.AddDate(0, 0, matchWindowDays+1),
+		})
+		if err != nil {
+			return 0, err
+		}
+		candidates[i] = cs
+	}
+
+	suggestions := assign(credits, candidates)
+	for _, s := range suggestions {
+		if err := u.store.SuggestMatch(ctx, s.Line.ID, s.Candidate, s.Score); err != nil {
+			return 0, err
+		}
+	}
+	return len(suggestions), nil
+}
+
+func (u *Usecase) result(ctx context.Context, imp *Import) (*ImportResult, error) {
+	lines, err := u.store.ListLines(ctx, imp.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ImportResult{Import: *imp, Lines: lines}
+	for _, l := range lines {
+		if l.Direction != DirectionCredit {
+			continue
+		}
+		out.Credits++
+		switch l.Status {
+		case StatusSuggested:
+			out.Suggested++
+		case StatusUnmatched:
+			out.Unmatched++
+		}
+	}
+	return out, nil
+}
+
+func sameAmount(a, b string) bool {
+	x, ok1 := new(big.Rat).SetString(a)
+	y, ok2 := new(big.Rat).SetString(b)
+	return ok1 && ok2 && x.Cmp(y) == 0
+}
+
+func trimPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,90 @@
+package reconciliation
+
+import "time"
+
+type Import struct {
+	ID         string    `json:"id"`
+	Bank       string    `json:"bank"`
+	AccountNo  *string   `json:"accountNo,omitempty"`
+	FileName   *string   `json:"fileName,omitempty"`
+	Currency   string    `json:"currency"`
+	LineCount  int       `json:"lineCount"`
+	ImportedAt time.Time `json:"importedAt"`
+}
+
+type ImportResult struct {
+	Import    Import `json:"import"`
+	Credits   int    `json:"credits"`
+	Suggested int    `json:"suggested"`
+	Unmatched int    `json:"unmatched"`
+	// Duplicates counts parsed lines skipped because an earlier import holds them.
+	Duplicates int    `json:"duplicates"`
+	Lines      []Line `json:"lines"`
+}
+
+type Line struct {
+	ID          string    `json:"id"`
+	ImportID    string    `json:"importId"`
+	LineNo      int       `json:"lineNo"`
+	BookedAt    time.Time `json:"bookedAt"`
+	Description string    `json:"description"`
+	Reference   *string   `json:"reference,omitempty"`
+	Direction   string    `json:"direction"` // credit | debit
+	Amount      string    `json:"amount"`
+	Balance     *string   `json:"balance,omitempty"`
+	Status      string    `json:"status"` // unmatched | suggested | confirmed | flagged
+	PaymentID   *string   `json:"paymentId,omitempty"`
+	ReceiptID   *string   `json:"receiptId,omitempty"`
+	MatchScore  *int      `json:"matchScore,omitempty"`
+	Note        *string   `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// ParsedLine is one mutation read from a bank CSV before it is stored.
+type ParsedLine struct {
+	LineNo      int
+	BookedAt    time.Time
+	Description string
+	Reference   *string
+	Direction   string
+	Amount      string
+	Balance     *string
+}
+
+type ImportInput struct {
+	Bank      string
+	AccountNo *string
+	FileName  *string
+	Currency  string
+	Year      int // used for BCA rows that only carry dd/mm
+	Data      []byte
+}
+
+// Candidate is a posted transfer that a bank line may correspond to.
+type Candidate struct {
+	Kind       string // payment | receipt
+	ID         string
+	Amount     string
+	Currency   string
+	PaidAt     time.Time
+	Reference  *string
+	SenderName *string
+}
+
+type CandidateQuery struct {
+	Amount   string
+	Currency string
+	From     time.Time
+	To       time.Time
+}
+
+type ConfirmInput struct {
+	PaymentID *string `json:"paymentId"`
+	ReceiptID *string `json:"receiptId"`
+	Note      *string `json:"note"`
+}
+
+type FlagInput struct {
+	Note *string `json:"note"`
+}
@@ -0,0 +1,42 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+)
+
+// SalesCSV renders the report rows (no totals) as CSV with a header line.
+func SalesCSV(r *SalesReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write([]string{"period", "key", "label", "currency", "count", "qty", "gross_amount", "discount_amount", "net_amount"}); err != nil {
+		return nil, err
+	}
+	for _, row := range r.Rows {
+		key := ""
+		if row.Key != nil {
+			key = *row.Key
+		}
+		if err := w.Write([]string{
+			row.Period,
+			key,
+			row.Label,
+			row.Currency,
+			strconv.Itoa(row.Count),
+			strconv.Itoa(row.Qty),
+			row.GrossAmount,
+			row.DiscountAmount,
+			row.NetAmount,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
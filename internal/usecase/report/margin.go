@@ -0,0 +1,91 @@
+package report
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	MarginByProduct     = "product"
+	MarginByTransaction = "transaction"
+)
+
+// WithBaseCurrency sets the currency margins are reported in; costs are kept in it.
+func (u *Usecase) WithBaseCurrency(currency string) *Usecase {
+	if c := strings.ToUpper(strings.TrimSpace(currency)); c != "" {
+		u.baseCurrency = c
+	}
+	return u
+}
+
+func (u *Usecase) Margin(ctx context.Context, q MarginQuery) (*MarginReport, error) {
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.AddDate(0, 0, -defaultSalesDays)
+	}
+	if !q.From.Before(q.To) || q.To.Sub(q.From) > maxSalesDays*24*time.Hour {
+		return nil, ErrInvalidInput
+	}
+	q.Currency = u.baseCurrency
+
+	var (
+		rows []MarginRow
+		err  error
+	)
+	if q.By == "" {
+		q.By = MarginByProduct
+	}
+	switch q.By {
+	case MarginByProduct:
+		rows, err = u.store.MarginByProduct(ctx, q)
+	case MarginByTransaction:
+		rows, err = u.store.MarginByTransaction(ctx, q)
+	default:
+		return nil, ErrInvalidInput
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []MarginRow{}
+	}
+
+	revenue, cogs := new(big.Rat), new(big.Rat)
+	var total MarginTotal
+	for i := range rows {
+		r := &rows[i]
+		rev, cost := parseMoney(r.Revenue), parseMoney(r.COGS)
+		r.Margin, r.MarginPct = margin(rev, cost)
+
+		revenue.Add(revenue, rev)
+		cogs.Add(cogs, cost)
+		total.Qty += r.Qty
+		total.UncostedLines += r.UncostedLines
+	}
+	total.Revenue = revenue.FloatString(2)
+	total.COGS = cogs.FloatString(2)
+	total.Margin, total.MarginPct = margin(revenue, cogs)
+
+	return &MarginReport{
+		From:     q.From,
+		To:       q.To,
+		By:       q.By,
+		Currency: q.Currency,
+		Rows:     rows,
+		Total:    total,
+	}, nil
+}
+
+// margin returns revenue - cogs and its share of revenue in percent.
+func margin(revenue, cogs *big.Rat) (string, *string) {
+	m := new(big.Rat).Sub(revenue, cogs)
+	if revenue.Sign() == 0 {
+		return m.FloatString(2), nil
+	}
+	pct := new(big.Rat).Mul(new(big.Rat).Quo(m, revenue), big.NewRat(100, 1)).FloatString(2)
+	return m.FloatString(2), &pct
+}
@@ -0,0 +1,158 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var ErrInvalidInput = errors.New("invalid input")
+
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+const (
+	ByPeriod           = "period"
+	ByProduct          = "product"
+	ByCustomerCategory = "customer_category"
+	ByPaymentMethod    = "payment_method"
+)
+
+const (
+	defaultSalesDays = 30
+	maxSalesDays     = 731
+)
+
+type Store interface {
+	// Each returns rows ordered by period; only completed transactions count.
+	SalesByPeriod(ctx context.Context, q SalesQuery) ([]SalesRow, error)
+	SalesByProduct(ctx context.Context, q SalesQuery) ([]SalesRow, error)
+	SalesByCustomerCategory(ctx context.Context, q SalesQuery) ([]SalesRow, error)
+	// SalesByPaymentMethod buckets posted payments on completed transactions by paid_at.
+	SalesByPaymentMethod(ctx context.Context, q SalesQuery) ([]SalesRow, error)
+
+	// Margin rows leave Margin/MarginPct empty; the usecase fills them.
+	MarginByProduct(ctx context.Context, q MarginQuery) ([]MarginRow, error)
+	MarginByTransaction(ctx context.Context, q MarginQuery) ([]MarginRow, error)
+}
+
+type Usecase struct {
+	store        Store
+	baseCurrency string
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store, baseCurrency: "IDR"}
+}
+
+func (u *Usecase) Sales(ctx context.Context, q SalesQuery) (*SalesReport, error) {
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.AddDate(0, 0, -defaultSalesDays)
+	}
+	if !q.From.Before(q.To) || q.To.Sub(q.From) > maxSalesDays*24*time.Hour {
+		return nil, ErrInvalidInput
+	}
+	if q.Interval == "" {
+		q.Interval = IntervalDay
+	}
+	switch q.Interval {
+	case IntervalDay, IntervalWeek, IntervalMonth:
+	default:
+		return nil, ErrInvalidInput
+	}
+	if q.Currency != nil {
+		cur := strings.ToUpper(strings.TrimSpace(*q.Currency))
+		if len(cur) != 3 {
+			return nil, ErrInvalidInput
+		}
+		q.Currency = &cur
+	}
+
+	var (
+		rows []SalesRow
+		err  error
+	)
+	if q.By == "" {
+		q.By = ByPeriod
+	}
+	switch q.By {
+	case ByPeriod:
+		rows, err = u.store.SalesByPeriod(ctx, q)
+	case ByProduct:
+		rows, err = u.store.SalesByProduct(ctx, q)
+	case ByCustomerCategory:
+		rows, err = u.store.SalesByCustomerCategory(ctx, q)
+	case ByPaymentMethod:
+		rows, err = u.store.SalesByPaymentMethod(ctx, q)
+	default:
+		return nil, ErrInvalidInput
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []SalesRow{}
+	}
+
+	return &SalesReport{
+		From:     q.From,
+		To:       q.To,
+		Interval: q.Interval,
+		By:       q.By,
+		Rows:     rows,
+		Totals:   salesTotals(rows),
+	}, nil
+}
+
+// salesTotals sums rows per currency, in order of first appearance.
+func salesTotals(rows []SalesRow) []SalesTotal {
+	type acc struct {
+		count, qty           int
+		gross, discount, net *big.Rat
+	}
+	order := make([]string, 0, 1)
+	sums := map[string]*acc{}
+	for _, r := range rows {
+		a, ok := sums[r.Currency]
+		if !ok {
+			a = &acc{gross: new(big.Rat), discount: new(big.Rat), net: new(big.Rat)}
+			sums[r.Currency] = a
+			order = append(order, r.Currency)
+		}
+		a.count += r.Count
+		a.qty += r.Qty
+		a.gross.Add(a.gross, parseMoney(r.GrossAmount))
+		a.discount.Add(a.discount, parseMoney(r.DiscountAmount))
+		a.net.Add(a.net, parseMoney(r.NetAmount))
+	}
+
+	out := make([]SalesTotal, 0, len(order))
+	for _, cur := range order {
+		a := sums[cur]
+		out = append(out, SalesTotal{
+			Currency:       cur,
+			Count:          a.count,
+			Qty:            a.qty,
+			GrossAmount:    a.gross.FloatString(2),
+			DiscountAmount: a.discount.FloatString(2),
+			NetAmount:      a.net.FloatString(2),
+		})
+	}
+	return out
+}
+
+func parseMoney(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
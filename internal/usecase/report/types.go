@@ -0,0 +1,85 @@
+package report
+
+import "time"
+
+type SalesQuery struct {
+	From     time.Time // inclusive; default To - 30 days
+	To       time.Time // exclusive; default now
+	Interval string    // day | week | month
+	By       string    // period | product | customer_category | payment_method
+	Currency *string
+}
+
+// SalesRow is one bucket of completed sales. Count is the number of transactions
+// (payments for by=payment_method); Qty is only filled for by=product and by=period.
+type SalesRow struct {
+	Period         string  `json:"period"` // bucket start, YYYY-MM-DD
+	Key            *string `json:"key,omitempty"`
+	Label          string  `json:"label,omitempty"`
+	Currency       string  `json:"currency"`
+	Count          int     `json:"count"`
+	Qty            int     `json:"qty"`
+	GrossAmount    string  `json:"grossAmount"`
+	DiscountAmount string  `json:"discountAmount"`
+	NetAmount      string  `json:"netAmount"`
+}
+
+type SalesTotal struct {
+	Currency       string `json:"currency"`
+	Count          int    `json:"count"`
+	Qty            int    `json:"qty"`
+	GrossAmount    string `json:"grossAmount"`
+	DiscountAmount string `json:"discountAmount"`
+	NetAmount      string `json:"netAmount"`
+}
+
+type SalesReport struct {
+	From     time.Time    `json:"from"`
+	To       time.Time    `json:"to"`
+	Interval string       `json:"interval"`
+	By       string       `json:"by"`
+	Rows     []SalesRow   `json:"rows"`
+	Totals   []SalesTotal `json:"totals"`
+}
+
+// MarginQuery covers completed transactions in the base currency, the currency
+// purchase costs are kept in.
+type MarginQuery struct {
+	From     time.Time // inclusive; default To - 30 days
+	To       time.Time // exclusive; default now
+	By       string    // product | transaction
+	Currency string    // set by the usecase
+}
+
+// MarginRow is gross margin for one product or transaction. Revenue is net of
+// line discounts (by=product) or the amount due (by=transaction). Lines sold
+// before their product had a cost count in UncostedLines and add no COGS.
+type MarginRow struct {
+	Key           string     `json:"key"`
+	Label         string     `json:"label"`
+	At            *time.Time `json:"at,omitempty"` // transaction time, by=transaction only
+	Qty           int        `json:"qty"`
+	Revenue       string     `json:"revenue"`
+	COGS          string     `json:"cogs"`
+	Margin        string     `json:"margin"`
+	MarginPct     *string    `json:"marginPct,omitempty"` // nil when revenue is zero
+	UncostedLines int        `json:"uncostedLines"`
+}
+
+type MarginTotal struct {
+	Qty           int     `json:"qty"`
+	Revenue       string  `json:"revenue"`
+	COGS          string  `json:"cogs"`
+	Margin        string  `json:"margin"`
+	MarginPct     *string `json:"marginPct,omitempty"`
+	UncostedLines int     `json:"uncostedLines"`
+}
+
+type MarginReport struct {
+	From     time.Time   `json:"from"`
+	To       time.Time   `json:"to"`
+	By       string      `json:"by"`
+	Currency string      `json:"currency"`
+	Rows     []MarginRow `json:"rows"`
+	Total    MarginTotal `json:"total"`
+}
@@ -0,0 +1,167 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrNotFound         = errors.New("shift not found")
+	ErrNoOpenShift      = errors.New("no open shift")
+	ErrShiftAlreadyOpen = errors.New("admin already has an open shift")
+	ErrShiftClosed      = errors.New("shift already closed")
+	ErrNotShiftOwner    = errors.New("shift belongs to another admin")
+	ErrAdminMissing     = errors.New("admin not found")
+)
+
+const (
+	StatusOpen   = "open"
+	StatusClosed = "closed"
+)
+
+type Store interface {
+	Open(ctx context.Context, in OpenInput) (*Shift, error)
+	GetByID(ctx context.Context, id string) (*Shift, error)
+	// GetOpenByAdmin returns ErrNoOpenShift when the admin has none.
+	GetOpenByAdmin(ctx context.Context, adminID string) (*Shift, error)
+	List(ctx context.Context, q ListQuery) ([]Shift, error)
+
+	// Close locks the shift, computes expected cash (float + cash received in the
+	// shift currency) and stores counted cash and variance atomically.
+	Close(ctx context.Context, id string, in CloseInput) (*Shift, error)
+
+	// CashReceived sums cash taken in the shift currency: direct cash payments plus cash receipts.
+	CashReceived(ctx context.Context, id string) (string, error)
+	MethodTotals(ctx context.Context, id string) ([]MethodTotal, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+func (u *Usecase) Open(ctx context.Context, in OpenInput) (*Shift, error) {
+	if _, err := uuid.Parse(in.AdminID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if strings.TrimSpace(in.OpeningFloat) == "" {
+		in.OpeningFloat = "0"
+	}
+	float, ok := parseAmount(in.OpeningFloat)
+	if !ok || float.Sign() < 0 {
+		return nil, ErrInvalidInput
+	}
+	in.OpeningFloat = float.FloatString(2)
+
+	in.Currency = strings.ToUpper(strings.TrimSpace(in.Currency))
+	if in.Currency == "" {
+		in.Currency = "IDR"
+	}
+	if len(in.Currency) != 3 {
+		return nil, ErrInvalidInput
+	}
+
+	return u.store.Open(ctx, in)
+}
+
+func (u *Usecase) Close(ctx context.Context, id string, in CloseInput) (*Shift, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if _, err := uuid.Parse(in.AdminID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	counted, ok := parseAmount(in.CountedCash)
+	if !ok || counted.Sign() < 0 {
+		return nil, ErrInvalidInput
+	}
+	in.CountedCash = counted.FloatString(2)
+
+	return u.store.Close(ctx, id, in)
+}
+
+func (u *Usecase) Current(ctx context.Context, adminID string) (*Shift, error) {
+	if _, err := uuid.Parse(adminID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetOpenByAdmin(ctx, adminID)
+}
+
+func (u *Usecase) GetByID(ctx context.Context, id string) (*Shift, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetByID(ctx, id)
+}
+
+func (u *Usecase) List(ctx context.Context, q ListQuery) ([]Shift, error) {
+	if q.Status != nil && *q.Status != StatusOpen && *q.Status != StatusClosed {
+		return nil, ErrInvalidInput
+	}
+	if q.AdminID != nil {
+		if _, err := uuid.Parse(*q.AdminID); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	if q.Limit <= 0 || q.Limit > 100 {
+		q.Limit = 20
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return u.store.List(ctx, q)
+}
+
+func (u *Usecase) ZReport(ctx context.Context, id string) (*ZReport, error) {
+	s, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := u.store.MethodTotals(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if totals == nil {
+		totals = []MethodTotal{}
+	}
+
+	cash, err := u.store.CashReceived(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ZReport{
+		Shift:        *s,
+		Totals:       totals,
+		CashReceived: cash,
+		CountedCash:  s.CountedCash,
+		Variance:     s.Variance,
+		GeneratedAt:  time.Now(),
+	}
+	if s.ExpectedCash != nil {
+		out.ExpectedCash = *s.ExpectedCash
+	} else {
+		float, _ := parseAmount(s.OpeningFloat)
+		received, _ := parseAmount(cash)
+		out.ExpectedCash = new(big.Rat).Add(float, received).FloatString(2)
+	}
+	return out, nil
+}
+
+func parseAmount(s string) (*big.Rat, bool) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return new(big.Rat), false
+	}
+	return r, true
+}
@@ -0,0 +1,60 @@
+package shift
+
+import "time"
+
+type Shift struct {
+	ID           string     `json:"id"`
+	AdminID      string     `json:"adminId"`
+	Status       string     `json:"status"` // open | closed
+	Currency     string     `json:"currency"`
+	OpeningFloat string     `json:"openingFloat"`
+	ExpectedCash *string    `json:"expectedCash,omitempty"` // float + cash received; set on close
+	CountedCash  *string    `json:"countedCash,omitempty"`
+	Variance     *string    `json:"variance,omitempty"` // counted - expected
+	Note         *string    `json:"note,omitempty"`
+	CloseNote    *string    `json:"closeNote,omitempty"`
+	OpenedAt     time.Time  `json:"openedAt"`
+	ClosedAt     *time.Time `json:"closedAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+type OpenInput struct {
+	AdminID      string  `json:"-"`
+	OpeningFloat string  `json:"openingFloat"`
+	Currency     string  `json:"currency"` // optional, default IDR
+	Note         *string `json:"note"`
+}
+
+type CloseInput struct {
+	AdminID     string  `json:"-"`
+	CountedCash string  `json:"countedCash"`
+	Note        *string `json:"note"`
+}
+
+type ListQuery struct {
+	AdminID *string
+	Status  *string
+	Limit   int
+	Offset  int
+}
+
+// MethodTotal sums what was taken during a shift per method, in the tendered currency.
+type MethodTotal struct {
+	Method   string `json:"method"`
+	Currency string `json:"currency"`
+	Count    int    `json:"count"`
+	Amount   string `json:"amount"`
+}
+
+// ZReport is the close-out summary of one shift. For an open shift ExpectedCash is
+// the running figure and CountedCash/Variance are empty.
+type ZReport struct {
+	Shift        Shift         `json:"shift"`
+	Totals       []MethodTotal `json:"totals"`
+	CashReceived string        `json:"cashReceived"` // cash in the shift currency
+	ExpectedCash string        `json:"expectedCash"`
+	CountedCash  *string       `json:"countedCash,omitempty"`
+	Variance     *string       `json:"variance,omitempty"`
+	GeneratedAt  time.Time     `json:"generatedAt"`
+}
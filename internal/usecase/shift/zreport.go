@@ -0,0 +1,63 @@
+package shift
+
+import (
+	"fmt"
+	"strings"
+)
+
+const zReportWidth = 40
+
+// RenderZReport formats the report as fixed-width plain text for receipt printers.
+func RenderZReport(z *ZReport) string {
+	var b strings.Builder
+	line := strings.Repeat("-", zReportWidth)
+
+	center := func(s string) {
+		pad := (zReportWidth - len(s)) / 2
+		if pad < 0 {
+			pad = 0
+		}
+		fmt.Fprintf(&b, "%s%s\n", strings.Repeat(" ", pad), s)
+	}
+	row := func(label, value string) {
+		fmt.Fprintf(&b, "%-*s%*s\n", zReportWidth/2, label, zReportWidth-zReportWidth/2, value)
+	}
+	const ts = "2006-01-02 15:04"
+
+	if z.Shift.Status == StatusClosed {
+		center("Z-REPORT")
+	} else {
+		center("X-REPORT (SHIFT OPEN)")
+	}
+	b.WriteString(line + "\n")
+	row("Shift", z.Shift.ID[:8])
+	row("Admin", z.Shift.AdminID[:8])
+	row("Opened", z.Shift.OpenedAt.Format(ts))
+	if z.Shift.ClosedAt != nil {
+		row("Closed", z.Shift.ClosedAt.Format(ts))
+	}
+	b.WriteString(line + "\n")
+
+	for _, t := range z.Totals {
+		row(fmt.Sprintf("%s x%d", strings.ToUpper(t.Method), t.Count), t.Currency+" "+t.Amount)
+	}
+	if len(z.Totals) == 0 {
+		center("no payments")
+	}
+	b.WriteString(line + "\n")
+
+	cur := z.Shift.Currency
+	row("Opening float", cur+" "+z.Shift.OpeningFloat)
+	row("Cash received", cur+" "+z.CashReceived)
+	row("Expected cash", cur+" "+z.ExpectedCash)
+	if z.CountedCash != nil {
+		row("Counted cash", cur+" "+*z.CountedCash)
+	}
+	if z.Variance != nil {
+		row("Variance", cur+" "+*z.Variance)
+	}
+	b.WriteString(line + "\n")
+	row("Printed", z.GeneratedAt.Format(ts))
+
+	return b.String()
+}
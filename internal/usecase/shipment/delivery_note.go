@@ -0,0 +1,84 @@
+package shipment
+
+import (
+	"fmt"
+	"strings"
+)
+
+const deliveryNoteWidth = 48
+
+// RenderDeliveryNote formats the note as plain text for a receipt printer.
+func RenderDeliveryNote(n *DeliveryNote) string {
+	var b strings.Builder
+	line := strings.Repeat("-", deliveryNoteWidth)
+	s := n.Shipment
+
+	center := func(v string) {
+		pad := (deliveryNoteWidth - len(v)) / 2
+		if pad < 0 {
+			pad = 0
+		}
+		fmt.Fprintf(&b, "%s%s\n", strings.Repeat(" ", pad), v)
+	}
+	row := func(label, value string) {
+		fmt.Fprintf(&b, "%-*s%*s\n", deliveryNoteWidth/2, label, deliveryNoteWidth-deliveryNoteWidth/2, value)
+	}
+	opt := func(v *string) {
+		if v != nil && *v != "" {
+			b.WriteString(*v + "\n")
+		}
+	}
+	const ts = "2006-01-02 15:04"
+
+	center("DELIVERY NOTE")
+	center(s.DeliveryNoteNumber)
+	b.WriteString(line + "\n")
+	row("Order", s.TransactionID[:8])
+	row("Customer", n.CustomerName)
+	row("Status", strings.ToUpper(s.Status))
+	if s.ShippedAt != nil {
+		row("Shipped", s.ShippedAt.Format(ts))
+	}
+	if s.Carrier != nil {
+		row("Carrier", *s.Carrier)
+	}
+	if s.TrackingNumber != nil {
+		row("Tracking", *s.TrackingNumber)
+	}
+	b.WriteString(line + "\n")
+
+	b.WriteString("Ship to:\n")
+	b.WriteString(s.ShipTo.Name + "\n")
+	opt(s.ShipTo.Phone)
+	b.WriteString(s.ShipTo.Line1 + "\n")
+	opt(s.ShipTo.Line2)
+	city := make([]string, 0, 3)
+	for _, v := range []*string{s.ShipTo.City, s.ShipTo.Province, s.ShipTo.PostalCode} {
+		if v != nil && *v != "" {
+			city = append(city, *v)
+		}
+	}
+	if len(city) > 0 {
+		b.WriteString(strings.Join(city, ", ") + "\n")
+	}
+	b.WriteString(s.ShipTo.Country + "\n")
+	b.WriteString(line + "\n")
+
+	for _, l := range s.Lines {
+		label := l.ProductName
+		if l.SKU != nil {
+			label = *l.SKU + " " + label
+		}
+		row(label, fmt.Sprintf("x%d", l.Qty))
+	}
+	b.WriteString(line + "\n")
+	if s.Note != nil {
+		b.WriteString(*s.Note + "\n")
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("Received by:\n\n\n")
+	b.WriteString(strings.Repeat("_", deliveryNoteWidth/2) + "\n")
+	row("Printed", n.GeneratedAt.Format(ts))
+
+	return b.String()
+}
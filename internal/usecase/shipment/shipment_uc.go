@@ -0,0 +1,143 @@
+package shipment
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrNotFound           = errors.New("shipment not found")
+	ErrTransactionMissing = errors.New("transaction not found")
+	ErrItemMissing        = errors.New("transaction item not found")
+	ErrAddressMissing     = errors.New("customer has no usable address")
+	ErrInvalidTransition  = errors.New("invalid shipment status transition")
+	ErrOverShipped        = errors.New("quantity exceeds what is left to ship")
+	ErrNothingToShip      = errors.New("nothing left to ship")
+	ErrExpiredStock       = errors.New("remaining stock is expired")
+)
+
+const (
+	StatusPicking   = "picking"
+	StatusPacked    = "packed"
+	StatusShipped   = "shipped"
+	StatusDelivered = "delivered"
+	StatusCancelled = "cancelled"
+)
+
+type Store interface {
+	// Create requires a pending transaction; lines are checked against what
+	// is neither shipped nor in another open shipment.
+	Create(ctx context.Context, transactionID string, in CreateInput) (*Shipment, error)
+	GetByID(ctx context.Context, id string) (*Shipment, error)
+	ListByTransaction(ctx context.Context, transactionID string) ([]Shipment, error)
+
+	Pack(ctx context.Context, id string) (*Shipment, error)
+	// Ship commits the lines' reserved stock; the transaction completes once
+	// everything is shipped.
+	Ship(ctx context.Context, id string, in ShipInput) (*Shipment, error)
+	Deliver(ctx context.Context, id string) (*Shipment, error)
+	Cancel(ctx context.Context, id string) (*Shipment, error)
+
+	CustomerName(ctx context.Context, transactionID string) (string, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+func (u *Usecase) Create(ctx context.Context, transactionID string, in CreateInput) (*Shipment, error) {
+	if _, err := uuid.Parse(transactionID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if in.AddressID != nil {
+		if _, err := uuid.Parse(*in.AddressID); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	if in.ShipTo != nil {
+		in.ShipTo.Name = strings.TrimSpace(in.ShipTo.Name)
+		in.ShipTo.Line1 = strings.TrimSpace(in.ShipTo.Line1)
+		in.ShipTo.Country = strings.ToUpper(strings.TrimSpace(in.ShipTo.Country))
+		if in.ShipTo.Line1 == "" {
+			return nil, ErrInvalidInput
+		}
+		if in.ShipTo.Country == "" {
+			in.ShipTo.Country = "ID"
+		}
+	}
+	seen := map[string]bool{}
+	for _, l := range in.Lines {
+		if _, err := uuid.Parse(l.TransactionItemID); err != nil || l.Qty <= 0 || seen[l.TransactionItemID] {
+			return nil, ErrInvalidInput
+		}
+		seen[l.TransactionItemID] = true
+	}
+	return u.store.Create(ctx, transactionID, in)
+}
+
+func (u *Usecase) GetByID(ctx context.Context, id string) (*Shipment, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetByID(ctx, id)
+}
+
+func (u *Usecase) ListByTransaction(ctx context.Context, transactionID string) ([]Shipment, error) {
+	if _, err := uuid.Parse(transactionID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.ListByTransaction(ctx, transactionID)
+}
+
+func (u *Usecase) Pack(ctx context.Context, id string) (*Shipment, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.Pack(ctx, id)
+}
+
+func (u *Usecase) Ship(ctx context.Context, id string, in ShipInput) (*Shipment, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.Ship(ctx, id, in)
+}
+
+func (u *Usecase) Deliver(ctx context.Context, id string) (*Shipment, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.Deliver(ctx, id)
+}
+
+func (u *Usecase) Cancel(ctx context.Context, id string) (*Shipment, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.Cancel(ctx, id)
+}
+
+// DeliveryNote is available in every status but cancelled.
+func (u *Usecase) DeliveryNote(ctx context.Context, id string) (*DeliveryNote, error) {
+	s, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Status == StatusCancelled {
+		return nil, ErrInvalidTransition
+	}
+	name, err := u.store.CustomerName(ctx, s.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	return &DeliveryNote{Shipment: *s, CustomerName: name, GeneratedAt: time.Now()}, nil
+}
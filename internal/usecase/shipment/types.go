@@ -0,0 +1,79 @@
+package shipment
+
+import "time"
+
+// Shipment is one delivery of part or all of a pending transaction:
+// picking -> packed -> shipped -> delivered (or cancelled before it ships).
+// The reserved stock of its lines is committed when it ships.
+type Shipment struct {
+	ID                 string     `json:"id"`
+	TransactionID      string     `json:"transactionId"`
+	DeliveryNoteNumber string     `json:"deliveryNoteNumber"`
+	Status             string     `json:"status"`
+	AddressID          *string    `json:"addressId,omitempty"`
+	ShipTo             Address    `json:"shipTo"`
+	Carrier            *string    `json:"carrier,omitempty"`
+	TrackingNumber     *string    `json:"trackingNumber,omitempty"`
+	Note               *string    `json:"note,omitempty"`
+	CreatedBy          *string    `json:"createdBy,omitempty"`
+	PackedAt           *time.Time `json:"packedAt,omitempty"`
+	ShippedAt          *time.Time `json:"shippedAt,omitempty"`
+	DeliveredAt        *time.Time `json:"deliveredAt,omitempty"`
+	CancelledAt        *time.Time `json:"cancelledAt,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+
+	Lines []Line `json:"lines,omitempty"`
+}
+
+// Address is the ship-to address as printed on the delivery note.
+type Address struct {
+	Name       string  `json:"name"`
+	Phone      *string `json:"phone,omitempty"`
+	Line1      string  `json:"line1"`
+	Line2      *string `json:"line2,omitempty"`
+	City       *string `json:"city,omitempty"`
+	Province   *string `json:"province,omitempty"`
+	PostalCode *string `json:"postalCode,omitempty"`
+	Country    string  `json:"country"`
+}
+
+// Line: Qty is in units of the transaction item (packs stay packs).
+type Line struct {
+	ID                string  `json:"id"`
+	TransactionItemID string  `json:"transactionItemId"`
+	ProductID         string  `json:"productId"`
+	SKU               *string `json:"sku,omitempty"`
+	ProductName       string  `json:"productName"`
+	Qty               int     `json:"qty"`
+}
+
+type LineInput struct {
+	TransactionItemID string `json:"transactionItemId"`
+	Qty               int    `json:"qty"`
+}
+
+// CreateInput: without lines the shipment takes everything not yet shipped
+// or in another open shipment. The ship-to address is ShipTo when given, else
+// the customer address AddressID, else the customer's default address.
+type CreateInput struct {
+	Lines     []LineInput `json:"lines"`
+	AddressID *string     `json:"addressId"`
+	ShipTo    *Address    `json:"shipTo"`
+	Carrier   *string     `json:"carrier"`
+	Note      *string     `json:"note"`
+
+	CreatedBy *string `json:"-"` // admin id, set by the handler
+}
+
+type ShipInput struct {
+	Carrier        *string `json:"carrier"`
+	TrackingNumber *string `json:"trackingNumber"`
+}
+
+// DeliveryNote is the printable document handed over with a shipment.
+type DeliveryNote struct {
+	Shipment     Shipment  `json:"shipment"`
+	CustomerName string    `json:"customerName"`
+	GeneratedAt  time.Time `json:"generatedAt"`
+}
@@ -0,0 +1,180 @@
+package stocktake
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrNotFound          = errors.New("stock take not found")
+	ErrLocationMissing   = errors.New("location not found or inactive")
+	ErrProductMissing    = errors.New("product not found, not a stock product or not in this stock take")
+	ErrSessionOpen       = errors.New("a stock take is already open for this location")
+	ErrInvalidTransition = errors.New("invalid stock take status transition")
+	ErrBelowReserved     = errors.New("counted stock is below what is reserved")
+)
+
+const (
+	StatusOpen      = "open"
+	StatusApproved  = "approved"
+	StatusCancelled = "cancelled"
+)
+
+type Store interface {
+	Create(ctx context.Context, in CreateInput) (*StockTake, error)
+	GetByID(ctx context.Context, id string) (*StockTake, error)
+	List(ctx context.Context, q ListQuery) ([]StockTake, error)
+
+	// RecordCounts upserts the counter's counts; only open sessions.
+	RecordCounts(ctx context.Context, id string, in CountInput) error
+
+	// Approve posts each counted line's variance to the location's current
+	// on_hand as a stock adjustment, all in one DB transaction. Fails with
+	// ErrBelowReserved when a line would leave less on hand than is reserved.
+	Approve(ctx context.Context, id string, approvedBy *string) (*ApproveResult, error)
+	Cancel(ctx context.Context, id string) (*StockTake, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+func (u *Usecase) Create(ctx context.Context, in CreateInput) (*StockTake, error) {
+	if in.LocationID != nil {
+		if _, err := uuid.Parse(*in.LocationID); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	seen := map[string]bool{}
+	for _, id := range in.ProductIDs {
+		if _, err := uuid.Parse(id); err != nil || seen[id] {
+			return nil, ErrInvalidInput
+		}
+		seen[id] = true
+	}
+	st, err := u.store.Create(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	summarize(st)
+	return st, nil
+}
+
+func (u *Usecase) GetByID(ctx context.Context, id string) (*StockTake, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	st, err := u.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	summarize(st)
+	return st, nil
+}
+
+func (u *Usecase) List(ctx context.Context, q ListQuery) ([]StockTake, error) {
+	if q.LocationID != nil {
+		if _, err := uuid.Parse(*q.LocationID); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	if q.Status != nil && !validStatus(*q.Status) {
+		return nil, ErrInvalidInput
+	}
+	if q.Limit <= 0 || q.Limit > 200 {
+		q.Limit = 50
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return u.store.List(ctx, q)
+}
+
+// RecordCounts stores one counter's counts and returns the updated session.
+func (u *Usecase) RecordCounts(ctx context.Context, id string, in CountInput) (*StockTake, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if _, err := uuid.Parse(in.CounterID); err != nil || len(in.Counts) == 0 {
+		return nil, ErrInvalidInput
+	}
+	seen := map[string]bool{}
+	for _, c := range in.Counts {
+		if _, err := uuid.Parse(c.ProductID); err != nil || c.Qty < 0 || seen[c.ProductID] {
+			return nil, ErrInvalidInput
+		}
+		seen[c.ProductID] = true
+	}
+	if err := u.store.RecordCounts(ctx, id, in); err != nil {
+		return nil, err
+	}
+	return u.GetByID(ctx, id)
+}
+
+// Approve posts the variances. Uncounted lines are left alone.
+func (u *Usecase) Approve(ctx context.Context, id string, approvedBy *string) (*ApproveResult, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	out, err := u.store.Approve(ctx, id, approvedBy)
+	if err != nil {
+		return nil, err
+	}
+	summarize(out.StockTake)
+	return out, nil
+}
+
+func (u *Usecase) Cancel(ctx context.Context, id string) (*StockTake, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	st, err := u.store.Cancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	summarize(st)
+	return st, nil
+}
+
+// summarize fills each line's counted quantity and variance and the totals.
+func summarize(st *StockTake) {
+	if st == nil || st.Lines == nil {
+		return
+	}
+	sum := &Summary{Lines: len(st.Lines)}
+	for i := range st.Lines {
+		l := &st.Lines[i]
+		if len(l.Counts) == 0 {
+			continue
+		}
+		counted := 0
+		for _, c := range l.Counts {
+			counted += c.Qty
+		}
+		variance := counted - l.ExpectedQty
+		l.CountedQty = &counted
+		l.Variance = &variance
+
+		sum.CountedLines++
+		if variance != 0 {
+			sum.VarianceLines++
+			sum.NetVariance += variance
+		}
+	}
+	st.Summary = sum
+}
+
+func validStatus(s string) bool {
+	switch s {
+	case StatusOpen, StatusApproved, StatusCancelled:
+		return true
+	}
+	return false
+}
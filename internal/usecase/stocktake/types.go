@@ -0,0 +1,91 @@
+package stocktake
+
+import "time"
+
+// StockTake is a physical count of one location. Opening it snapshots each
+// stock product's on_hand there as the expected quantity.
+type StockTake struct {
+	ID           string     `json:"id"`
+	LocationID   string     `json:"locationId"`
+	LocationName string     `json:"locationName"`
+	Status       string     `json:"status"`
+	Note         *string    `json:"note,omitempty"`
+	CreatedBy    *string    `json:"createdBy,omitempty"`
+	ApprovedBy   *string    `json:"approvedBy,omitempty"`
+	ApprovedAt   *time.Time `json:"approvedAt,omitempty"`
+	CancelledAt  *time.Time `json:"cancelledAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+
+	Lines   []Line   `json:"lines,omitempty"`
+	Summary *Summary `json:"summary,omitempty"`
+}
+
+// Line: CountedQty is the sum over counters, nil until someone counted it;
+// Variance = CountedQty - ExpectedQty. All quantities are base units.
+type Line struct {
+	ID          string  `json:"id"`
+	ProductID   string  `json:"productId"`
+	SKU         *string `json:"sku,omitempty"`
+	Name        string  `json:"name"`
+	ExpectedQty int     `json:"expectedQty"`
+	CountedQty  *int    `json:"countedQty,omitempty"`
+	Variance    *int    `json:"variance,omitempty"`
+	Counts      []Count `json:"counts,omitempty"`
+}
+
+type Count struct {
+	CounterID string    `json:"counterId"`
+	Qty       int       `json:"qty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type Summary struct {
+	Lines         int `json:"lines"`
+	CountedLines  int `json:"countedLines"`
+	VarianceLines int `json:"varianceLines"`
+	NetVariance   int `json:"netVariance"`
+}
+
+type CreateInput struct {
+	LocationID *string  `json:"locationId"` // optional (default location)
+	ProductIDs []string `json:"productIds"` // optional; default every active stock product
+	Note       *string  `json:"note"`
+
+	CreatedBy *string `json:"-"` // admin id, set by the handler
+}
+
+type CountLine struct {
+	ProductID string `json:"productId"`
+	Qty       int    `json:"qty"`
+}
+
+// CountInput replaces the counter's earlier count of the same products.
+type CountInput struct {
+	Counts []CountLine `json:"counts"`
+
+	CounterID string `json:"-"` // admin id, set by the handler
+}
+
+type ListQuery struct {
+	LocationID *string
+	Status     *string
+	Limit      int
+	Offset     int
+}
+
+// Adjustment is a stock ledger entry posted on approval.
+type Adjustment struct {
+	ID           string    `json:"id"`
+	ProductID    string    `json:"productId"`
+	LocationID   string    `json:"locationId"`
+	QtyDelta     int       `json:"qtyDelta"`
+	OnHandBefore int       `json:"onHandBefore"`
+	OnHandAfter  int       `json:"onHandAfter"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type ApproveResult struct {
+	StockTake   *StockTake   `json:"stockTake"`
+	Adjustments []Adjustment `json:"adjustments"`
+}
@@ -0,0 +1,103 @@
+package supplier
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrNotFound     = errors.New("supplier not found")
+	ErrCodeConflict = errors.New("supplier code already exists")
+)
+
+type Store interface {
+	Create(ctx context.Context, in CreateInput) (*Supplier, error)
+	GetByID(ctx context.Context, id string) (*Supplier, error)
+	List(ctx context.Context, q ListQuery) ([]Supplier, error)
+	Update(ctx context.Context, id string, in UpdateInput) (*Supplier, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Supplier, error) {
+	in.Code = normalizeCode(in.Code)
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Code == "" || in.Name == "" {
+		return nil, ErrInvalidInput
+	}
+	if in.Email != nil {
+		e := strings.TrimSpace(strings.ToLower(*in.Email))
+		if e != "" && !strings.Contains(e, "@") {
+			return nil, ErrInvalidInput
+		}
+		in.Email = &e
+	}
+	return u.store.Create(ctx, in)
+}
+
+func (u *Usecase) GetByID(ctx context.Context, id string) (*Supplier, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetByID(ctx, id)
+}
+
+func (u *Usecase) List(ctx context.Context, q ListQuery) ([]Supplier, error) {
+	if q.Search != nil {
+		s := strings.TrimSpace(*q.Search)
+		if s == "" {
+			q.Search = nil
+		} else {
+			q.Search = &s
+		}
+	}
+	if q.Limit <= 0 || q.Limit > 200 {
+		q.Limit = 50
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return u.store.List(ctx, q)
+}
+
+func (u *Usecase) Update(ctx context.Context, id string, in UpdateInput) (*Supplier, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if in.Code != nil {
+		c := normalizeCode(*in.Code)
+		if c == "" {
+			return nil, ErrInvalidInput
+		}
+		in.Code = &c
+	}
+	if in.Name != nil {
+		n := strings.TrimSpace(*in.Name)
+		if n == "" {
+			return nil, ErrInvalidInput
+		}
+		in.Name = &n
+	}
+	if in.Email != nil {
+		e := strings.TrimSpace(strings.ToLower(*in.Email))
+		if e != "" && !strings.Contains(e, "@") {
+			return nil, ErrInvalidInput
+		}
+		in.Email = &e
+	}
+	return u.store.Update(ctx, id, in)
+}
+
+func normalizeCode(c string) string {
+	return strings.ToUpper(strings.TrimSpace(c))
+}
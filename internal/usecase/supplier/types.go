@@ -0,0 +1,45 @@
+package supplier
+
+import "time"
+
+type Supplier struct {
+	ID          string    `json:"id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	ContactName *string   `json:"contactName,omitempty"`
+	Phone       *string   `json:"phone,omitempty"`
+	Email       *string   `json:"email,omitempty"`
+	Address     *string   `json:"address,omitempty"`
+	Note        *string   `json:"note,omitempty"`
+	IsActive    bool      `json:"isActive"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type CreateInput struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	ContactName *string `json:"contactName"`
+	Phone       *string `json:"phone"`
+	Email       *string `json:"email"`
+	Address     *string `json:"address"`
+	Note        *string `json:"note"`
+}
+
+type UpdateInput struct {
+	Code        *string `json:"code"`
+	Name        *string `json:"name"`
+	ContactName *string `json:"contactName"`
+	Phone       *string `json:"phone"`
+	Email       *string `json:"email"`
+	Address     *string `json:"address"`
+	Note        *string `json:"note"`
+	IsActive    *bool   `json:"isActive"`
+}
+
+type ListQuery struct {
+	Search   *string // code or name, case-insensitive
+	IsActive *bool
+	Limit    int
+	Offset   int
+}
@@ -0,0 +1,60 @@
+package transaction
+
+import (
+	"context"
+	"time"
+
+	promouc "github.com/riolentius/cahaya-gading-backend/internal/usecase/promotion"
+)
+
+// PromotionSource lists the promotions a customer is eligible for at a moment.
+type PromotionSource interface {
+	Applicable(ctx context.Context, customerID string, at time.Time) ([]promouc.Promotion, error)
+}
+
+// WithPromotions enables promotion evaluation on Create.
+func (u *Usecase) WithPromotions(p PromotionSource) *Usecase {
+	u.promos = p
+	return u
+}
+
+// discounter returns the Discounter for a new transaction, or nil when no promotion applies.
+func (u *Usecase) discounter(ctx context.Context, customerID string, at time.Time) (func(string, []PricedLine) ([]AppliedDiscount, error), error) {
+	if u.promos == nil {
+		return nil, nil
+	}
+	promos, err := u.promos.Applicable(ctx, customerID, at)
+	if err != nil {
+		return nil, err
+	}
+	if len(promos) == 0 {
+		return nil, nil
+	}
+
+	return func(currency string, priced []PricedLine) ([]AppliedDiscount, error) {
+		lines := make([]promouc.Line, 0, len(priced))
+		for _, l := range priced {
+			lines = append(lines, promouc.Line{
+				Index:      l.Index,
+				ProductID:  l.ProductID,
+				Category:   l.ProductCategory,
+				Qty:        l.Qty,
+				UnitAmount: l.UnitAmount,
+				LineTotal:  l.LineTotal,
+			})
+		}
+
+		discounts := promouc.Evaluate(promos, currency, lines)
+		out := make([]AppliedDiscount, 0, len(discounts))
+		for _, d := range discounts {
+			out = append(out, AppliedDiscount{
+				LineIndex:     d.LineIndex,
+				PromotionID:   d.PromotionID,
+				PromotionCode: d.PromotionCode,
+				Description:   d.Description,
+				Amount:        d.Amount,
+			})
+		}
+		return out, nil
+	}, nil
+}
@@ -0,0 +1,44 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ExpireReservations cancels pending transactions whose stock has been
+// reserved for longer than ttl, releasing it the same way a manual
+// pending -> cancelled change does. Returns how many were cancelled. A
+// transaction that fails is skipped so it cannot hold up the rest of the
+// batch; the failures come back joined in the error.
+func (u *Usecase) ExpireReservations(ctx context.Context, ttl time.Duration, limit int) (int, error) {
+	if ttl <= 0 {
+		return 0, ErrInvalidInput
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	ids, err := u.store.ListExpiredReservations(ctx, time.Now().Add(-ttl), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	var errs []error
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		ok, err := u.store.ExpireReservation(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("transaction %s: %w", id, err))
+			continue
+		}
+		if ok {
+			n++
+		}
+	}
+	return n, errors.Join(errs...)
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strings"
+	"time"
 )
 
 var (
@@ -19,6 +21,10 @@ var (
 	ErrTransactionMissing  = errors.New("transaction not found")
 	ErrTransactionCanceled = errors.New("transaction cancelled")
 	ErrInvalidPackSize     = errors.New("invalid pack size")
+	ErrMixedCurrency       = errors.New("items priced in different currencies")
+	ErrLocationMissing     = errors.New("location not found")
+	ErrExpiredStock        = errors.New("remaining stock is expired")
+	ErrShipmentsOpen       = errors.New("transaction has open shipments")
 )
 
 const (
@@ -28,6 +34,14 @@ const (
 	StatusCancelled = "cancelled"
 )
 
+// Fulfillment statuses; the transaction's own status stays the order status.
+const (
+	FulfillmentUnfulfilled      = "unfulfilled"
+	FulfillmentPartiallyShipped = "partially_shipped"
+	FulfillmentShipped          = "shipped"
+	FulfillmentDelivered        = "delivered"
+)
+
 type Store interface {
 	CustomerExists(ctx context.Context, customerID string) (bool, error)
 	ProductExists(ctx context.Context, productID string) (bool, error)
@@ -37,8 +51,9 @@ type Store interface {
 	// packSize: how many base units consumed by qty=1 of productID (default 1)
 	GetStockRule(ctx context.Context, productID string) (stockProductID string, packSize float64, err error)
 
-	// IMPORTANT: available stock is in BASE UNITS of the stockProductID
-	GetAvailableStock(ctx context.Context, stockProductID string) (int, error)
+	// IMPORTANT: available stock is in BASE UNITS of the stockProductID, at
+	// the location (nil = default location)
+	GetAvailableStock(ctx context.Context, stockProductID string, locationID *string) (int, error)
 
 	Create(ctx context.Context, in CreateInput) (*Transaction, error)
 	List(ctx context.Context, in ListInput) ([]Transaction, error)
@@ -48,6 +63,11 @@ type Store interface {
 	ReleaseStockForTx(ctx context.Context, txID string) error
 	CommitStockForTx(ctx context.Context, txID string) error
 
+	// pending transactions reserved before cutoff, oldest first
+	ListExpiredReservations(ctx context.Context, cutoff time.Time, limit int) ([]string, error)
+	// releases the stock and cancels; false when no longer pending
+	ExpireReservation(ctx context.Context, txID string) (bool, error)
+
 	UpdateStatus(ctx context.Context, id string, status string) (*Transaction, error)
 	GetViewByID(ctx context.Context, id string) (*TransactionView, error)
 
@@ -55,7 +75,8 @@ type Store interface {
 }
 
 type Usecase struct {
-	store Store
+	store  Store
+	promos PromotionSource
 }
 
 func New(store Store) *Usecase {
@@ -78,6 +99,23 @@ func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Transaction, err
 	if !isValidStatus(in.Status) {
 		return nil, ErrInvalidStatus
 	}
+	if in.Currency != nil {
+		cur := strings.ToUpper(strings.TrimSpace(*in.Currency))
+		if len(cur) != 3 {
+			return nil, ErrInvalidInput
+		}
+		in.Currency = &cur
+	}
+	if in.PaymentTermsDays != nil && *in.PaymentTermsDays < 0 {
+		return nil, ErrInvalidInput
+	}
+	if in.DueDateRaw != nil && *in.DueDateRaw != "" {
+		d, err := time.Parse("2006-01-02", *in.DueDateRaw)
+		if err != nil {
+			return nil, ErrInvalidInput
+		}
+		in.DueDate = &d
+	}
 
 	// 2) Validate customer exists
 	ok, err := u.store.CustomerExists(ctx, in.CustomerID)
@@ -114,7 +152,7 @@ func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Transaction, err
 
 			requiredBaseUnits := int(packSize) * it.Qty
 
-			avail, err := u.store.GetAvailableStock(ctx, stockProductID)
+			avail, err := u.store.GetAvailableStock(ctx, stockProductID, in.LocationID)
 			if err != nil {
 				return nil, err
 			}
@@ -127,29 +165,15 @@ func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Transaction, err
 		}
 	}
 
-	// 4) Create the transaction row + items
-	tx, err := u.store.Create(ctx, in)
+	// 4) Promotions are evaluated by the store once lines are priced
+	in.Discounter, err = u.discounter(ctx, in.CustomerID, time.Now())
 	if err != nil {
 		return nil, err
 	}
 
-	// 5) If created as pending -> reserve stock now
-	// If created as completed -> reserve + commit
-	switch tx.Status {
-	case StatusPending:
-		if err := u.store.ReserveStockForTx(ctx, tx.ID); err != nil {
-			return nil, err
-		}
-	case StatusCompleted:
-		if err := u.store.ReserveStockForTx(ctx, tx.ID); err != nil {
-			return nil, err
-		}
-		if err := u.store.CommitStockForTx(ctx, tx.ID); err != nil {
-			return nil, err
-		}
-	}
-
-	return tx, nil
+	// 5) Create the transaction row + items (+ discounts); the store reserves
+	// (pending) or reserves and commits (completed) stock in the same DB transaction
+	return u.store.Create(ctx, in)
 }
 
 func (u *Usecase) List(ctx context.Context, in ListInput) ([]Transaction, error) {
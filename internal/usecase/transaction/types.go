@@ -3,15 +3,23 @@ package transaction
 import "time"
 
 type Transaction struct {
-	ID          string    `json:"id"`
-	CustomerID  string    `json:"customerId"`
-	Status      string    `json:"status"`
-	Currency    string    `json:"currency"`
-	TotalAmount string    `json:"totalAmount"`
-	Notes       *string   `json:"notes,omitempty"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
-	Items       []Item    `json:"items,omitempty"`
+	ID         string `json:"id"`
+	CustomerID string `json:"customerId"`
+	LocationID string `json:"locationId"`
+	Status     string `json:"status"`
+	// unfulfilled | partially_shipped | shipped | delivered
+	FulfillmentStatus string     `json:"fulfillmentStatus"`
+	Currency          string     `json:"currency"`
+	SubtotalAmount    string     `json:"subtotalAmount"`
+	DiscountAmount    string     `json:"discountAmount"`
+	TotalAmount       string     `json:"totalAmount"` // subtotal - discount
+	Notes             *string    `json:"notes,omitempty"`
+	PaymentTermsDays  int        `json:"paymentTermsDays"`
+	DueDate           string     `json:"dueDate"` // YYYY-MM-DD
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+	Items             []Item     `json:"items,omitempty"`
+	Discounts         []Discount `json:"discounts,omitempty"`
 }
 
 type Item struct {
@@ -30,6 +38,23 @@ type CreateInput struct {
 	Notes      *string        `json:"notes"`
 	Items      []CreateItemIn `json:"items"`
 	Status     string         `json:"status"`
+	// optional; every item must be priced in this currency. Default: the
+	// currency of the first item, and all others must match it.
+	Currency *string `json:"currency"`
+
+	// optional; the location stock is reserved and shipped from. Default:
+	// the default location.
+	LocationID *string `json:"locationId"`
+
+	// optional; default is the customer category payment terms
+	PaymentTermsDays *int `json:"paymentTermsDays"`
+	// optional override (YYYY-MM-DD); default is today + payment terms
+	DueDateRaw *string    `json:"dueDate"`
+	DueDate    *time.Time `json:"-"`
+
+	// set by the usecase when promotions apply; called by the store with the
+	// transaction currency and the priced lines inside the create transaction
+	Discounter func(currency string, lines []PricedLine) ([]AppliedDiscount, error) `json:"-"`
 }
 
 type CreateItemIn struct {
@@ -46,3 +71,32 @@ type ListInput struct {
 type UpdateStatusInput struct {
 	Status string `json:"status"`
 }
+
+// Discount is a recorded discount line; TransactionItemID is nil for header-level discounts.
+type Discount struct {
+	ID                string    `json:"id"`
+	TransactionItemID *string   `json:"transactionItemId,omitempty"`
+	PromotionID       *string   `json:"promotionId,omitempty"`
+	PromotionCode     string    `json:"promotionCode"`
+	Description       string    `json:"description"`
+	Amount            string    `json:"amount"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
+
+// PricedLine is an item after price resolution, as handed to the Discounter.
+type PricedLine struct {
+	Index           int // position in CreateInput.Items
+	ProductID       string
+	ProductCategory *string
+	Qty             int
+	UnitAmount      string
+	LineTotal       string
+}
+
+type AppliedDiscount struct {
+	LineIndex     int
+	PromotionID   string
+	PromotionCode string
+	Description   string
+	Amount        string
+}
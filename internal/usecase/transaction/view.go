@@ -1,30 +1,42 @@
 package transaction
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type TransactionView struct {
-	ID            string     `json:"id"`
-	CustomerID    string     `json:"customerId"`
-	CustomerName  string     `json:"customerName"`
-	CategoryID    *string    `json:"categoryId,omitempty"`
-	Status        string     `json:"status"`
-	Currency      string     `json:"currency"`
-	TotalAmount   string     `json:"totalAmount"`
-	PaidAmount    string     `json:"paidAmount"`
-	PaymentStatus string     `json:"paymentStatus"`
-	BalanceDue    string     `json:"balanceDue"`
-	Notes         *string    `json:"notes,omitempty"`
-	CreatedAt     time.Time  `json:"createdAt"`
-	UpdatedAt     time.Time  `json:"updatedAt"`
-	Items         []ViewItem `json:"items"`
-	Payments      []ViewPay  `json:"payments"`
+	ID                string      `json:"id"`
+	CustomerID        string      `json:"customerId"`
+	CustomerName      string      `json:"customerName"`
+	CategoryID        *string     `json:"categoryId,omitempty"`
+	Status            string      `json:"status"`
+	FulfillmentStatus string      `json:"fulfillmentStatus"`
+	Currency          string      `json:"currency"`
+	Subtotal          string      `json:"subtotalAmount"`
+	Discount          string      `json:"discountAmount"`
+	TotalAmount       string      `json:"totalAmount"`
+	PaidAmount        string      `json:"paidAmount"`
+	PaymentStatus     string      `json:"paymentStatus"`
+	BalanceDue        string      `json:"balanceDue"`
+	Notes             *string     `json:"notes,omitempty"`
+	PaymentTerms      int         `json:"paymentTermsDays"`
+	DueDate           string      `json:"dueDate"`
+	CreatedAt         time.Time   `json:"createdAt"`
+	UpdatedAt         time.Time   `json:"updatedAt"`
+	Items             []ViewItem  `json:"items"`
+	Discounts         []Discount  `json:"discounts"`
+	Payments          []ViewPay   `json:"payments"`
+	Timeline          []ViewEvent `json:"timeline"`
 }
 
 type ViewItem struct {
+	ID          string  `json:"id"`
 	ProductID   string  `json:"productId"`
 	SKU         *string `json:"sku,omitempty"`
 	ProductName string  `json:"productName"`
 	Qty         int     `json:"qty"`
+	QtyShipped  int     `json:"qtyShipped"`
 	UnitAmount  string  `json:"unitAmount"`
 	LineTotal   string  `json:"lineTotal"`
 
@@ -44,3 +56,17 @@ type ViewPay struct {
 	Note       *string   `json:"note,omitempty"`
 	Status     string    `json:"status"`
 }
+
+// ViewEvent is one entry of the transaction's timeline. From/To hold the
+// status (or fulfillment status) before and after; ActorID is nil for
+// background jobs.
+type ViewEvent struct {
+	ID         string          `json:"id"`
+	Kind       string          `json:"kind"`
+	From       *string         `json:"from,omitempty"`
+	To         *string         `json:"to,omitempty"`
+	ActorID    *string         `json:"actorId,omitempty"`
+	ActorEmail *string         `json:"actorEmail,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,110 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrNotFound          = errors.New("stock transfer not found")
+	ErrLocationMissing   = errors.New("location not found or inactive")
+	ErrProductMissing    = errors.New("product not found or not a stock product")
+	ErrInvalidTransition = errors.New("invalid stock transfer status transition")
+	ErrInsufficientStock = errors.New("insufficient stock at the source location")
+)
+
+const (
+	StatusDraft     = "draft"
+	StatusCompleted = "completed"
+	StatusCancelled = "cancelled"
+)
+
+type Store interface {
+	Create(ctx context.Context, in CreateInput) (*Transfer, error)
+	GetByID(ctx context.Context, id string) (*Transfer, error)
+	List(ctx context.Context, q ListQuery) ([]Transfer, error)
+
+	// Complete moves every line's stock from the source to the destination
+	// location in one DB transaction. Only unreserved stock at the source can
+	// move; otherwise ErrInsufficientStock and nothing changes.
+	Complete(ctx context.Context, id string) (*Transfer, error)
+	Cancel(ctx context.Context, id string) (*Transfer, error)
+}
+
+type Usecase struct {
+	store Store
+}
+
+func New(store Store) *Usecase {
+	return &Usecase{store: store}
+}
+
+func (u *Usecase) Create(ctx context.Context, in CreateInput) (*Transfer, error) {
+	if _, err := uuid.Parse(in.FromLocationID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if _, err := uuid.Parse(in.ToLocationID); err != nil {
+		return nil, ErrInvalidInput
+	}
+	if in.FromLocationID == in.ToLocationID || len(in.Lines) == 0 {
+		return nil, ErrInvalidInput
+	}
+	seen := map[string]bool{}
+	for _, l := range in.Lines {
+		if _, err := uuid.Parse(l.ProductID); err != nil || l.Qty <= 0 || seen[l.ProductID] {
+			return nil, ErrInvalidInput
+		}
+		seen[l.ProductID] = true
+	}
+	return u.store.Create(ctx, in)
+}
+
+func (u *Usecase) GetByID(ctx context.Context, id string) (*Transfer, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.GetByID(ctx, id)
+}
+
+func (u *Usecase) List(ctx context.Context, q ListQuery) ([]Transfer, error) {
+	if q.LocationID != nil {
+		if _, err := uuid.Parse(*q.LocationID); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	if q.Status != nil && !validStatus(*q.Status) {
+		return nil, ErrInvalidInput
+	}
+	if q.Limit <= 0 || q.Limit > 200 {
+		q.Limit = 50
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return u.store.List(ctx, q)
+}
+
+func (u *Usecase) Complete(ctx context.Context, id string) (*Transfer, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.Complete(ctx, id)
+}
+
+func (u *Usecase) Cancel(ctx context.Context, id string) (*Transfer, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidInput
+	}
+	return u.store.Cancel(ctx, id)
+}
+
+func validStatus(s string) bool {
+	switch s {
+	case StatusDraft, StatusCompleted, StatusCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package transfer
+
+import "time"
+
+// Transfer moves stock between two locations. A draft changes nothing;
+// completing it moves every line in one go, cancelling discards it.
+type Transfer struct {
+	ID               string     `json:"id"`
+	FromLocationID   string     `json:"fromLocationId"`
+	FromLocationName string     `json:"fromLocationName"`
+	ToLocationID     string     `json:"toLocationId"`
+	ToLocationName   string     `json:"toLocationName"`
+	Status           string     `json:"status"`
+	Reference        *string    `json:"reference,omitempty"`
+	Note             *string    `json:"note,omitempty"`
+	CreatedBy        *string    `json:"createdBy,omitempty"`
+	CompletedAt      *time.Time `json:"completedAt,omitempty"`
+	CancelledAt      *time.Time `json:"cancelledAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+
+	Lines []Line `json:"lines,omitempty"`
+}
+
+// Line: qty is in base units of a stock product.
+type Line struct {
+	ID          string `json:"id"`
+	ProductID   string `json:"productId"`
+	ProductName string `json:"productName"`
+	Qty         int    `json:"qty"`
+}
+
+type LineInput struct {
+	ProductID string `json:"productId"`
+	Qty       int    `json:"qty"`
+}
+
+type CreateInput struct {
+	FromLocationID string      `json:"fromLocationId"`
+	ToLocationID   string      `json:"toLocationId"`
+	Reference      *string     `json:"reference"`
+	Note           *string     `json:"note"`
+	Lines          []LineInput `json:"lines"`
+
+	CreatedBy *string `json:"-"` // admin id, set by the handler
+}
+
+type ListQuery struct {
+	LocationID *string // either side
+	Status     *string
+	Limit      int
+	Offset     int
+}
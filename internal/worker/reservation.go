@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/riolentius/cahaya-gading-backend/internal/db"
+	txuc "github.com/riolentius/cahaya-gading-backend/internal/usecase/transaction"
+)
+
+// batch per sweep; a backlog drains over consecutive sweeps
+const reservationBatch = 100
+
+// ReservationExpiry periodically cancels pending transactions whose stock
+// reservation is older than the TTL. Instances elect a leader per sweep
+// through a Postgres advisory lock, so each transaction is handled once.
+type ReservationExpiry struct {
+	pool     *pgxpool.Pool
+	uc       *txuc.Usecase
+	ttl      time.Duration
+	interval time.Duration
+}
+
+func NewReservationExpiry(pool *pgxpool.Pool, uc *txuc.Usecase, ttl, interval time.Duration) *ReservationExpiry {
+	return &ReservationExpiry{pool: pool, uc: uc, ttl: ttl, interval: interval}
+}
+
+// Run sweeps every interval until ctx is done.
+func (w *ReservationExpiry) Run(ctx context.Context) {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			w.sweep(ctx)
+		}
+	}
+}
+
+func (w *ReservationExpiry) sweep(ctx context.Context) {
+	_, err := db.WithAdvisoryLock(ctx, w.pool, db.LockReservationExpiry, func(ctx context.Context) error {
+		n, err := w.uc.ExpireReservations(ctx, w.ttl, reservationBatch)
+		if n > 0 {
+			log.Printf("reservation expiry: cancelled %d pending transaction(s)", n)
+		}
+		return err
+	})
+	if err != nil && ctx.Err() == nil {
+		log.Printf("reservation expiry: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+-- +goose Up
+
+-- Stores the outcome of POST requests sent with an Idempotency-Key header so
+-- a retried request (double tap, flaky network) replays the first response.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    scope text NOT NULL DEFAULT '', -- admin id (JWT sub) that sent the key
+    key text NOT NULL,
+    method text NOT NULL,
+    path text NOT NULL,
+    request_hash text NOT NULL, -- sha256 of method + path + body
+    status_code integer, -- NULL while the first request is still in flight
+    content_type text,
+    response_body bytea,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT uq_idempotency_keys_scope_key UNIQUE (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,44 @@
+-- +goose Up
+
+-- Store credit ledger: balance = SUM(amount) per customer + currency.
+-- overpayment: surplus of a payment converted to credit (positive)
+-- applied:     credit spent as a payment on a transaction (negative)
+-- adjustment:  manual correction (either sign)
+CREATE TABLE IF NOT EXISTS customer_credit_entries (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    customer_id uuid NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    transaction_id uuid REFERENCES transactions (id) ON DELETE SET NULL,
+    payment_id uuid REFERENCES payments (id) ON DELETE SET NULL,
+    kind text NOT NULL CHECK (
+        kind IN (
+            'overpayment',
+            'applied',
+            'adjustment'
+        )
+    ),
+    amount numeric(18, 2) NOT NULL CHECK (amount <> 0),
+    currency text NOT NULL DEFAULT 'IDR',
+    note text,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_credit_entries_customer_id ON customer_credit_entries (customer_id);
+
+CREATE INDEX IF NOT EXISTS idx_customer_credit_entries_transaction_id ON customer_credit_entries (transaction_id);
+
+-- credit can be used as a payment method
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_method_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_method_check CHECK (
+    method IN ('cash', 'transfer', 'credit')
+);
+
+-- +goose Down
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_method_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_method_check CHECK (method IN ('cash', 'transfer'));
+
+DROP TABLE IF EXISTS customer_credit_entries;
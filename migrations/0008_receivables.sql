@@ -0,0 +1,30 @@
+-- +goose Up
+
+-- default payment terms (tempo) per customer category, e.g. WHOLESALE = 30 days
+ALTER TABLE customer_categories
+ADD COLUMN IF NOT EXISTS payment_terms_days integer NOT NULL DEFAULT 0 CHECK (payment_terms_days >= 0);
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS payment_terms_days integer NOT NULL DEFAULT 0 CHECK (payment_terms_days >= 0),
+ADD COLUMN IF NOT EXISTS due_date date;
+
+-- existing transactions were all cash-on-delivery
+UPDATE transactions
+SET due_date = created_at::date
+WHERE due_date IS NULL;
+
+ALTER TABLE transactions ALTER COLUMN due_date SET NOT NULL;
+
+ALTER TABLE transactions ALTER COLUMN due_date SET DEFAULT CURRENT_DATE;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_customer_due_date ON transactions (customer_id, due_date);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_transactions_customer_due_date;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS due_date,
+DROP COLUMN IF EXISTS payment_terms_days;
+
+ALTER TABLE customer_categories DROP COLUMN IF EXISTS payment_terms_days;
@@ -0,0 +1,41 @@
+-- +goose Up
+
+-- A receipt is one amount of money received from a customer (e.g. a single bank
+-- transfer). It is allocated to one or more transactions; each allocation is a
+-- row in payments with receipt_id set, so per-transaction payment state keeps
+-- being computed from payments only. Any unallocated remainder goes to store credit.
+CREATE TABLE IF NOT EXISTS receipts (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    customer_id uuid NOT NULL REFERENCES customers (id),
+    method text NOT NULL CHECK (method IN ('cash', 'transfer')),
+    amount numeric(18, 2) NOT NULL CHECK (amount > 0),
+    currency text NOT NULL DEFAULT 'IDR',
+    received_at timestamptz NOT NULL DEFAULT now(),
+    sender_name text,
+    reference text,
+    note text,
+    unallocated_amount numeric(18, 2) NOT NULL DEFAULT 0 CHECK (unallocated_amount >= 0),
+    status text NOT NULL DEFAULT 'posted' CHECK (status IN ('posted', 'voided')),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_receipts_customer_id ON receipts (customer_id);
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS receipt_id uuid REFERENCES receipts (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payments_receipt_id ON payments (receipt_id);
+
+ALTER TABLE customer_credit_entries
+ADD COLUMN IF NOT EXISTS receipt_id uuid REFERENCES receipts (id) ON DELETE SET NULL;
+
+-- +goose Down
+
+ALTER TABLE customer_credit_entries DROP COLUMN IF EXISTS receipt_id;
+
+DROP INDEX IF EXISTS idx_payments_receipt_id;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS receipt_id;
+
+DROP TABLE IF EXISTS receipts;
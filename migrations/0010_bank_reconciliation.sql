@@ -0,0 +1,61 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS bank_statement_imports (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    bank text NOT NULL, -- bca, mandiri
+    account_no text,
+    file_name text,
+    currency text NOT NULL DEFAULT 'IDR',
+    line_count integer NOT NULL DEFAULT 0,
+    imported_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- staging table: one row per bank mutation, matched against transfer payments/receipts
+CREATE TABLE IF NOT EXISTS bank_statement_lines (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    import_id uuid NOT NULL REFERENCES bank_statement_imports (id) ON DELETE CASCADE,
+    line_no integer NOT NULL,
+    booked_at date NOT NULL,
+    description text NOT NULL,
+    reference text,
+    direction text NOT NULL CHECK (direction IN ('credit', 'debit')),
+    amount numeric(18, 2) NOT NULL CHECK (amount > 0),
+    balance numeric(18, 2),
+    -- unmatched -> suggested (auto match) -> confirmed | flagged
+    status text NOT NULL DEFAULT 'unmatched' CHECK (
+        status IN (
+            'unmatched',
+            'suggested',
+            'confirmed',
+            'flagged'
+        )
+    ),
+    payment_id uuid REFERENCES payments (id) ON DELETE SET NULL,
+    receipt_id uuid REFERENCES receipts (id) ON DELETE SET NULL,
+    match_score integer,
+    note text,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT uq_bank_statement_lines_import_line UNIQUE (import_id, line_no),
+    CONSTRAINT chk_bank_statement_lines_single_target CHECK (
+        payment_id IS NULL
+        OR receipt_id IS NULL
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_statement_lines_status ON bank_statement_lines (status);
+
+-- a payment / receipt can be reconciled against only one bank line
+CREATE UNIQUE INDEX IF NOT EXISTS uq_bank_statement_lines_confirmed_payment ON bank_statement_lines (payment_id)
+WHERE
+    status = 'confirmed';
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_bank_statement_lines_confirmed_receipt ON bank_statement_lines (receipt_id)
+WHERE
+    status = 'confirmed';
+
+-- +goose Down
+
+DROP TABLE IF EXISTS bank_statement_lines;
+
+DROP TABLE IF EXISTS bank_statement_imports;
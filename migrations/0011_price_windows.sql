@@ -0,0 +1,62 @@
+-- +goose Up
+
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- data fix: ordered by valid_from, every price ends where the next one for the same
+-- product/category starts. Rows sharing a valid_from end up with an empty window
+-- (the newest row keeps the slot, matching the previous resolution order).
+WITH ordered AS (
+    SELECT
+        id,
+        valid_to,
+        LEAD(valid_from) OVER (
+            PARTITION BY
+                product_id,
+                category_id
+            ORDER BY valid_from, created_at, id
+        ) AS next_from
+    FROM product_prices
+)
+UPDATE product_prices p
+SET
+    valid_to = o.next_from,
+    updated_at = now()
+FROM ordered o
+WHERE
+    p.id = o.id
+    AND o.next_from IS NOT NULL
+    AND (
+        o.valid_to IS NULL
+        OR o.valid_to > o.next_from
+    );
+
+ALTER TABLE product_prices
+ADD CONSTRAINT chk_product_prices_window CHECK (
+    valid_to IS NULL
+    OR valid_to >= valid_from
+);
+
+-- one price per product + category (NULL = default) at any moment
+ALTER TABLE product_prices
+ADD CONSTRAINT ex_product_prices_no_overlap EXCLUDE USING gist (
+    product_id WITH =,
+    (
+        COALESCE(
+            category_id,
+            '00000000-0000-0000-0000-000000000000'::uuid
+        )
+    ) WITH =,
+    tstzrange (valid_from, valid_to, '[)') WITH &&
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_prices_valid_from ON product_prices (valid_from);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_product_prices_valid_from;
+
+ALTER TABLE product_prices
+DROP CONSTRAINT IF EXISTS ex_product_prices_no_overlap;
+
+ALTER TABLE product_prices
+DROP CONSTRAINT IF EXISTS chk_product_prices_window;
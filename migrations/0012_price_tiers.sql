@@ -0,0 +1,51 @@
+-- +goose Up
+
+-- quantity-break pricing: a row applies once the quantity reaches min_qty.
+-- qty_basis = 'line'       -> compared with the item qty
+-- qty_basis = 'base_units' -> compared with the base units of the whole transaction
+--                             for the same stock product (qty * pack_size over all packs)
+ALTER TABLE product_prices
+ADD COLUMN IF NOT EXISTS min_qty integer NOT NULL DEFAULT 1 CHECK (min_qty >= 1),
+ADD COLUMN IF NOT EXISTS qty_basis text NOT NULL DEFAULT 'line' CHECK (
+    qty_basis IN ('line', 'base_units')
+);
+
+-- windows must not overlap per product + category + tier
+ALTER TABLE product_prices
+DROP CONSTRAINT IF EXISTS ex_product_prices_no_overlap;
+
+ALTER TABLE product_prices
+ADD CONSTRAINT ex_product_prices_no_overlap EXCLUDE USING gist (
+    product_id WITH =,
+    (
+        COALESCE(
+            category_id,
+            '00000000-0000-0000-0000-000000000000'::uuid
+        )
+    ) WITH =,
+    min_qty WITH =,
+    tstzrange (valid_from, valid_to, '[)') WITH &&
+);
+
+-- +goose Down
+
+DELETE FROM product_prices WHERE min_qty > 1;
+
+ALTER TABLE product_prices
+DROP CONSTRAINT IF EXISTS ex_product_prices_no_overlap;
+
+ALTER TABLE product_prices
+ADD CONSTRAINT ex_product_prices_no_overlap EXCLUDE USING gist (
+    product_id WITH =,
+    (
+        COALESCE(
+            category_id,
+            '00000000-0000-0000-0000-000000000000'::uuid
+        )
+    ) WITH =,
+    tstzrange (valid_from, valid_to, '[)') WITH &&
+);
+
+ALTER TABLE product_prices
+DROP COLUMN IF EXISTS qty_basis,
+DROP COLUMN IF EXISTS min_qty;
@@ -0,0 +1,130 @@
+-- +goose Up
+
+-- product category code (e.g. 'minuman'), used by promotion scopes
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category text;
+
+CREATE INDEX IF NOT EXISTS idx_products_category ON products (category);
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    code text NOT NULL UNIQUE,
+    name text NOT NULL,
+    -- percent_off: percent of eligible lines
+    -- amount_off:  fixed amount spread over eligible lines
+    -- buy_x_get_y: per eligible line, every buy_qty + get_qty units -> get_qty units free
+    kind text NOT NULL CHECK (
+        kind IN (
+            'percent_off',
+            'amount_off',
+            'buy_x_get_y'
+        )
+    ),
+    percent numeric(5, 2) CHECK (
+        percent > 0
+        AND percent <= 100
+    ),
+    amount numeric(18, 2) CHECK (amount > 0),
+    buy_qty integer CHECK (buy_qty > 0),
+    get_qty integer CHECK (get_qty > 0),
+    -- all | products | product_categories
+    scope text NOT NULL DEFAULT 'all' CHECK (
+        scope IN (
+            'all',
+            'products',
+            'product_categories'
+        )
+    ),
+    starts_at timestamptz NOT NULL,
+    ends_at timestamptz,
+    -- non-stackable promotions never share a line with another promotion
+    stackable boolean NOT NULL DEFAULT false,
+    priority integer NOT NULL DEFAULT 0, -- higher is evaluated first
+    is_active boolean NOT NULL DEFAULT true,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT chk_promotions_window CHECK (
+        ends_at IS NULL
+        OR ends_at > starts_at
+    ),
+    CONSTRAINT chk_promotions_kind_params CHECK (
+        (
+            kind = 'percent_off'
+            AND percent IS NOT NULL
+        )
+        OR (
+            kind = 'amount_off'
+            AND amount IS NOT NULL
+        )
+        OR (
+            kind = 'buy_x_get_y'
+            AND buy_qty IS NOT NULL
+            AND get_qty IS NOT NULL
+        )
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_window ON promotions (starts_at, ends_at)
+WHERE
+    is_active;
+
+CREATE TABLE IF NOT EXISTS promotion_products (
+    promotion_id uuid NOT NULL REFERENCES promotions (id) ON DELETE CASCADE,
+    product_id uuid NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    PRIMARY KEY (promotion_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS promotion_product_categories (
+    promotion_id uuid NOT NULL REFERENCES promotions (id) ON DELETE CASCADE,
+    category text NOT NULL,
+    PRIMARY KEY (promotion_id, category)
+);
+
+-- no rows = every customer is eligible
+CREATE TABLE IF NOT EXISTS promotion_customer_categories (
+    promotion_id uuid NOT NULL REFERENCES promotions (id) ON DELETE CASCADE,
+    category_id uuid NOT NULL REFERENCES customer_categories (id) ON DELETE CASCADE,
+    PRIMARY KEY (promotion_id, category_id)
+);
+
+-- one row per discounted line and promotion
+CREATE TABLE IF NOT EXISTS transaction_discounts (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    transaction_id uuid NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    transaction_item_id uuid REFERENCES transaction_items (id) ON DELETE CASCADE,
+    promotion_id uuid REFERENCES promotions (id) ON DELETE SET NULL,
+    promotion_code text NOT NULL,
+    description text NOT NULL,
+    amount numeric(18, 2) NOT NULL CHECK (amount > 0),
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_discounts_transaction_id ON transaction_discounts (transaction_id);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_discounts_promotion_id ON transaction_discounts (promotion_id);
+
+-- total_amount stays the amount due: subtotal_amount - discount_amount
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS subtotal_amount numeric(18, 2) NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS discount_amount numeric(18, 2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0);
+
+UPDATE transactions SET subtotal_amount = total_amount;
+
+-- +goose Down
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS discount_amount,
+DROP COLUMN IF EXISTS subtotal_amount;
+
+DROP TABLE IF EXISTS transaction_discounts;
+
+DROP TABLE IF EXISTS promotion_customer_categories;
+
+DROP TABLE IF EXISTS promotion_product_categories;
+
+DROP TABLE IF EXISTS promotion_products;
+
+DROP TABLE IF EXISTS promotions;
+
+DROP INDEX IF EXISTS idx_products_category;
+
+ALTER TABLE products DROP COLUMN IF EXISTS category;
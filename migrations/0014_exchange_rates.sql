@@ -0,0 +1,60 @@
+-- +goose Up
+
+-- 1 unit of from_currency = rate units of to_currency, valid from effective_at
+-- until the next rate for the same pair
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    from_currency text NOT NULL CHECK (from_currency ~ '^[A-Z]{3}$'),
+    to_currency text NOT NULL CHECK (to_currency ~ '^[A-Z]{3}$'),
+    rate numeric(20, 10) NOT NULL CHECK (rate > 0),
+    effective_at timestamptz NOT NULL DEFAULT now(),
+    source text,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT chk_exchange_rates_pair CHECK (from_currency <> to_currency),
+    CONSTRAINT uq_exchange_rates_pair_effective UNIQUE (
+        from_currency,
+        to_currency,
+        effective_at
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_lookup ON exchange_rates (
+    from_currency,
+    to_currency,
+    effective_at DESC
+);
+
+-- payments stay in the transaction currency; a payment received in another
+-- currency keeps what was tendered and the rate used to convert it
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS original_amount numeric(18, 2),
+ADD COLUMN IF NOT EXISTS original_currency text,
+ADD COLUMN IF NOT EXISTS exchange_rate numeric(20, 10),
+ADD COLUMN IF NOT EXISTS exchange_rate_id uuid REFERENCES exchange_rates (id) ON DELETE RESTRICT;
+
+ALTER TABLE payments
+ADD CONSTRAINT chk_payments_conversion CHECK (
+    (
+        original_currency IS NULL
+        AND original_amount IS NULL
+        AND exchange_rate IS NULL
+    )
+    OR (
+        original_currency IS NOT NULL
+        AND original_amount IS NOT NULL
+        AND exchange_rate IS NOT NULL
+        AND original_currency <> currency
+    )
+);
+
+-- +goose Down
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS chk_payments_conversion;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS exchange_rate_id,
+DROP COLUMN IF EXISTS exchange_rate,
+DROP COLUMN IF EXISTS original_currency,
+DROP COLUMN IF EXISTS original_amount;
+
+DROP TABLE IF EXISTS exchange_rates;
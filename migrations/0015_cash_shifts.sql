@@ -0,0 +1,64 @@
+-- +goose Up
+
+-- one cashier session: opened with a float, closed with the counted drawer
+CREATE TABLE IF NOT EXISTS cash_shifts (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    admin_id uuid NOT NULL REFERENCES admins (id),
+    status text NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    currency text NOT NULL DEFAULT 'IDR',
+    opening_float numeric(18, 2) NOT NULL DEFAULT 0 CHECK (opening_float >= 0),
+    -- filled on close
+    expected_cash numeric(18, 2),
+    counted_cash numeric(18, 2) CHECK (counted_cash >= 0),
+    variance numeric(18, 2), -- counted - expected
+    note text,
+    close_note text,
+    opened_at timestamptz NOT NULL DEFAULT now(),
+    closed_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT chk_cash_shifts_closed CHECK (
+        status = 'open'
+        OR (
+            closed_at IS NOT NULL
+            AND counted_cash IS NOT NULL
+            AND expected_cash IS NOT NULL
+            AND variance IS NOT NULL
+        )
+    )
+);
+
+-- an admin has at most one open shift
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cash_shifts_open_admin ON cash_shifts (admin_id)
+WHERE
+    status = 'open';
+
+CREATE INDEX IF NOT EXISTS idx_cash_shifts_opened_at ON cash_shifts (opened_at);
+
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS posted_by uuid REFERENCES admins (id),
+ADD COLUMN IF NOT EXISTS shift_id uuid REFERENCES cash_shifts (id);
+
+ALTER TABLE receipts
+ADD COLUMN IF NOT EXISTS posted_by uuid REFERENCES admins (id),
+ADD COLUMN IF NOT EXISTS shift_id uuid REFERENCES cash_shifts (id);
+
+CREATE INDEX IF NOT EXISTS idx_payments_shift_id ON payments (shift_id);
+
+CREATE INDEX IF NOT EXISTS idx_receipts_shift_id ON receipts (shift_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_receipts_shift_id;
+
+DROP INDEX IF EXISTS idx_payments_shift_id;
+
+ALTER TABLE receipts
+DROP COLUMN IF EXISTS shift_id,
+DROP COLUMN IF EXISTS posted_by;
+
+ALTER TABLE payments
+DROP COLUMN IF EXISTS shift_id,
+DROP COLUMN IF EXISTS posted_by;
+
+DROP TABLE IF EXISTS cash_shifts;
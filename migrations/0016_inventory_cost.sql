@@ -0,0 +1,43 @@
+-- +goose Up
+
+-- weighted-average unit cost of a stock product, in the base currency.
+-- NULL until the first goods receipt; pack products are costed through their base product.
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS avg_cost numeric(18, 4) CHECK (avg_cost >= 0);
+
+-- stock received at a purchase cost; product_id/qty/unit_cost are as received
+-- (possibly a pack), base_qty/base_unit_cost are in units of the stock product
+CREATE TABLE IF NOT EXISTS goods_receipts (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    product_id uuid NOT NULL REFERENCES products (id),
+    stock_product_id uuid NOT NULL REFERENCES products (id),
+    qty integer NOT NULL CHECK (qty > 0),
+    unit_cost numeric(18, 4) NOT NULL CHECK (unit_cost >= 0),
+    base_qty integer NOT NULL CHECK (base_qty > 0),
+    base_unit_cost numeric(18, 4) NOT NULL CHECK (base_unit_cost >= 0),
+    stock_before integer NOT NULL,
+    avg_cost_before numeric(18, 4),
+    avg_cost_after numeric(18, 4) NOT NULL,
+    reference text,
+    note text,
+    received_at timestamptz NOT NULL DEFAULT now(),
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_goods_receipts_stock_product_id ON goods_receipts (stock_product_id, received_at DESC);
+
+-- cost of goods sold, captured when stock is committed (transaction completed);
+-- NULL when the stock product had no cost yet
+ALTER TABLE transaction_items
+ADD COLUMN IF NOT EXISTS unit_cost numeric(18, 4),
+ADD COLUMN IF NOT EXISTS cogs_amount numeric(18, 2);
+
+-- +goose Down
+
+ALTER TABLE transaction_items
+DROP COLUMN IF EXISTS cogs_amount,
+DROP COLUMN IF EXISTS unit_cost;
+
+DROP TABLE IF EXISTS goods_receipts;
+
+ALTER TABLE products DROP COLUMN IF EXISTS avg_cost;
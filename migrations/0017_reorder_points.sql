@@ -0,0 +1,31 @@
+-- +goose Up
+
+-- per stock product: warn when available (on_hand - reserved) drops to
+-- reorder_point, and suggest buying at least reorder_qty
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS reorder_point integer CHECK (reorder_point >= 0),
+ADD COLUMN IF NOT EXISTS reorder_qty integer CHECK (reorder_qty > 0);
+
+-- written in the same DB transaction as the stock change that crossed the
+-- threshold: low_stock when available falls to/below it, restocked when back above
+CREATE TABLE IF NOT EXISTS stock_events (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    product_id uuid NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    kind text NOT NULL CHECK (kind IN ('low_stock', 'restocked')),
+    available_before integer NOT NULL,
+    available_after integer NOT NULL,
+    reorder_point integer NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_events_created_at ON stock_events (created_at);
+
+CREATE INDEX IF NOT EXISTS idx_stock_events_product_id ON stock_events (product_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS stock_events;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS reorder_qty,
+DROP COLUMN IF EXISTS reorder_point;
@@ -0,0 +1,76 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS suppliers (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    code text NOT NULL UNIQUE,
+    name text NOT NULL,
+    contact_name text,
+    phone text,
+    email text,
+    address text,
+    note text,
+    is_active boolean NOT NULL DEFAULT true,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- costs are in the base currency, the one inventory is valued in
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    supplier_id uuid NOT NULL REFERENCES suppliers (id),
+    status text NOT NULL DEFAULT 'draft' CHECK (
+        status IN (
+            'draft',
+            'ordered',
+            'partially_received',
+            'received',
+            'cancelled'
+        )
+    ),
+    reference text, -- supplier-facing PO number
+    expected_at date,
+    note text,
+    total_amount numeric(18, 2) NOT NULL DEFAULT 0,
+    ordered_at timestamptz,
+    received_at timestamptz, -- when fully received
+    cancelled_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier_id ON purchase_orders (supplier_id);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_status ON purchase_orders (status);
+
+-- qty/unit_cost are per product as ordered (possibly a pack)
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    purchase_order_id uuid NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    product_id uuid NOT NULL REFERENCES products (id),
+    qty integer NOT NULL CHECK (qty > 0),
+    unit_cost numeric(18, 4) NOT NULL CHECK (unit_cost >= 0),
+    line_total numeric(18, 2) NOT NULL CHECK (line_total >= 0),
+    received_qty integer NOT NULL DEFAULT 0 CHECK (received_qty >= 0),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT chk_purchase_order_lines_received CHECK (received_qty <= qty)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_po_id ON purchase_order_lines (purchase_order_id);
+
+ALTER TABLE goods_receipts
+ADD COLUMN IF NOT EXISTS purchase_order_line_id uuid REFERENCES purchase_order_lines (id);
+
+CREATE INDEX IF NOT EXISTS idx_goods_receipts_po_line_id ON goods_receipts (purchase_order_line_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_goods_receipts_po_line_id;
+
+ALTER TABLE goods_receipts DROP COLUMN IF EXISTS purchase_order_line_id;
+
+DROP TABLE IF EXISTS purchase_order_lines;
+
+DROP TABLE IF EXISTS purchase_orders;
+
+DROP TABLE IF EXISTS suppliers;
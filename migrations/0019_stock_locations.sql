@@ -0,0 +1,142 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS locations (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    code text NOT NULL UNIQUE,
+    name text NOT NULL,
+    address text,
+    is_default boolean NOT NULL DEFAULT false,
+    is_active boolean NOT NULL DEFAULT true,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- exactly one default location: where unplaced stock and transactions go
+CREATE UNIQUE INDEX IF NOT EXISTS uq_locations_default ON locations (is_default)
+WHERE
+    is_default;
+
+INSERT INTO
+    locations (code, name, is_default)
+VALUES ('MAIN', 'Main store', true)
+ON CONFLICT (code) DO NOTHING;
+
+-- per-location stock of stock products (base units); products.stock_on_hand
+-- and products.stock_reserved stay as the totals across locations
+CREATE TABLE IF NOT EXISTS stock_levels (
+    product_id uuid NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    location_id uuid NOT NULL REFERENCES locations (id),
+    on_hand integer NOT NULL DEFAULT 0 CHECK (on_hand >= 0),
+    reserved integer NOT NULL DEFAULT 0 CHECK (reserved >= 0),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (product_id, location_id),
+    CONSTRAINT chk_stock_levels_reserved_le_on_hand CHECK (reserved <= on_hand)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_levels_location_id ON stock_levels (location_id);
+
+INSERT INTO
+    stock_levels (
+        product_id,
+        location_id,
+        on_hand,
+        reserved
+    )
+SELECT p.id, l.id, p.stock_on_hand, p.stock_reserved
+FROM products p, locations l
+WHERE
+    l.is_default
+    AND p.base_product_id IS NULL
+ON CONFLICT (product_id, location_id) DO NOTHING;
+
+-- the location a transaction reserves and ships stock from
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS location_id uuid REFERENCES locations (id);
+
+UPDATE transactions
+SET
+    location_id = (
+        SELECT id
+        FROM locations
+        WHERE
+            is_default
+    )
+WHERE
+    location_id IS NULL;
+
+ALTER TABLE transactions ALTER COLUMN location_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_location_id ON transactions (location_id);
+
+ALTER TABLE goods_receipts
+ADD COLUMN IF NOT EXISTS location_id uuid REFERENCES locations (id);
+
+UPDATE goods_receipts
+SET
+    location_id = (
+        SELECT id
+        FROM locations
+        WHERE
+            is_default
+    )
+WHERE
+    location_id IS NULL;
+
+ALTER TABLE goods_receipts ALTER COLUMN location_id SET NOT NULL;
+
+-- where the order is delivered; NULL = default location
+ALTER TABLE purchase_orders
+ADD COLUMN IF NOT EXISTS location_id uuid REFERENCES locations (id);
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    from_location_id uuid NOT NULL REFERENCES locations (id),
+    to_location_id uuid NOT NULL REFERENCES locations (id),
+    status text NOT NULL DEFAULT 'draft' CHECK (
+        status IN (
+            'draft',
+            'completed',
+            'cancelled'
+        )
+    ),
+    reference text,
+    note text,
+    created_by uuid REFERENCES admins (id),
+    completed_at timestamptz,
+    cancelled_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT chk_stock_transfers_locations CHECK (
+        from_location_id <> to_location_id
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_status ON stock_transfers (status);
+
+-- qty is in base units of a stock product (no packs)
+CREATE TABLE IF NOT EXISTS stock_transfer_lines (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    stock_transfer_id uuid NOT NULL REFERENCES stock_transfers (id) ON DELETE CASCADE,
+    product_id uuid NOT NULL REFERENCES products (id),
+    qty integer NOT NULL CHECK (qty > 0),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (stock_transfer_id, product_id)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS stock_transfer_lines;
+
+DROP TABLE IF EXISTS stock_transfers;
+
+ALTER TABLE purchase_orders DROP COLUMN IF EXISTS location_id;
+
+ALTER TABLE goods_receipts DROP COLUMN IF EXISTS location_id;
+
+DROP INDEX IF EXISTS idx_transactions_location_id;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS location_id;
+
+DROP TABLE IF EXISTS stock_levels;
+
+DROP TABLE IF EXISTS locations;
@@ -0,0 +1,64 @@
+-- +goose Up
+
+-- received stock with a lot number and/or expiry date; stock received without
+-- either stays untracked, so per (product, location) the lots' qty_on_hand
+-- sums to at most stock_levels.on_hand and the rest is untracked
+CREATE TABLE IF NOT EXISTS stock_lots (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    product_id uuid NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    location_id uuid NOT NULL REFERENCES locations (id),
+    lot_number text,
+    expires_at date,
+    qty_received integer NOT NULL CHECK (qty_received > 0),
+    qty_on_hand integer NOT NULL CHECK (qty_on_hand >= 0),
+    goods_receipt_id uuid REFERENCES goods_receipts (id),
+    source_lot_id uuid REFERENCES stock_lots (id), -- set when moved in by a transfer
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT chk_stock_lots_on_hand_le_received CHECK (qty_on_hand <= qty_received),
+    CONSTRAINT chk_stock_lots_tracked CHECK (
+        lot_number IS NOT NULL
+        OR expires_at IS NOT NULL
+    )
+);
+
+-- FEFO picking order
+CREATE INDEX IF NOT EXISTS idx_stock_lots_fefo ON stock_lots (
+    product_id,
+    location_id,
+    expires_at,
+    created_at
+)
+WHERE
+    qty_on_hand > 0;
+
+CREATE INDEX IF NOT EXISTS idx_stock_lots_expires_at ON stock_lots (expires_at)
+WHERE
+    qty_on_hand > 0;
+
+ALTER TABLE goods_receipts
+ADD COLUMN IF NOT EXISTS lot_number text,
+ADD COLUMN IF NOT EXISTS expires_at date;
+
+-- which lots a completed transaction's stock was taken from (base units)
+CREATE TABLE IF NOT EXISTS stock_lot_allocations (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    transaction_id uuid NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    lot_id uuid NOT NULL REFERENCES stock_lots (id),
+    qty integer NOT NULL CHECK (qty > 0),
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_lot_allocations_transaction_id ON stock_lot_allocations (transaction_id);
+
+CREATE INDEX IF NOT EXISTS idx_stock_lot_allocations_lot_id ON stock_lot_allocations (lot_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS stock_lot_allocations;
+
+ALTER TABLE goods_receipts
+DROP COLUMN IF EXISTS expires_at,
+DROP COLUMN IF EXISTS lot_number;
+
+DROP TABLE IF EXISTS stock_lots;
@@ -0,0 +1,77 @@
+-- +goose Up
+
+CREATE TABLE IF NOT EXISTS stock_takes (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    location_id uuid NOT NULL REFERENCES locations (id),
+    status text NOT NULL DEFAULT 'open' CHECK (
+        status IN (
+            'open',
+            'approved',
+            'cancelled'
+        )
+    ),
+    note text,
+    created_by uuid REFERENCES admins (id),
+    approved_by uuid REFERENCES admins (id),
+    approved_at timestamptz,
+    cancelled_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_takes_location_status ON stock_takes (location_id, status);
+
+-- one open session per location, so counts cannot be posted twice
+CREATE UNIQUE INDEX IF NOT EXISTS uq_stock_takes_open_location ON stock_takes (location_id)
+WHERE
+    status = 'open';
+
+-- expected_qty is the location's on_hand when the session was opened
+CREATE TABLE IF NOT EXISTS stock_take_lines (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    stock_take_id uuid NOT NULL REFERENCES stock_takes (id) ON DELETE CASCADE,
+    product_id uuid NOT NULL REFERENCES products (id),
+    expected_qty integer NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (stock_take_id, product_id)
+);
+
+-- one count per counter and line; counters split the area, so a line's
+-- counted quantity is the sum over its counters
+CREATE TABLE IF NOT EXISTS stock_take_counts (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    stock_take_line_id uuid NOT NULL REFERENCES stock_take_lines (id) ON DELETE CASCADE,
+    counter_id uuid NOT NULL REFERENCES admins (id),
+    qty integer NOT NULL CHECK (qty >= 0),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (stock_take_line_id, counter_id)
+);
+
+-- ledger of stock corrections that are neither receipts nor sales (base units)
+CREATE TABLE IF NOT EXISTS stock_adjustments (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    product_id uuid NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    location_id uuid NOT NULL REFERENCES locations (id),
+    qty_delta integer NOT NULL CHECK (qty_delta <> 0),
+    on_hand_before integer NOT NULL,
+    on_hand_after integer NOT NULL,
+    reason text NOT NULL CHECK (reason IN ('stock_take')),
+    stock_take_id uuid REFERENCES stock_takes (id),
+    created_by uuid REFERENCES admins (id),
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_product_id ON stock_adjustments (product_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_stock_take_id ON stock_adjustments (stock_take_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS stock_adjustments;
+
+DROP TABLE IF EXISTS stock_take_counts;
+
+DROP TABLE IF EXISTS stock_take_lines;
+
+DROP TABLE IF EXISTS stock_takes;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+-- when the transaction's stock was reserved (draft -> pending); pending
+-- transactions older than RESERVATION_TTL_MINUTES are cancelled by the worker
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS reserved_at timestamptz;
+
+UPDATE transactions
+SET
+    reserved_at = updated_at
+WHERE
+    status = 'pending'
+    AND reserved_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_pending_reserved_at ON transactions (reserved_at)
+WHERE
+    status = 'pending';
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_transactions_pending_reserved_at;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS reserved_at;
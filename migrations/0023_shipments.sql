@@ -0,0 +1,107 @@
+-- +goose Up
+
+-- delivery progress, apart from the order status:
+-- unfulfilled -> partially_shipped -> shipped -> delivered
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS fulfillment_status text NOT NULL DEFAULT 'unfulfilled' CHECK (
+    fulfillment_status IN (
+        'unfulfilled',
+        'partially_shipped',
+        'shipped',
+        'delivered'
+    )
+);
+
+-- stock of completed transactions was committed in one go on fulfill
+UPDATE transactions
+SET
+    fulfillment_status = 'delivered'
+WHERE
+    status = 'completed';
+
+ALTER TABLE transaction_items
+ADD COLUMN IF NOT EXISTS qty_shipped integer NOT NULL DEFAULT 0 CHECK (qty_shipped >= 0);
+
+UPDATE transaction_items ti
+SET
+    qty_shipped = ti.qty
+FROM transactions t
+WHERE
+    t.id = ti.transaction_id
+    AND t.status = 'completed';
+
+ALTER TABLE transaction_items
+ADD CONSTRAINT chk_transaction_items_qty_shipped CHECK (qty_shipped <= qty);
+
+CREATE SEQUENCE IF NOT EXISTS delivery_note_seq;
+
+-- one delivery of part or all of a transaction; stock is committed when it
+-- ships. ship_to_* is the customer's address as it was when the shipment was
+-- created, so the delivery note does not change afterwards.
+CREATE TABLE IF NOT EXISTS shipments (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    transaction_id uuid NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    delivery_note_number text NOT NULL UNIQUE DEFAULT (
+        'DN-' || lpad(
+            nextval('delivery_note_seq')::text,
+            6,
+            '0'
+        )
+    ),
+    status text NOT NULL DEFAULT 'picking' CHECK (
+        status IN (
+            'picking',
+            'packed',
+            'shipped',
+            'delivered',
+            'cancelled'
+        )
+    ),
+    address_id uuid REFERENCES customer_addresses (id) ON DELETE SET NULL,
+    ship_to_name text NOT NULL,
+    ship_to_phone text,
+    ship_to_line1 text NOT NULL,
+    ship_to_line2 text,
+    ship_to_city text,
+    ship_to_province text,
+    ship_to_postal_code text,
+    ship_to_country text NOT NULL,
+    carrier text,
+    tracking_number text,
+    note text,
+    created_by uuid REFERENCES admins (id),
+    packed_at timestamptz,
+    shipped_at timestamptz,
+    delivered_at timestamptz,
+    cancelled_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipments_transaction_id ON shipments (transaction_id);
+
+CREATE INDEX IF NOT EXISTS idx_shipments_status ON shipments (status);
+
+CREATE TABLE IF NOT EXISTS shipment_lines (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    shipment_id uuid NOT NULL REFERENCES shipments (id) ON DELETE CASCADE,
+    transaction_item_id uuid NOT NULL REFERENCES transaction_items (id) ON DELETE CASCADE,
+    qty integer NOT NULL CHECK (qty > 0),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (shipment_id, transaction_item_id)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS shipment_lines;
+
+DROP TABLE IF EXISTS shipments;
+
+DROP SEQUENCE IF EXISTS delivery_note_seq;
+
+ALTER TABLE transaction_items
+DROP CONSTRAINT IF EXISTS chk_transaction_items_qty_shipped;
+
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS qty_shipped;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS fulfillment_status;
@@ -0,0 +1,34 @@
+-- +goose Up
+
+-- timeline of a transaction: status and fulfillment changes, payments and
+-- shipments. actor_id is the admin (JWT sub) behind it; NULL for background
+-- jobs such as reservation expiry.
+CREATE TABLE IF NOT EXISTS transaction_events (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    transaction_id uuid NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    kind text NOT NULL CHECK (
+        kind IN (
+            'created',
+            'status_changed',
+            'fulfillment_changed',
+            'payment_posted',
+            'shipment_created',
+            'shipment_packed',
+            'shipment_shipped',
+            'shipment_delivered',
+            'shipment_cancelled',
+            'reservation_expired'
+        )
+    ),
+    from_value text,
+    to_value text,
+    actor_id uuid REFERENCES admins (id) ON DELETE SET NULL,
+    data jsonb,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_events_transaction_id ON transaction_events (transaction_id, created_at);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS transaction_events;
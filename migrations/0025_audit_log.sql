@@ -0,0 +1,33 @@
+-- +goose Up
+
+-- every admin mutation: who, what, on which entity and the before/after
+-- snapshots with the changed fields. Customer, product and price writes are
+-- logged in their own DB transaction; other endpoints are logged by the HTTP
+-- middleware once the handler succeeded (before/changes are then NULL and
+-- after holds the request body).
+CREATE TABLE IF NOT EXISTS audit_log (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid (),
+    actor_id uuid REFERENCES admins (id) ON DELETE SET NULL,
+    action text NOT NULL,
+    entity text NOT NULL,
+    entity_id text,
+    before jsonb,
+    after jsonb,
+    changes jsonb,
+    request_id text,
+    method text,
+    path text,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity, entity_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_request ON audit_log (request_id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at DESC);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS audit_log;
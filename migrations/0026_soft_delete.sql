@@ -0,0 +1,29 @@
+-- +goose Up
+
+-- archived (soft-deleted) customers and products keep their history but are
+-- hidden from listings and cannot be used on new transactions.
+ALTER TABLE customers
+ADD COLUMN IF NOT EXISTS deleted_at timestamptz NULL;
+
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS deleted_at timestamptz NULL;
+
+CREATE INDEX IF NOT EXISTS idx_customers_active ON customers (created_at DESC)
+WHERE
+    deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_active ON products (created_at DESC)
+WHERE
+    deleted_at IS NULL;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_products_active;
+
+DROP INDEX IF EXISTS idx_customers_active;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE customers
+DROP COLUMN IF EXISTS deleted_at;
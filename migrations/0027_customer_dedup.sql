@@ -0,0 +1,45 @@
+-- +goose Up
+
+-- walk-in customers may be known by phone only
+ALTER TABLE customers
+ALTER COLUMN email
+DROP NOT NULL;
+
+-- phones are stored in E.164; local Indonesian numbers (08xx, 8xx, 62xx)
+-- become +62xx
+UPDATE customers
+SET
+    phone = CASE
+        WHEN phone ~ '^\s*\+' THEN '+' || regexp_replace(phone, '\D', '', 'g')
+        WHEN regexp_replace(phone, '\D', '', 'g') ~ '^62' THEN '+' || regexp_replace(phone, '\D', '', 'g')
+        WHEN regexp_replace(phone, '\D', '', 'g') ~ '^0' THEN '+62' || substr(regexp_replace(phone, '\D', '', 'g'), 2)
+        WHEN regexp_replace(phone, '\D', '', 'g') ~ '^8' THEN '+62' || regexp_replace(phone, '\D', '', 'g')
+        ELSE phone
+    END
+WHERE
+    phone IS NOT NULL;
+
+UPDATE customers SET phone = NULL WHERE phone IN ('', '+');
+
+CREATE INDEX IF NOT EXISTS idx_customers_phone ON customers (phone);
+
+-- a merged duplicate is archived and points at the customer that absorbed it
+ALTER TABLE customers
+ADD COLUMN IF NOT EXISTS merged_into_id uuid NULL REFERENCES customers (id);
+
+-- +goose Down
+
+ALTER TABLE customers
+DROP COLUMN IF EXISTS merged_into_id;
+
+DROP INDEX IF EXISTS idx_customers_phone;
+
+UPDATE customers
+SET
+    email = id::text || '@no-email.invalid'
+WHERE
+    email IS NULL;
+
+ALTER TABLE customers
+ALTER COLUMN email
+SET NOT NULL;
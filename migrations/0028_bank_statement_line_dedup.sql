@@ -0,0 +1,8 @@
+-- +goose Up
+
+-- re-imports look up earlier lines by date and amount before inserting
+CREATE INDEX IF NOT EXISTS idx_bank_statement_lines_booked_amount ON bank_statement_lines (booked_at, amount);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_bank_statement_lines_booked_amount;
@@ -0,0 +1,19 @@
+-- +goose Up
+
+-- events written in one DB transaction share created_at (now()); seq keeps
+-- them in the order they were recorded
+ALTER TABLE transaction_events
+ADD COLUMN IF NOT EXISTS seq bigint GENERATED ALWAYS AS IDENTITY;
+
+DROP INDEX IF EXISTS idx_transaction_events_transaction_id;
+
+CREATE INDEX IF NOT EXISTS idx_transaction_events_transaction_id ON transaction_events (transaction_id, created_at, seq);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_transaction_events_transaction_id;
+
+CREATE INDEX IF NOT EXISTS idx_transaction_events_transaction_id ON transaction_events (transaction_id, created_at);
+
+ALTER TABLE transaction_events
+DROP COLUMN IF EXISTS seq;
@@ -0,0 +1,22 @@
+-- +goose Up
+
+-- a fixed amount off is money in one currency; it only applies to
+-- transactions in that currency
+ALTER TABLE promotions
+ADD COLUMN IF NOT EXISTS currency text;
+
+UPDATE promotions SET currency = 'IDR' WHERE kind = 'amount_off' AND currency IS NULL;
+
+ALTER TABLE promotions
+ADD CONSTRAINT chk_promotions_amount_currency CHECK (
+    kind <> 'amount_off'
+    OR currency IS NOT NULL
+);
+
+-- +goose Down
+
+ALTER TABLE promotions
+DROP CONSTRAINT IF EXISTS chk_promotions_amount_currency;
+
+ALTER TABLE promotions
+DROP COLUMN IF EXISTS currency;